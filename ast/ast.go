@@ -3,11 +3,13 @@ package ast
 import (
 	"bytes"
 	"curryLang/token"
+	"strings"
 )
 
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Token // token errors of the node are reported at
 }
 
 type Statement interface {
@@ -20,19 +22,26 @@ type Expression interface {
 	expressionNode()
 }
 
+// LetStatement declares the variable Name, destructuring lets declare the variables of Pattern instead
 type LetStatement struct {
-	Token token.Token // the token.LET token
-	Name  *Identifier
-	Value Expression
+	Token   token.Token // the token.LET token
+	Name    *Identifier
+	Pattern Pattern
+	Value   Expression
 }
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() token.Token     { return ls.Token }
 
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+	if ls.Pattern != nil {
+		out.WriteString(ls.Pattern.String())
+	} else {
+		out.WriteString(ls.Name.String())
+	}
 	out.WriteString(" = ")
 	if ls.Value != nil {
 		out.WriteString(ls.Value.String())
@@ -49,6 +58,7 @@ type AssignmentStatement struct {
 
 func (ls *AssignmentStatement) statementNode()       {}
 func (ls *AssignmentStatement) TokenLiteral() string { return "<assignment>" }
+func (ls *AssignmentStatement) Pos() token.Token     { return ls.Name.Token }
 
 func (ls *AssignmentStatement) String() string {
 	var out bytes.Buffer
@@ -70,6 +80,7 @@ type WhileStatement struct {
 
 func (ls *WhileStatement) statementNode()       {}
 func (ls *WhileStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *WhileStatement) Pos() token.Token     { return ls.Token }
 
 func (ls *WhileStatement) String() string {
 	var out bytes.Buffer
@@ -79,6 +90,29 @@ func (ls *WhileStatement) String() string {
 	return out.String()
 }
 
+// ForInStatement runs the body for every element of Iterable, the value of the element is bound to Variable.
+// Key is bound to the index of the element or the field name for instances, it is nil if the loop has one variable.
+type ForInStatement struct {
+	Token    token.Token // the token.FOR token
+	Key      *Identifier
+	Variable *Identifier
+	Iterable Expression
+	Body     []Statement
+}
+
+func (fs *ForInStatement) statementNode()       {}
+func (fs *ForInStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForInStatement) Pos() token.Token     { return fs.Token }
+
+func (fs *ForInStatement) String() string {
+	variables := fs.Variable.String()
+	if fs.Key != nil {
+		variables = fs.Key.String() + ", " + variables
+	}
+
+	return fs.TokenLiteral() + " " + variables + " in " + fs.Iterable.String()
+}
+
 type PackageStatement struct {
 	Token      token.Token // the token.PACKAGE token
 	Identifier *Identifier
@@ -86,6 +120,7 @@ type PackageStatement struct {
 
 func (ls *PackageStatement) statementNode()       {}
 func (ls *PackageStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *PackageStatement) Pos() token.Token     { return ls.Token }
 
 func (ls *PackageStatement) String() string {
 	var out bytes.Buffer
@@ -102,6 +137,7 @@ type ImportStatement struct {
 
 func (ls *ImportStatement) statementNode()       {}
 func (ls *ImportStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *ImportStatement) Pos() token.Token     { return ls.Token }
 
 func (ls *ImportStatement) String() string {
 	var out bytes.Buffer
@@ -114,6 +150,231 @@ func (ls *ImportStatement) String() string {
 	return out.String()
 }
 
+type StructStatement struct {
+	Token  token.Token // the token.STRUCT token
+	Name   *Identifier
+	Fields []*Identifier
+}
+
+func (ss *StructStatement) statementNode()       {}
+func (ss *StructStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *StructStatement) Pos() token.Token     { return ss.Token }
+
+func (ss *StructStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(ss.TokenLiteral() + " ")
+	out.WriteString(ss.Name.String())
+	out.WriteString(" { ")
+
+	for i, field := range ss.Fields {
+		out.WriteString(field.String())
+		if i < len(ss.Fields)-1 {
+			out.WriteString(", ")
+		}
+	}
+
+	out.WriteString(" }")
+	return out.String()
+}
+
+type TraitStatement struct {
+	Token           token.Token // the token.TRAIT token
+	Name            *Identifier
+	RequiredMethods []*FunctionExpression // signatures without a body
+	DefaultMethods  []*FunctionExpression
+}
+
+func (ts *TraitStatement) statementNode()       {}
+func (ts *TraitStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *TraitStatement) Pos() token.Token     { return ts.Token }
+
+func (ts *TraitStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(ts.TokenLiteral() + " ")
+	out.WriteString(ts.Name.String())
+	out.WriteString(" { ")
+
+	for _, method := range ts.RequiredMethods {
+		out.WriteString("fn " + method.Name + method.ParametersString() + " ")
+	}
+
+	for _, method := range ts.DefaultMethods {
+		out.WriteString("fn " + method.Name + method.ParametersString() + " { " + method.BodyString() + " } ")
+	}
+
+	out.WriteString("}")
+	return out.String()
+}
+
+type ImplStatement struct {
+	Token   token.Token // the token.IMPL token
+	Trait   *Identifier // nil for methods which don't belong to a trait
+	Struct  *Identifier
+	Methods []*FunctionExpression
+}
+
+func (is *ImplStatement) statementNode()       {}
+func (is *ImplStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *ImplStatement) Pos() token.Token     { return is.Token }
+
+func (is *ImplStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(is.TokenLiteral() + " ")
+	if is.Trait != nil {
+		out.WriteString(is.Trait.String())
+		out.WriteString(" for ")
+	}
+	out.WriteString(is.Struct.String())
+	out.WriteString(" { ")
+
+	for _, method := range is.Methods {
+		out.WriteString("fn " + method.Name + method.ParametersString() + " { " + method.BodyString() + " } ")
+	}
+
+	out.WriteString("}")
+	return out.String()
+}
+
+type FieldAssignmentStatement struct {
+	Target *DotAccessExpression
+	Value  Expression
+}
+
+func (fs *FieldAssignmentStatement) statementNode()       {}
+func (fs *FieldAssignmentStatement) TokenLiteral() string { return "<field-assignment>" }
+func (fs *FieldAssignmentStatement) Pos() token.Token     { return fs.Target.Token }
+
+func (fs *FieldAssignmentStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(fs.Target.String())
+	out.WriteString(" = ")
+	if fs.Value != nil {
+		out.WriteString(fs.Value.String())
+	}
+
+	out.WriteString(";")
+	return out.String()
+}
+
+// TryStatement runs Handler if an error is raised in Body, the error is bound to Parameter if it is set
+type TryStatement struct {
+	Token     token.Token // the token.TRY token
+	Body      []Statement
+	Parameter *Identifier
+	Handler   []Statement
+}
+
+func (ts *TryStatement) statementNode()       {}
+func (ts *TryStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *TryStatement) Pos() token.Token     { return ts.Token }
+
+func (ts *TryStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("try { ")
+	for _, statement := range ts.Body {
+		out.WriteString(statement.String())
+	}
+	out.WriteString(" } catch")
+	if ts.Parameter != nil {
+		out.WriteString(" (" + ts.Parameter.String() + ")")
+	}
+	out.WriteString(" { ")
+	for _, statement := range ts.Handler {
+		out.WriteString(statement.String())
+	}
+	out.WriteString(" }")
+	return out.String()
+}
+
+type ThrowStatement struct {
+	Token token.Token // the token.THROW token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) Pos() token.Token     { return ts.Token }
+
+func (ts *ThrowStatement) String() string {
+	return ts.TokenLiteral() + " " + ts.Value.String() + ";"
+}
+
+// YieldStatement passes a value to the code iterating the generator, functions containing it return generators
+type YieldStatement struct {
+	Token token.Token // the token.YIELD token
+	Value Expression
+}
+
+func (ys *YieldStatement) statementNode()       {}
+func (ys *YieldStatement) TokenLiteral() string { return ys.Token.Literal }
+func (ys *YieldStatement) Pos() token.Token     { return ys.Token }
+
+func (ys *YieldStatement) String() string {
+	return ys.TokenLiteral() + " " + ys.Value.String() + ";"
+}
+
+// SelectCase sends Value to Channel or receives from it, the received value is bound to Name if it is set
+type SelectCase struct {
+	Token   token.Token // the token.CASE token
+	Name    *Identifier
+	Channel Expression
+	Send    bool
+	Value   Expression // nil for receives
+	Body    []Statement
+}
+
+func (sc *SelectCase) String() string {
+	var out bytes.Buffer
+	out.WriteString("case ")
+	if sc.Name != nil {
+		out.WriteString(sc.Name.String() + " = ")
+	}
+
+	out.WriteString(sc.Channel.String())
+	if sc.Send {
+		out.WriteString(".send(" + sc.Value.String() + ")")
+	} else {
+		out.WriteString(".receive()")
+	}
+
+	out.WriteString(" { ")
+	for _, statement := range sc.Body {
+		out.WriteString(statement.String())
+	}
+	out.WriteString(" }")
+	return out.String()
+}
+
+// SelectStatement waits until one of its cases can send or receive and runs the body of this case,
+// the Default body runs instead of waiting if HasDefault is set
+type SelectStatement struct {
+	Token      token.Token // the token.SELECT token
+	Cases      []*SelectCase
+	HasDefault bool
+	Default    []Statement
+}
+
+func (ss *SelectStatement) statementNode()       {}
+func (ss *SelectStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *SelectStatement) Pos() token.Token     { return ss.Token }
+
+func (ss *SelectStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("select { ")
+	for _, selectCase := range ss.Cases {
+		out.WriteString(selectCase.String() + " ")
+	}
+	if ss.HasDefault {
+		out.WriteString("default { ")
+		for _, statement := range ss.Default {
+			out.WriteString(statement.String())
+		}
+		out.WriteString(" } ")
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
 type ReturnStatement struct {
 	Token       token.Token
 	ReturnValue Expression
@@ -121,6 +382,7 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Token     { return rs.Token }
 
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
@@ -139,6 +401,7 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Token     { return es.Token }
 
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
@@ -156,6 +419,7 @@ type PrefixExpression struct {
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() token.Token     { return pe.Token }
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -174,6 +438,7 @@ type InfixExpression struct {
 
 func (pe *InfixExpression) expressionNode()      {}
 func (pe *InfixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *InfixExpression) Pos() token.Token     { return pe.Token }
 func (pe *InfixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -191,6 +456,7 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Token     { return i.Token }
 
 func (i *Identifier) String() string { return i.Value }
 
@@ -201,6 +467,7 @@ type IntegerLiteral struct {
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Token     { return il.Token }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
 type Boolean struct {
@@ -210,6 +477,7 @@ type Boolean struct {
 
 func (il *Boolean) expressionNode()      {}
 func (il *Boolean) TokenLiteral() string { return il.Token.Literal }
+func (il *Boolean) Pos() token.Token     { return il.Token }
 func (il *Boolean) String() string       { return il.Token.Literal }
 
 type StringLiteral struct {
@@ -219,6 +487,7 @@ type StringLiteral struct {
 
 func (str *StringLiteral) expressionNode()      {}
 func (str *StringLiteral) TokenLiteral() string { return str.Token.Literal }
+func (str *StringLiteral) Pos() token.Token     { return str.Token }
 func (str *StringLiteral) String() string       { return str.Value }
 
 type ListExpression struct {
@@ -228,6 +497,7 @@ type ListExpression struct {
 
 func (list *ListExpression) expressionNode()      {}
 func (list *ListExpression) TokenLiteral() string { return list.Token.Literal }
+func (list *ListExpression) Pos() token.Token     { return list.Token }
 func (list *ListExpression) String() string {
 	var out bytes.Buffer
 
@@ -245,20 +515,67 @@ func (list *ListExpression) String() string {
 	return out.String()
 }
 
+type StructField struct {
+	Name  *Identifier
+	Value Expression
+}
+
+type StructExpression struct {
+	Token  token.Token // the { token
+	Name   *Identifier
+	Fields []StructField
+}
+
+func (se *StructExpression) expressionNode()      {}
+func (se *StructExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *StructExpression) Pos() token.Token     { return se.Token }
+func (se *StructExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(se.Name.String())
+	out.WriteString("{")
+
+	for i, field := range se.Fields {
+		out.WriteString(field.Name.String())
+		out.WriteString(": ")
+		out.WriteString(field.Value.String())
+		if i < len(se.Fields)-1 {
+			out.WriteString(", ")
+		}
+	}
+
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// IndexAccessExpression accesses the element at the index Value of Source. Slices like list[1:3] access the elements
+// from Value up to End instead, both of them are nil if they are omitted.
 type IndexAccessExpression struct {
 	Token  token.Token
 	Source Expression
 	Value  Expression
+	End    Expression
+	Slice  bool
 }
 
 func (index *IndexAccessExpression) expressionNode()      {}
 func (index *IndexAccessExpression) TokenLiteral() string { return index.Token.Literal }
+func (index *IndexAccessExpression) Pos() token.Token     { return index.Token }
 func (index *IndexAccessExpression) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(index.Source.String())
 	out.WriteString("[")
-	out.WriteString(index.Value.String())
+	if index.Value != nil {
+		out.WriteString(index.Value.String())
+	}
+	if index.Slice {
+		out.WriteString(":")
+		if index.End != nil {
+			out.WriteString(index.End.String())
+		}
+	}
 	out.WriteString("]")
 
 	return out.String()
@@ -272,6 +589,7 @@ type DotAccessExpression struct {
 
 func (access *DotAccessExpression) expressionNode()      {}
 func (access *DotAccessExpression) TokenLiteral() string { return access.Token.Literal }
+func (access *DotAccessExpression) Pos() token.Token     { return access.Token }
 func (access *DotAccessExpression) String() string {
 	var out bytes.Buffer
 
@@ -291,6 +609,7 @@ type IfElseExpression struct {
 
 func (il *IfElseExpression) expressionNode()      {}
 func (il *IfElseExpression) TokenLiteral() string { return il.Token.Literal }
+func (il *IfElseExpression) Pos() token.Token     { return il.Token }
 func (il *IfElseExpression) String() string       { return il.Token.Literal }
 func (il *IfElseExpression) ConsequenceString() string {
 	var out bytes.Buffer
@@ -307,12 +626,30 @@ func (il *IfElseExpression) AlternativeString() string {
 	return out.String()
 }
 
+// Parameter is a parameter of a function, destructuring parameters have a Pattern and no Name.
+// Default is nil for parameters without default value, a variadic parameter collects the remaining arguments.
 type Parameter struct {
-	Name string
+	Name     string
+	Pattern  Pattern
+	Default  Expression
+	Variadic bool
 }
 
 func (p Parameter) String() string {
-	return p.Name
+	name := p.Name
+	if p.Pattern != nil {
+		name = p.Pattern.String()
+	}
+
+	if p.Variadic {
+		return "..." + name
+	}
+
+	if p.Default != nil {
+		return name + " = " + p.Default.String()
+	}
+
+	return name
 }
 
 type FunctionExpression struct {
@@ -324,6 +661,7 @@ type FunctionExpression struct {
 
 func (il *FunctionExpression) expressionNode()      {}
 func (il *FunctionExpression) TokenLiteral() string { return il.Token.Literal }
+func (il *FunctionExpression) Pos() token.Token     { return il.Token }
 func (il *FunctionExpression) String() string       { return il.Token.Literal }
 
 func (il *FunctionExpression) ParametersString() string {
@@ -351,30 +689,250 @@ func (il *FunctionExpression) BodyString() string {
 	return out.String()
 }
 
-type FunctionCallExpression struct {
+// SpawnExpression runs Function in a new task, a called function gets its arguments evaluated by the spawning task
+type SpawnExpression struct {
+	Token    token.Token // the token.SPAWN token
+	Function Expression  // a function or a call of one
+}
+
+func (se *SpawnExpression) expressionNode()      {}
+func (se *SpawnExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpawnExpression) Pos() token.Token     { return se.Token }
+func (se *SpawnExpression) String() string       { return "spawn " + se.Function.String() }
+
+// MatchExpression evaluates the body of the first arm whose pattern matches Value and whose guard is true
+type MatchExpression struct {
+	Token token.Token // the token.MATCH token
+	Value Expression
+	Arms  []*MatchArm
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) Pos() token.Token     { return me.Token }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("match " + me.Value.String() + " { ")
+	for i, arm := range me.Arms {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(arm.String())
+	}
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// MatchArm is an arm of a match expression, Guard is nil for arms without one.
+// The body of an arm written as a single expression is an expression statement.
+type MatchArm struct {
+	Token   token.Token // the first token of the pattern
+	Pattern Pattern
+	Guard   Expression
+	Body    []Statement
+}
+
+func (arm *MatchArm) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(arm.Pattern.String())
+	if arm.Guard != nil {
+		out.WriteString(" if " + arm.Guard.String())
+	}
+	out.WriteString(" => ")
+	for _, statement := range arm.Body {
+		out.WriteString(statement.String())
+	}
+
+	return out.String()
+}
+
+// Pattern is matched against values by the arms of match expressions
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// WildcardPattern is the pattern _, which matches every value
+type WildcardPattern struct {
+	Token token.Token
+}
+
+func (wp *WildcardPattern) patternNode()         {}
+func (wp *WildcardPattern) TokenLiteral() string { return wp.Token.Literal }
+func (wp *WildcardPattern) Pos() token.Token     { return wp.Token }
+func (wp *WildcardPattern) String() string       { return "_" }
+
+// BindingPattern matches every value and binds it to the variable Name
+type BindingPattern struct {
+	Name *Identifier
+}
+
+func (bp *BindingPattern) patternNode()         {}
+func (bp *BindingPattern) TokenLiteral() string { return bp.Name.TokenLiteral() }
+func (bp *BindingPattern) Pos() token.Token     { return bp.Name.Pos() }
+func (bp *BindingPattern) String() string       { return bp.Name.String() }
+
+// LiteralPattern matches values equal to an integer, string or boolean literal
+type LiteralPattern struct {
+	Token token.Token
+	Value Expression
+}
+
+func (lp *LiteralPattern) patternNode()         {}
+func (lp *LiteralPattern) TokenLiteral() string { return lp.Token.Literal }
+func (lp *LiteralPattern) Pos() token.Token     { return lp.Token }
+func (lp *LiteralPattern) String() string       { return lp.Value.String() }
+
+// AlternativePattern matches values matching one of its alternatives, "a" | "b"
+type AlternativePattern struct {
 	Token        token.Token
-	FunctionExpr Expression
-	Parameters   []Expression
+	Alternatives []Pattern
+}
+
+func (ap *AlternativePattern) patternNode()         {}
+func (ap *AlternativePattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *AlternativePattern) Pos() token.Token     { return ap.Token }
+func (ap *AlternativePattern) String() string {
+	alternatives := make([]string, len(ap.Alternatives))
+	for i, alternative := range ap.Alternatives {
+		alternatives[i] = alternative.String()
+	}
+
+	return strings.Join(alternatives, " | ")
+}
+
+// ListPattern matches lists whose elements match Elements. With a rest, [first, ...rest], lists can have more
+// elements, they are bound as list to Rest unless it is nil.
+type ListPattern struct {
+	Token    token.Token // the token.LBRACKET token
+	Elements []Pattern
+	HasRest  bool
+	Rest     *Identifier
+}
+
+func (lp *ListPattern) patternNode()         {}
+func (lp *ListPattern) TokenLiteral() string { return lp.Token.Literal }
+func (lp *ListPattern) Pos() token.Token     { return lp.Token }
+func (lp *ListPattern) String() string {
+	elements := make([]string, 0, len(lp.Elements)+1)
+	for _, element := range lp.Elements {
+		elements = append(elements, element.String())
+	}
+
+	if lp.HasRest {
+		rest := "..."
+		if lp.Rest != nil {
+			rest += lp.Rest.String()
+		}
+		elements = append(elements, rest)
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// StructPattern matches instances of the struct Name whose fields match the patterns of Fields,
+// the other fields are not matched. Without a Name instances of every struct with these fields match.
+type StructPattern struct {
+	Token  token.Token
+	Name   *Identifier
+	Fields []*FieldPattern
+}
+
+// FieldPattern matches a field of an instance, the field of Point{x} is bound to the variable x
+type FieldPattern struct {
+	Name    *Identifier
+	Pattern Pattern
+}
+
+func (sp *StructPattern) patternNode()         {}
+func (sp *StructPattern) TokenLiteral() string { return sp.Token.Literal }
+func (sp *StructPattern) Pos() token.Token     { return sp.Token }
+func (sp *StructPattern) String() string {
+	fields := make([]string, len(sp.Fields))
+	for i, field := range sp.Fields {
+		if binding, ok := field.Pattern.(*BindingPattern); ok && binding.Name.Value == field.Name.Value {
+			fields[i] = field.Name.String()
+		} else {
+			fields[i] = field.Name.String() + ": " + field.Pattern.String()
+		}
+	}
+
+	if sp.Name == nil {
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+
+	return sp.Name.String() + "{" + strings.Join(fields, ", ") + "}"
+}
+
+// PatternBindings returns the names of the variables bound by the pattern in the order of their declaration
+func PatternBindings(pattern Pattern) []string {
+	var names []string
+
+	switch pattern := pattern.(type) {
+	case *BindingPattern:
+		names = append(names, pattern.Name.Value)
+	case *AlternativePattern:
+		for _, alternative := range pattern.Alternatives {
+			names = append(names, PatternBindings(alternative)...)
+		}
+	case *ListPattern:
+		for _, element := range pattern.Elements {
+			names = append(names, PatternBindings(element)...)
+		}
+		if pattern.Rest != nil {
+			names = append(names, pattern.Rest.Value)
+		}
+	case *StructPattern:
+		for _, field := range pattern.Fields {
+			names = append(names, PatternBindings(field.Pattern)...)
+		}
+	}
+
+	return names
+}
+
+// FunctionCallExpression calls a function with the positional arguments Parameters followed by the named ones
+type FunctionCallExpression struct {
+	Token           token.Token
+	FunctionExpr    Expression
+	Parameters      []Expression
+	NamedParameters []*NamedArgument
+}
+
+// NamedArgument is an argument passed by the name of its parameter, like b in f(b: 3)
+type NamedArgument struct {
+	Name  *Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) String() string {
+	return na.Name.String() + ": " + na.Value.String()
 }
 
 func (il *FunctionCallExpression) expressionNode()      {}
 func (il *FunctionCallExpression) TokenLiteral() string { return il.Token.Literal }
+func (il *FunctionCallExpression) Pos() token.Token     { return il.FunctionExpr.Pos() }
 func (il *FunctionCallExpression) String() string {
 	return il.FunctionExpr.String() + il.ParametersString()
 }
 
 func (il *FunctionCallExpression) ParametersString() string {
 	var out bytes.Buffer
-	totalParameters := len(il.Parameters) - 1
-	out.WriteString("(")
+	parameters := make([]string, 0, len(il.Parameters)+len(il.NamedParameters))
 
-	for i, s := range il.Parameters {
-		out.WriteString(s.String())
-		if i < totalParameters {
-			out.WriteString(", ")
-		}
+	for _, s := range il.Parameters {
+		parameters = append(parameters, s.String())
+	}
+
+	for _, s := range il.NamedParameters {
+		parameters = append(parameters, s.String())
 	}
 
+	out.WriteString("(")
+	out.WriteString(strings.Join(parameters, ", "))
 	out.WriteString(")")
 
 	return out.String()
@@ -384,6 +942,14 @@ type Program struct {
 	Statements []Statement
 }
 
+func (p *Program) Pos() token.Token {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+
+	return token.Token{}
+}
+
 func (p *Program) TokenLiteral() string {
 	if len(p.Statements) > 0 {
 		return p.Statements[0].TokenLiteral()
@@ -2,6 +2,7 @@ package ast
 
 import (
 	"curryLang/token"
+	"fmt"
 	"testing"
 )
 
@@ -26,3 +27,107 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. got=%q", program.String())
 	}
 }
+
+func TestIsExported(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"Open", true},
+		{"open", false},
+		{"Äpfel", true},
+		{"ärger", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if IsExported(tt.name) != tt.expected {
+			t.Errorf("IsExported(%q) wrong. want=%t", tt.name, tt.expected)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	var nilStatement *LetStatement
+
+	program := &Program{
+		Statements: []Statement{
+			nilStatement,
+			&ExpressionStatement{
+				Expression: &InfixExpression{
+					Left:     &Identifier{Value: "a"},
+					Operator: "+",
+					Right: &FunctionCallExpression{
+						FunctionExpr: &Identifier{Value: "b"},
+						Parameters:   []Expression{&Identifier{Value: "c"}},
+					},
+				},
+			},
+			&ExpressionStatement{
+				Expression: &FunctionExpression{
+					Body: []Statement{
+						&ReturnStatement{ReturnValue: &Identifier{Value: "d"}},
+					},
+				},
+			},
+		},
+	}
+
+	visited := ""
+	Inspect(program, func(node Node) bool {
+		if identifier, ok := node.(*Identifier); ok {
+			visited += identifier.Value
+		}
+
+		_, isFunction := node.(*FunctionExpression)
+		return !isFunction
+	})
+
+	if visited != "abc" {
+		t.Errorf("wrong identifiers visited. want=%q, got=%q", "abc", visited)
+	}
+}
+
+func TestContainsYield(t *testing.T) {
+	yield := &YieldStatement{Value: &Identifier{Value: "a"}}
+
+	tests := []struct {
+		body     []Statement
+		expected bool
+	}{
+		{[]Statement{&ReturnStatement{ReturnValue: &Identifier{Value: "a"}}}, false},
+		{[]Statement{&WhileStatement{Condition: &Boolean{Value: true}, Body: []Statement{yield}}}, true},
+		{[]Statement{&ExpressionStatement{Expression: &FunctionExpression{Body: []Statement{yield}}}}, false},
+	}
+
+	for i, tt := range tests {
+		if ContainsYield(tt.body) != tt.expected {
+			t.Errorf("wrong result for body %d. want=%t, got=%t", i, tt.expected, !tt.expected)
+		}
+	}
+}
+
+func TestPatternBindings(t *testing.T) {
+	x := &BindingPattern{Name: &Identifier{Value: "x"}}
+	y := &BindingPattern{Name: &Identifier{Value: "y"}}
+
+	tests := []struct {
+		pattern  Pattern
+		expected string
+	}{
+		{&WildcardPattern{}, "[]"},
+		{x, "[x]"},
+		{&ListPattern{Elements: []Pattern{x, &WildcardPattern{}}, HasRest: true, Rest: &Identifier{Value: "rest"}}, "[x rest]"},
+		{&StructPattern{Name: &Identifier{Value: "Point"}, Fields: []*FieldPattern{
+			{Name: &Identifier{Value: "y"}, Pattern: y},
+			{Name: &Identifier{Value: "x"}, Pattern: &ListPattern{Elements: []Pattern{x}}},
+		}}, "[y x]"},
+	}
+
+	for _, tt := range tests {
+		names := fmt.Sprint(PatternBindings(tt.pattern))
+		if names != tt.expected {
+			t.Errorf("wrong bindings of %s. want=%s, got=%s", tt.pattern.String(), tt.expected, names)
+		}
+	}
+}
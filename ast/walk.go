@@ -0,0 +1,190 @@
+package ast
+
+import (
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+// IsExported reports whether name starts with an upper-case letter and can be accessed from other packages
+func IsExported(name string) bool {
+	ch, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(ch)
+}
+
+// Inspect traverses the tree of node depth first, the children of a node are only visited when f returns true
+func Inspect(node Node, f func(Node) bool) {
+	if isNil(node) || !f(node) {
+		return
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		inspectStatements(node.Statements, f)
+	case *LetStatement:
+		Inspect(node.Name, f)
+		Inspect(node.Pattern, f)
+		Inspect(node.Value, f)
+	case *AssignmentStatement:
+		Inspect(node.Name, f)
+		Inspect(node.Value, f)
+	case *FieldAssignmentStatement:
+		Inspect(node.Target, f)
+		Inspect(node.Value, f)
+	case *WhileStatement:
+		Inspect(node.Condition, f)
+		inspectStatements(node.Body, f)
+	case *ForInStatement:
+		Inspect(node.Key, f)
+		Inspect(node.Variable, f)
+		Inspect(node.Iterable, f)
+		inspectStatements(node.Body, f)
+	case *PackageStatement:
+		Inspect(node.Identifier, f)
+	case *ReturnStatement:
+		Inspect(node.ReturnValue, f)
+	case *TryStatement:
+		inspectStatements(node.Body, f)
+		Inspect(node.Parameter, f)
+		inspectStatements(node.Handler, f)
+	case *ThrowStatement:
+		Inspect(node.Value, f)
+	case *YieldStatement:
+		Inspect(node.Value, f)
+	case *SelectStatement:
+		for _, selectCase := range node.Cases {
+			Inspect(selectCase.Name, f)
+			Inspect(selectCase.Channel, f)
+			Inspect(selectCase.Value, f)
+			inspectStatements(selectCase.Body, f)
+		}
+		inspectStatements(node.Default, f)
+	case *ExpressionStatement:
+		Inspect(node.Expression, f)
+	case *StructStatement:
+		Inspect(node.Name, f)
+		for _, field := range node.Fields {
+			Inspect(field, f)
+		}
+	case *TraitStatement:
+		Inspect(node.Name, f)
+		for _, method := range node.RequiredMethods {
+			Inspect(method, f)
+		}
+		for _, method := range node.DefaultMethods {
+			Inspect(method, f)
+		}
+	case *ImplStatement:
+		Inspect(node.Trait, f)
+		Inspect(node.Struct, f)
+		for _, method := range node.Methods {
+			Inspect(method, f)
+		}
+	case *PrefixExpression:
+		Inspect(node.Right, f)
+	case *InfixExpression:
+		Inspect(node.Left, f)
+		Inspect(node.Right, f)
+	case *ListExpression:
+		inspectExpressions(node.Value, f)
+	case *StructExpression:
+		Inspect(node.Name, f)
+		for _, field := range node.Fields {
+			Inspect(field.Name, f)
+			Inspect(field.Value, f)
+		}
+	case *IndexAccessExpression:
+		Inspect(node.Source, f)
+		Inspect(node.Value, f)
+		Inspect(node.End, f)
+	case *DotAccessExpression:
+		Inspect(node.Source, f)
+		Inspect(node.Value, f)
+	case *IfElseExpression:
+		Inspect(node.Condition, f)
+		inspectStatements(node.Consequence, f)
+		inspectStatements(node.Alternative, f)
+	case *FunctionExpression:
+		for _, parameter := range node.Parameters {
+			Inspect(parameter.Default, f)
+		}
+		inspectStatements(node.Body, f)
+	case *SpawnExpression:
+		Inspect(node.Function, f)
+	case *MatchExpression:
+		Inspect(node.Value, f)
+		for _, arm := range node.Arms {
+			Inspect(arm.Pattern, f)
+			Inspect(arm.Guard, f)
+			inspectStatements(arm.Body, f)
+		}
+	case *BindingPattern:
+		Inspect(node.Name, f)
+	case *LiteralPattern:
+		Inspect(node.Value, f)
+	case *AlternativePattern:
+		for _, alternative := range node.Alternatives {
+			Inspect(alternative, f)
+		}
+	case *ListPattern:
+		for _, element := range node.Elements {
+			Inspect(element, f)
+		}
+		Inspect(node.Rest, f)
+	case *StructPattern:
+		Inspect(node.Name, f)
+		for _, field := range node.Fields {
+			Inspect(field.Name, f)
+			Inspect(field.Pattern, f)
+		}
+	case *FunctionCallExpression:
+		Inspect(node.FunctionExpr, f)
+		inspectExpressions(node.Parameters, f)
+		for _, argument := range node.NamedParameters {
+			Inspect(argument.Name, f)
+			Inspect(argument.Value, f)
+		}
+	}
+}
+
+// ContainsYield reports whether the body of a function yields values, yields of nested functions are not counted
+func ContainsYield(body []Statement) bool {
+	found := false
+
+	for _, statement := range body {
+		Inspect(statement, func(node Node) bool {
+			switch node.(type) {
+			case *YieldStatement:
+				found = true
+			case *FunctionExpression:
+				return false
+			}
+
+			return !found
+		})
+	}
+
+	return found
+}
+
+func inspectStatements(statements []Statement, f func(Node) bool) {
+	for _, statement := range statements {
+		Inspect(statement, f)
+	}
+}
+
+func inspectExpressions(expressions []Expression, f func(Node) bool) {
+	for _, expression := range expressions {
+		Inspect(expression, f)
+	}
+}
+
+// isNil also detects typed nil pointers, which the parser returns for statements it failed to parse
+func isNil(node Node) bool {
+	if node == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(node)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"curryLang/currytest"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// runBenchCommand executes "curry bench [flags] [packages]" and returns false if a benchmark failed
+func runBenchCommand(args []string) (bool, error) {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	bench := flags.String("bench", "", "only run benchmarks matching the regular expression")
+	benchTime := flags.Duration("benchtime", 0, "minimal run time of every benchmark (default 1s)")
+	backend := flags.String("backend", "both", "engine running the benchmarks: evaluator, vm or both")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return false, err
+	}
+
+	options := currytest.BenchOptions{
+		Time:      *benchTime,
+		NewEngine: newEngine,
+		Out:       os.Stdout,
+	}
+
+	switch *backend {
+	case "evaluator":
+		options.Backends = []currytest.Backend{currytest.BackendEvaluator}
+	case "vm":
+		options.Backends = []currytest.Backend{currytest.BackendVM}
+	case "both":
+		options.Backends = []currytest.Backend{currytest.BackendEvaluator, currytest.BackendVM}
+	default:
+		return false, fmt.Errorf("unknown backend %s, expected evaluator, vm or both", *backend)
+	}
+
+	if *bench != "" {
+		options.Bench, err = regexp.Compile(*bench)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	dirs, err := currytest.Discover(flags.Args())
+	if err != nil {
+		return false, err
+	}
+
+	return currytest.RunBenchmarks(dirs, options), nil
+}
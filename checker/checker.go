@@ -0,0 +1,184 @@
+package checker
+
+import (
+	"curryLang/ast"
+	"fmt"
+	"path"
+)
+
+type checker struct {
+	// scopes maps the names declared in each scope to the import path of packages, other declarations map to ""
+	scopes []map[string]string
+	errors []string
+}
+
+// Check verifies the rules of a program which can be decided without executing it
+func Check(program *ast.Program) []string {
+	c := &checker{
+		scopes: []map[string]string{{}},
+		errors: []string{},
+	}
+
+	ast.Inspect(program, c.visit)
+
+	return c.errors
+}
+
+func (c *checker) visit(node ast.Node) bool {
+	switch node := node.(type) {
+	case *ast.ImportStatement:
+		for _, importPath := range node.Packages {
+			c.declare(path.Base(importPath), importPath)
+		}
+
+	case *ast.LetStatement:
+		ast.Inspect(node.Value, c.visit)
+		if node.Name != nil {
+			c.declare(node.Name.Value, "")
+		}
+		c.declarePattern(node.Pattern)
+		return false
+
+	case *ast.FunctionExpression:
+		c.enterScope()
+		for _, parameter := range node.Parameters {
+			ast.Inspect(parameter.Default, c.visit)
+			c.declare(parameter.Name, "")
+			c.declarePattern(parameter.Pattern)
+		}
+		c.inspectStatements(node.Body)
+		c.leaveScope()
+		return false
+
+	case *ast.ForInStatement:
+		ast.Inspect(node.Iterable, c.visit)
+		c.enterScope()
+		if node.Key != nil {
+			c.declare(node.Key.Value, "")
+		}
+		if node.Variable != nil {
+			c.declare(node.Variable.Value, "")
+		}
+		c.inspectStatements(node.Body)
+		c.leaveScope()
+		return false
+
+	case *ast.TryStatement:
+		c.inspectStatements(node.Body)
+		c.enterScope()
+		if node.Parameter != nil {
+			c.declare(node.Parameter.Value, "")
+		}
+		c.inspectStatements(node.Handler)
+		c.leaveScope()
+		return false
+
+	case *ast.MatchExpression:
+		ast.Inspect(node.Value, c.visit)
+		for _, arm := range node.Arms {
+			c.enterScope()
+			c.declarePattern(arm.Pattern)
+			ast.Inspect(arm.Guard, c.visit)
+			c.inspectStatements(arm.Body)
+			c.leaveScope()
+		}
+		return false
+
+	case *ast.SelectStatement:
+		for _, selectCase := range node.Cases {
+			ast.Inspect(selectCase.Channel, c.visit)
+			ast.Inspect(selectCase.Value, c.visit)
+			c.enterScope()
+			if selectCase.Name != nil {
+				c.declare(selectCase.Name.Value, "")
+			}
+			c.inspectStatements(selectCase.Body)
+			c.leaveScope()
+		}
+		c.inspectStatements(node.Default)
+		return false
+
+	case *ast.DotAccessExpression:
+		c.checkPackageMember(node)
+	}
+
+	return true
+}
+
+func (c *checker) inspectStatements(statements []ast.Statement) {
+	for _, statement := range statements {
+		ast.Inspect(statement, c.visit)
+	}
+}
+
+func (c *checker) enterScope() {
+	c.scopes = append(c.scopes, map[string]string{})
+}
+
+func (c *checker) leaveScope() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+// declare adds a name to the innermost scope, it shadows the packages imported with the same name
+func (c *checker) declare(name string, importPath string) {
+	if name != "" {
+		c.scopes[len(c.scopes)-1][name] = importPath
+	}
+}
+
+func (c *checker) declarePattern(pattern ast.Pattern) {
+	for _, name := range ast.PatternBindings(pattern) {
+		c.declare(name, "")
+	}
+}
+
+// lookupImport returns the import path of the package a name refers to, it is empty for other declarations
+func (c *checker) lookupImport(name string) string {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if importPath, ok := c.scopes[i][name]; ok {
+			return importPath
+		}
+	}
+
+	return ""
+}
+
+func (c *checker) checkPackageMember(access *ast.DotAccessExpression) {
+	source, ok := access.Source.(*ast.Identifier)
+	if !ok {
+		return
+	}
+
+	importPath := c.lookupImport(source.Value)
+	if importPath == "" {
+		return
+	}
+
+	kind, name := MemberKind(access.Value)
+	if name == "" || ast.IsExported(name) {
+		return
+	}
+
+	c.errors = append(c.errors, UnexportedError(kind, name, importPath))
+}
+
+// MemberKind returns what is accessed from a package and the name of the member
+func MemberKind(value ast.Expression) (string, string) {
+	switch value := value.(type) {
+	case *ast.Identifier:
+		return "global", value.Value
+	case *ast.FunctionCallExpression:
+		if identifier, ok := value.FunctionExpr.(*ast.Identifier); ok {
+			return "function", identifier.Value
+		}
+	case *ast.StructExpression:
+		return "struct", value.Name.Value
+	}
+
+	return "", ""
+}
+
+// UnexportedError is used for accessing a package member which is not exported, both when checking and at runtime
+func UnexportedError(kind string, name string, importPath string) string {
+	return fmt.Sprintf("Cannot access unexported %s %s of package %s", kind, name, importPath)
+}
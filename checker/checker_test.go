@@ -0,0 +1,98 @@
+package checker
+
+import (
+	"curryLang/lexer"
+	"curryLang/parser"
+	"testing"
+)
+
+func TestCheckUnexportedPackageMembers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`import "mymodule/geo"; geo.Distance(1, 2); geo.Origin; geo.Point{x: 1};`, []string{}},
+		{`import "mymodule/geo"; geo.abs(1);`, []string{"Cannot access unexported function abs of package mymodule/geo"}},
+		{`import "mymodule/geo"; let x = geo.origin;`, []string{"Cannot access unexported global origin of package mymodule/geo"}},
+		{`import "mymodule/geo"; fn foo() { return geo.point{x: 1}; }`, []string{"Cannot access unexported struct point of package mymodule/geo"}},
+		{`struct Point { x }; let p = Point{x: 1}; p.x;`, []string{}},
+		{
+			`import ("mymodule/geo" "internal/os"); os.open("file"); geo.helper();`,
+			[]string{
+				"Cannot access unexported function open of package internal/os",
+				"Cannot access unexported function helper of package mymodule/geo",
+			},
+		},
+		// variables with the name of a package shadow it
+		{`import "strings"; fn f(strings) { strings.foo }`, []string{}},
+		{`import "strings"; fn f() { let strings = 1; strings.foo }`, []string{}},
+		{`import "strings"; for strings in [] { strings.foo }`, []string{}},
+		{`import "strings"; match 1 { [strings] => strings.foo }`, []string{}},
+		{`import "strings"; try { } catch (strings) { strings.foo }`, []string{}},
+		{
+			`import "strings"; fn f(strings) { strings.foo } strings.bar();`,
+			[]string{"Cannot access unexported function bar of package strings"},
+		},
+		{
+			`import "strings"; let x = strings.foo; let strings = 1; strings.bar;`,
+			[]string{"Cannot access unexported global foo of package strings"},
+		},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+
+		if len(p.Errors()) > 0 {
+			t.Fatalf("parser errors for %q: %v", tt.input, p.Errors())
+		}
+
+		errors := Check(program)
+
+		if len(errors) != len(tt.expected) {
+			t.Fatalf("wrong number of errors for %q. want=%v, got=%v", tt.input, tt.expected, errors)
+		}
+
+		for i, err := range tt.expected {
+			if errors[i] != err {
+				t.Errorf("wrong error. want=%q, got=%q", err, errors[i])
+			}
+		}
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"match x { 1 => a, _ => b };", []string{}},
+		{"match x { 1 => a, n => b };", []string{}},
+		{"match x { true => a, false => b };", []string{}},
+		{"match x { 1 => a, 2 => b };", []string{"1:1: match is not exhaustive, add a _ arm"}},
+		{"match x { _ if y => a };", []string{"1:1: match is not exhaustive, add a _ arm"}},
+		{"match x { _ => a, 1 => b, _ => c };", []string{"1:19: unreachable match arm 1", "1:27: unreachable match arm _"}},
+		{"fn f() { match x { true => a } }", []string{"1:10: match is not exhaustive, add a _ arm"}},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+
+		if len(p.Errors()) > 0 {
+			t.Fatalf("parser errors for %q: %v", tt.input, p.Errors())
+		}
+
+		warnings := Warnings(program)
+
+		if len(warnings) != len(tt.expected) {
+			t.Fatalf("wrong number of warnings for %q. want=%v, got=%v", tt.input, tt.expected, warnings)
+		}
+
+		for i, warning := range tt.expected {
+			if warnings[i] != warning {
+				t.Errorf("wrong warning. want=%q, got=%q", warning, warnings[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package checker
+
+import (
+	"curryLang/ast"
+	"fmt"
+)
+
+// Warnings returns the problems of a program which do not stop it from running,
+// like match expressions which do not handle every value
+func Warnings(program *ast.Program) []string {
+	warnings := []string{}
+
+	ast.Inspect(program, func(node ast.Node) bool {
+		if match, ok := node.(*ast.MatchExpression); ok {
+			warnings = append(warnings, checkMatch(match)...)
+		}
+
+		return true
+	})
+
+	return warnings
+}
+
+// checkMatch warns about arms after an arm matching every value and about matches without such an arm,
+// matches of both booleans are exhaustive as well
+func checkMatch(match *ast.MatchExpression) []string {
+	var warnings []string
+
+	exhaustive := false
+	booleans := map[bool]bool{}
+
+	for _, arm := range match.Arms {
+		if exhaustive {
+			position := arm.Pattern.Pos()
+			warnings = append(warnings, fmt.Sprintf("%d:%d: unreachable match arm %s", position.Line, position.Column, arm.Pattern.String()))
+			continue
+		}
+
+		if arm.Guard != nil {
+			continue
+		}
+
+		switch pattern := arm.Pattern.(type) {
+		case *ast.WildcardPattern, *ast.BindingPattern:
+			exhaustive = true
+		default:
+			for _, value := range booleanLiterals(pattern) {
+				booleans[value] = true
+			}
+		}
+
+		if booleans[true] && booleans[false] {
+			exhaustive = true
+		}
+	}
+
+	if !exhaustive {
+		warnings = append(warnings, fmt.Sprintf("%d:%d: match is not exhaustive, add a _ arm", match.Token.Line, match.Token.Column))
+	}
+
+	return warnings
+}
+
+func booleanLiterals(pattern ast.Pattern) []bool {
+	switch pattern := pattern.(type) {
+	case *ast.LiteralPattern:
+		if boolean, ok := pattern.Value.(*ast.Boolean); ok {
+			return []bool{boolean.Value}
+		}
+	case *ast.AlternativePattern:
+		var values []bool
+		for _, alternative := range pattern.Alternatives {
+			values = append(values, booleanLiterals(alternative)...)
+		}
+
+		return values
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"strings"
 )
 
 type Instructions []byte
@@ -32,8 +33,86 @@ const (
 
 	OpGetGlobal
 	OpSetGlobal
+
+	OpNull
+
+	// OpInstance pops the field values of the struct definition constant and pushes a new instance
+	OpInstance
+	// OpGetField pops an instance and pushes the value of the field named by the constant
+	OpGetField
+	// OpSetField pops a value and an instance and stores the value in the field named by the constant
+	OpSetField
+
+	// OpCall calls the function below its u8 arguments on the stack
+	OpCall
+	// OpReturnValue returns the value on top of the stack to the caller
+	OpReturnValue
+	// OpReturn returns null to the caller
+	OpReturn
+	OpGetLocal
+	OpSetLocal
+
+	// OpThrow pops a value and raises it as an error which can be caught by a handler
+	OpThrow
+
+	// OpGetBuiltin pushes the builtin of object.BuiltinNames with the u8 index
+	OpGetBuiltin
+	// OpSpawn calls the function below its u8 arguments on the stack in a new task and pushes null
+	OpSpawn
+	// OpSelect pops the channel, value and send flag of its u8 cases and pushes the received value of the selected one.
+	// It is followed by a jump to the body of every case and one to the default body if the second operand is 1,
+	// the vm continues at the jump of the selected case.
+	OpSelect
+
+	// OpYield pops a value, suspends the frame of the generator function and passes the value to the resuming code
+	OpYield
+	// OpIter pops an iterable value and pushes the iterator used by OpIterNext, the u8 operand is the number of
+	// loop variables, instances iterate only their field names for a single one
+	OpIter
+	// OpIterNext pops an iterator and pushes the key and the value of its next element,
+	// it jumps to the u16 target once the iterator is done
+	OpIterNext
+
+	// OpList pops its u16 elements and pushes a list of them
+	OpList
+	// OpRange pops the end and the start of a range and pushes the range, the end is included if the u8 operand is 1
+	OpRange
+	// OpIndex pops an index and a list or string and pushes the element at the index
+	OpIndex
+	// OpSlice pops the end, the start and a list or string and pushes the slice, omitted bounds are null
+	OpSlice
+
+	// OpMatch pops a value and matches it against the pattern constant with the u16 index. If it matches, the
+	// values of the variables bound by the pattern are pushed followed by true, otherwise only false is pushed.
+	OpMatch
+	// OpNoMatch pops the value of a match expression none of the arms matched and raises a MatchError
+	OpNoMatch
+	// OpJumpTable pops a value and jumps to the target of the jump table constant with the u16 index
+	OpJumpTable
+	// OpDestructure pops a value and pushes the values of the variables bound by the pattern constant with the u16
+	// index, values which do not match the pattern raise an error
+	OpDestructure
+
+	// OpCallNamed calls the function below its u8 arguments on the stack, the last of them are passed by the names
+	// in the list constant with the u16 index
+	OpCallNamed
+	// OpDefault jumps to the u16 target if the parameter with the u8 local index got an argument, otherwise the
+	// instructions before the target set its default value
+	OpDefault
+
+	// OpGetMethod pops an instance and pushes its method named by the constant with the u16 index bound to it,
+	// it pushes the value of the field with the name like OpGetField if the struct has no such method
+	OpGetMethod
 )
 
+// Handler catches errors raised by the instructions in [Start, End) and continues at Target
+// with the error instance on top of the stack
+type Handler struct {
+	Start  int
+	End    int
+	Target int
+}
+
 const (
 	OpcodeU8  = 1
 	OpcodeU16 = 2
@@ -64,6 +143,33 @@ var definitions = map[Opcode]*Definition{
 	OpJumpIfFalse: {"OpJumpIfFalse", []int{OpcodeU16}},
 	OpGetGlobal:   {"OpGetGlobal", []int{OpcodeU16}},
 	OpSetGlobal:   {"OpSetGlobal", []int{OpcodeU16}},
+	OpNull:        {"OpNull", []int{}},
+	OpInstance:    {"OpInstance", []int{OpcodeU16}},
+	OpGetField:    {"OpGetField", []int{OpcodeU16}},
+	OpSetField:    {"OpSetField", []int{OpcodeU16}},
+	OpCall:        {"OpCall", []int{OpcodeU8}},
+	OpReturnValue: {"OpReturnValue", []int{}},
+	OpReturn:      {"OpReturn", []int{}},
+	OpGetLocal:    {"OpGetLocal", []int{OpcodeU8}},
+	OpSetLocal:    {"OpSetLocal", []int{OpcodeU8}},
+	OpThrow:       {"OpThrow", []int{}},
+	OpGetBuiltin:  {"OpGetBuiltin", []int{OpcodeU8}},
+	OpSpawn:       {"OpSpawn", []int{OpcodeU8}},
+	OpSelect:      {"OpSelect", []int{OpcodeU8, OpcodeU8}},
+	OpYield:       {"OpYield", []int{}},
+	OpIter:        {"OpIter", []int{OpcodeU8}},
+	OpIterNext:    {"OpIterNext", []int{OpcodeU16}},
+	OpList:        {"OpList", []int{OpcodeU16}},
+	OpRange:       {"OpRange", []int{OpcodeU8}},
+	OpIndex:       {"OpIndex", []int{}},
+	OpSlice:       {"OpSlice", []int{}},
+	OpMatch:       {"OpMatch", []int{OpcodeU16}},
+	OpNoMatch:     {"OpNoMatch", []int{}},
+	OpDestructure: {"OpDestructure", []int{OpcodeU16}},
+	OpCallNamed:   {"OpCallNamed", []int{OpcodeU8, OpcodeU16}},
+	OpDefault:     {"OpDefault", []int{OpcodeU8, OpcodeU16}},
+	OpJumpTable:   {"OpJumpTable", []int{OpcodeU16}},
+	OpGetMethod:   {"OpGetMethod", []int{OpcodeU16}},
 }
 
 func Lookup(op byte) (*Definition, error) {
@@ -94,6 +200,8 @@ func Make(op Opcode, operands ...int) []byte {
 		width := def.OperandWidths[i]
 
 		switch width {
+		case OpcodeU8:
+			instruction[offset] = byte(o)
 		case OpcodeU16:
 			binary.BigEndian.PutUint16(instruction[offset:], uint16(o))
 		}
@@ -109,7 +217,9 @@ func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
 	offset := 0
 	for i, width := range def.OperandWidths {
 		switch width {
-		case 2:
+		case OpcodeU8:
+			operands[i] = int(ReadUint8(ins[offset:]))
+		case OpcodeU16:
 			operands[i] = int(ReadUint16(ins[offset:]))
 		}
 		offset += width
@@ -117,6 +227,10 @@ func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
 	return operands, offset
 }
 
+func ReadUint8(ins Instructions) uint8 {
+	return ins[0]
+}
+
 func ReadUint16(ins Instructions) uint16 {
 	return binary.BigEndian.Uint16(ins)
 }
@@ -137,6 +251,39 @@ func (ins Instructions) String() string {
 	return out.String()
 }
 
+// Disassemble prints the instructions like String and annotates them with the source lines they were compiled from,
+// the text of a line is shown if sources contains the lines of its file
+func (ins Instructions) Disassemble(statements *Statements, sources map[string][]string) string {
+	var out bytes.Buffer
+	var annotated Position
+
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			return out.String()
+		}
+
+		if position, ok := statements.Lookup(i); ok && (position.File != annotated.File || position.Line != annotated.Line) {
+			fmt.Fprintf(&out, "; %s:%d", position.fileName(), position.Line)
+
+			if source := sources[position.File]; position.Line <= len(source) {
+				fmt.Fprintf(&out, "  %s", strings.TrimSpace(source[position.Line-1]))
+			}
+
+			out.WriteString("\n")
+			annotated = position
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s\n", i, ins.fmtInstruction(def, operands))
+		i += 1 + read
+	}
+
+	return out.String()
+}
+
 func (ins Instructions) fmtInstruction(def *Definition, operands []int) string {
 	operandCount := len(def.OperandWidths)
 	if len(operands) != operandCount {
@@ -147,6 +294,8 @@ func (ins Instructions) fmtInstruction(def *Definition, operands []int) string {
 		return def.Name
 	case 1:
 		return fmt.Sprintf("%s %d", def.Name, operands[0])
+	case 2:
+		return fmt.Sprintf("%s %d %d", def.Name, operands[0], operands[1])
 	}
 	return fmt.Sprintf("ERROR: unhandled operandCount for %s\n", def.Name)
 }
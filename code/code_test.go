@@ -16,6 +16,13 @@ func TestMake(t *testing.T) {
 		{OpPop, []int{}, []byte{byte(OpPop)}},
 		{OpTrue, []int{}, []byte{byte(OpTrue)}},
 		{OpFalse, []int{}, []byte{byte(OpFalse)}},
+		{OpNull, []int{}, []byte{byte(OpNull)}},
+		{OpInstance, []int{3}, []byte{byte(OpInstance), 0x00, 0x03}},
+		{OpGetField, []int{0xfffe}, []byte{byte(OpGetField), 0xff, 0xfe}},
+		{OpSetField, []int{0x0102}, []byte{byte(OpSetField), 0x01, 0x02}},
+		{OpCall, []int{255}, []byte{byte(OpCall), 0xff}},
+		{OpReturnValue, []int{}, []byte{byte(OpReturnValue)}},
+		{OpGetLocal, []int{3}, []byte{byte(OpGetLocal), 0x03}},
 	}
 
 	for _, tt := range tests {
@@ -60,6 +67,7 @@ func TestReadOperands(t *testing.T) {
 		bytesRead int
 	}{
 		{OpConstant, []int{65535}, 2},
+		{OpGetLocal, []int{255}, 1},
 	}
 	for _, tt := range tests {
 		instruction := Make(tt.op, tt.operands...)
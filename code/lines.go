@@ -0,0 +1,226 @@
+package code
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync/atomic"
+)
+
+// Position is the source span an instruction was compiled from
+type Position struct {
+	File   string
+	Line   int
+	Column int
+	Length int // number of characters of the token
+}
+
+func (position Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", position.fileName(), position.Line, position.Column)
+}
+
+func (position Position) fileName() string {
+	if position.File == "" {
+		return "<input>"
+	}
+
+	return position.File
+}
+
+// Statement marks the first instruction of a compiled statement, debuggers pause the vm at statements
+type Statement struct {
+	Offset int
+	File   string
+	Line   int
+	Column int
+}
+
+// Statements maps instruction offsets to the source positions they were compiled from and marks the first
+// instruction of every compiled statement. Statements starting at the same offset are ordered from the outermost
+// to the innermost one. Positions of instructions are only stored when they change.
+// An entry is encoded relative to the previous one as varints: the offset delta, the file index with the statement
+// flag in its lowest bit, the line delta, the column and the length.
+type Statements struct {
+	data  []byte
+	files []string
+
+	// the next entry is encoded relative to the last one
+	lastOffset int
+	lastLine   int
+	entries    int
+
+	// position of the last instructions which were added
+	position    Position
+	hasPosition bool
+
+	// entries decoded by the first lookup, vms look up the position of every instruction while they are hooked
+	decoded atomic.Pointer[decodedStatements]
+}
+
+// decodedStatements are the entries of a table sorted by their offsets
+type decodedStatements struct {
+	positions  []positionEntry
+	statements []Statement
+}
+
+type positionEntry struct {
+	offset   int
+	position Position
+}
+
+// Add maps the instructions starting at offset to the position, offsets have to be added in increasing order
+func (table *Statements) Add(offset int, position Position) {
+	if position.Line == 0 || (table.hasPosition && position == table.position) {
+		return
+	}
+
+	table.add(offset, position, false)
+	table.position = position
+	table.hasPosition = true
+}
+
+// AddStatement marks the instruction at the offset of the statement as the first one of the statement
+func (table *Statements) AddStatement(statement Statement) {
+	table.add(statement.Offset, Position{File: statement.File, Line: statement.Line, Column: statement.Column}, true)
+}
+
+func (table *Statements) add(offset int, position Position, statement bool) {
+	flags := uint64(table.fileIndex(position.File)) << 1
+	if statement {
+		flags |= 1
+	}
+
+	var buffer [5 * binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buffer[:], uint64(offset-table.lastOffset))
+	n += binary.PutUvarint(buffer[n:], flags)
+	n += binary.PutVarint(buffer[n:], int64(position.Line-table.lastLine))
+	n += binary.PutUvarint(buffer[n:], uint64(position.Column))
+	n += binary.PutUvarint(buffer[n:], uint64(position.Length))
+
+	table.data = append(table.data, buffer[:n]...)
+	table.lastOffset = offset
+	table.lastLine = position.Line
+	table.entries++
+	table.decoded.Store(nil)
+}
+
+// Lookup returns the position of the instruction at the offset
+func (table *Statements) Lookup(offset int) (Position, bool) {
+	positions := table.decode().positions
+
+	// the last entry starting at or before the offset
+	i := sort.Search(len(positions), func(i int) bool { return positions[i].offset > offset }) - 1
+	if i < 0 {
+		return Position{}, false
+	}
+
+	return positions[i].position, true
+}
+
+// StatementsAt returns the statements which start at the instruction offset
+func (table *Statements) StatementsAt(offset int) []Statement {
+	statements := table.decode().statements
+
+	start := sort.Search(len(statements), func(i int) bool { return statements[i].Offset >= offset })
+	end := start
+	for end < len(statements) && statements[end].Offset == offset {
+		end++
+	}
+
+	if start == end {
+		return nil
+	}
+
+	return statements[start:end:end]
+}
+
+// StatementOf returns the statement the instruction at the offset belongs to, the last one starting before it
+func (table *Statements) StatementOf(offset int) (Statement, bool) {
+	statements := table.decode().statements
+
+	i := sort.Search(len(statements), func(i int) bool { return statements[i].Offset > offset }) - 1
+	if i < 0 {
+		return Statement{}, false
+	}
+
+	return statements[i], true
+}
+
+// decode returns the decoded entries, they are decoded once after the last entry was added
+func (table *Statements) decode() *decodedStatements {
+	if table == nil {
+		return &decodedStatements{}
+	}
+
+	if decoded := table.decoded.Load(); decoded != nil {
+		return decoded
+	}
+
+	decoded := &decodedStatements{}
+	table.each(func(offset int, position Position, statement bool) bool {
+		if statement {
+			decoded.statements = append(decoded.statements, Statement{Offset: offset, File: position.File, Line: position.Line, Column: position.Column})
+		} else {
+			decoded.positions = append(decoded.positions, positionEntry{offset: offset, position: position})
+		}
+
+		return true
+	})
+
+	table.decoded.Store(decoded)
+
+	return decoded
+}
+
+// Size returns the number of bytes used by the encoded entries
+func (table *Statements) Size() int {
+	return len(table.data)
+}
+
+// each decodes the entries in order until the callback returns false
+func (table *Statements) each(callback func(offset int, position Position, statement bool) bool) {
+	if table == nil {
+		return
+	}
+
+	offset := 0
+	line := 0
+
+	for data := table.data; len(data) > 0; {
+		delta, n := binary.Uvarint(data)
+		data = data[n:]
+		flags, n := binary.Uvarint(data)
+		data = data[n:]
+		lineDelta, n := binary.Varint(data)
+		data = data[n:]
+		column, n := binary.Uvarint(data)
+		data = data[n:]
+		length, n := binary.Uvarint(data)
+		data = data[n:]
+
+		offset += int(delta)
+		line += int(lineDelta)
+
+		position := Position{
+			File:   table.files[flags>>1],
+			Line:   line,
+			Column: int(column),
+			Length: int(length),
+		}
+
+		if !callback(offset, position, flags&1 == 1) {
+			return
+		}
+	}
+}
+
+func (table *Statements) fileIndex(file string) int {
+	for i, known := range table.files {
+		if known == file {
+			return i
+		}
+	}
+
+	table.files = append(table.files, file)
+	return len(table.files) - 1
+}
@@ -0,0 +1,136 @@
+package code
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStatementPositions(t *testing.T) {
+	table := &Statements{}
+	table.Add(0, Position{File: "main.curry", Line: 1, Column: 1, Length: 3})
+	table.Add(3, Position{File: "main.curry", Line: 1, Column: 1, Length: 3})
+	table.Add(4, Position{Line: 0})
+	table.Add(6, Position{File: "main.curry", Line: 12, Column: 5, Length: 1})
+	table.Add(9, Position{File: "lib.curry", Line: 2, Column: 3, Length: 4})
+	table.Add(300, Position{File: "main.curry", Line: 3, Column: 1, Length: 2})
+
+	tests := []struct {
+		offset   int
+		expected string
+	}{
+		{0, "main.curry:1:1"},
+		{5, "main.curry:1:1"},
+		{6, "main.curry:12:5"},
+		{299, "lib.curry:2:3"},
+		{300, "main.curry:3:1"},
+		{1000, "main.curry:3:1"},
+	}
+
+	for _, tt := range tests {
+		position, ok := table.Lookup(tt.offset)
+		if !ok || position.String() != tt.expected {
+			t.Errorf("wrong position of %d. want=%s, got=%s", tt.offset, tt.expected, position)
+		}
+	}
+
+	// unchanged and unknown positions are not stored
+	if table.entries != 4 {
+		t.Errorf("wrong number of entries. want=4, got=%d", table.entries)
+	}
+
+	if table.Size() > 4*5+1 {
+		t.Errorf("line table is not compact. got=%d bytes", table.Size())
+	}
+
+	if _, ok := (&Statements{}).Lookup(0); ok {
+		t.Errorf("empty table has a position")
+	}
+}
+
+func TestStatementsAt(t *testing.T) {
+	table := &Statements{}
+	table.AddStatement(Statement{Offset: 0, File: "main.curry", Line: 1, Column: 1})
+	table.Add(0, Position{File: "main.curry", Line: 1, Column: 9, Length: 1})
+	table.AddStatement(Statement{Offset: 6, File: "main.curry", Line: 2, Column: 1})
+	table.AddStatement(Statement{Offset: 6, File: "main.curry", Line: 2, Column: 7})
+	table.Add(6, Position{File: "main.curry", Line: 2, Column: 11, Length: 1})
+
+	if statements := table.StatementsAt(6); fmt.Sprint(statements) != "[{6 main.curry 2 1} {6 main.curry 2 7}]" {
+		t.Errorf("wrong statements at 6. got=%v", statements)
+	}
+
+	if statements := table.StatementsAt(3); len(statements) != 0 {
+		t.Errorf("wrong statements at 3. got=%v", statements)
+	}
+
+	tests := []struct {
+		offset    int
+		statement string
+		position  string
+	}{
+		{0, "{0 main.curry 1 1}", "main.curry:1:9"},
+		{5, "{0 main.curry 1 1}", "main.curry:1:9"},
+		{6, "{6 main.curry 2 7}", "main.curry:2:11"},
+	}
+
+	for _, tt := range tests {
+		statement, ok := table.StatementOf(tt.offset)
+		if !ok || fmt.Sprint(statement) != tt.statement {
+			t.Errorf("wrong statement of %d. want=%s, got=%v", tt.offset, tt.statement, statement)
+		}
+
+		// statements do not change the positions of the instructions
+		position, ok := table.Lookup(tt.offset)
+		if !ok || position.String() != tt.position {
+			t.Errorf("wrong position of %d. want=%s, got=%s", tt.offset, tt.position, position)
+		}
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	instructions := Instructions{}
+	instructions = append(instructions, Make(OpConstant, 1)...)
+	instructions = append(instructions, Make(OpConstant, 2)...)
+	instructions = append(instructions, Make(OpAdd)...)
+	instructions = append(instructions, Make(OpPop)...)
+
+	table := &Statements{}
+	table.Add(0, Position{File: "main.curry", Line: 1, Column: 1})
+	table.Add(3, Position{File: "main.curry", Line: 1, Column: 5})
+	table.Add(7, Position{File: "main.curry", Line: 2, Column: 1})
+
+	sources := map[string][]string{"main.curry": {"1 + 2", "  ;"}}
+
+	expected := `; main.curry:1  1 + 2
+0000 OpConstant 1
+0003 OpConstant 2
+0006 OpAdd
+; main.curry:2  ;
+0007 OpPop
+`
+
+	if disassembled := instructions.Disassemble(table, sources); disassembled != expected {
+		t.Errorf("instructions wrongly disassembled.\nwant=%q\ngot=%q", expected, disassembled)
+	}
+}
+
+// BenchmarkStatementLookup looks up the statement and position of every instruction of a large function,
+// like a hooked vm does while it runs
+func BenchmarkStatementLookup(b *testing.B) {
+	table := &Statements{}
+	for i := 0; i < 2000; i++ {
+		table.AddStatement(Statement{Offset: i * 10, File: "main.curry", Line: i + 1, Column: 1})
+		table.Add(i*10, Position{File: "main.curry", Line: i + 1, Column: 5, Length: 1})
+		table.Add(i*10+5, Position{File: "main.curry", Line: i + 1, Column: 9, Length: 1})
+	}
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		for offset := 0; offset < 20000; offset += 3 {
+			table.StatementsAt(offset)
+			table.StatementOf(offset)
+			table.Lookup(offset)
+		}
+	}
+}
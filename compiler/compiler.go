@@ -13,31 +13,78 @@ type EmittedInstruction struct {
 	Pos  int
 }
 
-type Compiler struct {
-	instructions code.Instructions
-	constants    []object.Object
-	symbols      *SymbolTable
+type CompiledStruct struct {
+	Struct        *object.Struct
+	ConstantIndex int
+}
+
+// CompiledTrait is a declared trait together with its default methods, they are added to the structs implementing it
+type CompiledTrait struct {
+	Trait   *object.Trait
+	Methods map[string]*object.CompiledFunction
+}
 
+// CompilationScope holds the instructions of the program or of the function which is currently compiled
+type CompilationScope struct {
+	instructions  code.Instructions
+	handlers      []code.Handler
+	statements    *code.Statements
+	pending       []code.Statement // statements whose first instruction was not emitted yet
 	previousInstr *EmittedInstruction
 	currentInstr  *EmittedInstruction
 }
 
+type Compiler struct {
+	constants []object.Object
+	symbols   *SymbolTable
+	structs   map[string]CompiledStruct
+	traits    map[string]CompiledTrait
+
+	scopes     []CompilationScope
+	scopeIndex int
+
+	// File is the source file which is compiled next, it is stored in the line tables
+	File string
+
+	// token of the node which is currently compiled, emitted instructions are mapped to its position
+	position token.Token
+}
+
 type Bytecode struct {
 	Instructions code.Instructions
 	Constants    []object.Object
+	Globals      map[string]int   // index of every global by its name
+	Handlers     []code.Handler   // try blocks of the main program
+	Statements   *code.Statements // source positions of the statements and instructions of the main program
 }
 
 func New() *Compiler {
+	symbols := NewSymbolTable()
+	for i, name := range object.BuiltinNames {
+		symbols.DefineBuiltin(i, name)
+	}
+
+	traits := map[string]CompiledTrait{}
+	for name, trait := range object.BuiltinTraits() {
+		traits[name] = CompiledTrait{Trait: trait, Methods: map[string]*object.CompiledFunction{}}
+	}
+
 	return &Compiler{
-		instructions: code.Instructions{},
-		constants:    []object.Object{},
-		symbols:      NewSymbolTable(),
+		constants: []object.Object{},
+		symbols:   symbols,
+		structs:   map[string]CompiledStruct{},
+		traits:    traits,
+		scopes:    []CompilationScope{newCompilationScope()},
 	}
 }
 
+func newCompilationScope() CompilationScope {
+	return CompilationScope{instructions: code.Instructions{}, statements: &code.Statements{}}
+}
+
 func (c *Compiler) CompileStatements(statements []ast.Statement) error {
 	for _, s := range statements {
-		err := c.Compile(s)
+		err := c.compileStatement(s)
 		if err != nil {
 			return err
 		}
@@ -46,7 +93,50 @@ func (c *Compiler) CompileStatements(statements []ast.Statement) error {
 	return nil
 }
 
+func (c *Compiler) compileStatement(statement ast.Statement) error {
+	return c.compileStatementWith(statement, func() error { return c.Compile(statement) })
+}
+
+// compileStatementWith compiles a statement with the function and records its position for debuggers,
+// statements without instructions like struct declarations are not recorded
+func (c *Compiler) compileStatementWith(statement ast.Statement, compile func() error) error {
+	position := statement.Pos()
+	start := len(c.currentInstructions())
+
+	// the statement is added to the table by emit with its first instruction, before the nested statements
+	index := len(c.scopes[c.scopeIndex].pending)
+	c.scopes[c.scopeIndex].pending = append(c.scopes[c.scopeIndex].pending, code.Statement{
+		File:   c.File,
+		Line:   position.Line,
+		Column: position.Column,
+	})
+
+	err := compile()
+	if err != nil {
+		return err
+	}
+
+	scope := &c.scopes[c.scopeIndex]
+	if len(scope.instructions) == start {
+		scope.pending = scope.pending[:index]
+	}
+
+	return nil
+}
+
 func (c *Compiler) Compile(node ast.Node) error {
+	parent := c.position
+	if position := node.Pos(); position.Line != 0 {
+		c.position = position
+	}
+
+	err := c.compile(node)
+	c.position = parent
+
+	return err
+}
+
+func (c *Compiler) compile(node ast.Node) error {
 	switch node := node.(type) {
 	case *ast.Program:
 		err := c.CompileStatements(node.Statements)
@@ -55,17 +145,146 @@ func (c *Compiler) Compile(node ast.Node) error {
 		}
 
 	case *ast.LetStatement:
-		variableName := node.Name.Value
-		symbol := c.symbols.Define(variableName)
+		err := c.Compile(node.Value)
+		if err != nil {
+			return err
+		}
+
+		if node.Pattern != nil {
+			return c.compileDestructuring(node.Pattern)
+		}
+
+		symbol := c.symbols.Define(node.Name.Value)
+		c.setSymbol(symbol)
+
+	case *ast.AssignmentStatement:
+		symbol, err := c.resolve(node.Name.Value)
+		if err != nil {
+			return err
+		}
+
+		if symbol.Scope == BuiltinScope {
+			return fmt.Errorf("builtin %s can not be assigned", node.Name.Value)
+		}
+
+		err = c.Compile(node.Value)
+		if err != nil {
+			return err
+		}
+
+		c.setSymbol(symbol)
+
+	case *ast.WhileStatement:
+		err := c.compileWhileStatement(node)
+		if err != nil {
+			return err
+		}
+
+	case *ast.ForInStatement:
+		err := c.compileForInStatement(node)
+		if err != nil {
+			return err
+		}
+
+	case *ast.TryStatement:
+		err := c.compileTryStatement(node)
+		if err != nil {
+			return err
+		}
 
+	case *ast.ThrowStatement:
 		err := c.Compile(node.Value)
 		if err != nil {
 			return err
 		}
 
-		c.emit(code.OpSetGlobal, symbol.Index)
+		c.emit(code.OpThrow)
+
+	case *ast.YieldStatement:
+		if c.scopeIndex == 0 {
+			return fmt.Errorf("yield can only be used inside of functions")
+		}
+
+		err := c.Compile(node.Value)
+		if err != nil {
+			return err
+		}
+
+		c.emit(code.OpYield)
+
+	case *ast.SelectStatement:
+		err := c.compileSelectStatement(node)
+		if err != nil {
+			return err
+		}
+
+	case *ast.ReturnStatement:
+		err := c.Compile(node.ReturnValue)
+		if err != nil {
+			return err
+		}
+
+		c.emit(code.OpReturnValue)
+
+	case *ast.StructStatement:
+		structObj := &object.Struct{
+			Name:            node.Name.Value,
+			Traits:          map[string]*object.Trait{},
+			CompiledMethods: map[string]*object.CompiledFunction{},
+		}
+		for _, field := range node.Fields {
+			if structObj.HasField(field.Value) {
+				return fmt.Errorf("field %s is declared twice in struct %s", field.Value, structObj.Name)
+			}
+
+			structObj.Fields = append(structObj.Fields, field.Value)
+		}
+
+		c.structs[structObj.Name] = CompiledStruct{
+			Struct:        structObj,
+			ConstantIndex: c.addConstant(structObj),
+		}
+
+	case *ast.TraitStatement:
+		err := c.compileTraitStatement(node)
+		if err != nil {
+			return err
+		}
+
+	case *ast.ImplStatement:
+		err := c.compileImplStatement(node)
+		if err != nil {
+			return err
+		}
+
+	case *ast.FieldAssignmentStatement:
+		err := c.Compile(node.Target.Source)
+		if err != nil {
+			return err
+		}
+
+		err = c.Compile(node.Value)
+		if err != nil {
+			return err
+		}
+
+		fieldName := node.Target.Value.(*ast.Identifier).Value
+		c.emit(code.OpSetField, c.addConstant(&object.String{Value: fieldName}))
 
 	case *ast.ExpressionStatement:
+		// named functions are declared like variables and leave no value on the stack
+		if function, ok := node.Expression.(*ast.FunctionExpression); ok && function.Name != "" {
+			symbol := c.symbols.Define(function.Name)
+
+			err := c.compileFunction(function)
+			if err != nil {
+				return err
+			}
+
+			c.setSymbol(symbol)
+			return nil
+		}
+
 		err := c.Compile(node.Expression)
 		if err != nil {
 			return err
@@ -116,6 +335,10 @@ func (c *Compiler) Compile(node ast.Node) error {
 			c.emit(code.OpEqual)
 		case "!=":
 			c.emit(code.OpNotEqual)
+		case "..":
+			c.emit(code.OpRange, 0)
+		case "..=":
+			c.emit(code.OpRange, 1)
 		default:
 			return fmt.Errorf("unknown operator %s", node.Operator)
 		}
@@ -148,88 +371,1036 @@ func (c *Compiler) Compile(node ast.Node) error {
 			c.emit(code.OpFalse)
 		}
 
+	case *ast.StringLiteral:
+		c.emit(code.OpConstant, c.addConstant(&object.String{Value: node.Value}))
+
+	case *ast.IndexAccessExpression:
+		err := c.Compile(node.Source)
+		if err != nil {
+			return err
+		}
+
+		if !node.Slice {
+			err = c.Compile(node.Value)
+			if err != nil {
+				return err
+			}
+
+			c.emit(code.OpIndex)
+			return nil
+		}
+
+		for _, bound := range []ast.Expression{node.Value, node.End} {
+			if bound == nil {
+				c.emit(code.OpNull)
+				continue
+			}
+
+			err = c.Compile(bound)
+			if err != nil {
+				return err
+			}
+		}
+
+		c.emit(code.OpSlice)
+
+	case *ast.ListExpression:
+		for _, element := range node.Value {
+			err := c.Compile(element)
+			if err != nil {
+				return err
+			}
+		}
+
+		c.emit(code.OpList, len(node.Value))
+
 	case *ast.Identifier:
-		if val, ok := c.symbols.Resolve(node.Value); ok {
-			c.emit(code.OpGetGlobal, val.Index)
-		} else {
-			return fmt.Errorf("there variable %s has not yet been defined", node.Value)
+		symbol, err := c.resolve(node.Value)
+		if err != nil {
+			return err
+		}
+
+		switch symbol.Scope {
+		case LocalScope:
+			c.emit(code.OpGetLocal, symbol.Index)
+		case BuiltinScope:
+			c.emit(code.OpGetBuiltin, symbol.Index)
+		default:
+			c.emit(code.OpGetGlobal, symbol.Index)
+		}
+
+	case *ast.FunctionExpression:
+		err := c.compileFunction(node)
+		if err != nil {
+			return err
+		}
+
+	case *ast.FunctionCallExpression:
+		err := c.Compile(node.FunctionExpr)
+		if err != nil {
+			return err
+		}
+
+		err = c.compileArguments(node.Parameters, node.NamedParameters)
+		if err != nil {
+			return err
+		}
+
+	case *ast.SpawnExpression:
+		// the arguments of a spawned call are evaluated by the spawning task
+		call, ok := node.Function.(*ast.FunctionCallExpression)
+		if !ok {
+			err := c.Compile(node.Function)
+			if err != nil {
+				return err
+			}
+
+			c.emit(code.OpSpawn, 0)
+			return nil
+		}
+
+		if len(call.NamedParameters) > 0 {
+			return fmt.Errorf("spawned calls can not have named arguments")
+		}
+
+		err := c.Compile(call.FunctionExpr)
+		if err != nil {
+			return err
+		}
+
+		for _, argument := range call.Parameters {
+			err := c.Compile(argument)
+			if err != nil {
+				return err
+			}
 		}
 
+		c.emit(code.OpSpawn, len(call.Parameters))
+
 	case *ast.IfElseExpression:
-		err := c.compileIfExpression(node)
+		err := c.compileIfExpression(node, false)
+		if err != nil {
+			return err
+		}
+
+	case *ast.MatchExpression:
+		err := c.compileMatchExpression(node)
+		if err != nil {
+			return err
+		}
+
+	case *ast.StructExpression:
+		err := c.compileStructExpression(node)
+		if err != nil {
+			return err
+		}
+
+	case *ast.DotAccessExpression:
+		err := c.Compile(node.Source)
 		if err != nil {
 			return err
 		}
+
+		switch value := node.Value.(type) {
+		case *ast.Identifier:
+			c.emit(code.OpGetField, c.addConstant(&object.String{Value: value.Value}))
+
+		// calls a method of the instance or a function stored in one of its fields
+		case *ast.FunctionCallExpression:
+			method, ok := value.FunctionExpr.(*ast.Identifier)
+			if !ok {
+				return fmt.Errorf("only field access is supported on %s", node.Source.String())
+			}
+
+			c.emit(code.OpGetMethod, c.addConstant(&object.String{Value: method.Value}))
+
+			err = c.compileArguments(value.Parameters, value.NamedParameters)
+			if err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("only field access is supported on %s", node.Source.String())
+		}
 	}
 
 	return nil
 }
 
-func (c *Compiler) compileIfExpression(ifExpr *ast.IfElseExpression) error {
+// compileIfExpression compiles the branches as statements, if value is set they leave their value on the stack
+// like blocks of match arms and a missing alternative leaves null
+func (c *Compiler) compileIfExpression(ifExpr *ast.IfElseExpression, value bool) error {
 	err := c.Compile(ifExpr.Condition)
 	if err != nil {
 		return err
 	}
 
-	conditionJumpPos := c.emit(code.OpJumpIfFalse, 0)
+	compileBranch := c.CompileStatements
+	if value {
+		compileBranch = c.compileBlockValue
+	}
+
+	// the jump targets are patched once the length of the branches is known
+	conditionJumpPos := c.emit(code.OpJumpIfFalse, 9999)
 
-	err = c.CompileStatements(ifExpr.Consequence)
+	err = compileBranch(ifExpr.Consequence)
 	if err != nil {
 		return err
 	}
 
-	if len(ifExpr.Alternative) > 0 {
-		endJumpPos := c.emit(code.OpJump, 0)
-		c.updateInstruction(conditionJumpPos, code.OpJumpIfFalse, c.currentInstr.Pos+3-conditionJumpPos)
+	if len(ifExpr.Alternative) > 0 || value {
+		endJumpPos := c.emit(code.OpJump, 9999)
+		c.changeOperand(conditionJumpPos, len(c.currentInstructions()))
 
-		err = c.CompileStatements(ifExpr.Alternative)
+		err = compileBranch(ifExpr.Alternative)
 		if err != nil {
 			return err
 		}
 
-		c.updateInstruction(endJumpPos, code.OpJump, c.currentInstr.Pos-endJumpPos)
+		c.changeOperand(endJumpPos, len(c.currentInstructions()))
 	} else {
-		c.updateInstruction(conditionJumpPos, code.OpJumpIfFalse, c.currentInstr.Pos-1-conditionJumpPos)
+		c.changeOperand(conditionJumpPos, len(c.currentInstructions()))
 	}
 
 	return nil
 }
 
-func (c *Compiler) Bytecode() *Bytecode {
-	return &Bytecode{
-		Instructions: c.instructions,
-		Constants:    c.constants,
+// compileMatchExpression stores the value in a hidden variable and tests the arms in order, the body of the matching
+// arm leaves its value on the stack. Matches of literals without guards jump to the matching arm with a jump table.
+func (c *Compiler) compileMatchExpression(match *ast.MatchExpression) error {
+	err := c.Compile(match.Value)
+	if err != nil {
+		return err
 	}
-}
 
-func (c *Compiler) addConstant(obj object.Object) int {
-	c.constants = append(c.constants, obj)
-	return len(c.constants) - 1
-}
+	value := c.symbols.DefineHidden()
+	c.setSymbol(value)
 
-func (c *Compiler) emit(op code.Opcode, operands ...int) int {
-	ins := code.Make(op, operands...)
-	pos := c.addInstruction(ins)
+	if usesJumpTable(match) {
+		return c.compileJumpTable(match, value)
+	}
 
-	c.previousInstr = c.currentInstr
-	c.currentInstr = &EmittedInstruction{
-		Code: op,
-		Pos:  pos,
+	var endJumps []int
+
+	for _, arm := range match.Arms {
+		pattern, patternErr := object.NewPattern(arm.Pattern, c.lookupStruct)
+		if patternErr != nil {
+			return fmt.Errorf("%s", patternErr.Message)
+		}
+
+		c.getSymbol(value)
+		c.emit(code.OpMatch, c.addConstant(pattern))
+		nextJumps := []int{c.emit(code.OpJumpIfFalse, 9999)}
+
+		// the bound values are pushed in the order of their declaration, the variables are only visible in the arm
+		names := ast.PatternBindings(arm.Pattern)
+		endScope := c.symbols.Scope(names)
+		for i := len(names) - 1; i >= 0; i-- {
+			c.setSymbol(c.symbols.Define(names[i]))
+		}
+
+		if arm.Guard != nil {
+			err = c.Compile(arm.Guard)
+			if err != nil {
+				return err
+			}
+
+			nextJumps = append(nextJumps, c.emit(code.OpJumpIfFalse, 9999))
+		}
+
+		err = c.compileBlockValue(arm.Body)
+		if err != nil {
+			return err
+		}
+
+		endScope()
+		endJumps = append(endJumps, c.emit(code.OpJump, 9999))
+
+		for _, pos := range nextJumps {
+			c.changeOperand(pos, len(c.currentInstructions()))
+		}
 	}
 
-	return pos
+	c.getSymbol(value)
+	c.emit(code.OpNoMatch)
+
+	for _, pos := range endJumps {
+		c.changeOperand(pos, len(c.currentInstructions()))
+	}
+
+	return nil
 }
 
-func (c *Compiler) addInstruction(ins []byte) int {
-	posNewInstruction := len(c.instructions)
-	c.instructions = append(c.instructions, ins...)
-	return posNewInstruction
+// compileDefault sets the parameter to its default value if it got no argument
+func (c *Compiler) compileDefault(parameter Symbol, value ast.Expression) error {
+	pos := c.emit(code.OpDefault, parameter.Index, 9999)
+
+	err := c.Compile(value)
+	if err != nil {
+		return err
+	}
+
+	c.setSymbol(parameter)
+	c.replaceInstruction(pos, code.Make(code.OpDefault, parameter.Index, len(c.currentInstructions())))
+
+	return nil
 }
 
-func (c *Compiler) updateInstruction(pos int, op code.Opcode, operands ...int) {
-	ins := code.Make(op, operands...)
+// compileDestructuring destructures the value on the stack and sets the variables bound by the pattern
+func (c *Compiler) compileDestructuring(pattern ast.Pattern) error {
+	matcher, err := object.NewPattern(pattern, c.lookupStruct)
+	if err != nil {
+		return fmt.Errorf("%s", err.Message)
+	}
+
+	c.emit(code.OpDestructure, c.addConstant(matcher))
+
+	// the bound values are pushed in the order of their declaration
+	names := ast.PatternBindings(pattern)
+	for i := len(names) - 1; i >= 0; i-- {
+		c.setSymbol(c.symbols.Define(names[i]))
+	}
+
+	return nil
+}
+
+// usesJumpTable reports if the arms only match literals without guards, only the last arm can be a wildcard
+func usesJumpTable(match *ast.MatchExpression) bool {
+	for i, arm := range match.Arms {
+		if arm.Guard != nil {
+			return false
+		}
+
+		if _, ok := arm.Pattern.(*ast.WildcardPattern); ok && i == len(match.Arms)-1 {
+			continue
+		}
+
+		if len(literalPatterns(arm.Pattern)) == 0 {
+			return false
+		}
+	}
+
+	return len(match.Arms) > 0
+}
+
+// literalPatterns returns the literals of a literal pattern or of alternatives of them, nil for other patterns
+func literalPatterns(pattern ast.Pattern) []*ast.LiteralPattern {
+	switch pattern := pattern.(type) {
+	case *ast.LiteralPattern:
+		return []*ast.LiteralPattern{pattern}
+	case *ast.AlternativePattern:
+		var literals []*ast.LiteralPattern
+		for _, alternative := range pattern.Alternatives {
+			literal, ok := alternative.(*ast.LiteralPattern)
+			if !ok {
+				return nil
+			}
+
+			literals = append(literals, literal)
+		}
+
+		return literals
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileJumpTable(match *ast.MatchExpression, value Symbol) error {
+	table := &object.JumpTable{Targets: map[object.JumpKey]int{}, Default: -1}
+
+	c.getSymbol(value)
+	c.emit(code.OpJumpTable, c.addConstant(table))
+
+	var endJumps []int
+
+	for _, arm := range match.Arms {
+		start := len(c.currentInstructions())
+
+		literals := literalPatterns(arm.Pattern)
+		if literals == nil {
+			table.Default = start
+		}
+
+		for _, literal := range literals {
+			pattern, err := object.NewPattern(literal, c.lookupStruct)
+			if err != nil {
+				return fmt.Errorf("%s", err.Message)
+			}
+
+			key, _ := object.JumpKeyOf(pattern.(*object.LiteralPattern).Value)
+
+			// the first arm matching a literal is taken
+			if _, ok := table.Targets[key]; !ok {
+				table.Targets[key] = start
+			}
+		}
+
+		err := c.compileBlockValue(arm.Body)
+		if err != nil {
+			return err
+		}
+
+		endJumps = append(endJumps, c.emit(code.OpJump, 9999))
+	}
+
+	if table.Default < 0 {
+		table.Default = len(c.currentInstructions())
+		c.getSymbol(value)
+		c.emit(code.OpNoMatch)
+	}
+
+	for _, pos := range endJumps {
+		c.changeOperand(pos, len(c.currentInstructions()))
+	}
+
+	return nil
+}
+
+// compileBlockValue compiles a block which leaves the value of its last expression statement on the stack, or null.
+// An if expression as last statement leaves the value of its taken branch.
+func (c *Compiler) compileBlockValue(body []ast.Statement) error {
+	if last, ok := lastIfExpression(body); ok {
+		err := c.CompileStatements(body[:len(body)-1])
+		if err != nil {
+			return err
+		}
+
+		ifExpr := last.Expression.(*ast.IfElseExpression)
+		return c.compileStatementWith(last, func() error { return c.compileIfExpression(ifExpr, true) })
+	}
+
+	err := c.CompileStatements(body)
+	if err != nil {
+		return err
+	}
+
+	if len(body) > 0 && LeavesValue(body[len(body)-1]) {
+		c.removeLastPop()
+	} else {
+		c.emit(code.OpNull)
+	}
+
+	return nil
+}
+
+// compileTraitStatement declares a trait, its default methods are compiled once and shared by the implementing structs
+func (c *Compiler) compileTraitStatement(statement *ast.TraitStatement) error {
+	compiled := CompiledTrait{
+		Trait: &object.Trait{
+			Name:           statement.Name.Value,
+			DefaultMethods: map[string]*object.Function{},
+		},
+		Methods: map[string]*object.CompiledFunction{},
+	}
+
+	declared := map[string]bool{}
+
+	for _, method := range append(statement.RequiredMethods, statement.DefaultMethods...) {
+		if declared[method.Name] {
+			return fmt.Errorf("method %s is declared twice in trait %s", method.Name, statement.Name.Value)
+		}
+
+		if !hasSelfParameter(method) {
+			return fmt.Errorf("method %s of trait %s has to take self as first parameter", method.Name, statement.Name.Value)
+		}
+
+		declared[method.Name] = true
+	}
+
+	for _, method := range statement.RequiredMethods {
+		compiled.Trait.RequiredMethods = append(compiled.Trait.RequiredMethods, &object.Function{
+			Name:       method.Name,
+			Parameters: method.Parameters,
+		})
+	}
+
+	for _, method := range statement.DefaultMethods {
+		function, err := c.compileFunctionObject(method)
+		if err != nil {
+			return err
+		}
+
+		compiled.Trait.DefaultMethods[method.Name] = &object.Function{Name: method.Name, Parameters: method.Parameters}
+		compiled.Methods[method.Name] = function
+	}
+
+	c.traits[compiled.Trait.Name] = compiled
+
+	return nil
+}
+
+// compileImplStatement compiles the methods of an impl block and adds them to the struct,
+// the checks are the same as the ones of the interpreter
+func (c *Compiler) compileImplStatement(statement *ast.ImplStatement) error {
+	compiledStruct, ok := c.structs[statement.Struct.Value]
+	if !ok {
+		return fmt.Errorf("struct %s has not yet been defined", statement.Struct.Value)
+	}
+
+	structObj := compiledStruct.Struct
+	methods := map[string]*object.CompiledFunction{}
+	parameters := map[string]int{}
+
+	for _, method := range statement.Methods {
+		if _, ok := structObj.CompiledMethods[method.Name]; ok || methods[method.Name] != nil {
+			return fmt.Errorf("method %s is already implemented for struct %s", method.Name, structObj.Name)
+		}
+
+		if !hasSelfParameter(method) {
+			return fmt.Errorf("method %s of struct %s has to take self as first parameter", method.Name, structObj.Name)
+		}
+
+		function, err := c.compileFunctionObject(method)
+		if err != nil {
+			return err
+		}
+
+		methods[method.Name] = function
+		parameters[method.Name] = len(method.Parameters)
+	}
+
+	if statement.Trait != nil {
+		trait, ok := c.traits[statement.Trait.Value]
+		if !ok {
+			return fmt.Errorf("trait %s has not yet been defined", statement.Trait.Value)
+		}
+
+		if structObj.Implements(trait.Trait.Name) {
+			return fmt.Errorf("struct %s already implements trait %s", structObj.Name, trait.Trait.Name)
+		}
+
+		for _, required := range trait.Trait.RequiredMethods {
+			if _, ok := methods[required.Name]; !ok {
+				return fmt.Errorf("struct %s does not implement method %s of trait %s", structObj.Name, required.Name, trait.Trait.Name)
+			}
+
+			if parameters[required.Name] != len(required.Parameters) {
+				return fmt.Errorf(
+					"method %s of trait %s expects %d parameters but got %d",
+					required.Name,
+					trait.Trait.Name,
+					len(required.Parameters),
+					parameters[required.Name],
+				)
+			}
+		}
+
+		for name, method := range trait.Methods {
+			if _, ok := structObj.CompiledMethods[name]; !ok && methods[name] == nil {
+				methods[name] = method
+			}
+		}
+
+		structObj.Traits[trait.Trait.Name] = trait.Trait
+	}
+
+	for name, method := range methods {
+		structObj.CompiledMethods[name] = method
+	}
+
+	return nil
+}
+
+func hasSelfParameter(method *ast.FunctionExpression) bool {
+	return len(method.Parameters) > 0 && method.Parameters[0].Name == "self"
+}
+
+func (c *Compiler) lookupStruct(name string) (*object.Struct, bool) {
+	if name == object.ErrorStruct.Name {
+		return object.ErrorStruct, true
+	}
+
+	compiled, ok := c.structs[name]
+	return compiled.Struct, ok
+}
+
+func (c *Compiler) compileWhileStatement(statement *ast.WhileStatement) error {
+	conditionPos := len(c.currentInstructions())
+
+	err := c.Compile(statement.Condition)
+	if err != nil {
+		return err
+	}
+
+	exitJumpPos := c.emit(code.OpJumpIfFalse, 9999)
+
+	err = c.CompileStatements(statement.Body)
+	if err != nil {
+		return err
+	}
+
+	c.emit(code.OpJump, conditionPos)
+	c.changeOperand(exitJumpPos, len(c.currentInstructions()))
+
+	return nil
+}
+
+// compileForInStatement stores the iterator in a hidden variable, so the stack is empty while the body runs
+func (c *Compiler) compileForInStatement(statement *ast.ForInStatement) error {
+	err := c.Compile(statement.Iterable)
+	if err != nil {
+		return err
+	}
+
+	variables := 1
+	if statement.Key != nil {
+		variables = 2
+	}
+
+	c.emit(code.OpIter, variables)
+	iterator := c.symbols.DefineHidden()
+	c.setSymbol(iterator)
+
+	nextPos := len(c.currentInstructions())
+	c.getSymbol(iterator)
+	exitJumpPos := c.emit(code.OpIterNext, 9999)
+	c.setSymbol(c.symbols.Define(statement.Variable.Value))
+
+	// the key is pushed below the value
+	if statement.Key != nil {
+		c.setSymbol(c.symbols.Define(statement.Key.Value))
+	} else {
+		c.emit(code.OpPop)
+	}
+
+	err = c.CompileStatements(statement.Body)
+	if err != nil {
+		return err
+	}
+
+	c.emit(code.OpJump, nextPos)
+	c.changeOperand(exitJumpPos, len(c.currentInstructions()))
+
+	// the finished iterator is released
+	c.emit(code.OpNull)
+	c.setSymbol(iterator)
+
+	return nil
+}
+
+// compileTryStatement emits the body followed by a jump over the handler,
+// the handler table entry is added after the ones of nested try blocks so the innermost matches first
+func (c *Compiler) compileTryStatement(statement *ast.TryStatement) error {
+	start := len(c.currentInstructions())
+
+	err := c.CompileStatements(statement.Body)
+	if err != nil {
+		return err
+	}
+
+	end := len(c.currentInstructions())
+	endJumpPos := c.emit(code.OpJump, 9999)
+	target := len(c.currentInstructions())
+
+	// the vm pushes the error instance before it continues at the handler
+	if statement.Parameter != nil {
+		c.setSymbol(c.symbols.Define(statement.Parameter.Value))
+	} else {
+		c.emit(code.OpPop)
+	}
+
+	err = c.CompileStatements(statement.Handler)
+	if err != nil {
+		return err
+	}
+
+	c.changeOperand(endJumpPos, len(c.currentInstructions()))
+
+	scope := &c.scopes[c.scopeIndex]
+	scope.handlers = append(scope.handlers, code.Handler{Start: start, End: end, Target: target})
+
+	return nil
+}
+
+// compileSelectStatement pushes the operations of all cases for OpSelect, which is followed by the jumps to the
+// bodies of the cases, every body starts with the received value on top of the stack
+func (c *Compiler) compileSelectStatement(statement *ast.SelectStatement) error {
+	for _, selectCase := range statement.Cases {
+		err := c.Compile(selectCase.Channel)
+		if err != nil {
+			return err
+		}
+
+		if selectCase.Send {
+			err = c.Compile(selectCase.Value)
+			if err != nil {
+				return err
+			}
+
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpNull)
+			c.emit(code.OpFalse)
+		}
+	}
+
+	hasDefault := 0
+	if statement.HasDefault {
+		hasDefault = 1
+	}
+
+	c.emit(code.OpSelect, len(statement.Cases), hasDefault)
+
+	// the jump targets are patched once the bodies are compiled
+	jumps := make([]int, 0, len(statement.Cases)+hasDefault)
+	for i := 0; i < len(statement.Cases)+hasDefault; i++ {
+		jumps = append(jumps, c.emit(code.OpJump, 9999))
+	}
+
+	endJumps := make([]int, 0, len(statement.Cases))
+
+	for i, selectCase := range statement.Cases {
+		c.changeOperand(jumps[i], len(c.currentInstructions()))
+
+		if selectCase.Name != nil {
+			c.setSymbol(c.symbols.Define(selectCase.Name.Value))
+		} else {
+			c.emit(code.OpPop)
+		}
+
+		err := c.CompileStatements(selectCase.Body)
+		if err != nil {
+			return err
+		}
+
+		endJumps = append(endJumps, c.emit(code.OpJump, 9999))
+	}
+
+	if statement.HasDefault {
+		c.changeOperand(jumps[len(statement.Cases)], len(c.currentInstructions()))
+		c.emit(code.OpPop)
+
+		err := c.CompileStatements(statement.Default)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, pos := range endJumps {
+		c.changeOperand(pos, len(c.currentInstructions()))
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileFunction(function *ast.FunctionExpression) error {
+	compiled, err := c.compileFunctionObject(function)
+	if err != nil {
+		return err
+	}
+
+	c.emit(code.OpConstant, c.addConstant(compiled))
+
+	return nil
+}
+
+// compileFunctionObject compiles a function without adding it to the constants
+func (c *Compiler) compileFunctionObject(function *ast.FunctionExpression) (*object.CompiledFunction, error) {
+	c.enterScope()
+
+	// destructuring parameters are passed in hidden variables and destructured before the body
+	parameters := make([]Symbol, len(function.Parameters))
+	for i, parameter := range function.Parameters {
+		if parameter.Pattern != nil {
+			parameters[i] = c.symbols.DefineHidden()
+		} else {
+			parameters[i] = c.symbols.Define(parameter.Name)
+		}
+	}
+
+	for i, parameter := range function.Parameters {
+		if parameter.Default != nil {
+			err := c.compileDefault(parameters[i], parameter.Default)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if parameter.Pattern != nil {
+			c.getSymbol(parameters[i])
+
+			err := c.compileDestructuring(parameter.Pattern)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	compileBody := c.CompileStatements
+	_, returnsIf := lastIfExpression(function.Body)
+	if returnsIf {
+		compileBody = c.compileBlockValue
+	}
+
+	err := compileBody(function.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// the value of the last expression statement or if expression is returned implicitly
+	if returnsIf {
+		c.emit(code.OpReturnValue)
+	} else if len(function.Body) > 0 && LeavesValue(function.Body[len(function.Body)-1]) {
+		c.replaceLastPopWithReturn()
+	} else if len(function.Body) == 0 || !isReturn(function.Body[len(function.Body)-1]) {
+		c.emit(code.OpReturn)
+	}
+
+	numLocals := c.symbols.numDefinitions
+	locals := c.symbols.Definitions()
+	handlers := c.scopes[c.scopeIndex].handlers
+	statements := c.scopes[c.scopeIndex].statements
+	instructions := c.leaveScope()
+
+	compiled := &object.CompiledFunction{
+		Name:          function.Name,
+		Instructions:  instructions,
+		NumLocals:     numLocals,
+		NumParameters: len(function.Parameters),
+		Signature:     object.NewSignature(function.Parameters),
+		Handlers:      handlers,
+		Generator:     ast.ContainsYield(function.Body),
+		Statements:    statements,
+		Locals:        locals,
+	}
+
+	return compiled, nil
+}
+
+// leavesValue reports if the statement is compiled to an expression followed by OpPop
+func LeavesValue(statement ast.Statement) bool {
+	expression, ok := statement.(*ast.ExpressionStatement)
+	if !ok || expression.Token.Type == token.IF {
+		return false
+	}
+
+	function, ok := expression.Expression.(*ast.FunctionExpression)
+	return !ok || function.Name == ""
+}
+
+// lastIfExpression returns the last statement of the block if it is an if expression
+func lastIfExpression(body []ast.Statement) (*ast.ExpressionStatement, bool) {
+	if len(body) == 0 {
+		return nil, false
+	}
+
+	statement, ok := body[len(body)-1].(*ast.ExpressionStatement)
+	if !ok {
+		return nil, false
+	}
+
+	_, ok = statement.Expression.(*ast.IfElseExpression)
+	return statement, ok
+}
+
+func isReturn(statement ast.Statement) bool {
+	_, ok := statement.(*ast.ReturnStatement)
+	return ok
+}
+
+func (c *Compiler) compileArguments(arguments []ast.Expression, named []*ast.NamedArgument) error {
+	for _, argument := range arguments {
+		err := c.Compile(argument)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(named) == 0 {
+		c.emit(code.OpCall, len(arguments))
+		return nil
+	}
+
+	names := &object.List{ValueType: object.STRING_OBJ}
+	for _, argument := range named {
+		err := c.Compile(argument.Value)
+		if err != nil {
+			return err
+		}
+
+		names.Value = append(names.Value, &object.String{Value: argument.Name.Value})
+	}
+
+	c.emit(code.OpCallNamed, len(arguments)+len(named), c.addConstant(names))
+
+	return nil
+}
+
+func (c *Compiler) resolve(name string) (Symbol, error) {
+	symbol, ok := c.symbols.Resolve(name)
+	if !ok {
+		return symbol, fmt.Errorf("there variable %s has not yet been defined", name)
+	}
+
+	if symbol.Scope == FreeScope {
+		return symbol, fmt.Errorf("variable %s of an enclosing function can not be accessed, closures are not supported", name)
+	}
+
+	return symbol, nil
+}
+
+func (c *Compiler) getSymbol(symbol Symbol) {
+	if symbol.Scope == LocalScope {
+		c.emit(code.OpGetLocal, symbol.Index)
+	} else {
+		c.emit(code.OpGetGlobal, symbol.Index)
+	}
+}
+
+func (c *Compiler) setSymbol(symbol Symbol) {
+	if symbol.Scope == LocalScope {
+		c.emit(code.OpSetLocal, symbol.Index)
+	} else {
+		c.emit(code.OpSetGlobal, symbol.Index)
+	}
+}
+
+func (c *Compiler) compileStructExpression(structExpr *ast.StructExpression) error {
+	compiled, ok := c.structs[structExpr.Name.Value]
+	// the builtin error struct is only added to the constants once it is used
+	if !ok && structExpr.Name.Value == object.ErrorStruct.Name {
+		compiled = CompiledStruct{Struct: object.ErrorStruct, ConstantIndex: c.addConstant(object.ErrorStruct)}
+		c.structs[compiled.Struct.Name] = compiled
+		ok = true
+	}
+
+	if !ok {
+		return fmt.Errorf("struct %s has not yet been defined", structExpr.Name.Value)
+	}
+
+	values := map[string]ast.Expression{}
+	for _, field := range structExpr.Fields {
+		if !compiled.Struct.HasField(field.Name.Value) {
+			return fmt.Errorf("struct %s has no field %s", compiled.Struct.Name, field.Name.Value)
+		}
+
+		values[field.Name.Value] = field.Value
+	}
+
+	// field values are pushed in the order of the struct definition
+	for _, field := range compiled.Struct.Fields {
+		value, ok := values[field]
+		if !ok {
+			c.emit(code.OpNull)
+			continue
+		}
+
+		err := c.Compile(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.emit(code.OpInstance, compiled.ConstantIndex)
+
+	return nil
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.currentInstructions(),
+		Constants:    c.constants,
+		Globals:      c.symbols.Names(),
+		Handlers:     c.scopes[c.scopeIndex].handlers,
+		Statements:   c.scopes[c.scopeIndex].statements,
+	}
+}
+
+// DefineGlobal declares a global which is set by the embedding Go program, it returns the index of the global
+func (c *Compiler) DefineGlobal(name string) int {
+	symbol, ok := c.symbols.Resolve(name)
+	if !ok || symbol.Scope == BuiltinScope {
+		symbol = c.symbols.Define(name)
+	}
+
+	return symbol.Index
+}
+
+// ResetInstructions removes the instructions of the main program,
+// so the next program can be compiled with the globals, structs and constants of the previous ones
+func (c *Compiler) ResetInstructions() {
+	c.scopes[0] = newCompilationScope()
+}
+
+func (c *Compiler) currentInstructions() code.Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *Compiler) enterScope() {
+	c.scopes = append(c.scopes, newCompilationScope())
+	c.scopeIndex++
+	c.symbols = NewEnclosedSymbolTable(c.symbols)
+}
+
+func (c *Compiler) leaveScope() code.Instructions {
+	instructions := c.currentInstructions()
+
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex--
+	c.symbols = c.symbols.Outer
+
+	return instructions
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	ins := code.Make(op, operands...)
+	pos := c.addInstruction(ins)
+
+	scope := &c.scopes[c.scopeIndex]
+	for _, statement := range scope.pending {
+		statement.Offset = pos
+		scope.statements.AddStatement(statement)
+	}
+
+	scope.pending = scope.pending[:0]
+	scope.statements.Add(pos, code.Position{
+		File:   c.File,
+		Line:   c.position.Line,
+		Column: c.position.Column,
+		Length: len(c.position.Literal),
+	})
+
+	scope.previousInstr = scope.currentInstr
+	scope.currentInstr = &EmittedInstruction{
+		Code: op,
+		Pos:  pos,
+	}
+
+	return pos
+}
+
+func (c *Compiler) addInstruction(ins []byte) int {
+	posNewInstruction := len(c.currentInstructions())
+	c.scopes[c.scopeIndex].instructions = append(c.currentInstructions(), ins...)
+	return posNewInstruction
+}
+
+func (c *Compiler) replaceLastPopWithReturn() {
+	last := c.scopes[c.scopeIndex].currentInstr
+	c.replaceInstruction(last.Pos, code.Make(code.OpReturnValue))
+	last.Code = code.OpReturnValue
+}
+
+func (c *Compiler) removeLastPop() {
+	scope := &c.scopes[c.scopeIndex]
+	scope.instructions = scope.instructions[:scope.currentInstr.Pos]
+	scope.currentInstr = scope.previousInstr
+}
+
+func (c *Compiler) changeOperand(pos int, operand int) {
+	op := code.Opcode(c.currentInstructions()[pos])
+	c.replaceInstruction(pos, code.Make(op, operand))
+}
+
+func (c *Compiler) replaceInstruction(pos int, ins []byte) {
+	instructions := c.currentInstructions()
 
 	for i := 0; i < len(ins); i++ {
-		c.instructions[pos+i] = ins[i]
+		instructions[pos+i] = ins[i]
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"curryLang/object"
 	"curryLang/parser"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -162,10 +163,10 @@ func TestInstructionsGeneration(t *testing.T) {
 			expectedConstants: []interface{}{1, 2},
 			expectedInstructions: []code.Instructions{
 				code.Make(code.OpTrue),
-				code.Make(code.OpJumpIfFalse, 10),
+				code.Make(code.OpJumpIfFalse, 11),
 				code.Make(code.OpConstant, 0),
 				code.Make(code.OpPop),
-				code.Make(code.OpJump, 6),
+				code.Make(code.OpJump, 15),
 				code.Make(code.OpConstant, 1),
 				code.Make(code.OpPop),
 			},
@@ -175,7 +176,7 @@ func TestInstructionsGeneration(t *testing.T) {
 			expectedConstants: []interface{}{1},
 			expectedInstructions: []code.Instructions{
 				code.Make(code.OpTrue),
-				code.Make(code.OpJumpIfFalse, 5),
+				code.Make(code.OpJumpIfFalse, 8),
 				code.Make(code.OpConstant, 0),
 				code.Make(code.OpPop),
 			},
@@ -234,6 +235,497 @@ two;
 	runCompilerTests(t, tests)
 }
 
+func TestStructs(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input: `
+struct Point { x, y }
+let p = Point{y: 2};
+p.x;
+`,
+			expectedConstants: []interface{}{"struct Point", 2, "x"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpNull),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpInstance, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpGetField, 2),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: `
+struct Point { x, y }
+let p = Point{x: 1, y: 2};
+p.x = 3;
+`,
+			expectedConstants: []interface{}{"struct Point", 1, 2, 3, "x"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpInstance, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 3),
+				code.Make(code.OpSetField, 4),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestStructErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Point{x: 1}", "struct Point has not yet been defined"},
+		{"struct Point { x, y }; Point{z: 1}", "struct Point has no field z"},
+		{"struct Point { x, x }", "field x is declared twice in struct Point"},
+		{"let Point{x} = 1;", "undeclared struct Point used"},
+		{"struct Point { x, y }; fn f(Point{z}) { z }", "struct Point has no field z"},
+	}
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+		compiler := New()
+		err := compiler.Compile(program)
+		if err == nil {
+			t.Fatalf("expected compiler error %q", tt.expected)
+		}
+
+		if err.Error() != tt.expected {
+			t.Errorf("wrong compiler error. want=%q, got=%q", tt.expected, err.Error())
+		}
+	}
+}
+
+func TestTraitErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"struct Circle { r }; trait Shape { fn area(self) }; impl Shape for Circle { fn perimeter(self) { 1 } }", "struct Circle does not implement method area of trait Shape"},
+		{"struct Circle { r }; trait Shape { fn area(self) }; impl Shape for Circle { fn area(self, x) { 1 } }", "method area of trait Shape expects 1 parameters but got 2"},
+		{"struct Circle { r }; impl Shape for Circle { fn area(self) { 1 } }", "trait Shape has not yet been defined"},
+		{"impl Circle { fn area(self) { 1 } }", "struct Circle has not yet been defined"},
+		{"struct Circle { r }; impl Circle { fn area() { 1 } }", "method area of struct Circle has to take self as first parameter"},
+		{"struct Circle { r }; impl Circle { fn area(self) { 1 } } impl Circle { fn area(self) { 2 } }", "method area is already implemented for struct Circle"},
+		{"trait Shape { fn area(self) fn area(self) }", "method area is declared twice in trait Shape"},
+	}
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+		compiler := New()
+		err := compiler.Compile(program)
+		if err == nil {
+			t.Fatalf("expected compiler error %q", tt.expected)
+		}
+
+		if err.Error() != tt.expected {
+			t.Errorf("wrong compiler error. want=%q, got=%q", tt.expected, err.Error())
+		}
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input: "fn add(a, b) { return a + b; }; add(1, 2);",
+			expectedConstants: []interface{}{
+				[]code.Instructions{
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpGetLocal, 1),
+					code.Make(code.OpAdd),
+					code.Make(code.OpReturnValue),
+				},
+				1,
+				2,
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpCall, 2),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn one() { let x = 1; x }",
+			expectedConstants: []interface{}{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpSetGlobal, 0),
+			},
+		},
+		{
+			input: "fn nothing() { let x = 1; }",
+			expectedConstants: []interface{}{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpReturn),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpSetGlobal, 0),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestWhileStatements(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "let i = 0; while (i < 3) { i = i + 1; }",
+			expectedConstants: []interface{}{0, 3, 1},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpConstant, 0),
+				// 0003
+				code.Make(code.OpSetGlobal, 0),
+				// 0006
+				code.Make(code.OpConstant, 1),
+				// 0009
+				code.Make(code.OpGetGlobal, 0),
+				// 0012
+				code.Make(code.OpGreaterThan),
+				// 0013
+				code.Make(code.OpJumpIfFalse, 29),
+				// 0016
+				code.Make(code.OpGetGlobal, 0),
+				// 0019
+				code.Make(code.OpConstant, 2),
+				// 0022
+				code.Make(code.OpAdd),
+				// 0023
+				code.Make(code.OpSetGlobal, 0),
+				// 0026
+				code.Make(code.OpJump, 6),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestForInStatements(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "let g = 0; for x in g { x; }",
+			expectedConstants: []interface{}{0},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpConstant, 0),
+				// 0003
+				code.Make(code.OpSetGlobal, 0),
+				// 0006
+				code.Make(code.OpGetGlobal, 0),
+				// 0009
+				code.Make(code.OpIter, 1),
+				// 0011
+				code.Make(code.OpSetGlobal, 1),
+				// 0014
+				code.Make(code.OpGetGlobal, 1),
+				// 0017
+				code.Make(code.OpIterNext, 31),
+				// 0020
+				code.Make(code.OpSetGlobal, 2),
+				// 0023
+				code.Make(code.OpPop),
+				// 0024
+				code.Make(code.OpGetGlobal, 2),
+				// 0027
+				code.Make(code.OpPop),
+				// 0028
+				code.Make(code.OpJump, 14),
+				// 0031
+				code.Make(code.OpNull),
+				// 0032
+				code.Make(code.OpSetGlobal, 1),
+			},
+		},
+		{
+			input:             "for i, x in [1] { x; }",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpConstant, 0),
+				// 0003
+				code.Make(code.OpList, 1),
+				// 0006
+				code.Make(code.OpIter, 2),
+				// 0008
+				code.Make(code.OpSetGlobal, 0),
+				// 0011
+				code.Make(code.OpGetGlobal, 0),
+				// 0014
+				code.Make(code.OpIterNext, 30),
+				// 0017
+				code.Make(code.OpSetGlobal, 1),
+				// 0020
+				code.Make(code.OpSetGlobal, 2),
+				// 0023
+				code.Make(code.OpGetGlobal, 1),
+				// 0026
+				code.Make(code.OpPop),
+				// 0027
+				code.Make(code.OpJump, 11),
+				// 0030
+				code.Make(code.OpNull),
+				// 0031
+				code.Make(code.OpSetGlobal, 0),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestListRangeAndIndexExpressions(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "[1, 2];",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpList, 2),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "[];",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpList, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "1..3;",
+			expectedConstants: []interface{}{1, 3},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpRange, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "1..=3;",
+			expectedConstants: []interface{}{1, 3},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpRange, 1),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "[1][0];",
+			expectedConstants: []interface{}{1, 0},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpList, 1),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpIndex),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "[1][:1];",
+			expectedConstants: []interface{}{1, 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpList, 1),
+				code.Make(code.OpNull),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpSlice),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestGenerators(t *testing.T) {
+	compiler := New()
+	err := compiler.Compile(parse("fn count() { yield 1; }; fn plain() { fn() { yield 1; } }"))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	generators := map[string]bool{}
+	for _, constant := range compiler.Bytecode().Constants {
+		if fn, ok := constant.(*object.CompiledFunction); ok {
+			generators[fn.Name] = fn.Generator
+		}
+	}
+
+	if !generators["count"] || generators["plain"] || !generators[""] {
+		t.Errorf("wrong generator functions. got=%v", generators)
+	}
+
+	err = New().Compile(parse("yield 1;"))
+	if err == nil || err.Error() != "yield can only be used inside of functions" {
+		t.Errorf("wrong compiler error for yield outside of a function. got=%v", err)
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input     string
+		jumpTable bool
+	}{
+		{"match 1 { 1 | 2 => 10, \"a\" => 20, _ => 30 }", true},
+		{"match 1 { 1 => 10, 2 => 20 }", true},
+		{"match 1 { 1 => 10, n => n }", false},
+		{"match 1 { 1 if true => 10, _ => 20 }", false},
+		{"match 1 { _ => 10, 1 => 20 }", false},
+		{"match [1] { [a] => a }", false},
+	}
+
+	for _, tt := range tests {
+		compiler := New()
+		err := compiler.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		instructions := compiler.Bytecode().Instructions.String()
+		if strings.Contains(instructions, "OpJumpTable") != tt.jumpTable {
+			t.Errorf("wrong instructions for %q, jump table expected: %t. got=\n%s", tt.input, tt.jumpTable, instructions)
+		}
+
+		if strings.Contains(instructions, "OpMatch ") == tt.jumpTable {
+			t.Errorf("wrong instructions for %q, pattern matches expected: %t. got=\n%s", tt.input, !tt.jumpTable, instructions)
+		}
+	}
+
+	err := New().Compile(parse("match 1 { Point{x} => x }"))
+	if err == nil || err.Error() != "undeclared struct Point used" {
+		t.Errorf("wrong compiler error for an undeclared struct. got=%v", err)
+	}
+}
+
+func TestTryStatements(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "try { 1; } catch (e) { 2; }",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpConstant, 0),
+				// 0003
+				code.Make(code.OpPop),
+				// 0004
+				code.Make(code.OpJump, 14),
+				// 0007
+				code.Make(code.OpSetGlobal, 0),
+				// 0010
+				code.Make(code.OpConstant, 1),
+				// 0013
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "try { throw 1; } catch { }",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpConstant, 0),
+				// 0003
+				code.Make(code.OpThrow),
+				// 0004
+				code.Make(code.OpJump, 8),
+				// 0007
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestTryHandlers(t *testing.T) {
+	compiler := New()
+	err := compiler.Compile(parse("try { try { 1; } catch { } } catch { }"))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	// the inner try block is listed first, so it is found first by the vm
+	expected := []code.Handler{
+		{Start: 0, End: 4, Target: 7},
+		{Start: 0, End: 8, Target: 11},
+	}
+
+	handlers := compiler.Bytecode().Handlers
+	if fmt.Sprint(handlers) != fmt.Sprint(expected) {
+		t.Errorf("wrong handlers. want=%v, got=%v", expected, handlers)
+	}
+}
+
+func TestStatementPositions(t *testing.T) {
+	compiler := New()
+	err := compiler.Compile(parse("struct P { x }\nlet a = 1;\nfn f(b) {\n  let c = b;\n  c\n}\nwhile (a > 2) { a = 3; }"))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	// the struct declaration has no instructions, the while body is nested in the loop
+	expected := []code.Statement{
+		{Offset: 0, Line: 2, Column: 1},
+		{Offset: 6, Line: 3, Column: 1},
+		{Offset: 12, Line: 7, Column: 1},
+		{Offset: 22, Line: 7, Column: 17},
+	}
+
+	bytecode := compiler.Bytecode()
+	if statements := statementsOf(bytecode.Statements, bytecode.Instructions); fmt.Sprint(statements) != fmt.Sprint(expected) {
+		t.Errorf("wrong statements. want=%v, got=%v", expected, statements)
+	}
+
+	function := compiler.constants[2].(*object.CompiledFunction)
+
+	expectedFunction := []code.Statement{{Offset: 0, Line: 4, Column: 3}, {Offset: 4, Line: 5, Column: 3}}
+	if statements := statementsOf(function.Statements, function.Instructions); fmt.Sprint(statements) != fmt.Sprint(expectedFunction) {
+		t.Errorf("wrong statements of f. want=%v, got=%v", expectedFunction, statements)
+	}
+
+	if fmt.Sprint(function.Locals) != "[b c]" {
+		t.Errorf("wrong locals of f. got=%v", function.Locals)
+	}
+}
+
+// statementsOf returns the statements starting at the instructions in order of their offset
+func statementsOf(table *code.Statements, instructions code.Instructions) []code.Statement {
+	var statements []code.Statement
+	for offset := range instructions {
+		statements = append(statements, table.StatementsAt(offset)...)
+	}
+
+	return statements
+}
+
 func runCompilerTests(t *testing.T, tests []compilerTestCase) {
 	t.Helper()
 	for _, tt := range tests {
@@ -292,6 +784,20 @@ func testConstants(t *testing.T,
 			if err != nil {
 				return fmt.Errorf("constant %d - testIntegerObject failed: %s", i, err)
 			}
+		case []code.Instructions:
+			fn, ok := actual[i].(*object.CompiledFunction)
+			if !ok {
+				return fmt.Errorf("constant %d - not a function: %T", i, actual[i])
+			}
+
+			err := testInstructions(constant, fn.Instructions)
+			if err != nil {
+				return fmt.Errorf("constant %d - testInstructions failed: %s", i, err)
+			}
+		case string:
+			if actual[i].Inspect() != constant {
+				return fmt.Errorf("constant %d - wrong value. got=%q, want=%q", i, actual[i].Inspect(), constant)
+			}
 		}
 	}
 	return nil
@@ -318,3 +824,34 @@ func concatInstructions(s []code.Instructions) code.Instructions {
 	}
 	return out
 }
+
+func TestLinePositions(t *testing.T) {
+	compiler := New()
+	compiler.File = "main.curry"
+
+	err := compiler.Compile(parse("1 + 2;\nfn f(a) {\n  a - true\n}"))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	tests := []struct {
+		lines    *code.Statements
+		offset   int
+		expected string
+	}{
+		{compiler.Bytecode().Statements, 0, "main.curry:1:1"},
+		{compiler.Bytecode().Statements, 3, "main.curry:1:5"},
+		{compiler.Bytecode().Statements, 6, "main.curry:1:3"},
+		{compiler.Bytecode().Statements, 8, "main.curry:2:1"},
+		{compiler.constants[2].(*object.CompiledFunction).Statements, 0, "main.curry:3:3"},
+		{compiler.constants[2].(*object.CompiledFunction).Statements, 2, "main.curry:3:7"},
+		{compiler.constants[2].(*object.CompiledFunction).Statements, 3, "main.curry:3:5"},
+	}
+
+	for _, tt := range tests {
+		position, ok := tt.lines.Lookup(tt.offset)
+		if !ok || position.String() != tt.expected {
+			t.Errorf("wrong position of %d. want=%s, got=%s", tt.offset, tt.expected, position)
+		}
+	}
+}
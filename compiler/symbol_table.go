@@ -4,6 +4,11 @@ type SymbolScope string
 
 const (
 	GlobalScope SymbolScope = "GLOBAL"
+	LocalScope  SymbolScope = "LOCAL"
+	// FreeScope is a local of an enclosing function, which can not be accessed yet
+	FreeScope SymbolScope = "FREE"
+	// BuiltinScope is a builtin of object.BuiltinNames, it is shadowed by globals with the same name
+	BuiltinScope SymbolScope = "BUILTIN"
 )
 
 type Symbol struct {
@@ -12,8 +17,13 @@ type Symbol struct {
 	Index int
 }
 type SymbolTable struct {
+	Outer *SymbolTable
+
 	store          map[string]Symbol
 	numDefinitions int
+
+	// names of the definitions by their index
+	definitions []string
 }
 
 func NewSymbolTable() *SymbolTable {
@@ -21,14 +31,91 @@ func NewSymbolTable() *SymbolTable {
 	return &SymbolTable{store: s}
 }
 
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	return s
+}
+
 func (s *SymbolTable) Define(name string) Symbol {
 	symbol := Symbol{Name: name, Index: s.numDefinitions, Scope: GlobalScope}
+	if s.Outer != nil {
+		symbol.Scope = LocalScope
+	}
+
 	s.store[name] = symbol
+	s.definitions = append(s.definitions, name)
+	s.numDefinitions++
+	return symbol
+}
+
+// DefineHidden adds a variable which can not be resolved by name, like the iterator of a for loop
+func (s *SymbolTable) DefineHidden() Symbol {
+	symbol := Symbol{Index: s.numDefinitions, Scope: GlobalScope}
+	if s.Outer != nil {
+		symbol.Scope = LocalScope
+	}
+
+	s.definitions = append(s.definitions, "")
 	s.numDefinitions++
 	return symbol
 }
 
+// Scope saves the symbols of the names before a block defines variables with them, the returned function makes
+// the saved symbols visible again once the block ended. Names which were not defined before become undefined.
+func (s *SymbolTable) Scope(names []string) func() {
+	saved := make(map[string]Symbol, len(names))
+	for _, name := range names {
+		if symbol, ok := s.store[name]; ok {
+			saved[name] = symbol
+		}
+	}
+
+	return func() {
+		for _, name := range names {
+			if symbol, ok := saved[name]; ok {
+				s.store[name] = symbol
+			} else {
+				delete(s.store, name)
+			}
+		}
+	}
+}
+
+// DefineBuiltin adds a builtin, it is not counted as definition of the table
+func (s *SymbolTable) DefineBuiltin(index int, name string) Symbol {
+	symbol := Symbol{Name: name, Index: index, Scope: BuiltinScope}
+	s.store[name] = symbol
+	return symbol
+}
+
 func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
 	obj, ok := s.store[name]
+	if ok || s.Outer == nil {
+		return obj, ok
+	}
+
+	obj, ok = s.Outer.Resolve(name)
+	if ok && (obj.Scope == LocalScope || obj.Scope == FreeScope) {
+		return Symbol{Name: name, Scope: FreeScope}, true
+	}
+
 	return obj, ok
 }
+
+// Names returns the index of every symbol defined in this table
+func (s *SymbolTable) Names() map[string]int {
+	names := make(map[string]int, len(s.store))
+	for name, symbol := range s.store {
+		if symbol.Scope != BuiltinScope {
+			names[name] = symbol.Index
+		}
+	}
+
+	return names
+}
+
+// Definitions returns the names of the symbols by their index, redefined names occur several times
+func (s *SymbolTable) Definitions() []string {
+	return append([]string(nil), s.definitions...)
+}
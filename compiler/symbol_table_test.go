@@ -38,3 +38,57 @@ func TestResolveGlobal(t *testing.T) {
 		}
 	}
 }
+
+func TestResolveLocal(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("a")
+
+	first := NewEnclosedSymbolTable(global)
+	first.Define("b")
+
+	second := NewEnclosedSymbolTable(first)
+	second.Define("c")
+
+	expected := []Symbol{
+		{Name: "a", Scope: GlobalScope, Index: 0},
+		{Name: "b", Scope: FreeScope, Index: 0},
+		{Name: "c", Scope: LocalScope, Index: 0},
+	}
+	for _, sym := range expected {
+		result, ok := second.Resolve(sym.Name)
+		if !ok {
+			t.Errorf("name %s not resolvable", sym.Name)
+			continue
+		}
+		if result != sym {
+			t.Errorf("expected %s to resolve to %+v, got=%+v",
+				sym.Name, sym, result)
+		}
+	}
+
+	if _, ok := second.Resolve("d"); ok {
+		t.Errorf("name d should not be resolvable")
+	}
+}
+
+func TestScope(t *testing.T) {
+	global := NewSymbolTable()
+	outer := global.Define("a")
+
+	endScope := global.Scope([]string{"a", "b"})
+	global.Define("a")
+	global.Define("b")
+	endScope()
+
+	if result, ok := global.Resolve("a"); !ok || result != outer {
+		t.Errorf("expected a to resolve to %+v, got=%+v", outer, result)
+	}
+
+	if result, ok := global.Resolve("b"); ok {
+		t.Errorf("expected b to be undefined, got=%+v", result)
+	}
+
+	if global.numDefinitions != 3 {
+		t.Errorf("wrong number of definitions. want=3, got=%d", global.numDefinitions)
+	}
+}
@@ -0,0 +1,318 @@
+// Package coverage records which statements and branches of Curry programs are executed.
+// The files are added to a profile before they are evaluated, the profile's hook counts their statements and branches:
+//
+//	profile := coverage.New()
+//	profile.AddFile("example.com/lib/lib.curry", path, source, program)
+//	engine.Hook = profile.EvaluatorHook()
+//	engine.Eval(program)
+//	profile.WriteProfile(out)
+package coverage
+
+import (
+	"curryLang/ast"
+	"curryLang/evaluator"
+	"curryLang/object"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BranchKind is the arm of a condition a branch belongs to
+type BranchKind string
+
+const (
+	BranchThen BranchKind = "then" // the consequence of an if expression
+	BranchElse BranchKind = "else" // the alternative of an if expression, also if it has none
+	BranchLoop BranchKind = "loop" // the body of a while or for loop
+)
+
+// Statement is a statement of a covered file and how often it was executed,
+// the span of a statement ends with its first line, nested statements are separate ones
+type Statement struct {
+	Line      int
+	Column    int
+	EndColumn int // column after the last character of the statement on its first line
+	Count     int64
+}
+
+// Branch is an arm of a condition and how often it was taken
+type Branch struct {
+	Line   int // position of the if or while
+	Column int
+	Kind   BranchKind
+	Count  int64
+}
+
+func (branch *Branch) String() string {
+	switch branch.Kind {
+	case BranchThen:
+		return "if condition was never true"
+	case BranchElse:
+		return "if condition was never false"
+	}
+
+	return "loop body was never entered"
+}
+
+// File is a source file whose statements and branches are recorded
+type File struct {
+	Name       string // name used in the profile, like the import path of the package and the file name
+	Path       string
+	Lines      []string
+	Statements []*Statement // ordered by their position
+	Branches   []*Branch    // ordered by their position
+
+	statements map[position]*Statement
+	branches   map[branchKey]*Branch
+}
+
+type position struct {
+	line   int
+	column int
+}
+
+type branchKey struct {
+	position
+	kind BranchKind
+}
+
+// Summary is the number of covered statements and branches
+type Summary struct {
+	Statements        int
+	CoveredStatements int
+	Branches          int
+	CoveredBranches   int
+}
+
+func (summary Summary) String() string {
+	return fmt.Sprintf("coverage: %.1f%% of statements, %.1f%% of branches",
+		percent(summary.CoveredStatements, summary.Statements), percent(summary.CoveredBranches, summary.Branches))
+}
+
+// Add returns the sum of both summaries
+func (summary Summary) Add(other Summary) Summary {
+	return Summary{
+		Statements:        summary.Statements + other.Statements,
+		CoveredStatements: summary.CoveredStatements + other.CoveredStatements,
+		Branches:          summary.Branches + other.Branches,
+		CoveredBranches:   summary.CoveredBranches + other.CoveredBranches,
+	}
+}
+
+// Profile holds the recorded files, it is shared by all engines running them
+type Profile struct {
+	files  []*File
+	byPath map[string]*File
+}
+
+func New() *Profile {
+	return &Profile{byPath: map[string]*File{}}
+}
+
+// AddFile adds the statements and branches of a parsed file, declarations like structs or named functions are not
+// statements themselves, only their bodies are recorded
+func (profile *Profile) AddFile(name string, path string, source string, program *ast.Program) *File {
+	file := &File{
+		Name:       name,
+		Path:       path,
+		Lines:      strings.Split(source, "\n"),
+		statements: map[position]*Statement{},
+		branches:   map[branchKey]*Branch{},
+	}
+
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.IfElseExpression:
+			file.addBranch(node, BranchThen)
+			file.addBranch(node, BranchElse)
+		case *ast.WhileStatement, *ast.ForInStatement:
+			file.addBranch(node, BranchLoop)
+		}
+
+		if statement, ok := node.(ast.Statement); ok && !isDeclaration(statement) {
+			file.addStatement(statement)
+		}
+
+		return true
+	})
+
+	sort.Slice(file.Statements, func(i, j int) bool {
+		return before(file.Statements[i].Line, file.Statements[i].Column, file.Statements[j].Line, file.Statements[j].Column)
+	})
+	sort.SliceStable(file.Branches, func(i, j int) bool {
+		return before(file.Branches[i].Line, file.Branches[i].Column, file.Branches[j].Line, file.Branches[j].Column)
+	})
+
+	file.setEndColumns()
+
+	profile.files = append(profile.files, file)
+	profile.byPath[path] = file
+
+	return file
+}
+
+// Files returns the files in the order they were added
+func (profile *Profile) Files() []*File {
+	return profile.files
+}
+
+// Summary returns the coverage of all files
+func (profile *Profile) Summary() Summary {
+	var summary Summary
+	for _, file := range profile.files {
+		summary = summary.Add(file.Summary())
+	}
+
+	return summary
+}
+
+// EvaluatorHook returns the hook which records the statements and branches executed by the evaluator
+func (profile *Profile) EvaluatorHook() evaluator.BranchHook {
+	return evaluatorHook{profile: profile}
+}
+
+// Summary returns the coverage of the file
+func (file *File) Summary() Summary {
+	summary := Summary{Statements: len(file.Statements), Branches: len(file.Branches)}
+
+	for _, statement := range file.Statements {
+		if statement.Count > 0 {
+			summary.CoveredStatements++
+		}
+	}
+
+	for _, branch := range file.Branches {
+		if branch.Count > 0 {
+			summary.CoveredBranches++
+		}
+	}
+
+	return summary
+}
+
+func (file *File) addStatement(statement ast.Statement) {
+	token := statement.Pos()
+	if token.Line == 0 {
+		return
+	}
+
+	key := position{line: token.Line, column: token.Column}
+	if _, ok := file.statements[key]; ok {
+		return
+	}
+
+	recorded := &Statement{Line: token.Line, Column: token.Column}
+	file.statements[key] = recorded
+	file.Statements = append(file.Statements, recorded)
+}
+
+func (file *File) addBranch(node ast.Node, kind BranchKind) {
+	token := node.Pos()
+	branch := &Branch{Line: token.Line, Column: token.Column, Kind: kind}
+
+	file.branches[branchKey{position: position{line: token.Line, column: token.Column}, kind: kind}] = branch
+	file.Branches = append(file.Branches, branch)
+}
+
+// setEndColumns ends every statement before the next one on its line or at the last character of the line
+func (file *File) setEndColumns() {
+	for i, statement := range file.Statements {
+		line := []rune(file.line(statement.Line))
+		end := len(line)
+
+		if i+1 < len(file.Statements) && file.Statements[i+1].Line == statement.Line {
+			end = file.Statements[i+1].Column - 1
+		}
+
+		for end > statement.Column && end <= len(line) && isSpace(line[end-1]) {
+			end--
+		}
+
+		statement.EndColumn = end + 1
+	}
+}
+
+func (file *File) line(number int) string {
+	if number < 1 || number > len(file.Lines) {
+		return ""
+	}
+
+	return strings.TrimRight(file.Lines[number-1], "\r")
+}
+
+type evaluatorHook struct {
+	profile *Profile
+}
+
+func (hook evaluatorHook) BeforeStatement(engine *evaluator.ExecutionEngine, statement ast.Statement) *object.Error {
+	file, ok := hook.profile.byPath[engine.File]
+	if !ok {
+		return nil
+	}
+
+	token := statement.Pos()
+	if recorded, ok := file.statements[position{line: token.Line, column: token.Column}]; ok {
+		recorded.Count++
+	}
+
+	return nil
+}
+
+func (hook evaluatorHook) EnterFunction(engine *evaluator.ExecutionEngine, frame evaluator.Frame) {
+}
+
+func (hook evaluatorHook) LeaveFunction(engine *evaluator.ExecutionEngine, frame evaluator.Frame, result object.Object) {
+}
+
+func (hook evaluatorHook) Branch(engine *evaluator.ExecutionEngine, node ast.Node, taken bool) {
+	file, ok := hook.profile.byPath[engine.File]
+	if !ok {
+		return
+	}
+
+	kind := BranchLoop
+	if _, ok := node.(*ast.IfElseExpression); ok {
+		kind = BranchElse
+		if taken {
+			kind = BranchThen
+		}
+	} else if !taken {
+		// leaving a loop is not a branch of its own, every loop is left eventually
+		return
+	}
+
+	token := node.Pos()
+	if branch, ok := file.branches[branchKey{position: position{line: token.Line, column: token.Column}, kind: kind}]; ok {
+		branch.Count++
+	}
+}
+
+// isDeclaration reports if a statement only declares something, these statements are always executed when a file
+// is loaded, so they are not counted
+func isDeclaration(statement ast.Statement) bool {
+	switch statement := statement.(type) {
+	case *ast.PackageStatement, *ast.ImportStatement, *ast.StructStatement, *ast.TraitStatement, *ast.ImplStatement:
+		return true
+	case *ast.ExpressionStatement:
+		function, ok := statement.Expression.(*ast.FunctionExpression)
+		return ok && function.Name != ""
+	}
+
+	return false
+}
+
+func before(line int, column int, otherLine int, otherColumn int) bool {
+	return line < otherLine || (line == otherLine && column < otherColumn)
+}
+
+func isSpace(ch rune) bool {
+	return ch == ' ' || ch == '\t'
+}
+
+func percent(covered int, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+
+	return float64(covered) * 100 / float64(total)
+}
@@ -0,0 +1,139 @@
+package coverage
+
+import (
+	"bytes"
+	"curryLang/ast"
+	"curryLang/evaluator"
+	"curryLang/lexer"
+	"curryLang/parser"
+	"strings"
+	"testing"
+)
+
+const source = `struct Point { x, y }
+
+fn Abs(n) {
+    if (n < 0) {
+        return 0 - n;
+    }
+    return n;
+}
+
+fn Sum(n) {
+    let total = 0; let i = 0;
+    while (i < n) {
+        total = total + i; i = i + 1;
+    }
+    return total;
+}
+
+let a = Abs(3);
+let b = Sum(3);`
+
+func TestProfile(t *testing.T) {
+	profile := New()
+	file := profile.AddFile("example.com/lib/lib.curry", "lib.curry", source, parse(source))
+
+	engine := evaluator.NewEngine()
+	engine.File = "lib.curry"
+	engine.Hook = profile.EvaluatorHook()
+	engine.Eval(parse(source))
+
+	expectedStatements := []Statement{
+		{Line: 4, Column: 5, EndColumn: 17, Count: 1},
+		{Line: 5, Column: 9, EndColumn: 22, Count: 0},
+		{Line: 7, Column: 5, EndColumn: 14, Count: 1},
+		{Line: 11, Column: 5, EndColumn: 19, Count: 1},
+		{Line: 11, Column: 20, EndColumn: 30, Count: 1},
+		{Line: 12, Column: 5, EndColumn: 20, Count: 1},
+		{Line: 13, Column: 9, EndColumn: 27, Count: 3},
+		{Line: 13, Column: 28, EndColumn: 38, Count: 3},
+		{Line: 15, Column: 5, EndColumn: 18, Count: 1},
+		{Line: 18, Column: 1, EndColumn: 16, Count: 1},
+		{Line: 19, Column: 1, EndColumn: 16, Count: 1},
+	}
+
+	if len(file.Statements) != len(expectedStatements) {
+		t.Fatalf("wrong number of statements. want=%d, got=%d", len(expectedStatements), len(file.Statements))
+	}
+
+	for i, expected := range expectedStatements {
+		if *file.Statements[i] != expected {
+			t.Errorf("wrong statement %d. want=%+v, got=%+v", i, expected, *file.Statements[i])
+		}
+	}
+
+	expectedBranches := []Branch{
+		{Line: 4, Column: 5, Kind: BranchThen, Count: 0},
+		{Line: 4, Column: 5, Kind: BranchElse, Count: 1},
+		{Line: 12, Column: 5, Kind: BranchLoop, Count: 3},
+	}
+
+	if len(file.Branches) != len(expectedBranches) {
+		t.Fatalf("wrong number of branches. want=%d, got=%d", len(expectedBranches), len(file.Branches))
+	}
+
+	for i, expected := range expectedBranches {
+		if *file.Branches[i] != expected {
+			t.Errorf("wrong branch %d. want=%+v, got=%+v", i, expected, *file.Branches[i])
+		}
+	}
+
+	summary := profile.Summary()
+	if summary.String() != "coverage: 90.9% of statements, 66.7% of branches" {
+		t.Errorf("wrong summary. got=%s", summary)
+	}
+}
+
+func TestWriteProfile(t *testing.T) {
+	profile := New()
+	profile.AddFile("example.com/lib/lib.curry", "lib.curry", "let a = 1;\nif (a > 2) { a = 3; }", parse("let a = 1;\nif (a > 2) { a = 3; }"))
+	profile.files[0].Statements[0].Count = 2
+
+	var out bytes.Buffer
+	err := profile.WriteProfile(&out)
+	if err != nil {
+		t.Fatalf("failed to write profile: %s", err)
+	}
+
+	expected := `mode: count
+example.com/lib/lib.curry:1.1,1.11 1 2
+example.com/lib/lib.curry:2.1,2.13 1 0
+example.com/lib/lib.curry:2.14,2.22 1 0
+`
+
+	if out.String() != expected {
+		t.Errorf("wrong profile.\nwant=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	profile := New()
+	profile.AddFile("lib.curry", "lib.curry", "let a = 1 < 2;\nif (a) { a = false; }", parse("let a = 1 < 2;\nif (a) { a = false; }"))
+	profile.files[0].Statements[0].Count = 1
+
+	var out bytes.Buffer
+	err := profile.WriteHTML(&out)
+	if err != nil {
+		t.Fatalf("failed to write report: %s", err)
+	}
+
+	expected := []string{
+		`<span class="number">1</span><span class="covered" title="executed 1 times">let a = 1 &lt; 2;</span>`,
+		`<span class="number">2</span><span class="uncovered" title="executed 0 times">if (a) {</span> <span class="uncovered" title="executed 0 times">a = false; }</span>`,
+		"<li>2:1: if condition was never true</li><li>2:1: if condition was never false</li>",
+		"lib.curry (coverage: 33.3% of statements, 0.0% of branches)",
+	}
+
+	for _, html := range expected {
+		if !strings.Contains(out.String(), html) {
+			t.Errorf("report does not contain %q. got=\n%s", html, out.String())
+		}
+	}
+}
+
+func parse(input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	return p.ParseProgram()
+}
@@ -0,0 +1,138 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// WriteProfile writes the statements in the format of go test -coverprofile with the count mode,
+// every statement is a block of its own
+func (profile *Profile) WriteProfile(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "mode: count")
+
+	for _, file := range profile.files {
+		for _, statement := range file.Statements {
+			fmt.Fprintf(out, "%s:%d.%d,%d.%d 1 %d\n", file.Name, statement.Line, statement.Column, statement.Line, statement.EndColumn, statement.Count)
+		}
+	}
+
+	return out.Flush()
+}
+
+// segment is a part of a source line, statements are highlighted depending on whether they were executed
+type segment struct {
+	Text  string
+	Class string // covered, uncovered or empty for text outside of statements
+	Title string
+}
+
+type htmlLine struct {
+	Number   int
+	Segments []segment
+}
+
+type htmlFile struct {
+	Name     string
+	Summary  Summary
+	Lines    []htmlLine
+	Branches []string // the branches which were never taken
+}
+
+var htmlTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Curry coverage</title>
+<style>
+body { background: #fff; color: #222; font-family: sans-serif; }
+pre { font-family: Menlo, monospace; margin: 0; }
+.number { color: #999; display: inline-block; text-align: right; width: 4em; margin-right: 1em; }
+.covered { background: #c7f0c4; }
+.uncovered { background: #f6c5c5; }
+.branches { color: #b00; }
+h2 { font-size: 1em; margin-top: 2em; }
+</style>
+</head>
+<body>
+<p>{{.Summary}}</p>
+{{range .Files}}
+<h2 id="{{.Name}}">{{.Name}} ({{.Summary}})</h2>
+{{if .Branches}}<ul class="branches">{{range .Branches}}<li>{{.}}</li>{{end}}</ul>{{end}}
+<pre>{{range .Lines}}<span class="number">{{.Number}}</span>{{range .Segments}}{{if .Class}}<span class="{{.Class}}" title="{{.Title}}">{{.Text}}</span>{{else}}{{.Text}}{{end}}{{end}}
+{{end}}</pre>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes a report which shows the sources of the files with the covered statements highlighted in green
+// and the uncovered ones in red, the branches which were never taken are listed above every file
+func (profile *Profile) WriteHTML(w io.Writer) error {
+	var files []htmlFile
+
+	for _, file := range profile.files {
+		report := htmlFile{Name: file.Name, Summary: file.Summary()}
+
+		for _, branch := range file.Branches {
+			if branch.Count == 0 {
+				report.Branches = append(report.Branches, fmt.Sprintf("%d:%d: %s", branch.Line, branch.Column, branch))
+			}
+		}
+
+		statements := file.Statements
+		for number := range file.Lines {
+			line := []rune(file.line(number + 1))
+			htmlLine := htmlLine{Number: number + 1}
+
+			// the statements are ordered, so the ones of the line are at the start
+			column := 1
+			for len(statements) > 0 && statements[0].Line == number+1 {
+				statement := statements[0]
+				statements = statements[1:]
+
+				start := clamp(statement.Column, column, len(line)+1)
+				end := clamp(statement.EndColumn, start, len(line)+1)
+
+				htmlLine.Segments = append(htmlLine.Segments, segment{Text: string(line[column-1 : start-1])})
+
+				class := "uncovered"
+				if statement.Count > 0 {
+					class = "covered"
+				}
+
+				htmlLine.Segments = append(htmlLine.Segments, segment{
+					Text:  string(line[start-1 : end-1]),
+					Class: class,
+					Title: fmt.Sprintf("executed %d times", statement.Count),
+				})
+
+				column = end
+			}
+
+			htmlLine.Segments = append(htmlLine.Segments, segment{Text: string(line[column-1:])})
+			report.Lines = append(report.Lines, htmlLine)
+		}
+
+		files = append(files, report)
+	}
+
+	return htmlTemplate.Execute(w, struct {
+		Summary Summary
+		Files   []htmlFile
+	}{profile.Summary(), files})
+}
+
+func clamp(value int, low int, high int) int {
+	if value < low {
+		return low
+	}
+
+	if value > high {
+		return high
+	}
+
+	return value
+}
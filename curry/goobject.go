@@ -0,0 +1,148 @@
+package curry
+
+import (
+	"curryLang/object"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TagName is the struct tag which renames a field for Curry, "-" hides the field
+const TagName = "curry"
+
+// GoObject wraps a Go struct, so programs can access its fields and call its methods.
+// Read-only objects can not be modified, their fields can not be assigned
+// and only the methods with a value receiver can be called.
+type GoObject struct {
+	runtime  *Runtime
+	value    reflect.Value // addressable struct
+	readOnly bool
+}
+
+type readOnlyValue struct {
+	value interface{}
+}
+
+// ReadOnly marks a Go struct or pointer to a struct, so it is converted to a read-only GoObject
+func ReadOnly(value interface{}) interface{} {
+	return readOnlyValue{value: value}
+}
+
+func (obj *GoObject) Type() object.ObjectType { return object.HOST_OBJ }
+func (obj *GoObject) Inspect() string {
+	if stringer, ok := obj.Value().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+
+	return fmt.Sprintf("%s%+v", obj.value.Type().Name(), obj.value.Interface())
+}
+
+// Value returns a pointer to the wrapped struct, or a copy of the struct if the object is read-only
+func (obj *GoObject) Value() interface{} {
+	if obj.readOnly {
+		return obj.value.Interface()
+	}
+
+	return obj.value.Addr().Interface()
+}
+
+func (obj *GoObject) ReadOnly() bool {
+	return obj.readOnly
+}
+
+func (obj *GoObject) GetField(name string) (object.Object, *object.Error) {
+	if field, ok := fieldByName(obj.value.Type(), name); ok {
+		fieldValue, err := obj.value.FieldByIndexErr(field.Index)
+		if err != nil {
+			return nil, &object.Error{Kind: object.FIELD_ERROR, Message: fmt.Sprintf("field %s of %s: %s", name, obj.typeName(), err)}
+		}
+
+		value, err := obj.runtime.toObject(fieldValue, obj.readOnly)
+		if err != nil {
+			return nil, &object.Error{Kind: object.TYPE_ERROR, Message: fmt.Sprintf("field %s of %s: %s", name, obj.typeName(), err)}
+		}
+
+		return value, nil
+	}
+
+	if method := obj.method(name); method.IsValid() {
+		builtin, err := obj.runtime.wrapFunc(obj.typeName()+"."+name, method)
+		if err != nil {
+			return nil, &object.Error{Kind: object.TYPE_ERROR, Message: err.Error()}
+		}
+
+		return builtin, nil
+	}
+
+	if _, ok := reflect.PointerTo(obj.value.Type()).MethodByName(name); ok && obj.readOnly {
+		return nil, &object.Error{Kind: object.PERMISSION_ERROR, Message: fmt.Sprintf("method %s of %s requires a mutable receiver", name, obj.typeName())}
+	}
+
+	return nil, &object.Error{Kind: object.FIELD_ERROR, Message: fmt.Sprintf("%s has no field or method %s", obj.typeName(), name)}
+}
+
+func (obj *GoObject) SetField(name string, value object.Object) *object.Error {
+	field, ok := fieldByName(obj.value.Type(), name)
+	if !ok {
+		return &object.Error{Kind: object.FIELD_ERROR, Message: fmt.Sprintf("%s has no field %s", obj.typeName(), name)}
+	}
+
+	if obj.readOnly {
+		return &object.Error{Kind: object.PERMISSION_ERROR, Message: fmt.Sprintf("field %s of %s is read-only", name, obj.typeName())}
+	}
+
+	fieldValue, err := obj.value.FieldByIndexErr(field.Index)
+	if err != nil {
+		return &object.Error{Kind: object.FIELD_ERROR, Message: fmt.Sprintf("field %s of %s: %s", name, obj.typeName(), err)}
+	}
+
+	converted, err := obj.runtime.fromObject(value, field.Type)
+	if err != nil {
+		return &object.Error{Kind: object.TYPE_ERROR, Message: fmt.Sprintf("field %s of %s: %s", name, obj.typeName(), err)}
+	}
+
+	fieldValue.Set(converted)
+
+	return nil
+}
+
+// method returns the method bound to the struct, read-only objects only have the methods of the value type
+func (obj *GoObject) method(name string) reflect.Value {
+	if obj.readOnly {
+		return obj.value.MethodByName(name)
+	}
+
+	return obj.value.Addr().MethodByName(name)
+}
+
+func (obj *GoObject) typeName() string {
+	return obj.value.Type().Name()
+}
+
+// fieldByName returns the exported field of the struct type which has the name in Curry,
+// fields of embedded structs are promoted like in Go
+func fieldByName(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		fieldName := field.Name
+		if tag, ok := field.Tag.Lookup(TagName); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+
+			if tagName != "" {
+				fieldName = tagName
+			}
+		}
+
+		if fieldName == name {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,155 @@
+package curry
+
+import (
+	"curryLang/object"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testAddress struct {
+	City string `curry:"city"`
+}
+
+type testUser struct {
+	Name     string `curry:"name"`
+	Age      int
+	Password string      `curry:"-"`
+	Address  testAddress `curry:"address"`
+	Tags     []string
+	secret   string
+}
+
+func (user testUser) Greeting(prefix string) string {
+	return prefix + user.Name
+}
+
+func (user *testUser) Birthday() int {
+	user.Age++
+	return user.Age
+}
+
+func (user *testUser) Rename(name string) error {
+	if name == "" {
+		return errors.New("empty name")
+	}
+
+	user.Name = name
+	return nil
+}
+
+func TestGoObject(t *testing.T) {
+	for _, backend := range backends {
+		runtime := newTestRuntime(t, backend)
+
+		user := &testUser{Name: "Ann", Age: 30, Password: "x", Address: testAddress{City: "Zurich"}, Tags: []string{"a"}, secret: "s"}
+
+		for name, value := range map[string]interface{}{"user": user, "ro": ReadOnly(user)} {
+			err := runtime.Set(name, value)
+			if err != nil {
+				t.Fatalf("%s: failed to set %s: %s", backend, name, err)
+			}
+		}
+
+		err := runtime.RegisterFunc("describe", func(user *testUser) string { return user.Name + user.Address.City })
+		if err != nil {
+			t.Fatalf("%s: failed to register describe: %s", backend, err)
+		}
+
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"user.name", "Ann"},
+			{"user.Age", int64(30)},
+			{"user.address.city", "Zurich"},
+			{"user.Tags", []interface{}{"a"}},
+			{`user.Greeting("Hi")`, "HiAnn"},
+			{"user.Birthday()", int64(31)},
+			{`user.Rename("Bob"); user.name`, "Bob"},
+			{`user.address.city = "Bern"; describe(user)`, "BobBern"},
+			{"ro.Age", int64(31)},
+			{`ro.Greeting("Hi")`, "HiBob"},
+		}
+
+		for _, tt := range tests {
+			result, err := runtime.RunString(tt.input)
+			if err != nil {
+				t.Errorf("%s: unexpected error for %q: %s", backend, tt.input, err)
+				continue
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("%s: wrong result for %q. want=%#v, got=%#v", backend, tt.input, tt.expected, result)
+			}
+		}
+
+		// the script works on the Go struct
+		if user.Name != "Bob" || user.Age != 31 || user.Address.City != "Bern" {
+			t.Errorf("%s: struct was not changed by the script. got=%+v", backend, user)
+		}
+
+		errorTests := []struct {
+			input   string
+			kind    object.ErrorKind
+			message string
+		}{
+			{"user.Password", object.FIELD_ERROR, "testUser has no field or method Password"},
+			{"user.secret", object.FIELD_ERROR, "testUser has no field or method secret"},
+			{"user.Missing()", object.FIELD_ERROR, "testUser has no field or method Missing"},
+			{`user.Age = "old";`, object.TYPE_ERROR, "field Age of testUser: cannot use STRING as int"},
+			{"user.Greeting(1)", object.TYPE_ERROR, "argument 1 of testUser.Greeting: cannot use INTEGER as string"},
+			{"user.Greeting()", object.ARGUMENT_ERROR, "wrong number of arguments for testUser.Greeting: want=1, got=0"},
+			{`user.Rename("")`, object.RUNTIME_ERROR, "empty name"},
+			{`ro.name = "Eve";`, object.PERMISSION_ERROR, "field name of testUser is read-only"},
+			{`ro.address.city = "Rome";`, object.PERMISSION_ERROR, "field city of testAddress is read-only"},
+			{"ro.Birthday()", object.PERMISSION_ERROR, "method Birthday of testUser requires a mutable receiver"},
+			{"ro.Missing()", object.FIELD_ERROR, "testUser has no field or method Missing"},
+			{"describe(ro)", object.TYPE_ERROR, "argument 1 of describe: cannot use read-only testUser as *curry.testUser"},
+			{"describe(1)", object.TYPE_ERROR, "argument 1 of describe: cannot use INTEGER as *curry.testUser"},
+		}
+
+		for _, tt := range errorTests {
+			_, err := runtime.RunString(tt.input)
+
+			var runtimeErr *object.Error
+			if !errors.As(err, &runtimeErr) {
+				t.Errorf("%s: error is not object.Error for %q. got=%T (%+v)", backend, tt.input, err, err)
+				continue
+			}
+
+			if runtimeErr.Kind != tt.kind || runtimeErr.Message != tt.message {
+				t.Errorf("%s: wrong error for %q. want=%s: %s, got=%s: %s", backend, tt.input, tt.kind, tt.message, runtimeErr.Kind, runtimeErr.Message)
+			}
+		}
+
+		if user.Name != "Bob" || user.Address.City != "Bern" {
+			t.Errorf("%s: read-only struct was changed. got=%+v", backend, user)
+		}
+
+		value, err := runtime.Get("user")
+		if err != nil || value != user {
+			t.Errorf("%s: Get does not return the struct pointer. got=%v, %v", backend, value, err)
+		}
+	}
+}
+
+func TestGoObjectByValue(t *testing.T) {
+	runtime := newTestRuntime(t, BackendEvaluator)
+
+	user := testUser{Name: "Ann"}
+	err := runtime.Set("user", user)
+	if err != nil {
+		t.Fatalf("failed to set user: %s", err)
+	}
+
+	// structs passed by value are copied
+	result, err := runtime.RunString(`user.name = "Bob"; user.name`)
+	if err != nil || result != "Bob" {
+		t.Errorf("wrong result. got=%v, %v", result, err)
+	}
+
+	if user.Name != "Ann" {
+		t.Errorf("struct passed by value was changed. got=%+v", user)
+	}
+}
@@ -0,0 +1,531 @@
+package curry
+
+import (
+	"curryLang/object"
+	"curryLang/token"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// MapStruct is the struct of instances created from Go maps, every key is a field
+const MapStruct = "Map"
+
+var (
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// ToObject converts a Go value into a Curry object:
+// integers, strings and bools are converted to their Curry types, slices and arrays to lists,
+// maps with string keys to instances of the Map struct and functions to builtins.
+// Structs and pointers to structs are wrapped as GoObject, values wrapped by ReadOnly as read-only GoObject.
+// Other pointers and interfaces are converted by their value, nil results in null.
+func (runtime *Runtime) ToObject(value interface{}) (object.Object, error) {
+	return runtime.toObject(reflect.ValueOf(value), false)
+}
+
+// toObject converts the value, structs inside of read-only objects are read-only as well
+func (runtime *Runtime) toObject(value reflect.Value, readOnly bool) (object.Object, error) {
+	if !value.IsValid() {
+		return &object.Null{}, nil
+	}
+
+	if value.CanInterface() {
+		switch wrapped := value.Interface().(type) {
+		case object.Object:
+			return wrapped, nil
+		case readOnlyValue:
+			return runtime.toObject(reflect.ValueOf(wrapped.value), true)
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		return &object.Boolean{Value: value.Bool()}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: value.Int()}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("%d overflows the integers of curry", value.Uint())
+		}
+
+		return &object.Integer{Value: int64(value.Uint())}, nil
+
+	case reflect.String:
+		return &object.String{Value: value.String()}, nil
+
+	case reflect.Slice, reflect.Array:
+		return runtime.listObject(value, readOnly)
+
+	case reflect.Map:
+		return runtime.mapObject(value, readOnly)
+
+	case reflect.Struct:
+		// structs which are passed by value are copied, so their fields can be changed
+		if !value.CanAddr() {
+			copied := reflect.New(value.Type()).Elem()
+			copied.Set(value)
+			value = copied
+		}
+
+		return &GoObject{runtime: runtime, value: value, readOnly: readOnly}, nil
+
+	case reflect.Func:
+		if value.IsNil() {
+			return &object.Null{}, nil
+		}
+
+		return runtime.wrapFunc(value.Type().String(), value)
+
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return &object.Null{}, nil
+		}
+
+		return runtime.toObject(value.Elem(), readOnly)
+	}
+
+	return nil, fmt.Errorf("unsupported go type %s", value.Type())
+}
+
+func (runtime *Runtime) listObject(value reflect.Value, readOnly bool) (object.Object, error) {
+	list := &object.List{Value: make([]object.Object, 0, value.Len())}
+
+	for i := 0; i < value.Len(); i++ {
+		element, err := runtime.toObject(value.Index(i), readOnly)
+		if err != nil {
+			return nil, err
+		}
+
+		// lists of curry contain values of a single type
+		if i == 0 {
+			list.ValueType = element.Type()
+		} else if element.Type() != list.ValueType {
+			return nil, fmt.Errorf("list elements have to be of the same type, element %d is %s instead of %s", i, element.Type(), list.ValueType)
+		}
+
+		list.Value = append(list.Value, element)
+	}
+
+	return list, nil
+}
+
+func (runtime *Runtime) mapObject(value reflect.Value, readOnly bool) (object.Object, error) {
+	if value.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("unsupported go type %s, only maps with string keys can be converted", value.Type())
+	}
+
+	structObj := &object.Struct{Name: MapStruct, Methods: map[string]*object.Function{}, Traits: map[string]*object.Trait{}}
+	instance := &object.Instance{Struct: structObj, Fields: make(map[string]object.Object, value.Len())}
+
+	iter := value.MapRange()
+	for iter.Next() {
+		field, err := runtime.toObject(iter.Value(), readOnly)
+		if err != nil {
+			return nil, err
+		}
+
+		name := iter.Key().String()
+		structObj.Fields = append(structObj.Fields, name)
+		instance.Fields[name] = field
+	}
+
+	sort.Strings(structObj.Fields)
+
+	return instance, nil
+}
+
+// FromObject converts a Curry object into its natural Go value:
+// integers are converted to int64, lists to []interface{}, instances to map[string]interface{}
+// and functions to func(args ...interface{}) (interface{}, error). Null results in nil.
+func (runtime *Runtime) FromObject(obj object.Object) (interface{}, error) {
+	value, err := runtime.fromObject(obj, interfaceType)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.Interface(), nil
+}
+
+// fromObject converts the object into a value of the Go type
+func (runtime *Runtime) fromObject(obj object.Object, typ reflect.Type) (reflect.Value, error) {
+	if typ == interfaceType {
+		return runtime.naturalValue(obj)
+	}
+
+	if typ.Kind() == reflect.Interface && reflect.TypeOf(obj).Implements(typ) {
+		return reflect.ValueOf(obj), nil
+	}
+
+	if goObject, ok := obj.(*GoObject); ok {
+		return goObjectValue(goObject, typ)
+	}
+
+	if typ.Kind() == reflect.Interface {
+		return reflect.Value{}, conversionError(obj, typ)
+	}
+
+	if _, ok := obj.(*object.Null); ok {
+		switch typ.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Func, reflect.Ptr:
+			return reflect.Zero(typ), nil
+		}
+
+		return reflect.Value{}, conversionError(obj, typ)
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		if boolean, ok := obj.(*object.Boolean); ok {
+			return reflect.ValueOf(boolean.Value).Convert(typ), nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if integer, ok := obj.(*object.Integer); ok {
+			value := reflect.New(typ).Elem()
+			if value.OverflowInt(integer.Value) {
+				return reflect.Value{}, fmt.Errorf("%d overflows %s", integer.Value, typ)
+			}
+
+			value.SetInt(integer.Value)
+			return value, nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if integer, ok := obj.(*object.Integer); ok {
+			value := reflect.New(typ).Elem()
+			if integer.Value < 0 || value.OverflowUint(uint64(integer.Value)) {
+				return reflect.Value{}, fmt.Errorf("%d overflows %s", integer.Value, typ)
+			}
+
+			value.SetUint(uint64(integer.Value))
+			return value, nil
+		}
+
+	case reflect.String:
+		if str, ok := obj.(*object.String); ok {
+			return reflect.ValueOf(str.Value).Convert(typ), nil
+		}
+
+	case reflect.Slice:
+		if list, ok := obj.(*object.List); ok {
+			value := reflect.MakeSlice(typ, len(list.Value), len(list.Value))
+			for i, element := range list.Value {
+				converted, err := runtime.fromObject(element, typ.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+
+				value.Index(i).Set(converted)
+			}
+
+			return value, nil
+		}
+
+	case reflect.Map:
+		if instance, ok := obj.(*object.Instance); ok && typ.Key().Kind() == reflect.String {
+			value := reflect.MakeMapWithSize(typ, len(instance.Fields))
+			for _, field := range instance.Struct.Fields {
+				converted, err := runtime.fromObject(instance.Fields[field], typ.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+
+				value.SetMapIndex(reflect.ValueOf(field).Convert(typ.Key()), converted)
+			}
+
+			return value, nil
+		}
+
+	case reflect.Func:
+		if isCallable(obj) {
+			return runtime.makeFunc(obj, typ)
+		}
+	}
+
+	return reflect.Value{}, conversionError(obj, typ)
+}
+
+// goObjectValue returns the wrapped struct or a pointer to it, read-only structs are only passed by value
+func goObjectValue(obj *GoObject, typ reflect.Type) (reflect.Value, error) {
+	structType := obj.value.Type()
+
+	switch {
+	case typ == structType:
+		return obj.value, nil
+	case typ == reflect.PtrTo(structType) && !obj.readOnly:
+		return obj.value.Addr(), nil
+	case typ.Kind() == reflect.Interface && structType.Implements(typ):
+		return obj.value, nil
+	case typ.Kind() == reflect.Interface && reflect.PtrTo(structType).Implements(typ) && !obj.readOnly:
+		return obj.value.Addr(), nil
+	case obj.readOnly && typ == reflect.PtrTo(structType):
+		return reflect.Value{}, fmt.Errorf("cannot use read-only %s as %s", structType.Name(), typ)
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot use %s as %s", structType.Name(), typ)
+}
+
+func (runtime *Runtime) naturalValue(obj object.Object) (reflect.Value, error) {
+	var value interface{}
+
+	switch obj := obj.(type) {
+	case *object.Null:
+		return reflect.Zero(interfaceType), nil
+	case *GoObject:
+		value = obj.Value()
+	case *object.Integer:
+		value = obj.Value
+	case *object.Boolean:
+		value = obj.Value
+	case *object.String:
+		value = obj.Value
+	case *object.List:
+		list := make([]interface{}, 0, len(obj.Value))
+		for _, element := range obj.Value {
+			converted, err := runtime.FromObject(element)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			list = append(list, converted)
+		}
+
+		value = list
+	case *object.Instance:
+		fields := make(map[string]interface{}, len(obj.Fields))
+		for _, field := range obj.Struct.Fields {
+			converted, err := runtime.FromObject(obj.Fields[field])
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			fields[field] = converted
+		}
+
+		value = fields
+	default:
+		if isCallable(obj) {
+			var function func(args ...interface{}) (interface{}, error)
+			return runtime.makeFunc(obj, reflect.TypeOf(function))
+		}
+
+		// objects without a Go counterpart like structs are passed as they are
+		value = obj
+	}
+
+	// the value is wrapped, so it can be assigned to interface{}
+	converted := reflect.New(interfaceType).Elem()
+	converted.Set(reflect.ValueOf(value))
+
+	return converted, nil
+}
+
+func isCallable(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Function, *object.CompiledFunction, *object.Builtin:
+		return true
+	}
+
+	return false
+}
+
+func conversionError(obj object.Object, typ reflect.Type) error {
+	return fmt.Errorf("cannot use %s as %s", obj.Type(), typ)
+}
+
+// checkResults returns an error if the function type can not be called by curry,
+// functions can return a value, an error or both
+func checkResults(name string, typ reflect.Type) error {
+	switch typ.NumOut() {
+	case 0:
+		return nil
+	case 1:
+		return nil
+	case 2:
+		if typ.Out(1) == errorType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("function %s has to return at most one value and an error", name)
+}
+
+// wrapFunc creates a builtin which converts its arguments for the Go function and its results back to Curry
+func (runtime *Runtime) wrapFunc(name string, function reflect.Value) (*object.Builtin, error) {
+	if function.Kind() != reflect.Func {
+		return nil, fmt.Errorf("%s is not a function but %s", name, function.Kind())
+	}
+
+	typ := function.Type()
+
+	err := checkResults(name, typ)
+	if err != nil {
+		return nil, err
+	}
+
+	builtin := &object.Builtin{Name: name}
+	builtin.Function = func(position token.Token, args ...object.Object) (result object.Object) {
+		// a panicking Go function must not stop the embedding program
+		defer func() {
+			if r := recover(); r != nil {
+				result = &object.Error{Kind: object.RUNTIME_ERROR, Message: fmt.Sprintf("%s panicked: %v", name, r)}
+			}
+		}()
+
+		in, errObj := runtime.arguments(name, typ, args)
+		if errObj != nil {
+			return errObj
+		}
+
+		// Curry funcs without error result which fail while the Go function runs raise their error in this call
+		outer := runtime.funcErr
+		runtime.funcErr = nil
+		defer func() { runtime.funcErr = outer }()
+
+		out := function.Call(in)
+		if runtime.funcErr != nil {
+			return errorObject(runtime.funcErr)
+		}
+
+		return runtime.builtinResult(typ, out)
+	}
+
+	return builtin, nil
+}
+
+// arguments converts the arguments of a call to a Go function
+func (runtime *Runtime) arguments(name string, typ reflect.Type, args []object.Object) ([]reflect.Value, *object.Error) {
+	numIn := typ.NumIn()
+	if typ.IsVariadic() && len(args) < numIn-1 {
+		return nil, &object.Error{
+			Kind:    object.ARGUMENT_ERROR,
+			Message: fmt.Sprintf("wrong number of arguments for %s: want at least %d, got=%d", name, numIn-1, len(args)),
+		}
+	}
+
+	if !typ.IsVariadic() && len(args) != numIn {
+		return nil, &object.Error{
+			Kind:    object.ARGUMENT_ERROR,
+			Message: fmt.Sprintf("wrong number of arguments for %s: want=%d, got=%d", name, numIn, len(args)),
+		}
+	}
+
+	in := make([]reflect.Value, 0, len(args))
+	for i, arg := range args {
+		var argType reflect.Type
+		if typ.IsVariadic() && i >= numIn-1 {
+			argType = typ.In(numIn - 1).Elem()
+		} else {
+			argType = typ.In(i)
+		}
+
+		value, err := runtime.fromObject(arg, argType)
+		if err != nil {
+			return nil, &object.Error{Kind: object.TYPE_ERROR, Message: fmt.Sprintf("argument %d of %s: %s", i+1, name, err)}
+		}
+
+		in = append(in, value)
+	}
+
+	return in, nil
+}
+
+// builtinResult converts the results of a Go function, a returned error is raised as runtime error
+func (runtime *Runtime) builtinResult(typ reflect.Type, out []reflect.Value) object.Object {
+	if len(out) > 0 && typ.Out(len(out)-1) == errorType {
+		errValue := out[len(out)-1]
+		out = out[:len(out)-1]
+
+		if !errValue.IsNil() {
+			return errorObject(errValue.Interface().(error))
+		}
+	}
+
+	if len(out) == 0 {
+		return nil
+	}
+
+	result, err := runtime.toObject(out[0], false)
+	if err != nil {
+		return &object.Error{Kind: object.TYPE_ERROR, Message: err.Error()}
+	}
+
+	return result
+}
+
+// errorObject raises a Go error in Curry, errors of Curry functions keep their kind
+func errorObject(err error) *object.Error {
+	if errObj, ok := err.(*object.Error); ok {
+		return errObj
+	}
+
+	return &object.Error{Kind: object.RUNTIME_ERROR, Message: err.Error()}
+}
+
+// makeFunc creates a Go function of the type which calls the Curry function, errors are returned if the type has an
+// error result. Otherwise the function returns zero values and the error is reported by FuncError.
+func (runtime *Runtime) makeFunc(function object.Object, typ reflect.Type) (reflect.Value, error) {
+	err := checkResults(function.Inspect(), typ)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return reflect.MakeFunc(typ, func(in []reflect.Value) []reflect.Value {
+		if typ.IsVariadic() {
+			variadic := in[len(in)-1]
+			in = in[:len(in)-1]
+			for i := 0; i < variadic.Len(); i++ {
+				in = append(in, variadic.Index(i))
+			}
+		}
+
+		args := make([]object.Object, 0, len(in))
+		for _, value := range in {
+			arg, err := runtime.toObject(value, false)
+			if err != nil {
+				return runtime.funcResults(typ, nil, err)
+			}
+
+			args = append(args, arg)
+		}
+
+		result, err := runtime.call(function, args)
+		if err != nil || typ.NumOut() == 0 || typ.Out(0) == errorType {
+			return runtime.funcResults(typ, nil, err)
+		}
+
+		value, err := runtime.fromObject(result, typ.Out(0))
+		return runtime.funcResults(typ, &value, err)
+	}), nil
+}
+
+func (runtime *Runtime) funcResults(typ reflect.Type, value *reflect.Value, err error) []reflect.Value {
+	out := make([]reflect.Value, typ.NumOut())
+	for i := range out {
+		out[i] = reflect.Zero(typ.Out(i))
+	}
+
+	if err != nil {
+		if typ.NumOut() == 0 || typ.Out(typ.NumOut()-1) != errorType {
+			if runtime.funcErr == nil {
+				runtime.funcErr = err
+			}
+
+			return out
+		}
+
+		out[typ.NumOut()-1] = reflect.ValueOf(&err).Elem()
+		return out
+	}
+
+	if value != nil {
+		out[0] = *value
+	}
+
+	return out
+}
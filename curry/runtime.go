@@ -0,0 +1,365 @@
+// Package curry embeds Curry programs into Go programs.
+//
+//	runtime, err := curry.NewRuntime(curry.Options{Backend: curry.BackendVM})
+//	runtime.Set("limit", 10)
+//	runtime.RegisterFunc("log", func(message string) { fmt.Println(message) })
+//	result, err := runtime.RunString(`log("hello"); limit * 2;`)
+//
+// Go values are converted to Curry objects and back, see Runtime.ToObject and Runtime.FromObject.
+// A Runtime must not be used by multiple goroutines at the same time.
+package curry
+
+import (
+	"curryLang/ast"
+	"curryLang/checker"
+	"curryLang/compiler"
+	"curryLang/evaluator"
+	"curryLang/lexer"
+	"curryLang/object"
+	"curryLang/parser"
+	"curryLang/token"
+	"curryLang/vm"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Backend selects how programs are executed
+type Backend string
+
+const (
+	// BackendEvaluator runs programs with the tree-walking interpreter, which supports the whole language
+	BackendEvaluator Backend = "evaluator"
+	// BackendVM compiles programs to bytecode for the virtual machine
+	BackendVM Backend = "vm"
+)
+
+// StandardLibraryModule is the module the standard library is imported from
+const StandardLibraryModule = "internal"
+
+type Options struct {
+	// BackendEvaluator is used if empty
+	Backend Backend
+
+	// directory of the standard library, only the evaluator supports imports
+	StandardLibraryPath string
+
+	// resources every run can use, the call depth of the evaluator is limited by default
+	Limits object.Limits
+
+	// DisableOS denies imports of internal/os
+	DisableOS bool
+}
+
+// Runtime runs programs which share their globals, so values set or declared by one program
+// can be used by the following ones
+type Runtime struct {
+	options Options
+
+	// evaluator backend
+	engine *evaluator.ExecutionEngine
+
+	// vm backend
+	compiler *compiler.Compiler
+	globals  []object.Object
+	tasks    *object.Scheduler // shared by the vms, so channels created by a program can be used by the next ones
+	machine  *vm.VM
+
+	funcErr error // first error of a Curry function called through a Go func without error result, see FuncError
+}
+
+// SyntaxError lists the errors found while parsing and checking a program
+type SyntaxError struct {
+	File   string
+	Errors []string
+}
+
+func (err *SyntaxError) Error() string {
+	file := err.File
+	if file == "" {
+		file = "<input>"
+	}
+
+	return fmt.Sprintf("%s: %s", file, strings.Join(err.Errors, ", "))
+}
+
+func NewRuntime(options Options) (*Runtime, error) {
+	if options.Backend == "" {
+		options.Backend = BackendEvaluator
+	}
+
+	runtime := &Runtime{options: options}
+
+	switch options.Backend {
+	case BackendEvaluator:
+		engine := evaluator.NewEngine()
+		engine.DisableOS = options.DisableOS
+		engine.Limits = options.Limits
+		if engine.Limits.MaxCallDepth == 0 {
+			engine.Limits.MaxCallDepth = evaluator.DefaultMaxCallDepth
+		}
+
+		if options.StandardLibraryPath != "" {
+			engine.StandardLibraryPath = options.StandardLibraryPath
+			engine.StandardLibraryModule = StandardLibraryModule
+
+			err := engine.IndexStandardLibrary(engine.StandardLibraryPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		runtime.engine = engine
+
+	case BackendVM:
+		if options.StandardLibraryPath != "" {
+			return nil, fmt.Errorf("the vm backend does not support the standard library")
+		}
+
+		runtime.compiler = compiler.New()
+		runtime.globals = make([]object.Object, vm.GlobalsSize)
+		runtime.tasks = object.NewScheduler()
+
+	default:
+		return nil, fmt.Errorf("unknown backend %s", options.Backend)
+	}
+
+	return runtime, nil
+}
+
+func (runtime *Runtime) Backend() Backend {
+	return runtime.options.Backend
+}
+
+// Close ends the tasks and generators left behind by functions called with Call, the ones of a program already end
+// with it.
+// The runtime must not be used afterwards.
+func (runtime *Runtime) Close() {
+	if runtime.engine != nil {
+		runtime.engine.Finish()
+		return
+	}
+
+	runtime.tasks.Cancel()
+}
+
+// RunString runs the source code and returns the value of its last expression statement,
+// errors raised by the program are returned as *object.Error
+func (runtime *Runtime) RunString(source string) (interface{}, error) {
+	return runtime.run("", source)
+}
+
+// RunFile runs the program of the file, the evaluator also loads the module of its directory
+func (runtime *Runtime) RunFile(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if runtime.engine != nil {
+		err = runtime.engine.LoadModule(filepath.Dir(path))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return runtime.run(path, string(data))
+}
+
+func (runtime *Runtime) run(file string, source string) (interface{}, error) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		return nil, &SyntaxError{File: file, Errors: p.Errors()}
+	}
+
+	if errors := checker.Check(program); len(errors) > 0 {
+		return nil, &SyntaxError{File: file, Errors: errors}
+	}
+
+	var result object.Object
+	var err error
+
+	if runtime.engine != nil {
+		result, err = runtime.evaluate(file, program)
+	} else {
+		result, err = runtime.execute(file, program)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// only expression statements result in a value, the same for both backends
+	if len(program.Statements) == 0 || !compiler.LeavesValue(program.Statements[len(program.Statements)-1]) || result == nil {
+		return nil, nil
+	}
+
+	return runtime.FromObject(result)
+}
+
+func (runtime *Runtime) evaluate(file string, program *ast.Program) (object.Object, error) {
+	runtime.engine.File = file
+	runtime.engine.ResetUsage()
+
+	result := runtime.engine.Eval(program)
+	runtime.engine.Finish()
+	if err, ok := result.(*object.Error); ok {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (runtime *Runtime) execute(file string, program *ast.Program) (object.Object, error) {
+	runtime.compiler.ResetInstructions()
+	runtime.compiler.File = file
+
+	err := runtime.compiler.Compile(program)
+	if err != nil {
+		return nil, err
+	}
+
+	runtime.machine = vm.NewWithGlobals(runtime.compiler.Bytecode(), runtime.globals)
+	runtime.machine.Limits = runtime.options.Limits
+	runtime.machine.Tasks = runtime.tasks
+
+	err = runtime.machine.Run()
+	if err != nil {
+		// the frames of the failed program must not be used by Call
+		runtime.machine = nil
+		return nil, err
+	}
+
+	return runtime.machine.LastPoppedStackElem(), nil
+}
+
+// Set converts the Go value and assigns it to the global with the name
+func (runtime *Runtime) Set(name string, value interface{}) error {
+	obj, err := runtime.ToObject(value)
+	if err != nil {
+		return err
+	}
+
+	runtime.setObject(name, obj)
+
+	return nil
+}
+
+func (runtime *Runtime) setObject(name string, obj object.Object) {
+	if runtime.engine != nil {
+		runtime.engine.SetVariable(name, obj)
+		return
+	}
+
+	runtime.globals[runtime.compiler.DefineGlobal(name)] = obj
+}
+
+// Get returns the value of the global with the name converted to Go, see FromObject
+func (runtime *Runtime) Get(name string) (interface{}, error) {
+	obj, ok := runtime.getObject(name)
+	if !ok {
+		return nil, fmt.Errorf("variable %s is not defined", name)
+	}
+
+	return runtime.FromObject(obj)
+}
+
+func (runtime *Runtime) getObject(name string) (object.Object, bool) {
+	if runtime.engine != nil {
+		return runtime.engine.Lookup(name)
+	}
+
+	index, ok := runtime.compiler.Bytecode().Globals[name]
+	if !ok || runtime.globals[index] == nil {
+		return nil, false
+	}
+
+	return runtime.globals[index], true
+}
+
+// RegisterFunc makes the Go function callable by programs, see ToObject for the conversion of its arguments
+func (runtime *Runtime) RegisterFunc(name string, function interface{}) error {
+	builtin, err := runtime.wrapFunc(name, reflect.ValueOf(function))
+	if err != nil {
+		return err
+	}
+
+	runtime.setObject(name, builtin)
+
+	return nil
+}
+
+// Call calls the function stored in the global with the name and returns its result converted to Go
+func (runtime *Runtime) Call(name string, args ...interface{}) (interface{}, error) {
+	function, ok := runtime.getObject(name)
+	if !ok {
+		return nil, fmt.Errorf("function %s is not defined", name)
+	}
+
+	objects := make([]object.Object, 0, len(args))
+	for _, arg := range args {
+		obj, err := runtime.ToObject(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, obj)
+	}
+
+	result, err := runtime.call(function, objects)
+	if err != nil {
+		return nil, err
+	}
+
+	return runtime.FromObject(result)
+}
+
+// FuncError returns the first error of a Curry function which was converted to a Go func type without an error
+// result, since the last call of FuncError. Such funcs return zero values when the Curry function fails.
+// Errors of funcs called while a function registered with RegisterFunc runs are raised by its call instead.
+func (runtime *Runtime) FuncError() error {
+	err := runtime.funcErr
+	runtime.funcErr = nil
+
+	return err
+}
+
+// call runs a Curry function with the backend of the runtime
+func (runtime *Runtime) call(function object.Object, args []object.Object) (object.Object, error) {
+	if builtin, ok := function.(*object.Builtin); ok {
+		return errorResult(builtin.Function(token.Token{}, args...))
+	}
+
+	if runtime.engine != nil {
+		curryFunction, ok := function.(*object.Function)
+		if !ok {
+			return nil, fmt.Errorf("calling non-function: %s", function.Type())
+		}
+
+		return errorResult(runtime.engine.CallFunction(curryFunction, args...))
+	}
+
+	if runtime.machine == nil {
+		runtime.machine = vm.NewWithGlobals(runtime.compiler.Bytecode(), runtime.globals)
+		runtime.machine.Limits = runtime.options.Limits
+		runtime.machine.Tasks = runtime.tasks
+	}
+
+	return runtime.machine.Call(function, args...)
+}
+
+func errorResult(result object.Object) (object.Object, error) {
+	if err, ok := result.(*object.Error); ok {
+		return nil, err
+	}
+
+	if result == nil {
+		return evaluator.NULL, nil
+	}
+
+	return result, nil
+}
@@ -0,0 +1,423 @@
+package curry
+
+import (
+	"curryLang/object"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	goroutine "runtime"
+	"testing"
+)
+
+var backends = []Backend{BackendEvaluator, BackendVM}
+
+func newTestRuntime(t *testing.T, backend Backend) *Runtime {
+	t.Helper()
+
+	runtime, err := NewRuntime(Options{Backend: backend})
+	if err != nil {
+		t.Fatalf("failed to create runtime: %s", err)
+	}
+
+	return runtime
+}
+
+func TestRunString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1 + 2", int64(3)},
+		{`"cur" + "ry"`, "curry"},
+		{"1 == 1", true},
+		{"let x = 1;", nil},
+		{"fn add(a, b) { a + b }; add(1, 2)", int64(3)},
+		{"struct Point { x, y }; Point{x: 1, y: 2}", map[string]interface{}{"x": int64(1), "y": int64(2)}},
+	}
+
+	for _, backend := range backends {
+		for _, tt := range tests {
+			runtime := newTestRuntime(t, backend)
+
+			result, err := runtime.RunString(tt.input)
+			if err != nil {
+				t.Errorf("%s: unexpected error for %q: %s", backend, tt.input, err)
+				continue
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("%s: wrong result for %q. want=%#v, got=%#v", backend, tt.input, tt.expected, result)
+			}
+		}
+	}
+}
+
+func TestRunStringErrors(t *testing.T) {
+	for _, backend := range backends {
+		runtime := newTestRuntime(t, backend)
+
+		_, err := runtime.RunString("let = 1;")
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%s: error is not SyntaxError. got=%T (%+v)", backend, err, err)
+		}
+
+		_, err = runtime.RunString("1 + true")
+		var runtimeErr *object.Error
+		if !errors.As(err, &runtimeErr) || runtimeErr.Kind != object.TYPE_ERROR {
+			t.Errorf("%s: error is not a TypeError. got=%T (%+v)", backend, err, err)
+		}
+
+		// a failed program does not affect the following ones
+		result, err := runtime.RunString("1 + 1")
+		if err != nil || result != int64(2) {
+			t.Errorf("%s: runtime is broken after an error. got=%v, %v", backend, result, err)
+		}
+	}
+}
+
+func TestGlobals(t *testing.T) {
+	for _, backend := range backends {
+		runtime := newTestRuntime(t, backend)
+
+		err := runtime.Set("limit", 10)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", backend, err)
+		}
+
+		_, err = runtime.RunString("let doubled = limit * 2;")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", backend, err)
+		}
+
+		// globals are shared by the programs of a runtime
+		result, err := runtime.RunString("doubled + limit")
+		if err != nil || result != int64(30) {
+			t.Errorf("%s: wrong result. got=%v, %v", backend, result, err)
+		}
+
+		err = runtime.Set("limit", 1)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", backend, err)
+		}
+
+		value, err := runtime.Get("limit")
+		if err != nil || value != int64(1) {
+			t.Errorf("%s: wrong value of limit. got=%v, %v", backend, value, err)
+		}
+
+		_, err = runtime.Get("missing")
+		if err == nil || err.Error() != "variable missing is not defined" {
+			t.Errorf("%s: wrong error for missing variable. got=%v", backend, err)
+		}
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	for _, backend := range backends {
+		runtime := newTestRuntime(t, backend)
+
+		var logged []string
+		functions := map[string]interface{}{
+			"log":    func(message string) { logged = append(logged, message) },
+			"repeat": func(s string, n int) string { return s + s[:n] },
+			"sum": func(values ...int) int {
+				sum := 0
+				for _, value := range values {
+					sum += value
+				}
+				return sum
+			},
+			"check": func(ok bool) (int, error) {
+				if !ok {
+					return 0, errors.New("check failed")
+				}
+				return 1, nil
+			},
+			"apply": func(f func(int) int, value int) int { return f(value) },
+			"keys":  func(m map[string]int) []string { return []string{"a"} },
+			"boom":  func() { panic("boom") },
+		}
+
+		for name, function := range functions {
+			err := runtime.RegisterFunc(name, function)
+			if err != nil {
+				t.Fatalf("%s: failed to register %s: %s", backend, name, err)
+			}
+		}
+
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{`log("hi"); repeat("ab", 1)`, "aba"},
+			{"sum(1, 2, 3)", int64(6)},
+			{"sum()", int64(0)},
+			{"check(true)", int64(1)},
+			{"fn double(x) { x * 2 }; apply(double, 21)", int64(42)},
+		}
+
+		for _, tt := range tests {
+			result, err := runtime.RunString(tt.input)
+			if err != nil {
+				t.Errorf("%s: unexpected error for %q: %s", backend, tt.input, err)
+				continue
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("%s: wrong result for %q. want=%#v, got=%#v", backend, tt.input, tt.expected, result)
+			}
+		}
+
+		if !reflect.DeepEqual(logged, []string{"hi"}) {
+			t.Errorf("%s: log was not called. got=%v", backend, logged)
+		}
+
+		errorTests := []struct {
+			input   string
+			kind    object.ErrorKind
+			message string
+		}{
+			{`repeat(1, 1)`, object.TYPE_ERROR, "argument 1 of repeat: cannot use INTEGER as string"},
+			{`repeat("a")`, object.ARGUMENT_ERROR, "wrong number of arguments for repeat: want=2, got=1"},
+			{"check(false)", object.RUNTIME_ERROR, "check failed"},
+			{"boom()", object.RUNTIME_ERROR, "boom panicked: boom"},
+		}
+
+		for _, tt := range errorTests {
+			_, err := runtime.RunString(tt.input)
+
+			var runtimeErr *object.Error
+			if !errors.As(err, &runtimeErr) {
+				t.Errorf("%s: error is not object.Error for %q. got=%T (%+v)", backend, tt.input, err, err)
+				continue
+			}
+
+			if runtimeErr.Kind != tt.kind || runtimeErr.Message != tt.message {
+				t.Errorf("%s: wrong error for %q. want=%s: %s, got=%s: %s", backend, tt.input, tt.kind, tt.message, runtimeErr.Kind, runtimeErr.Message)
+			}
+		}
+
+		err := runtime.RegisterFunc("invalid", func() (int, int) { return 1, 2 })
+		if err == nil || err.Error() != "function invalid has to return at most one value and an error" {
+			t.Errorf("%s: wrong error for invalid function. got=%v", backend, err)
+		}
+	}
+}
+
+func TestFuncWithoutErrorResult(t *testing.T) {
+	for _, backend := range backends {
+		runtime := newTestRuntime(t, backend)
+
+		var stored func(int) int
+		functions := map[string]interface{}{
+			"apply": func(f func(int) int, value int) int { return f(value) + 1 },
+			"store": func(f func(int) int) { stored = f },
+		}
+
+		for name, function := range functions {
+			err := runtime.RegisterFunc(name, function)
+			if err != nil {
+				t.Fatalf("%s: failed to register %s: %s", backend, name, err)
+			}
+		}
+
+		// the error is raised by the call of the registered function which called the func
+		_, err := runtime.RunString(`fn fail(x) { throw Error{message: "failed"}; } apply(fail, 1)`)
+		var runtimeErr *object.Error
+		if !errors.As(err, &runtimeErr) || runtimeErr.Message != "failed" {
+			t.Errorf("%s: wrong error of apply. got=%v", backend, err)
+		}
+
+		if err := runtime.FuncError(); err != nil {
+			t.Errorf("%s: error of apply was reported by FuncError. got=%v", backend, err)
+		}
+
+		// funcs called by Go itself return zero values and report the error with FuncError
+		_, err = runtime.RunString(`store(fail)`)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", backend, err)
+		}
+
+		if result := stored(1); result != 0 {
+			t.Errorf("%s: failed func did not return the zero value. got=%d", backend, result)
+		}
+
+		if err := runtime.FuncError(); err == nil || err.Error() != "failed" {
+			t.Errorf("%s: wrong error reported by FuncError. got=%v", backend, err)
+		}
+
+		if err := runtime.FuncError(); err != nil {
+			t.Errorf("%s: FuncError did not reset the error. got=%v", backend, err)
+		}
+	}
+}
+
+func TestCall(t *testing.T) {
+	for _, backend := range backends {
+		runtime := newTestRuntime(t, backend)
+
+		_, err := runtime.RunString("fn add(a, b) { return a + b; } fn fail() { return 1 + true; }")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", backend, err)
+		}
+
+		result, err := runtime.Call("add", 40, 2)
+		if err != nil || result != int64(42) {
+			t.Errorf("%s: wrong result of add. got=%v, %v", backend, result, err)
+		}
+
+		_, err = runtime.Call("fail")
+		var runtimeErr *object.Error
+		if !errors.As(err, &runtimeErr) || runtimeErr.Kind != object.TYPE_ERROR {
+			t.Errorf("%s: error is not a TypeError. got=%T (%+v)", backend, err, err)
+		}
+
+		_, err = runtime.Call("missing")
+		if err == nil || err.Error() != "function missing is not defined" {
+			t.Errorf("%s: wrong error for missing function. got=%v", backend, err)
+		}
+
+		// Curry functions are returned as Go functions
+		value, err := runtime.Get("add")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", backend, err)
+		}
+
+		add, ok := value.(func(args ...interface{}) (interface{}, error))
+		if !ok {
+			t.Fatalf("%s: add is not a Go function. got=%T", backend, value)
+		}
+
+		result, err = add(1, 2)
+		if err != nil || result != int64(3) {
+			t.Errorf("%s: wrong result of add. got=%v, %v", backend, result, err)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	runtime := newTestRuntime(t, BackendEvaluator)
+
+	tests := []struct {
+		input    interface{}
+		expected interface{}
+	}{
+		{nil, nil},
+		{42, int64(42)},
+		{uint8(7), int64(7)},
+		{"curry", "curry"},
+		{true, true},
+		{[]int{1, 2}, []interface{}{int64(1), int64(2)}},
+		{[2]string{"a", "b"}, []interface{}{"a", "b"}},
+		{map[string]interface{}{"a": 1, "b": "x"}, map[string]interface{}{"a": int64(1), "b": "x"}},
+		{&[]bool{true}, []interface{}{true}},
+	}
+
+	for _, tt := range tests {
+		obj, err := runtime.ToObject(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %#v: %s", tt.input, err)
+			continue
+		}
+
+		result, err := runtime.FromObject(obj)
+		if err != nil {
+			t.Errorf("unexpected error for %#v: %s", tt.input, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("wrong result. want=%#v, got=%#v", tt.expected, result)
+		}
+	}
+
+	errorTests := []struct {
+		input    interface{}
+		expected string
+	}{
+		{[]interface{}{1, "a"}, "list elements have to be of the same type, element 1 is STRING instead of INTEGER"},
+		{map[int]int{1: 1}, "unsupported go type map[int]int, only maps with string keys can be converted"},
+		{uint64(1 << 63), "9223372036854775808 overflows the integers of curry"},
+		{make(chan int), "unsupported go type chan int"},
+	}
+
+	for _, tt := range errorTests {
+		_, err := runtime.ToObject(tt.input)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error for %#v. want=%q, got=%v", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.curry")
+
+	err := os.WriteFile(file, []byte("fn main() { return 1 + missing; }\nmain();"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runtime := newTestRuntime(t, BackendEvaluator)
+
+	_, err = runtime.RunFile(file)
+
+	var runtimeErr *object.Error
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("error is not object.Error. got=%T (%+v)", err, err)
+	}
+
+	if runtimeErr.Span.File != file || runtimeErr.Span.Line != 1 {
+		t.Errorf("wrong error position. got=%s", runtimeErr.Span)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	for _, backend := range backends {
+		runtime, err := NewRuntime(Options{Backend: backend, Limits: object.Limits{MaxSteps: 100}})
+		if err != nil {
+			t.Fatalf("failed to create runtime: %s", err)
+		}
+
+		_, err = runtime.RunString("while (true) { }")
+
+		var runtimeErr *object.Error
+		if !errors.As(err, &runtimeErr) || runtimeErr.Kind != object.STEP_LIMIT_ERROR {
+			t.Errorf("%s: error is not a StepLimitError. got=%T (%+v)", backend, err, err)
+		}
+	}
+}
+
+func TestTasksEnd(t *testing.T) {
+	for _, backend := range backends {
+		runtime := newTestRuntime(t, backend)
+		before := goroutine.NumGoroutine()
+
+		_, err := runtime.RunString("fn wait(started, blocked) { started.send(1); blocked.receive(); } fn leave() { spawn wait(channel(1), channel()); }")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", backend, err)
+		}
+
+		// every program leaves a task behind which waits for a value nobody sends
+		for i := 0; i < 50; i++ {
+			_, err = runtime.RunString("let started = channel(); spawn wait(started, channel()); started.receive()")
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %s", backend, err)
+			}
+		}
+
+		for i := 0; i < 50; i++ {
+			if _, err = runtime.Call("leave"); err != nil {
+				t.Fatalf("%s: unexpected error: %s", backend, err)
+			}
+		}
+
+		runtime.Close()
+
+		if after := goroutine.NumGoroutine(); after > before {
+			t.Errorf("%s: goroutines of tasks were not ended. before=%d, after=%d", backend, before, after)
+		}
+	}
+}
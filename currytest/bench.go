@@ -0,0 +1,495 @@
+package currytest
+
+import (
+	"curryLang/ast"
+	"curryLang/compiler"
+	"curryLang/evaluator"
+	"curryLang/object"
+	"curryLang/token"
+	"curryLang/vm"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"time"
+)
+
+// Backend is the engine a benchmark is executed with
+type Backend string
+
+const (
+	BackendEvaluator Backend = "evaluator"
+	BackendVM        Backend = "vm"
+)
+
+// maxBenchN limits the number of iterations of a single benchmark run, like in testing.B
+const maxBenchN = 1e9
+
+// BStruct is the struct of the b parameter passed to every benchmark function
+var BStruct = &object.Struct{
+	Name:    "B",
+	Fields:  []string{"n", "resetTimer", "startTimer", "stopTimer"},
+	Methods: map[string]*object.Function{},
+	Traits:  map[string]*object.Trait{},
+}
+
+// Benchmark is a single fn benchXxx(b) function and its results for every backend
+type Benchmark struct {
+	Name    string
+	File    string
+	Line    int
+	Results []*BenchResult // in the order of BenchOptions.Backends
+}
+
+type BenchResult struct {
+	Backend   Backend
+	N         int
+	T         time.Duration
+	MemAllocs uint64
+	MemBytes  uint64
+	Err       error // set if the benchmark failed
+}
+
+func (result *BenchResult) NsPerOp() int64 {
+	if result.N <= 0 {
+		return 0
+	}
+
+	return result.T.Nanoseconds() / int64(result.N)
+}
+
+func (result *BenchResult) AllocsPerOp() int64 {
+	if result.N <= 0 {
+		return 0
+	}
+
+	return int64(result.MemAllocs) / int64(result.N)
+}
+
+func (result *BenchResult) AllocedBytesPerOp() int64 {
+	if result.N <= 0 {
+		return 0
+	}
+
+	return int64(result.MemBytes) / int64(result.N)
+}
+
+type BenchPackage struct {
+	Path       string
+	Dir        string
+	Benchmarks []*Benchmark
+	Err        error // set if the package could not be loaded
+	Elapsed    time.Duration
+}
+
+func (pkg *BenchPackage) Failed() bool {
+	if pkg.Err != nil {
+		return true
+	}
+
+	for _, benchmark := range pkg.Benchmarks {
+		for _, result := range benchmark.Results {
+			if result.Err != nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type BenchOptions struct {
+	Bench     *regexp.Regexp // only benchmarks with a matching name are run, all if nil
+	Time      time.Duration  // minimal run time of every benchmark, one second if zero
+	Backends  []Backend      // the evaluator if empty
+	NewEngine func() *evaluator.ExecutionEngine
+	Out       io.Writer // receives the results, nothing is printed if nil
+}
+
+// RunBenchmarks runs the benchmarks of all directories and returns false if any of them failed
+func RunBenchmarks(dirs []string, options BenchOptions) bool {
+	ok := true
+
+	for _, dir := range dirs {
+		pkg := RunBenchPackage(dir, options)
+		if pkg.Failed() {
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+// RunBenchPackage loads dir once for every backend and runs each of its benchmarks with all backends
+func RunBenchPackage(dir string, options BenchOptions) *BenchPackage {
+	start := time.Now()
+	pkg := &BenchPackage{Path: dir, Dir: dir}
+
+	defer func() {
+		pkg.Elapsed = time.Since(start)
+
+		if options.Out != nil {
+			printBenchPackage(options.Out, pkg)
+		}
+	}()
+
+	engine := newEngine(Options{NewEngine: options.NewEngine})
+
+	err := engine.LoadModule(dir)
+	if engine.ModuleFile != nil {
+		pkg.Path = packagePath(engine, dir)
+	}
+
+	if err != nil {
+		pkg.Err = err
+		return pkg
+	}
+
+	files, err := parsePackage(dir)
+	if err != nil {
+		pkg.Err = err
+		return pkg
+	}
+
+	err = collectFunctions(files, "bench", "benchmark", func(file string, function *ast.FunctionExpression) {
+		if options.Bench != nil && !options.Bench.MatchString(function.Name) {
+			return
+		}
+
+		pkg.Benchmarks = append(pkg.Benchmarks, &Benchmark{
+			Name: function.Name,
+			File: file,
+			Line: function.Token.Line,
+		})
+	})
+	if err != nil {
+		pkg.Err = err
+		return pkg
+	}
+
+	if len(pkg.Benchmarks) == 0 {
+		return pkg
+	}
+
+	backends := options.Backends
+	if len(backends) == 0 {
+		backends = []Backend{BackendEvaluator}
+	}
+
+	benchTime := options.Time
+	if benchTime <= 0 {
+		benchTime = time.Second
+	}
+
+	runners := make([]benchRunner, len(backends))
+	for i, backend := range backends {
+		runners[i], err = newBenchRunner(backend, engine, files)
+		if err != nil {
+			// the other backends might still be able to run the benchmarks
+			runners[i] = failingRunner(fmt.Errorf("%s: %s", backend, err))
+		}
+	}
+
+	width := benchNameWidth(pkg.Benchmarks, backends)
+
+	for _, benchmark := range pkg.Benchmarks {
+		for i, backend := range backends {
+			result := runBenchmark(runners[i], benchmark, benchTime)
+			result.Backend = backend
+			benchmark.Results = append(benchmark.Results, result)
+
+			if options.Out != nil {
+				printBenchResult(options.Out, benchmark, result, width)
+			}
+		}
+
+		if options.Out != nil && len(benchmark.Results) > 1 {
+			printBenchComparison(options.Out, benchmark)
+		}
+	}
+
+	return pkg
+}
+
+// benchRunner calls the benchmark function with the given b object once
+type benchRunner func(benchmark *Benchmark, b object.Object) error
+
+func newBenchRunner(backend Backend, engine *evaluator.ExecutionEngine, files []sourceFile) (benchRunner, error) {
+	switch backend {
+	case BackendEvaluator:
+		return evaluatorRunner(engine, files)
+	case BackendVM:
+		return vmRunner(files)
+	}
+
+	return nil, fmt.Errorf("unknown backend %s", backend)
+}
+
+func evaluatorRunner(engine *evaluator.ExecutionEngine, files []sourceFile) (benchRunner, error) {
+	err := evaluateFiles(engine, files)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(benchmark *Benchmark, b object.Object) error {
+		function, ok := engine.Functions[benchmark.Name]
+		if !ok {
+			return fmt.Errorf("benchmark function %s was not declared", benchmark.Name)
+		}
+
+		if len(function.Parameters) != 1 {
+			return fmt.Errorf("benchmark function %s has to take exactly one parameter", benchmark.Name)
+		}
+
+		result := engine.CallFunction(function, b)
+		if err, ok := result.(*object.Error); ok {
+			return errors.New(err.Message)
+		}
+
+		return nil
+	}, nil
+}
+
+// vmRunner compiles all files of the package into one program, the package and import statements are not compiled
+func vmRunner(files []sourceFile) (benchRunner, error) {
+	comp := compiler.New()
+	for _, file := range files {
+		comp.File = file.path
+
+		err := comp.Compile(file.program)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	machine := vm.New(comp.Bytecode())
+	err := machine.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(benchmark *Benchmark, b object.Object) error {
+		function, ok := machine.Global(benchmark.Name)
+		if !ok {
+			return fmt.Errorf("benchmark function %s was not declared", benchmark.Name)
+		}
+
+		if compiled, ok := function.(*object.CompiledFunction); ok && compiled.NumParameters != 1 {
+			return fmt.Errorf("benchmark function %s has to take exactly one parameter", benchmark.Name)
+		}
+
+		_, err := machine.Call(function, b)
+		return err
+	}, nil
+}
+
+func failingRunner(err error) benchRunner {
+	return func(benchmark *Benchmark, b object.Object) error {
+		return err
+	}
+}
+
+// runBenchmark increases the number of iterations until the benchmark runs at least benchTime, like testing.B
+func runBenchmark(run benchRunner, benchmark *Benchmark, benchTime time.Duration) *BenchResult {
+	state := &benchState{}
+
+	err := state.runN(run, benchmark, 1)
+	for err == nil && state.duration < benchTime && state.n < maxBenchN {
+		last := int64(state.n)
+		err = state.runN(run, benchmark, predictN(benchTime.Nanoseconds(), last, state.duration.Nanoseconds(), last))
+	}
+
+	return &BenchResult{
+		N:         state.n,
+		T:         state.duration,
+		MemAllocs: state.netAllocs,
+		MemBytes:  state.netBytes,
+		Err:       err,
+	}
+}
+
+// predictN estimates the iterations needed to reach goalns, growing at most by the factor 100
+func predictN(goalns int64, prevIters int64, prevns int64, last int64) int {
+	if prevns <= 0 {
+		prevns = 1
+	}
+
+	n := goalns * prevIters / prevns
+	// run a bit more than predicted, so the goal is not missed by a small margin
+	n += n / 5
+	if n > 100*last {
+		n = 100 * last
+	}
+	if n < last+1 {
+		n = last + 1
+	}
+	if n > maxBenchN {
+		n = maxBenchN
+	}
+
+	return int(n)
+}
+
+// benchState measures one run of a benchmark, its timer can be controlled from the benchmark function
+type benchState struct {
+	n        int
+	timerOn  bool
+	start    time.Time
+	duration time.Duration
+
+	startAllocs uint64
+	startBytes  uint64
+	netAllocs   uint64
+	netBytes    uint64
+}
+
+func (state *benchState) runN(run benchRunner, benchmark *Benchmark, n int) error {
+	runtime.GC()
+
+	state.n = n
+	b := state.object()
+
+	state.resetTimer()
+	state.startTimer()
+	err := run(benchmark, b)
+	state.stopTimer()
+
+	return err
+}
+
+func (state *benchState) object() *object.Instance {
+	builtin := func(name string, function func()) *object.Builtin {
+		return &object.Builtin{Name: name, Function: func(position token.Token, args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return &object.Error{Message: fmt.Sprintf("%s expects no arguments but got %d", name, len(args))}
+			}
+
+			function()
+			return nil
+		}}
+	}
+
+	return &object.Instance{
+		Struct: BStruct,
+		Fields: map[string]object.Object{
+			"n":          &object.Integer{Value: int64(state.n)},
+			"resetTimer": builtin("resetTimer", state.resetTimer),
+			"startTimer": builtin("startTimer", state.startTimer),
+			"stopTimer":  builtin("stopTimer", state.stopTimer),
+		},
+	}
+}
+
+func (state *benchState) startTimer() {
+	if state.timerOn {
+		return
+	}
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	state.startAllocs = stats.Mallocs
+	state.startBytes = stats.TotalAlloc
+	state.start = time.Now()
+	state.timerOn = true
+}
+
+func (state *benchState) stopTimer() {
+	if !state.timerOn {
+		return
+	}
+
+	state.duration += time.Since(state.start)
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	state.netAllocs += stats.Mallocs - state.startAllocs
+	state.netBytes += stats.TotalAlloc - state.startBytes
+	state.timerOn = false
+}
+
+// resetTimer discards the measurements so far, but keeps the timer running if it is on
+func (state *benchState) resetTimer() {
+	if state.timerOn {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+
+		state.startAllocs = stats.Mallocs
+		state.startBytes = stats.TotalAlloc
+		state.start = time.Now()
+	}
+
+	state.duration = 0
+	state.netAllocs = 0
+	state.netBytes = 0
+}
+
+func benchNameWidth(benchmarks []*Benchmark, backends []Backend) int {
+	width := 0
+	for _, benchmark := range benchmarks {
+		for _, backend := range backends {
+			if length := len(benchName(benchmark, backend)); length > width {
+				width = length
+			}
+		}
+	}
+
+	return width
+}
+
+func benchName(benchmark *Benchmark, backend Backend) string {
+	return benchmark.Name + "/" + string(backend)
+}
+
+func printBenchResult(out io.Writer, benchmark *Benchmark, result *BenchResult, width int) {
+	name := benchName(benchmark, result.Backend)
+
+	if result.Err != nil {
+		fmt.Fprintf(out, "--- FAIL: %s\n    %s:%d: %s\n", name, filepath.Base(benchmark.File), benchmark.Line, result.Err)
+		return
+	}
+
+	fmt.Fprintf(
+		out,
+		"%-*s\t%8d\t%10d ns/op\t%8d B/op\t%8d allocs/op\n",
+		width, name, result.N, result.NsPerOp(), result.AllocedBytesPerOp(), result.AllocsPerOp(),
+	)
+}
+
+// printBenchComparison compares the time per operation of every backend with the first one
+func printBenchComparison(out io.Writer, benchmark *Benchmark) {
+	base := benchmark.Results[0]
+	if base.Err != nil || base.NsPerOp() == 0 {
+		return
+	}
+
+	for _, result := range benchmark.Results[1:] {
+		if result.Err != nil || result.NsPerOp() == 0 {
+			continue
+		}
+
+		factor := float64(base.NsPerOp()) / float64(result.NsPerOp())
+		if factor >= 1 {
+			fmt.Fprintf(out, "    %s: %s is %.2fx faster than %s\n", benchmark.Name, result.Backend, factor, base.Backend)
+		} else {
+			fmt.Fprintf(out, "    %s: %s is %.2fx slower than %s\n", benchmark.Name, result.Backend, 1/factor, base.Backend)
+		}
+	}
+}
+
+func printBenchPackage(out io.Writer, pkg *BenchPackage) {
+	if pkg.Err != nil {
+		fmt.Fprintf(out, "FAIL\t%s [setup failed]\n    %s\n", pkg.Path, pkg.Err)
+		return
+	}
+
+	if pkg.Failed() {
+		fmt.Fprintf(out, "FAIL\t%s\t%.3fs\n", pkg.Path, pkg.Elapsed.Seconds())
+		return
+	}
+
+	fmt.Fprintf(out, "ok  \t%s\t%.3fs\n", pkg.Path, pkg.Elapsed.Seconds())
+}
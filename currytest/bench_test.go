@@ -0,0 +1,166 @@
+package currytest
+
+import (
+	"bytes"
+	"curryLang/internal/testfiles"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fibSource = `package fib
+
+fn Fib(n) {
+    if (n < 2) {
+        return n;
+    }
+    return Fib(n - 1) + Fib(n - 2);
+}
+`
+
+const fibTestSource = `package fib
+
+fn benchFib(b) {
+    let i = 0;
+    while (i < b.n) {
+        Fib(10);
+        i = i + 1;
+    }
+}
+
+fn benchTimer(b) {
+    b.stopTimer();
+    Fib(15);
+    b.startTimer();
+    b.resetTimer();
+    let i = 0;
+    while (i < b.n) {
+        i = i + 1;
+    }
+}
+
+fn benchmark(b) {
+}
+`
+
+func TestRunBenchPackage(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"curry.mod":          "module demo",
+		"fib/fib.curry":      fibSource,
+		"fib/fib_test.curry": fibTestSource,
+	})
+
+	var out bytes.Buffer
+	pkg := RunBenchPackage(filepath.Join(root, "fib"), BenchOptions{
+		Time:     10 * time.Millisecond,
+		Backends: []Backend{BackendEvaluator, BackendVM},
+		Out:      &out,
+	})
+
+	if pkg.Err != nil {
+		t.Fatalf("unexpected error: %s", pkg.Err)
+	}
+
+	if pkg.Path != "demo/fib" {
+		t.Errorf("pkg.Path is not demo/fib. got=%s", pkg.Path)
+	}
+
+	if len(pkg.Benchmarks) != 2 {
+		t.Fatalf("wrong number of benchmarks. want=2, got=%d", len(pkg.Benchmarks))
+	}
+
+	for i, name := range []string{"benchFib", "benchTimer"} {
+		benchmark := pkg.Benchmarks[i]
+		if benchmark.Name != name {
+			t.Errorf("wrong benchmark name. want=%s, got=%s", name, benchmark.Name)
+		}
+
+		if len(benchmark.Results) != 2 {
+			t.Fatalf("wrong number of results for %s. want=2, got=%d", name, len(benchmark.Results))
+		}
+
+		for j, backend := range []Backend{BackendEvaluator, BackendVM} {
+			result := benchmark.Results[j]
+			if result.Backend != backend {
+				t.Errorf("wrong backend. want=%s, got=%s", backend, result.Backend)
+			}
+
+			if result.Err != nil {
+				t.Errorf("%s/%s failed: %s", name, backend, result.Err)
+			}
+
+			if result.N < 1 || result.NsPerOp() <= 0 {
+				t.Errorf("%s/%s was not measured. n=%d, t=%s", name, backend, result.N, result.T)
+			}
+		}
+	}
+
+	output := out.String()
+	for _, expected := range []string{"benchFib/evaluator", "benchFib/vm", " ns/op\t", " allocs/op\n", "    benchFib: vm is ", "ok  \tdemo/fib\t"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("output does not contain %q. got=%q", expected, output)
+		}
+	}
+}
+
+func TestRunBenchPackageErrors(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"fail/a_test.curry": "fn benchMissing(b) { return missing; }\nfn benchOk(b) { }",
+		"args/a_test.curry": "fn benchArgs(a, b) { }",
+	})
+
+	pkg := RunBenchPackage(filepath.Join(root, "fail"), BenchOptions{
+		Bench:    regexp.MustCompile("Missing"),
+		Time:     time.Millisecond,
+		Backends: []Backend{BackendEvaluator, BackendVM},
+	})
+
+	if len(pkg.Benchmarks) != 1 {
+		t.Fatalf("wrong number of benchmarks. want=1, got=%d", len(pkg.Benchmarks))
+	}
+
+	results := pkg.Benchmarks[0].Results
+	if results[0].Err == nil || results[0].Err.Error() != "Undeclared variable missing used" {
+		t.Errorf("wrong evaluator error. got=%v", results[0].Err)
+	}
+
+	if results[1].Err == nil || results[1].Err.Error() != "vm: there variable missing has not yet been defined" {
+		t.Errorf("wrong vm error. got=%v", results[1].Err)
+	}
+
+	if !pkg.Failed() {
+		t.Errorf("package should have failed")
+	}
+
+	pkg = RunBenchPackage(filepath.Join(root, "args"), BenchOptions{
+		Time:     time.Millisecond,
+		Backends: []Backend{BackendVM},
+	})
+
+	results = pkg.Benchmarks[0].Results
+	if results[0].Err == nil || results[0].Err.Error() != "benchmark function benchArgs has to take exactly one parameter" {
+		t.Errorf("wrong vm error. got=%v", results[0].Err)
+	}
+}
+
+func TestPredictN(t *testing.T) {
+	tests := []struct {
+		goalns, prevIters, prevns, last int64
+		expected                        int
+	}{
+		{1e9, 1, 1e6, 1, 100},
+		{1e9, 100, 1e8, 100, 1200},
+		{1e9, 1000, 2e9, 1000, 1001},
+		{1e9, 1, 0, 1, 100},
+		{1e9, 1e9, 1, 1e9, 1e9},
+	}
+
+	for _, tt := range tests {
+		n := predictN(tt.goalns, tt.prevIters, tt.prevns, tt.last)
+		if n != tt.expected {
+			t.Errorf("wrong prediction for %+v. got=%d", tt, n)
+		}
+	}
+}
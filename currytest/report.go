@@ -0,0 +1,285 @@
+package currytest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Reporter is notified about the progress of a test run
+type Reporter interface {
+	StartPackage(pkg *Package)
+	StartTest(pkg *Package, test *Test)
+	EndTest(pkg *Package, test *Test)
+	EndPackage(pkg *Package)
+}
+
+// TextReporter prints the results like go test, passing and skipped tests are only listed if Verbose is set
+type TextReporter struct {
+	Out     io.Writer
+	Verbose bool
+}
+
+func (reporter *TextReporter) StartPackage(pkg *Package) {}
+
+func (reporter *TextReporter) StartTest(pkg *Package, test *Test) {
+	if reporter.Verbose {
+		fmt.Fprintf(reporter.Out, "=== RUN   %s\n", test.Name)
+	}
+}
+
+func (reporter *TextReporter) EndTest(pkg *Package, test *Test) {
+	if test.Status != StatusFail && !reporter.Verbose {
+		return
+	}
+
+	fmt.Fprintf(reporter.Out, "--- %s: %s (%s)\n", statusLabel(test.Status), test.Name, seconds(test.Elapsed))
+	for _, line := range test.Output {
+		fmt.Fprintf(reporter.Out, "    %s\n", line)
+	}
+}
+
+func (reporter *TextReporter) EndPackage(pkg *Package) {
+	if pkg.Err != nil {
+		fmt.Fprintf(reporter.Out, "FAIL\t%s [setup failed]\n    %s\n", pkg.Path, pkg.Err)
+		return
+	}
+
+	if pkg.Failed() {
+		fmt.Fprintf(reporter.Out, "FAIL\t%s\t%.3fs\n", pkg.Path, pkg.Elapsed.Seconds())
+		return
+	}
+
+	if pkg.Coverage != nil {
+		fmt.Fprintf(reporter.Out, "ok  \t%s\t%.3fs\t%s\n", pkg.Path, pkg.Elapsed.Seconds(), pkg.Coverage)
+		return
+	}
+
+	fmt.Fprintf(reporter.Out, "ok  \t%s\t%.3fs\n", pkg.Path, pkg.Elapsed.Seconds())
+}
+
+// JSONReporter streams the events of go test -json, so the same tooling can be used for Curry tests
+type JSONReporter struct {
+	Out io.Writer
+
+	// returns the timestamp of the events, time.Now if nil
+	Now func() time.Time
+}
+
+type jsonEvent struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test,omitempty"`
+	Elapsed *float64  `json:"Elapsed,omitempty"`
+	Output  string    `json:"Output,omitempty"`
+}
+
+func (reporter *JSONReporter) StartPackage(pkg *Package) {
+	reporter.emit(jsonEvent{Action: "start", Package: pkg.Path})
+}
+
+func (reporter *JSONReporter) StartTest(pkg *Package, test *Test) {
+	reporter.emit(jsonEvent{Action: "run", Package: pkg.Path, Test: test.Name})
+	reporter.output(pkg, test.Name, fmt.Sprintf("=== RUN   %s\n", test.Name))
+}
+
+func (reporter *JSONReporter) EndTest(pkg *Package, test *Test) {
+	reporter.output(pkg, test.Name, fmt.Sprintf("--- %s: %s (%s)\n", statusLabel(test.Status), test.Name, seconds(test.Elapsed)))
+	for _, line := range test.Output {
+		reporter.output(pkg, test.Name, "    "+line+"\n")
+	}
+
+	elapsed := test.Elapsed.Seconds()
+	reporter.emit(jsonEvent{Action: string(test.Status), Package: pkg.Path, Test: test.Name, Elapsed: &elapsed})
+}
+
+func (reporter *JSONReporter) EndPackage(pkg *Package) {
+	if pkg.Err != nil {
+		reporter.output(pkg, "", fmt.Sprintf("%s\n", pkg.Err))
+	}
+
+	if pkg.Coverage != nil {
+		reporter.output(pkg, "", fmt.Sprintf("%s\n", pkg.Coverage))
+	}
+
+	action := StatusPass
+	if pkg.Failed() {
+		action = StatusFail
+	}
+
+	elapsed := pkg.Elapsed.Seconds()
+	reporter.emit(jsonEvent{Action: string(action), Package: pkg.Path, Elapsed: &elapsed})
+}
+
+func (reporter *JSONReporter) output(pkg *Package, test string, output string) {
+	reporter.emit(jsonEvent{Action: "output", Package: pkg.Path, Test: test, Output: output})
+}
+
+func (reporter *JSONReporter) emit(event jsonEvent) {
+	if reporter.Now != nil {
+		event.Time = reporter.Now()
+	} else {
+		event.Time = time.Now()
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(reporter.Out, "%s\n", data)
+}
+
+// JUnitReporter collects all results and writes them as JUnit XML when Flush is called
+type JUnitReporter struct {
+	Out    io.Writer
+	suites []junitSuite
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+func (reporter *JUnitReporter) StartPackage(pkg *Package) {}
+
+func (reporter *JUnitReporter) StartTest(pkg *Package, test *Test) {}
+
+func (reporter *JUnitReporter) EndTest(pkg *Package, test *Test) {}
+
+func (reporter *JUnitReporter) EndPackage(pkg *Package) {
+	suite := junitSuite{
+		Name: pkg.Path,
+		Time: fmt.Sprintf("%.3f", pkg.Elapsed.Seconds()),
+	}
+
+	if pkg.Err != nil {
+		suite.Errors = 1
+		suite.Cases = append(suite.Cases, junitCase{
+			Name:      "setup",
+			ClassName: pkg.Path,
+			Time:      suite.Time,
+			Error:     &junitMessage{Message: "package could not be loaded", Body: pkg.Err.Error()},
+		})
+	}
+
+	for _, test := range pkg.Tests {
+		testCase := junitCase{
+			Name:      test.Name,
+			ClassName: pkg.Path,
+			File:      test.File,
+			Line:      test.Line,
+			Time:      fmt.Sprintf("%.3f", test.Elapsed.Seconds()),
+		}
+
+		message := &junitMessage{Body: joinLines(test.Output)}
+		if len(test.Output) > 0 {
+			message.Message = test.Output[0]
+		}
+
+		switch test.Status {
+		case StatusFail:
+			suite.Failures++
+			testCase.Failure = message
+		case StatusSkip:
+			suite.Skipped++
+			testCase.Skipped = message
+		}
+
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	reporter.suites = append(reporter.suites, suite)
+}
+
+// Flush writes the XML document of all reported packages
+func (reporter *JUnitReporter) Flush() error {
+	data, err := xml.MarshalIndent(junitSuites{Suites: reporter.suites}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(reporter.Out, "%s%s\n", xml.Header, data)
+	return err
+}
+
+// MultiReporter forwards all events to each of its reporters
+type MultiReporter []Reporter
+
+func (reporters MultiReporter) StartPackage(pkg *Package) {
+	for _, reporter := range reporters {
+		reporter.StartPackage(pkg)
+	}
+}
+
+func (reporters MultiReporter) StartTest(pkg *Package, test *Test) {
+	for _, reporter := range reporters {
+		reporter.StartTest(pkg, test)
+	}
+}
+
+func (reporters MultiReporter) EndTest(pkg *Package, test *Test) {
+	for _, reporter := range reporters {
+		reporter.EndTest(pkg, test)
+	}
+}
+
+func (reporters MultiReporter) EndPackage(pkg *Package) {
+	for _, reporter := range reporters {
+		reporter.EndPackage(pkg)
+	}
+}
+
+func statusLabel(status Status) string {
+	switch status {
+	case StatusPass:
+		return "PASS"
+	case StatusSkip:
+		return "SKIP"
+	}
+
+	return "FAIL"
+}
+
+func seconds(duration time.Duration) string {
+	return fmt.Sprintf("%.2fs", duration.Seconds())
+}
+
+func joinLines(lines []string) string {
+	result := ""
+	for _, line := range lines {
+		result += line + "\n"
+	}
+
+	return result
+}
@@ -0,0 +1,180 @@
+package currytest
+
+import (
+	"bytes"
+	"curryLang/coverage"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func reportedPackage() *Package {
+	return &Package{
+		Path: "demo/math",
+		Tests: []*Test{
+			{Name: "testAdd", File: "math_test.curry", Line: 3, Status: StatusPass, Elapsed: 10 * time.Millisecond},
+			{Name: "testSub", File: "math_test.curry", Line: 7, Status: StatusFail, Output: []string{"math_test.curry:8:5: got 1, want 2"}},
+			{Name: "testMul", File: "math_test.curry", Line: 11, Status: StatusSkip, Output: []string{"math_test.curry:12:5: later"}},
+		},
+		Elapsed: 1500 * time.Millisecond,
+	}
+}
+
+func report(reporter Reporter, pkg *Package) {
+	reporter.StartPackage(pkg)
+	for _, test := range pkg.Tests {
+		reporter.StartTest(pkg, test)
+		reporter.EndTest(pkg, test)
+	}
+	reporter.EndPackage(pkg)
+}
+
+func TestTextReporter(t *testing.T) {
+	tests := []struct {
+		verbose  bool
+		expected string
+	}{
+		{false, `--- FAIL: testSub (0.00s)
+    math_test.curry:8:5: got 1, want 2
+FAIL	demo/math	1.500s
+`},
+		{true, `=== RUN   testAdd
+--- PASS: testAdd (0.01s)
+=== RUN   testSub
+--- FAIL: testSub (0.00s)
+    math_test.curry:8:5: got 1, want 2
+=== RUN   testMul
+--- SKIP: testMul (0.00s)
+    math_test.curry:12:5: later
+FAIL	demo/math	1.500s
+`},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		report(&TextReporter{Out: &out, Verbose: tt.verbose}, reportedPackage())
+
+		if out.String() != tt.expected {
+			t.Errorf("wrong output. want=%q, got=%q", tt.expected, out.String())
+		}
+	}
+
+	var out bytes.Buffer
+	report(&TextReporter{Out: &out}, &Package{Path: "demo/broken", Err: errors.New("parse error")})
+
+	expected := "FAIL\tdemo/broken [setup failed]\n    parse error\n"
+	if out.String() != expected {
+		t.Errorf("wrong output. want=%q, got=%q", expected, out.String())
+	}
+	out.Reset()
+	report(&TextReporter{Out: &out}, &Package{Path: "demo/covered", Elapsed: time.Second, Coverage: &coverage.Summary{Statements: 4, CoveredStatements: 3}})
+
+	expected = "ok  \tdemo/covered\t1.000s\tcoverage: 75.0% of statements, 100.0% of branches\n"
+	if out.String() != expected {
+		t.Errorf("wrong output. want=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestJSONReporter(t *testing.T) {
+	var out bytes.Buffer
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	report(&JSONReporter{Out: &out, Now: func() time.Time { return now }}, reportedPackage())
+
+	expected := []struct {
+		action string
+		test   string
+		output string
+	}{
+		{"start", "", ""},
+		{"run", "testAdd", ""},
+		{"output", "testAdd", "=== RUN   testAdd\n"},
+		{"output", "testAdd", "--- PASS: testAdd (0.01s)\n"},
+		{"pass", "testAdd", ""},
+		{"run", "testSub", ""},
+		{"output", "testSub", "=== RUN   testSub\n"},
+		{"output", "testSub", "--- FAIL: testSub (0.00s)\n"},
+		{"output", "testSub", "    math_test.curry:8:5: got 1, want 2\n"},
+		{"fail", "testSub", ""},
+		{"run", "testMul", ""},
+		{"output", "testMul", "=== RUN   testMul\n"},
+		{"output", "testMul", "--- SKIP: testMul (0.00s)\n"},
+		{"output", "testMul", "    math_test.curry:12:5: later\n"},
+		{"skip", "testMul", ""},
+		{"fail", "", ""},
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("wrong number of events. want=%d, got=%d", len(expected), len(lines))
+	}
+
+	for i, tt := range expected {
+		var event struct {
+			Time    time.Time
+			Action  string
+			Package string
+			Test    string
+			Output  string
+		}
+
+		err := json.Unmarshal([]byte(lines[i]), &event)
+		if err != nil {
+			t.Fatalf("event %d is no valid json: %s", i, err)
+		}
+
+		if event.Action != tt.action || event.Test != tt.test || event.Output != tt.output {
+			t.Errorf("events[%d] is not %+v. got=%s", i, tt, lines[i])
+		}
+
+		if event.Package != "demo/math" || !event.Time.Equal(now) {
+			t.Errorf("events[%d] has wrong package or time. got=%s", i, lines[i])
+		}
+	}
+}
+
+func TestJUnitReporter(t *testing.T) {
+	var out bytes.Buffer
+	reporter := &JUnitReporter{Out: &out}
+	report(reporter, reportedPackage())
+	report(reporter, &Package{Path: "demo/broken", Err: errors.New("parse error")})
+
+	err := reporter.Flush()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(out.String(), xml.Header) {
+		t.Errorf("output does not start with the xml header")
+	}
+
+	var suites junitSuites
+	err = xml.Unmarshal(out.Bytes(), &suites)
+	if err != nil {
+		t.Fatalf("output is no valid xml: %s", err)
+	}
+
+	if len(suites.Suites) != 2 {
+		t.Fatalf("wrong number of suites. want=2, got=%d", len(suites.Suites))
+	}
+
+	suite := suites.Suites[0]
+	if suite.Name != "demo/math" || suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 1 || suite.Time != "1.500" {
+		t.Errorf("wrong suite. got=%+v", suite)
+	}
+
+	failure := suite.Cases[1].Failure
+	if failure == nil || failure.Message != "math_test.curry:8:5: got 1, want 2" {
+		t.Errorf("testSub has wrong failure. got=%+v", failure)
+	}
+
+	if suite.Cases[2].Skipped == nil || suite.Cases[0].Failure != nil {
+		t.Errorf("wrong status of testcases. got=%+v", suite.Cases)
+	}
+
+	if suites.Suites[1].Errors != 1 || suites.Suites[1].Cases[0].Error.Body != "parse error" {
+		t.Errorf("setup error is not reported. got=%+v", suites.Suites[1])
+	}
+}
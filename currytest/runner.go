@@ -0,0 +1,467 @@
+package currytest
+
+import (
+	"curryLang/ast"
+	"curryLang/checker"
+	"curryLang/coverage"
+	"curryLang/evaluator"
+	"curryLang/lexer"
+	"curryLang/modfile"
+	"curryLang/object"
+	"curryLang/parser"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Test is a single fn testXxx(t) function and its result after it was run
+type Test struct {
+	Name    string
+	File    string
+	Line    int
+	Status  Status
+	Elapsed time.Duration
+	Output  []string
+}
+
+type Package struct {
+	Path    string // import path, or the directory for packages outside of a module
+	Dir     string
+	Tests   []*Test
+	Err     error // set if the package could not be loaded
+	Elapsed time.Duration
+
+	// coverage of the package files, nil if the coverage was not recorded
+	Coverage *coverage.Summary
+}
+
+func (pkg *Package) Failed() bool {
+	if pkg.Err != nil {
+		return true
+	}
+
+	for _, test := range pkg.Tests {
+		if test.Status == StatusFail {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Options struct {
+	Run       *regexp.Regexp // only tests with a matching name are run, all if nil
+	NewEngine func() *evaluator.ExecutionEngine
+	Reporter  Reporter
+
+	// records the statements and branches of the package files which are executed by the tests, if it is set
+	Coverage *coverage.Profile
+}
+
+// Discover returns the directories containing test files for the patterns,
+// "dir/..." matches dir and all of its sub directories
+func Discover(patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	var dirs []string
+	seen := map[string]bool{}
+
+	add := func(dir string) {
+		if !seen[dir] && hasTestFiles(dir) {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if pattern == "..." || strings.HasSuffix(pattern, "/...") {
+			root := strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/")
+			if root == "" {
+				root = "."
+			}
+
+			err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if !entry.IsDir() {
+					return nil
+				}
+
+				name := entry.Name()
+				if path != root && (name == modfile.VendorDir || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+
+				add(path)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		info, err := os.Stat(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", pattern)
+		}
+
+		add(filepath.Clean(pattern))
+	}
+
+	return dirs, nil
+}
+
+// Run runs the tests of all directories and returns false if any of them failed
+func Run(dirs []string, options Options) bool {
+	ok := true
+
+	for _, dir := range dirs {
+		pkg := RunPackage(dir, options)
+		if pkg.Failed() {
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+// RunPackage evaluates all files of dir inside of one engine and calls every test function of its test files
+func RunPackage(dir string, options Options) *Package {
+	start := time.Now()
+	pkg := &Package{Path: dir, Dir: dir}
+
+	defer func() {
+		pkg.Elapsed = time.Since(start)
+
+		if options.Reporter != nil {
+			options.Reporter.EndPackage(pkg)
+		}
+	}()
+
+	engine := newEngine(options)
+	defer engine.Finish()
+
+	err := engine.LoadModule(dir)
+
+	if engine.ModuleFile != nil {
+		pkg.Path = packagePath(engine, dir)
+	}
+
+	if options.Reporter != nil {
+		options.Reporter.StartPackage(pkg)
+	}
+
+	if err != nil {
+		pkg.Err = err
+		return pkg
+	}
+
+	tests, covered, err := loadFiles(engine, dir, pkg.Path, options.Coverage)
+	if err != nil {
+		pkg.Err = err
+		return pkg
+	}
+
+	if options.Coverage != nil {
+		defer func() {
+			var summary coverage.Summary
+			for _, file := range covered {
+				summary = summary.Add(file.Summary())
+			}
+
+			pkg.Coverage = &summary
+		}()
+	}
+
+	for _, test := range tests {
+		if options.Run != nil && !options.Run.MatchString(test.Name) {
+			continue
+		}
+
+		pkg.Tests = append(pkg.Tests, test)
+
+		if options.Reporter != nil {
+			options.Reporter.StartTest(pkg, test)
+		}
+
+		runTest(engine, test)
+
+		if options.Reporter != nil {
+			options.Reporter.EndTest(pkg, test)
+		}
+	}
+
+	return pkg
+}
+
+func newEngine(options Options) *evaluator.ExecutionEngine {
+	if options.NewEngine != nil {
+		return options.NewEngine()
+	}
+
+	return evaluator.NewEngine()
+}
+
+func packagePath(engine *evaluator.ExecutionEngine, dir string) string {
+	absolute, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+
+	rel, err := filepath.Rel(engine.ModuleRoot, absolute)
+	if err != nil || rel == "." {
+		return engine.ModuleFile.Module
+	}
+
+	return engine.ModuleFile.Module + "/" + filepath.ToSlash(rel)
+}
+
+type sourceFile struct {
+	path    string
+	source  string
+	program *ast.Program
+}
+
+// loadFiles evaluates the package and test files of dir and returns the declared tests in source order.
+// If a coverage profile is given, the package files are added to it and it records the evaluation.
+func loadFiles(engine *evaluator.ExecutionEngine, dir string, pkgPath string, profile *coverage.Profile) ([]*Test, []*coverage.File, error) {
+	files, err := parsePackage(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var covered []*coverage.File
+	if profile != nil {
+		for _, file := range files {
+			if !isTestFile(file.path) {
+				name := pkgPath + "/" + filepath.Base(file.path)
+				covered = append(covered, profile.AddFile(name, file.path, file.source, file.program))
+			}
+		}
+
+		engine.Hook = profile.EvaluatorHook()
+	}
+
+	err = evaluateFiles(engine, files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tests []*Test
+	err = collectFunctions(files, "test", "test", func(file string, function *ast.FunctionExpression) {
+		tests = append(tests, &Test{
+			Name: function.Name,
+			File: file,
+			Line: function.Token.Line,
+		})
+	})
+
+	return tests, covered, err
+}
+
+// parsePackage parses all files of dir, package files are sorted before the test files,
+// so the tests can use everything they declare
+func parsePackage(dir string) ([]sourceFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".curry") {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		return !isTestFile(paths[i]) && isTestFile(paths[j])
+	})
+
+	files := make([]sourceFile, 0, len(paths))
+	for _, path := range paths {
+		file, err := parseFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+func evaluateFiles(engine *evaluator.ExecutionEngine, files []sourceFile) error {
+	for _, file := range files {
+		engine.File = file.path
+		result := engine.Eval(file.program)
+		if err, ok := result.(*object.Error); ok {
+			return fmt.Errorf("%s: %s", file.path, err.Message)
+		}
+
+	}
+
+	return nil
+}
+
+// collectFunctions calls found for every function of the test files named prefixXxx, in source order
+func collectFunctions(files []sourceFile, prefix string, kind string, found func(file string, function *ast.FunctionExpression)) error {
+	declared := map[string]string{}
+
+	for _, file := range files {
+		if !isTestFile(file.path) {
+			continue
+		}
+
+		for _, statement := range file.program.Statements {
+			function := prefixedFunction(statement, prefix)
+			if function == nil {
+				continue
+			}
+
+			if previous, ok := declared[function.Name]; ok {
+				return fmt.Errorf("%s: %s %s is already declared in %s", file.path, kind, function.Name, previous)
+			}
+
+			declared[function.Name] = file.path
+			found(file.path, function)
+		}
+	}
+
+	return nil
+}
+
+func parseFile(file string) (sourceFile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return sourceFile{}, err
+	}
+
+	p := parser.New(lexer.New(string(data)))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		return sourceFile{}, fmt.Errorf("%s: %s", file, strings.Join(p.Errors(), ", "))
+	}
+
+	if errors := checker.Check(program); len(errors) > 0 {
+		return sourceFile{}, fmt.Errorf("%s: %s", file, strings.Join(errors, ", "))
+	}
+
+	return sourceFile{path: file, source: string(data), program: program}, nil
+}
+
+func hasTestFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && isTestFile(entry.Name()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTestFile(file string) bool {
+	return strings.HasSuffix(file, evaluator.TestFileSuffix)
+}
+
+// prefixedFunction returns the function declared by the statement if it is named prefixXxx,
+// where Xxx does not start with a lower-case letter
+func prefixedFunction(statement ast.Statement, prefix string) *ast.FunctionExpression {
+	expression, ok := statement.(*ast.ExpressionStatement)
+	if !ok {
+		return nil
+	}
+
+	function, ok := expression.Expression.(*ast.FunctionExpression)
+	if !ok || !strings.HasPrefix(function.Name, prefix) {
+		return nil
+	}
+
+	rest := strings.TrimPrefix(function.Name, prefix)
+	if rest == "" {
+		return function
+	}
+
+	first, _ := utf8.DecodeRuneInString(rest)
+	if unicode.IsLower(first) {
+		return nil
+	}
+
+	return function
+}
+
+func runTest(engine *evaluator.ExecutionEngine, test *Test) {
+	start := time.Now()
+	defer func() {
+		test.Elapsed = time.Since(start)
+	}()
+
+	function, ok := engine.Functions[test.Name]
+	if !ok {
+		test.Status = StatusFail
+		test.Output = append(test.Output, fmt.Sprintf("%s:%d: test function %s was not declared", filepath.Base(test.File), test.Line, test.Name))
+		return
+	}
+
+	if len(function.Parameters) != 1 {
+		test.Status = StatusFail
+		test.Output = append(test.Output, fmt.Sprintf("%s:%d: test function %s has to take exactly one parameter", filepath.Base(test.File), test.Line, test.Name))
+		return
+	}
+
+	state := &testState{test: test}
+	result := engine.CallFunction(function, state.object())
+
+	if err, ok := result.(*object.Error); ok && err != state.abort {
+		state.failed = true
+		test.Output = append(test.Output, errorLine(test, err))
+	}
+
+	switch {
+	case state.skipped:
+		test.Status = StatusSkip
+	case state.failed:
+		test.Status = StatusFail
+	default:
+		test.Status = StatusPass
+	}
+}
+
+// errorLine formats an error of a test with the position it was raised at
+func errorLine(test *Test, err *object.Error) string {
+	if err.Span.Line == 0 {
+		return fmt.Sprintf("%s:%d: %s", filepath.Base(test.File), test.Line, err.Message)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", filepath.Base(err.Span.File), err.Span.Line, err.Span.Column, err.Message)
+}
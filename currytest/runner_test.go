@@ -0,0 +1,233 @@
+package currytest
+
+import (
+	"curryLang/coverage"
+	"curryLang/internal/testfiles"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const mathSource = `package math
+
+fn Add(a, b) {
+    return a + b;
+}
+`
+
+const mathTestSource = `package math
+
+fn testAdd(t) {
+    t.assertEqual(Add(1, 2), 3);
+}
+
+fn testAddWrong(t) {
+    t.assertEqual(Add(1, 2), 4);
+    t.assertEqual(Add(2, 2), 5);
+}
+
+fn testSkipped(t) {
+    t.skip("later");
+    t.fail();
+}
+
+fn testFail(t) {
+    t.fail();
+    t.assertEqual(1, 2);
+}
+
+fn testError(t) {
+    return missing;
+}
+
+fn testWrongSignature() {
+}
+
+fn testing(t) {
+}
+`
+
+func TestRunPackage(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"curry.mod":            "module demo",
+		"math/math.curry":      mathSource,
+		"math/math_test.curry": mathTestSource,
+	})
+
+	pkg := RunPackage(filepath.Join(root, "math"), Options{})
+	if pkg.Err != nil {
+		t.Fatalf("unexpected error: %s", pkg.Err)
+	}
+
+	if pkg.Path != "demo/math" {
+		t.Errorf("pkg.Path is not demo/math. got=%s", pkg.Path)
+	}
+
+	tests := []struct {
+		name   string
+		line   int
+		status Status
+		output []string
+	}{
+		{"testAdd", 3, StatusPass, nil},
+		{"testAddWrong", 7, StatusFail, []string{"math_test.curry:8:18: got 3, want 4", "math_test.curry:9:18: got 4, want 5"}},
+		{"testSkipped", 12, StatusSkip, []string{"math_test.curry:13:11: later"}},
+		{"testFail", 17, StatusFail, []string{"math_test.curry:18:11: test failed"}},
+		{"testError", 22, StatusFail, []string{"math_test.curry:23:12: Undeclared variable missing used"}},
+		{"testWrongSignature", 26, StatusFail, []string{"math_test.curry:26: test function testWrongSignature has to take exactly one parameter"}},
+	}
+
+	if len(pkg.Tests) != len(tests) {
+		t.Fatalf("wrong number of tests. want=%d, got=%d", len(tests), len(pkg.Tests))
+	}
+
+	for i, tt := range tests {
+		test := pkg.Tests[i]
+
+		if test.Name != tt.name || test.Line != tt.line {
+			t.Errorf("tests[%d] is not %s at line %d. got=%s at line %d", i, tt.name, tt.line, test.Name, test.Line)
+		}
+
+		if test.Status != tt.status {
+			t.Errorf("%s has wrong status. want=%s, got=%s", tt.name, tt.status, test.Status)
+		}
+
+		if strings.Join(test.Output, "\n") != strings.Join(tt.output, "\n") {
+			t.Errorf("%s has wrong output. want=%q, got=%q", tt.name, tt.output, test.Output)
+		}
+	}
+
+	if !pkg.Failed() {
+		t.Errorf("package should have failed")
+	}
+}
+
+func TestRunPackageFilter(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"math.curry":      mathSource,
+		"math_test.curry": mathTestSource,
+	})
+
+	pkg := RunPackage(root, Options{Run: regexp.MustCompile("^testAdd$|Skip")})
+
+	var names []string
+	for _, test := range pkg.Tests {
+		names = append(names, test.Name)
+	}
+
+	if strings.Join(names, " ") != "testAdd testSkipped" {
+		t.Errorf("wrong tests were run. got=%v", names)
+	}
+
+	if pkg.Failed() {
+		t.Errorf("package should not have failed")
+	}
+
+	if pkg.Path != root {
+		t.Errorf("pkg.Path outside of a module is not the directory. got=%s", pkg.Path)
+	}
+}
+
+func TestRunPackageCoverage(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"curry.mod":            "module demo",
+		"math/math.curry":      mathSource + "\nfn Abs(n) {\n    if (n < 0) {\n        return 0 - n;\n    }\n    return n;\n}\n",
+		"math/math_test.curry": "package math\n\nfn testAbs(t) {\n    t.assertEqual(Abs(2), 2);\n}\n",
+	})
+
+	profile := coverage.New()
+	pkg := RunPackage(filepath.Join(root, "math"), Options{Coverage: profile})
+	if pkg.Failed() {
+		t.Fatalf("package should not have failed. got=%+v", pkg.Tests)
+	}
+
+	files := profile.Files()
+	if len(files) != 1 || files[0].Name != "demo/math/math.curry" {
+		t.Fatalf("only the package file should be covered. got=%+v", files)
+	}
+
+	expected := coverage.Summary{Statements: 4, CoveredStatements: 2, Branches: 2, CoveredBranches: 1}
+	if pkg.Coverage == nil || *pkg.Coverage != expected {
+		t.Errorf("wrong coverage. want=%+v, got=%+v", expected, pkg.Coverage)
+	}
+}
+
+func TestRunPackageErrors(t *testing.T) {
+	tests := []struct {
+		files    map[string]string
+		expected string
+	}{
+		{
+			map[string]string{"a_test.curry": "let x = ;"},
+			"a_test.curry: no prefix parse function for ; found",
+		},
+		{
+			map[string]string{"a_test.curry": "fn testA(t) {}", "b_test.curry": "fn testA(t) {}"},
+			"b_test.curry: test testA is already declared in",
+		},
+		{
+			map[string]string{"a_test.curry": "let x = missing;"},
+			"a_test.curry: Undeclared variable missing used",
+		},
+	}
+
+	for _, tt := range tests {
+		root := testfiles.Write(t, tt.files)
+
+		pkg := RunPackage(root, Options{})
+		if pkg.Err == nil {
+			t.Fatalf("expected error %q", tt.expected)
+		}
+
+		if !strings.Contains(pkg.Err.Error(), tt.expected) {
+			t.Errorf("wrong error. want=%q, got=%q", tt.expected, pkg.Err.Error())
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"a/a_test.curry":        "",
+		"a/b/b.curry":           "",
+		"a/b/c/c_test.curry":    "",
+		"vendor/x/x_test.curry": "",
+		".hidden/h_test.curry":  "",
+	})
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Chdir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	tests := []struct {
+		patterns []string
+		expected []string
+	}{
+		{[]string{"./..."}, []string{"a", "a/b/c"}},
+		{[]string{"a/b/..."}, []string{"a/b/c"}},
+		{[]string{"a", "a/b"}, []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		dirs, err := Discover(tt.patterns)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		for i := range dirs {
+			dirs[i] = filepath.ToSlash(dirs[i])
+		}
+
+		if strings.Join(dirs, " ") != strings.Join(tt.expected, " ") {
+			t.Errorf("wrong directories for %v. want=%v, got=%v", tt.patterns, tt.expected, dirs)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package currytest
+
+import (
+	"curryLang/object"
+	"curryLang/token"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// TStruct is the struct of the t parameter passed to every test function
+var TStruct = &object.Struct{
+	Name:    "T",
+	Fields:  []string{"name", "assertEqual", "fail", "skip"},
+	Methods: map[string]*object.Function{},
+	Traits:  map[string]*object.Trait{},
+}
+
+// testState collects the results of the builtins of one t instance
+type testState struct {
+	test    *Test
+	failed  bool
+	skipped bool
+
+	// returned by fail and skip to stop the test function
+	abort *object.Error
+}
+
+func (state *testState) object() *object.Instance {
+	state.abort = &object.Error{Message: "test " + state.test.Name + " stopped"}
+
+	return &object.Instance{
+		Struct: TStruct,
+		Fields: map[string]object.Object{
+			"name":        &object.String{Value: state.test.Name},
+			"assertEqual": &object.Builtin{Name: "assertEqual", Function: state.assertEqual},
+			"fail":        &object.Builtin{Name: "fail", Function: state.fail},
+			"skip":        &object.Builtin{Name: "skip", Function: state.skip},
+		},
+	}
+}
+
+// assertEqual(actual, expected) marks the test as failed if both values are not equal, but continues the test
+func (state *testState) assertEqual(position token.Token, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return &object.Error{Message: fmt.Sprintf("assertEqual expects 2 arguments but got %d", len(args))}
+	}
+
+	if object.Equal(args[0], args[1]) {
+		return &object.Boolean{Value: true}
+	}
+
+	state.failed = true
+	state.log(position, fmt.Sprintf("got %s, want %s", args[0].Inspect(), args[1].Inspect()))
+
+	return &object.Boolean{Value: false}
+}
+
+// fail(message...) marks the test as failed and stops it
+func (state *testState) fail(position token.Token, args ...object.Object) object.Object {
+	state.failed = true
+	state.log(position, message("test failed", args))
+
+	return state.abort
+}
+
+// skip(message...) marks the test as skipped and stops it
+func (state *testState) skip(position token.Token, args ...object.Object) object.Object {
+	state.skipped = true
+	state.log(position, message("test skipped", args))
+
+	return state.abort
+}
+
+func (state *testState) log(position token.Token, message string) {
+	state.test.Output = append(
+		state.test.Output,
+		fmt.Sprintf("%s:%d:%d: %s", filepath.Base(state.test.File), position.Line, position.Column, message),
+	)
+}
+
+func message(fallback string, args []object.Object) string {
+	if len(args) == 0 {
+		return fallback
+	}
+
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		parts = append(parts, arg.Inspect())
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"curryLang/dap"
+	"errors"
+	"os"
+)
+
+// runDapCommand executes "curry dap", which serves the Debug Adapter Protocol on stdin and stdout
+func runDapCommand(args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: curry dap")
+	}
+
+	server := dap.NewServer(os.Stdin, os.Stdout, dap.Options{NewEngine: newEngine})
+
+	return server.Serve()
+}
@@ -0,0 +1,679 @@
+// Package dap implements a server for the Debug Adapter Protocol, so editors like VS Code can debug Curry programs.
+// The server debugs a single program, which is launched with the evaluator or the virtual machine:
+//
+//	server := dap.NewServer(os.Stdin, os.Stdout, dap.Options{})
+//	err := server.Serve()
+package dap
+
+import (
+	"bufio"
+	"curryLang/ast"
+	"curryLang/checker"
+	"curryLang/compiler"
+	"curryLang/debugger"
+	"curryLang/evaluator"
+	"curryLang/lexer"
+	"curryLang/object"
+	"curryLang/parser"
+	"curryLang/vm"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// threadID is the only thread of a program, the protocol requires threads for stopped events
+const threadID = 1
+
+// Options configure how programs are launched
+type Options struct {
+	// NewEngine creates the engines of the evaluator backend, evaluator.NewEngine is used if nil
+	NewEngine func() *evaluator.ExecutionEngine
+}
+
+// Server answers the requests of a client and sends events about the debugged program.
+// Requests are handled one after another, while the program runs in its own goroutine.
+type Server struct {
+	reader  *bufio.Reader
+	writer  io.Writer
+	options Options
+
+	// guards the writer and the sequence number, events are sent by the goroutine of the program
+	writeLock sync.Mutex
+	seq       int
+
+	// lines are 1-based unless the client asks for 0-based lines
+	lineOffset int
+
+	launched   bool
+	configured bool
+	target     target
+	noDebug    bool
+	done       chan struct{} // closed when the program finished
+
+	// guards the fields below, which are shared with the goroutine of the program
+	lock    sync.Mutex
+	stepper *debugger.Stepper
+	stopped bool
+	aborted bool // the client disconnected, the program stops at its next statement
+	// variables references of the current stop, reference n is stored at index n-1
+	references []func() []variable
+
+	// the paused program waits for a value, false stops the program
+	resume chan bool
+}
+
+func NewServer(in io.Reader, out io.Writer, options Options) *Server {
+	if options.NewEngine == nil {
+		options.NewEngine = evaluator.NewEngine
+	}
+
+	return &Server{
+		reader:  bufio.NewReader(in),
+		writer:  out,
+		options: options,
+		stepper: debugger.NewStepper(),
+		resume:  make(chan bool),
+	}
+}
+
+// Serve handles requests until the client disconnects or closes the input
+func (server *Server) Serve() error {
+	for {
+		content, err := ReadMessage(server.reader)
+		if err == io.EOF {
+			server.stop()
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var request Request
+		err = json.Unmarshal(content, &request)
+		if err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+
+		if request.Type != "request" {
+			continue
+		}
+
+		body, err := server.handle(&request)
+		server.respond(&request, body, err)
+
+		switch request.Command {
+		case "initialize":
+			server.sendEvent("initialized", nil)
+		case "launch", "configurationDone":
+			server.start()
+		case "disconnect":
+			server.stop()
+			return nil
+		}
+	}
+}
+
+func (server *Server) handle(request *Request) (interface{}, error) {
+	switch request.Command {
+	case "initialize":
+		var arguments InitializeArguments
+		if err := decode(request, &arguments); err != nil {
+			return nil, err
+		}
+
+		if arguments.LinesStartAt1 != nil && !*arguments.LinesStartAt1 {
+			server.lineOffset = 1
+		}
+
+		return Capabilities{
+			SupportsConfigurationDoneRequest: true,
+			SupportsFunctionBreakpoints:      true,
+			SupportsEvaluateForHovers:        true,
+			SupportsTerminateRequest:         true,
+		}, nil
+
+	case "launch":
+		var arguments LaunchArguments
+		if err := decode(request, &arguments); err != nil {
+			return nil, err
+		}
+
+		return nil, server.launch(arguments)
+
+	case "configurationDone":
+		server.configured = true
+		return nil, nil
+
+	case "setBreakpoints":
+		var arguments SetBreakpointsArguments
+		if err := decode(request, &arguments); err != nil {
+			return nil, err
+		}
+
+		return server.setBreakpoints(arguments), nil
+
+	case "setFunctionBreakpoints":
+		var arguments SetFunctionBreakpointsArguments
+		if err := decode(request, &arguments); err != nil {
+			return nil, err
+		}
+
+		return server.setFunctionBreakpoints(arguments), nil
+
+	case "setExceptionBreakpoints":
+		return BreakpointsBody{Breakpoints: []Breakpoint{}}, nil
+
+	case "threads":
+		return ThreadsBody{Threads: []Thread{{ID: threadID, Name: "main"}}}, nil
+
+	case "stackTrace":
+		var arguments StackTraceArguments
+		if err := decode(request, &arguments); err != nil {
+			return nil, err
+		}
+
+		return server.stackTrace(arguments)
+
+	case "scopes":
+		var arguments ScopesArguments
+		if err := decode(request, &arguments); err != nil {
+			return nil, err
+		}
+
+		return server.scopes(arguments)
+
+	case "variables":
+		var arguments VariablesArguments
+		if err := decode(request, &arguments); err != nil {
+			return nil, err
+		}
+
+		return server.variables(arguments)
+
+	case "evaluate":
+		var arguments EvaluateArguments
+		if err := decode(request, &arguments); err != nil {
+			return nil, err
+		}
+
+		return server.evaluate(arguments)
+
+	case "continue":
+		return ContinuedEventBody{AllThreadsContinued: true}, server.continueProgram(debugger.Continue)
+	case "next":
+		return nil, server.continueProgram(debugger.StepOver)
+	case "stepIn":
+		return nil, server.continueProgram(debugger.StepIn)
+	case "stepOut":
+		return nil, server.continueProgram(debugger.StepOut)
+
+	case "pause":
+		server.lock.Lock()
+		server.stepper.Pause()
+		server.lock.Unlock()
+
+		return nil, nil
+
+	case "terminate":
+		server.abort()
+		return nil, nil
+
+	case "disconnect":
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unsupported request %s", request.Command)
+}
+
+// launch prepares the program, it is started once the client sent its configuration
+func (server *Server) launch(arguments LaunchArguments) error {
+	if server.launched {
+		return errors.New("a program was already launched")
+	}
+
+	if arguments.Program == "" {
+		return errors.New("the program to debug is missing")
+	}
+
+	file := absolutePath(arguments.Program)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	p := parser.New(lexer.New(string(data)))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		return fmt.Errorf("syntax errors in %s: %s", arguments.Program, strings.Join(p.Errors(), ", "))
+	}
+
+	if errors := checker.Check(program); len(errors) > 0 {
+		return fmt.Errorf("errors in %s: %s", arguments.Program, strings.Join(errors, ", "))
+	}
+
+	switch arguments.Backend {
+	case "", "evaluator":
+		server.target, err = server.newEvaluatorTarget(program, file, arguments.NoDebug)
+	case "vm":
+		server.target, err = server.newVMTarget(program, file, arguments.NoDebug)
+	default:
+		err = fmt.Errorf("unknown backend %s, expected evaluator or vm", arguments.Backend)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !arguments.StopOnEntry {
+		server.stepper.Resume(debugger.Continue)
+	}
+
+	server.launched = true
+	server.noDebug = arguments.NoDebug
+
+	return nil
+}
+
+func (server *Server) newEvaluatorTarget(program *ast.Program, file string, noDebug bool) (target, error) {
+	engine := server.options.NewEngine()
+
+	// setup module of the program
+	err := engine.LoadModule(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+
+	target := &evaluatorTarget{server: server, engine: engine, program: program}
+
+	engine.File = file
+	if !noDebug {
+		engine.Hook = target
+	}
+
+	return target, nil
+}
+
+func (server *Server) newVMTarget(program *ast.Program, file string, noDebug bool) (target, error) {
+	c := compiler.New()
+	c.File = file
+
+	err := c.Compile(program)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &vmTarget{server: server, machine: vm.New(c.Bytecode()), program: program, file: file}
+	if !noDebug {
+		target.machine.Hook = target
+	}
+
+	return target, nil
+}
+
+// start runs the program once it was launched and configured
+func (server *Server) start() {
+	if !server.launched || !server.configured || server.done != nil {
+		return
+	}
+
+	server.done = make(chan struct{})
+
+	go func() {
+		defer close(server.done)
+
+		result, err := server.target.run()
+		exitCode := 0
+
+		if err != nil {
+			exitCode = 1
+			server.sendEvent("output", OutputEventBody{Category: "stderr", Output: err.Traceback() + "\n"})
+		} else if result != nil && result.Type() != object.NULL_OBJ {
+			server.sendEvent("output", OutputEventBody{Category: "stdout", Output: result.Inspect() + "\n"})
+		}
+
+		server.sendEvent("exited", ExitedEventBody{ExitCode: exitCode})
+		server.sendEvent("terminated", nil)
+	}()
+}
+
+// stop ends the program and waits until it finished
+func (server *Server) stop() {
+	server.abort()
+
+	if server.done != nil {
+		<-server.done
+	}
+}
+
+// abort stops the program at its next statement
+func (server *Server) abort() {
+	server.lock.Lock()
+	server.aborted = true
+	stopped := server.stopped
+	server.stopped = false
+	server.lock.Unlock()
+
+	if stopped {
+		server.resume <- false
+	}
+}
+
+// reach is called by the hooks of the targets before a statement and pauses the program if needed
+func (server *Server) reach(location debugger.Location) *object.Error {
+	server.lock.Lock()
+
+	if server.aborted {
+		server.lock.Unlock()
+		return debugger.Abort()
+	}
+
+	stop, ok := server.stepper.Reach(location)
+	if !ok {
+		server.lock.Unlock()
+		return nil
+	}
+
+	server.stopped = true
+	server.references = nil
+	server.lock.Unlock()
+
+	body := StoppedEventBody{Reason: string(stop.Reason), ThreadID: threadID, AllThreadsStopped: true}
+	if stop.Breakpoint.ID != 0 {
+		body.HitBreakpointIDs = []int{stop.Breakpoint.ID}
+	}
+
+	server.sendEvent("stopped", body)
+
+	if !<-server.resume {
+		return debugger.Abort()
+	}
+
+	return nil
+}
+
+func (server *Server) enter(function string) {
+	server.lock.Lock()
+	server.stepper.Enter(function)
+	server.lock.Unlock()
+}
+
+func (server *Server) continueProgram(mode debugger.Mode) error {
+	server.lock.Lock()
+
+	if !server.stopped {
+		server.lock.Unlock()
+		return errors.New("the program is not paused")
+	}
+
+	server.stepper.Resume(mode)
+	server.stopped = false
+	server.lock.Unlock()
+
+	server.resume <- true
+
+	return nil
+}
+
+func (server *Server) setBreakpoints(arguments SetBreakpointsArguments) BreakpointsBody {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	file := absolutePath(arguments.Source.Path)
+	server.stepper.ClearLines(file)
+
+	body := BreakpointsBody{Breakpoints: []Breakpoint{}}
+	for _, requested := range arguments.Breakpoints {
+		breakpoint := server.stepper.BreakAtLine(file, requested.Line+server.lineOffset)
+		body.Breakpoints = append(body.Breakpoints, Breakpoint{
+			ID:       breakpoint.ID,
+			Verified: true,
+			Line:     requested.Line,
+			Source:   &arguments.Source,
+		})
+	}
+
+	return body
+}
+
+func (server *Server) setFunctionBreakpoints(arguments SetFunctionBreakpointsArguments) BreakpointsBody {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.stepper.ClearFunctions()
+
+	body := BreakpointsBody{Breakpoints: []Breakpoint{}}
+	for _, requested := range arguments.Breakpoints {
+		breakpoint := server.stepper.BreakAtFunction(requested.Name)
+		body.Breakpoints = append(body.Breakpoints, Breakpoint{ID: breakpoint.ID, Verified: true})
+	}
+
+	return body
+}
+
+func (server *Server) stackTrace(arguments StackTraceArguments) (interface{}, error) {
+	if err := server.checkStopped(); err != nil {
+		return nil, err
+	}
+
+	frames := server.target.frames()
+	body := StackTraceBody{StackFrames: []StackFrame{}, TotalFrames: len(frames)}
+
+	end := len(frames)
+	if arguments.Levels > 0 && arguments.StartFrame+arguments.Levels < end {
+		end = arguments.StartFrame + arguments.Levels
+	}
+
+	// frame ids are the index of the frame plus one, they are valid until the program continues
+	for i := arguments.StartFrame; i < end; i++ {
+		stackFrame := StackFrame{
+			ID:     i + 1,
+			Name:   frames[i].name,
+			Line:   frames[i].line - server.lineOffset,
+			Column: frames[i].column,
+		}
+
+		if frames[i].file != "" {
+			stackFrame.Source = &Source{Name: filepath.Base(frames[i].file), Path: frames[i].file}
+		}
+
+		body.StackFrames = append(body.StackFrames, stackFrame)
+	}
+
+	return body, nil
+}
+
+func (server *Server) scopes(arguments ScopesArguments) (interface{}, error) {
+	index, err := server.frameIndex(arguments.FrameID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := ScopesBody{Scopes: []Scope{}}
+	for _, scope := range server.target.scopes(index) {
+		variables := scope.variables
+		body.Scopes = append(body.Scopes, Scope{
+			Name:               scope.name,
+			VariablesReference: server.addReference(func() []variable { return variables }),
+		})
+	}
+
+	return body, nil
+}
+
+func (server *Server) variables(arguments VariablesArguments) (interface{}, error) {
+	if err := server.checkStopped(); err != nil {
+		return nil, err
+	}
+
+	server.lock.Lock()
+	index := arguments.VariablesReference - 1
+	if index < 0 || index >= len(server.references) {
+		server.lock.Unlock()
+		return nil, fmt.Errorf("unknown variables reference %d", arguments.VariablesReference)
+	}
+
+	reference := server.references[index]
+	server.lock.Unlock()
+
+	body := VariablesBody{Variables: []Variable{}}
+	for _, variable := range reference() {
+		body.Variables = append(body.Variables, Variable{
+			Name:               variable.name,
+			Value:              variable.value.Inspect(),
+			Type:               string(variable.value.Type()),
+			VariablesReference: server.childrenReference(variable.value),
+		})
+	}
+
+	return body, nil
+}
+
+func (server *Server) evaluate(arguments EvaluateArguments) (interface{}, error) {
+	index, err := server.frameIndex(arguments.FrameID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.target.evaluate(arguments.Expression, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return EvaluateBody{
+		Result:             result.Inspect(),
+		Type:               string(result.Type()),
+		VariablesReference: server.childrenReference(result),
+	}, nil
+}
+
+// frameIndex returns the index of the frame with the id, the innermost frame is used without id
+func (server *Server) frameIndex(id int) (int, error) {
+	if err := server.checkStopped(); err != nil {
+		return 0, err
+	}
+
+	if id == 0 {
+		return 0, nil
+	}
+
+	if id < 1 || id > len(server.target.frames()) {
+		return 0, fmt.Errorf("unknown frame %d", id)
+	}
+
+	return id - 1, nil
+}
+
+func (server *Server) checkStopped() error {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if !server.stopped {
+		return errors.New("the program is not paused")
+	}
+
+	return nil
+}
+
+// childrenReference returns a reference to the elements of lists and the fields of instances, 0 for other values
+func (server *Server) childrenReference(value object.Object) int {
+	switch value := value.(type) {
+	case *object.List:
+		return server.addReference(func() []variable {
+			elements := make([]variable, 0, len(value.Value))
+			for i, element := range value.Value {
+				elements = append(elements, variable{name: fmt.Sprintf("[%d]", i), value: element})
+			}
+
+			return elements
+		})
+
+	case *object.Instance:
+		return server.addReference(func() []variable {
+			names := make([]string, 0, len(value.Fields))
+			for name := range value.Fields {
+				names = append(names, name)
+			}
+
+			// fields are shown in the order of the struct declaration
+			order := map[string]int{}
+			for i, name := range value.Struct.Fields {
+				order[name] = i
+			}
+
+			sort.Slice(names, func(i, j int) bool { return order[names[i]] < order[names[j]] })
+
+			fields := make([]variable, 0, len(names))
+			for _, name := range names {
+				fields = append(fields, variable{name: name, value: value.Fields[name]})
+			}
+
+			return fields
+		})
+	}
+
+	return 0
+}
+
+func (server *Server) addReference(variables func() []variable) int {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.references = append(server.references, variables)
+
+	return len(server.references)
+}
+
+func (server *Server) respond(request *Request, body interface{}, err error) {
+	response := Response{
+		Message:    Message{Type: "response"},
+		RequestSeq: request.Seq,
+		Success:    err == nil,
+		Command:    request.Command,
+		Body:       body,
+	}
+
+	if err != nil {
+		response.ErrorText = err.Error()
+		response.Body = nil
+	}
+
+	server.send(&response.Message, &response)
+}
+
+func (server *Server) sendEvent(event string, body interface{}) {
+	message := Event{Message: Message{Type: "event"}, Event: event, Body: body}
+	server.send(&message.Message, &message)
+}
+
+func (server *Server) send(header *Message, message interface{}) {
+	server.writeLock.Lock()
+	defer server.writeLock.Unlock()
+
+	server.seq++
+	header.Seq = server.seq
+
+	// the client is gone if the message can not be written, the program is stopped when the input is closed
+	_ = WriteMessage(server.writer, message)
+}
+
+func decode(request *Request, arguments interface{}) error {
+	if len(request.Arguments) == 0 {
+		return nil
+	}
+
+	err := json.Unmarshal(request.Arguments, arguments)
+	if err != nil {
+		return fmt.Errorf("invalid arguments of %s: %w", request.Command, err)
+	}
+
+	return nil
+}
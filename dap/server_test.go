@@ -0,0 +1,369 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testProgram = `fn add(a, b) {
+    let sum = a + b;
+    return sum;
+}
+
+let x = 1;
+let y = add(x, 2);
+y`
+
+type testMessage struct {
+	Type       string          `json:"type"`
+	Command    string          `json:"command"`
+	Event      string          `json:"event"`
+	RequestSeq int             `json:"request_seq"`
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// testClient is a scripted client which sends requests and expects responses and events in order
+type testClient struct {
+	t        *testing.T
+	writer   io.WriteCloser
+	messages chan testMessage
+	served   chan error
+	seq      int
+}
+
+func newTestClient(t *testing.T) *testClient {
+	t.Helper()
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	client := &testClient{t: t, writer: clientWriter, messages: make(chan testMessage, 100), served: make(chan error, 1)}
+
+	go func() {
+		client.served <- NewServer(serverReader, serverWriter, Options{}).Serve()
+		serverWriter.Close()
+	}()
+
+	go func() {
+		reader := bufio.NewReader(clientReader)
+		for {
+			content, err := ReadMessage(reader)
+			if err != nil {
+				close(client.messages)
+				return
+			}
+
+			var message testMessage
+			if err := json.Unmarshal(content, &message); err != nil {
+				t.Errorf("invalid message %s: %s", content, err)
+			}
+
+			client.messages <- message
+		}
+	}()
+
+	return client
+}
+
+func (client *testClient) send(command string, arguments interface{}) {
+	client.t.Helper()
+
+	client.seq++
+	request := map[string]interface{}{"seq": client.seq, "type": "request", "command": command, "arguments": arguments}
+
+	if err := WriteMessage(client.writer, request); err != nil {
+		client.t.Fatalf("failed to send %s: %s", command, err)
+	}
+}
+
+func (client *testClient) next() testMessage {
+	client.t.Helper()
+
+	select {
+	case message, ok := <-client.messages:
+		if !ok {
+			client.t.Fatal("server closed the connection")
+		}
+
+		return message
+	case <-time.After(5 * time.Second):
+		client.t.Fatal("timeout while waiting for a message")
+	}
+
+	return testMessage{}
+}
+
+// request sends a request and decodes the body of its successful response
+func (client *testClient) request(command string, arguments interface{}, body interface{}) {
+	client.t.Helper()
+
+	client.send(command, arguments)
+
+	response := client.next()
+	if response.Type != "response" || response.Command != command || response.RequestSeq != client.seq {
+		client.t.Fatalf("expected response to %s. got=%+v", command, response)
+	}
+
+	if !response.Success {
+		client.t.Fatalf("request %s failed: %s", command, response.Message)
+	}
+
+	if body != nil {
+		if err := json.Unmarshal(response.Body, body); err != nil {
+			client.t.Fatalf("invalid body of %s: %s", command, err)
+		}
+	}
+}
+
+// fail sends a request and returns the error message of its response
+func (client *testClient) fail(command string, arguments interface{}) string {
+	client.t.Helper()
+
+	client.send(command, arguments)
+
+	response := client.next()
+	if response.Type != "response" || response.Command != command || response.Success {
+		client.t.Fatalf("expected failed response to %s. got=%+v", command, response)
+	}
+
+	return response.Message
+}
+
+func (client *testClient) event(event string, body interface{}) {
+	client.t.Helper()
+
+	message := client.next()
+	if message.Type != "event" || message.Event != event {
+		client.t.Fatalf("expected event %s. got=%+v", event, message)
+	}
+
+	if body != nil {
+		if err := json.Unmarshal(message.Body, body); err != nil {
+			client.t.Fatalf("invalid body of %s: %s", event, err)
+		}
+	}
+}
+
+func (client *testClient) stopped(reason string, line int) StoppedEventBody {
+	client.t.Helper()
+
+	var stopped StoppedEventBody
+	client.event("stopped", &stopped)
+
+	if stopped.Reason != reason {
+		client.t.Errorf("wrong stop reason. want=%s, got=%s", reason, stopped.Reason)
+	}
+
+	var trace StackTraceBody
+	client.request("stackTrace", StackTraceArguments{ThreadID: threadID}, &trace)
+
+	if len(trace.StackFrames) == 0 || trace.StackFrames[0].Line != line {
+		client.t.Errorf("stopped at wrong line. want=%d, got=%+v", line, trace.StackFrames)
+	}
+
+	return stopped
+}
+
+// locals returns the local variables of the innermost frame as name=value pairs
+func (client *testClient) locals() string {
+	client.t.Helper()
+
+	var scopes ScopesBody
+	client.request("scopes", ScopesArguments{FrameID: 1}, &scopes)
+
+	if len(scopes.Scopes) != 2 || scopes.Scopes[0].Name != "Locals" || scopes.Scopes[1].Name != "Globals" {
+		client.t.Fatalf("wrong scopes. got=%+v", scopes.Scopes)
+	}
+
+	var variables VariablesBody
+	client.request("variables", VariablesArguments{VariablesReference: scopes.Scopes[0].VariablesReference}, &variables)
+
+	pairs := []string{}
+	for _, variable := range variables.Variables {
+		pairs = append(pairs, variable.Name+"="+variable.Value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+func (client *testClient) disconnect() {
+	client.t.Helper()
+
+	client.request("disconnect", nil, nil)
+	client.writer.Close()
+
+	select {
+	case err := <-client.served:
+		if err != nil {
+			client.t.Errorf("server failed: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		client.t.Fatal("server did not stop")
+	}
+}
+
+func writeTestProgram(t *testing.T) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "main.curry")
+
+	err := os.WriteFile(file, []byte(testProgram), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestBreakpointsAndStepping(t *testing.T) {
+	file := writeTestProgram(t)
+
+	for _, backend := range []string{"evaluator", "vm"} {
+		client := newTestClient(t)
+
+		var capabilities Capabilities
+		client.request("initialize", map[string]interface{}{"adapterID": "curry"}, &capabilities)
+		if !capabilities.SupportsConfigurationDoneRequest || !capabilities.SupportsFunctionBreakpoints {
+			t.Errorf("%s: wrong capabilities. got=%+v", backend, capabilities)
+		}
+
+		client.event("initialized", nil)
+		client.request("launch", LaunchArguments{Program: file, Backend: backend}, nil)
+
+		var breakpoints BreakpointsBody
+		client.request("setBreakpoints", SetBreakpointsArguments{
+			Source:      Source{Path: file},
+			Breakpoints: []SourceBreakpoint{{Line: 2}},
+		}, &breakpoints)
+
+		if len(breakpoints.Breakpoints) != 1 || !breakpoints.Breakpoints[0].Verified || breakpoints.Breakpoints[0].Line != 2 {
+			t.Errorf("%s: wrong breakpoints. got=%+v", backend, breakpoints.Breakpoints)
+		}
+
+		client.request("configurationDone", nil, nil)
+
+		stopped := client.stopped("breakpoint", 2)
+		if len(stopped.HitBreakpointIDs) != 1 || stopped.HitBreakpointIDs[0] != breakpoints.Breakpoints[0].ID {
+			t.Errorf("%s: wrong hit breakpoints. got=%v", backend, stopped.HitBreakpointIDs)
+		}
+
+		var trace StackTraceBody
+		client.request("stackTrace", StackTraceArguments{ThreadID: threadID}, &trace)
+
+		if len(trace.StackFrames) != 2 || trace.StackFrames[0].Name != "add" || trace.StackFrames[1].Line != 7 {
+			t.Errorf("%s: wrong stack trace. got=%+v", backend, trace.StackFrames)
+		}
+
+		if source := trace.StackFrames[0].Source; source == nil || source.Path != file || source.Name != "main.curry" {
+			t.Errorf("%s: wrong source. got=%+v", backend, source)
+		}
+
+		if locals := client.locals(); locals != "a=1,b=2" {
+			t.Errorf("%s: wrong locals. got=%s", backend, locals)
+		}
+
+		var evaluated EvaluateBody
+		client.request("evaluate", EvaluateArguments{Expression: "b", FrameID: 1}, &evaluated)
+		if evaluated.Result != "2" || evaluated.Type != "INTEGER" {
+			t.Errorf("%s: wrong evaluation. got=%+v", backend, evaluated)
+		}
+
+		client.request("next", nil, nil)
+		client.stopped("step", 3)
+
+		if locals := client.locals(); locals != "a=1,b=2,sum=3" {
+			t.Errorf("%s: wrong locals. got=%s", backend, locals)
+		}
+
+		client.request("continue", nil, nil)
+
+		var output OutputEventBody
+		client.event("output", &output)
+		if output.Output != "3\n" {
+			t.Errorf("%s: wrong output. got=%q", backend, output.Output)
+		}
+
+		var exited ExitedEventBody
+		client.event("exited", &exited)
+		if exited.ExitCode != 0 {
+			t.Errorf("%s: wrong exit code. got=%d", backend, exited.ExitCode)
+		}
+
+		client.event("terminated", nil)
+
+		if message := client.fail("stackTrace", StackTraceArguments{ThreadID: threadID}); message != "the program is not paused" {
+			t.Errorf("%s: wrong error. got=%s", backend, message)
+		}
+
+		client.disconnect()
+	}
+}
+
+func TestFunctionBreakpointsAndStepOut(t *testing.T) {
+	file := writeTestProgram(t)
+
+	for _, backend := range []string{"evaluator", "vm"} {
+		client := newTestClient(t)
+
+		client.request("initialize", nil, nil)
+		client.event("initialized", nil)
+		client.request("launch", LaunchArguments{Program: file, Backend: backend, StopOnEntry: true}, nil)
+		client.request("setFunctionBreakpoints", SetFunctionBreakpointsArguments{Breakpoints: []FunctionBreakpoint{{Name: "add"}}}, nil)
+		client.request("configurationDone", nil, nil)
+
+		client.stopped("entry", 1)
+
+		client.request("continue", nil, nil)
+		client.stopped("function breakpoint", 2)
+
+		if backend == "evaluator" {
+			var evaluated EvaluateBody
+			client.request("evaluate", EvaluateArguments{Expression: "a + b * 10"}, &evaluated)
+			if evaluated.Result != "21" {
+				t.Errorf("%s: wrong evaluation. got=%+v", backend, evaluated)
+			}
+		} else if message := client.fail("evaluate", EvaluateArguments{Expression: "a + b"}); !strings.Contains(message, "only variables can be evaluated") {
+			t.Errorf("%s: wrong error. got=%s", backend, message)
+		}
+
+		client.request("stepOut", nil, nil)
+		client.stopped("step", 8)
+
+		// the paused program is stopped when the client disconnects
+		client.disconnect()
+	}
+}
+
+func TestLaunchErrors(t *testing.T) {
+	client := newTestClient(t)
+
+	client.request("initialize", nil, nil)
+	client.event("initialized", nil)
+
+	tests := []struct {
+		arguments LaunchArguments
+		expected  string
+	}{
+		{LaunchArguments{}, "the program to debug is missing"},
+		{LaunchArguments{Program: writeTestProgram(t), Backend: "jit"}, "unknown backend jit, expected evaluator or vm"},
+	}
+
+	for _, tt := range tests {
+		if message := client.fail("launch", tt.arguments); message != tt.expected {
+			t.Errorf("wrong error. want=%s, got=%s", tt.expected, message)
+		}
+	}
+
+	client.disconnect()
+}
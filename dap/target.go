@@ -0,0 +1,246 @@
+package dap
+
+import (
+	"curryLang/ast"
+	"curryLang/code"
+	"curryLang/compiler"
+	"curryLang/debugger"
+	"curryLang/evaluator"
+	"curryLang/lexer"
+	"curryLang/object"
+	"curryLang/parser"
+	"curryLang/vm"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// target is the engine running the debugged program, its state is only read while the program is paused
+type target interface {
+	// run runs the program and returns the value of its last expression statement
+	run() (object.Object, *object.Error)
+
+	// frames returns the active function calls, the innermost one first and the main program last
+	frames() []frame
+
+	// scopes returns the variables visible in the frame, the innermost scope first
+	scopes(frame int) []scope
+
+	// evaluate evaluates an expression in the frame
+	evaluate(expression string, frame int) (object.Object, error)
+}
+
+type frame struct {
+	name   string
+	file   string
+	line   int
+	column int
+}
+
+type scope struct {
+	name      string
+	variables []variable
+}
+
+type variable struct {
+	name  string
+	value object.Object
+}
+
+// evaluatorTarget debugs a program with the tree-walking evaluator
+type evaluatorTarget struct {
+	server  *Server
+	engine  *evaluator.ExecutionEngine
+	program *ast.Program
+
+	// statement the program is paused at
+	statement ast.Statement
+}
+
+func (target *evaluatorTarget) run() (object.Object, *object.Error) {
+	result := target.engine.Eval(target.program)
+	target.engine.Finish()
+	if err, ok := result.(*object.Error); ok {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (target *evaluatorTarget) BeforeStatement(engine *evaluator.ExecutionEngine, statement ast.Statement) *object.Error {
+	target.statement = statement
+
+	position := statement.Pos()
+	return target.server.reach(debugger.Location{File: engine.File, Line: position.Line, Column: position.Column, Depth: len(engine.Frames())})
+}
+
+func (target *evaluatorTarget) EnterFunction(engine *evaluator.ExecutionEngine, frame evaluator.Frame) {
+	target.server.enter(frame.Function.Name)
+}
+
+func (target *evaluatorTarget) LeaveFunction(engine *evaluator.ExecutionEngine, frame evaluator.Frame, result object.Object) {
+}
+
+func (target *evaluatorTarget) frames() []frame {
+	span := object.SpanOf(target.engine.File, target.statement.Pos())
+	frames := []frame{}
+
+	// every function was called at the position shown for the frame of its caller
+	for _, call := range target.engine.Frames() {
+		frames = append(frames, frame{name: call.Name(), file: span.File, line: span.Line, column: span.Column})
+		span = call.Call
+	}
+
+	return append(frames, frame{name: "<main>", file: span.File, line: span.Line, column: span.Column})
+}
+
+func (target *evaluatorTarget) scopes(index int) []scope {
+	scopes := target.engine.Scopes()
+	// the main program owns the scopes which are not declared by a function
+	owner := index
+	if index == len(target.engine.Frames()) {
+		owner = -1
+	}
+
+	var locals []variable
+	seen := map[string]bool{}
+
+	// the last scope holds the globals
+	for _, scope := range scopes[:len(scopes)-1] {
+		if scope.Frame != owner {
+			continue
+		}
+
+		// inner scopes shadow the variables of outer ones
+		for i := len(scope.Variables) - 1; i >= 0; i-- {
+			if !seen[scope.Variables[i].Name] {
+				seen[scope.Variables[i].Name] = true
+				locals = append(locals, variable{name: scope.Variables[i].Name, value: scope.Variables[i].Value})
+			}
+		}
+	}
+
+	var globals []variable
+	for _, global := range scopes[len(scopes)-1].Variables {
+		globals = append(globals, variable{name: global.Name, value: global.Value})
+	}
+
+	return []scope{{name: "Locals", variables: locals}, {name: "Globals", variables: globals}}
+}
+
+// evaluate ignores the frame, variables are resolved dynamically, so the ones of all frames are visible
+func (target *evaluatorTarget) evaluate(expression string, frame int) (object.Object, error) {
+	p := parser.New(lexer.New(expression))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		return nil, fmt.Errorf("invalid expression: %s", strings.Join(p.Errors(), ", "))
+	}
+
+	hook := target.engine.Hook
+	target.engine.Hook = nil
+	result := target.engine.Eval(program)
+	target.engine.Hook = hook
+	target.engine.IsReturnTriggered = false
+
+	if err, ok := result.(*object.Error); ok {
+		return nil, fmt.Errorf("%s: %s", err.Kind, err.Message)
+	}
+
+	if result == nil {
+		result = evaluator.NULL
+	}
+
+	return result, nil
+}
+
+// vmTarget debugs a program with the virtual machine, positions are mapped from instruction pointers to statements
+type vmTarget struct {
+	server  *Server
+	machine *vm.VM
+	program *ast.Program
+	file    string
+}
+
+func (target *vmTarget) run() (object.Object, *object.Error) {
+	err := target.machine.Run()
+	if err != nil {
+		runtimeErr, ok := err.(*object.Error)
+		if !ok {
+			runtimeErr = &object.Error{Kind: object.RUNTIME_ERROR, Message: err.Error()}
+		}
+
+		return nil, runtimeErr
+	}
+
+	statements := target.program.Statements
+	if len(statements) == 0 || !compiler.LeavesValue(statements[len(statements)-1]) {
+		return nil, nil
+	}
+
+	return target.machine.LastPoppedStackElem(), nil
+}
+
+func (target *vmTarget) BeforeStatement(machine *vm.VM, statement code.Statement) *object.Error {
+	return target.server.reach(debugger.Location{File: target.file, Line: statement.Line, Column: statement.Column, Depth: machine.Depth()})
+}
+
+func (target *vmTarget) EnterFunction(machine *vm.VM, function *object.CompiledFunction) {
+	target.server.enter(function.Name)
+}
+
+func (target *vmTarget) LeaveFunction(machine *vm.VM, function *object.CompiledFunction, result object.Object) {
+}
+
+func (target *vmTarget) frames() []frame {
+	var frames []frame
+	for _, debugFrame := range target.machine.Frames() {
+		frames = append(frames, frame{
+			name:   debugFrame.Function,
+			file:   target.file,
+			line:   debugFrame.Statement.Line,
+			column: debugFrame.Statement.Column,
+		})
+	}
+
+	return frames
+}
+
+func (target *vmTarget) scopes(index int) []scope {
+	var locals []variable
+	for _, local := range target.machine.Frames()[index].Locals {
+		locals = append(locals, variable{name: local.Name, value: local.Value})
+	}
+
+	var globals []variable
+	for _, global := range target.machine.Globals() {
+		globals = append(globals, variable{name: global.Name, value: global.Value})
+	}
+
+	return []scope{{name: "Locals", variables: locals}, {name: "Globals", variables: globals}}
+}
+
+// evaluate only supports the names of variables, the vm can not compile code while a program is running
+func (target *vmTarget) evaluate(expression string, frame int) (object.Object, error) {
+	name := strings.TrimSpace(expression)
+
+	for _, scope := range target.scopes(frame) {
+		for _, variable := range scope.variables {
+			if variable.name == name {
+				return variable.value, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("variable %s is not defined, only variables can be evaluated with the vm backend", name)
+}
+
+// absolutePath makes the paths of sources comparable with the ones sent by clients
+func absolutePath(file string) string {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+
+	return path
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"curryLang/debugger"
+	"curryLang/object"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// runDebugCommand executes "curry debug <file>" and returns false if the program failed
+func runDebugCommand(args []string) (bool, error) {
+	if len(args) != 1 {
+		return false, errors.New("usage: curry debug <file>")
+	}
+
+	file := args[0]
+
+	program, ok, err := parseFile(file)
+	if !ok || err != nil {
+		return false, err
+	}
+
+	engine := newEngine()
+
+	// setup module of the program
+	err = engine.LoadModule(filepath.Dir(file))
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Println("Debugging", file, "- type help for a list of commands")
+
+	engine.File = file
+	engine.Hook = debugger.New(os.Stdin, os.Stdout)
+	evalResult := engine.Eval(program)
+	engine.Finish()
+
+	if err, ok := evalResult.(*object.Error); ok {
+		fmt.Fprintln(os.Stderr, err.Traceback())
+		return false, nil
+	}
+
+	if evalResult != nil {
+		fmt.Println(evalResult.Inspect())
+	}
+
+	return true, nil
+}
@@ -0,0 +1,323 @@
+// Package debugger implements an interactive step debugger for the tree-walking evaluator.
+// It is installed as the evaluator.Hook of an engine and reads its commands line by line:
+//
+//	engine.Hook = debugger.New(os.Stdin, os.Stdout)
+//	engine.Eval(program)
+package debugger
+
+import (
+	"bufio"
+	"curryLang/ast"
+	"curryLang/evaluator"
+	"curryLang/lexer"
+	"curryLang/object"
+	"curryLang/parser"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prompt is printed while the debugger waits for a command
+const Prompt = "(curry) "
+
+const help = `Commands:
+  break <line>|<file:line>|<function>  set a breakpoint (b)
+  delete <id>                          remove a breakpoint (d)
+  breakpoints                          list the breakpoints (bl)
+  continue                             run until the next breakpoint (c)
+  step                                 step into the next statement (s)
+  next                                 step over function calls (n)
+  out                                  run until the current function returned (o)
+  locals                               show the variables by scope (l)
+  print <expression>                   evaluate an expression in the current frame (p)
+  stack                                show the call stack (bt)
+  quit                                 stop the program (q)
+  help                                 show this help (h)
+An empty line repeats the last command.`
+
+// Debugger pauses the program at breakpoints and after steps and then reads commands.
+// It starts in step mode, so the program is paused before its first statement.
+type Debugger struct {
+	*Stepper
+
+	in  *bufio.Scanner
+	out io.Writer
+
+	lastCommand string
+
+	// the input was closed, the program runs without pausing again
+	detached bool
+
+	// lines of the source files, read when the debugger pauses in them
+	sources map[string][]string
+}
+
+// New creates a debugger reading commands from in and writing to out
+func New(in io.Reader, out io.Writer) *Debugger {
+	return &Debugger{
+		Stepper: NewStepper(),
+		in:      bufio.NewScanner(in),
+		out:     out,
+		sources: map[string][]string{},
+	}
+}
+
+func (debugger *Debugger) BeforeStatement(engine *evaluator.ExecutionEngine, statement ast.Statement) *object.Error {
+	position := statement.Pos()
+	current := Location{File: engine.File, Line: position.Line, Column: position.Column, Depth: len(engine.Frames())}
+
+	stop, ok := debugger.Reach(current)
+	if !ok || debugger.detached {
+		return nil
+	}
+
+	return debugger.pause(engine, statement, stop)
+}
+
+func (debugger *Debugger) EnterFunction(engine *evaluator.ExecutionEngine, frame evaluator.Frame) {
+	debugger.Enter(frame.Function.Name)
+}
+
+func (debugger *Debugger) LeaveFunction(engine *evaluator.ExecutionEngine, frame evaluator.Frame, result object.Object) {
+	// show the result of the function the user steps out of
+	if debugger.detached || debugger.Mode() != StepOut || len(engine.Frames()) != debugger.From().Depth {
+		return
+	}
+
+	value := "nothing"
+	if result != nil {
+		value = result.Inspect()
+	}
+
+	fmt.Fprintf(debugger.out, "%s returned %s\n", frame.Name(), value)
+}
+
+// pause shows the statement and handles commands until the program is resumed
+func (debugger *Debugger) pause(engine *evaluator.ExecutionEngine, statement ast.Statement, stop Stop) *object.Error {
+	position := statement.Pos()
+
+	if stop.Breakpoint.ID != 0 {
+		fmt.Fprintln(debugger.out, stop.Breakpoint)
+	}
+
+	fmt.Fprintf(debugger.out, "> %s:%d\n", displayFile(engine.File), position.Line)
+	fmt.Fprintf(debugger.out, "%4d  %s\n", position.Line, debugger.sourceLine(engine.File, position.Line, statement))
+
+	for {
+		fmt.Fprint(debugger.out, Prompt)
+
+		if !debugger.in.Scan() {
+			// without input the program can only run to its end
+			fmt.Fprintln(debugger.out)
+			debugger.detached = true
+			return nil
+		}
+
+		line := strings.TrimSpace(debugger.in.Text())
+		if line == "" {
+			line = debugger.lastCommand
+		}
+
+		if line == "" {
+			continue
+		}
+
+		debugger.lastCommand = line
+
+		command, argument, _ := strings.Cut(line, " ")
+		argument = strings.TrimSpace(argument)
+
+		switch command {
+		case "continue", "c":
+			debugger.Resume(Continue)
+			return nil
+		case "step", "s":
+			debugger.Resume(StepIn)
+			return nil
+		case "next", "n":
+			debugger.Resume(StepOver)
+			return nil
+		case "out", "o":
+			debugger.Resume(StepOut)
+			return nil
+		case "quit", "q":
+			debugger.detached = true
+			return Abort()
+		case "break", "b":
+			debugger.breakCommand(engine, argument)
+		case "delete", "d":
+			debugger.deleteCommand(argument)
+		case "breakpoints", "bl":
+			debugger.listBreakpoints()
+		case "locals", "l":
+			debugger.printLocals(engine)
+		case "print", "p":
+			debugger.evaluate(engine, argument)
+		case "stack", "bt":
+			debugger.printStack(engine, statement)
+		case "help", "h":
+			fmt.Fprintln(debugger.out, help)
+		default:
+			fmt.Fprintf(debugger.out, "Unknown command %s, type help for a list of commands\n", command)
+		}
+	}
+}
+
+func (debugger *Debugger) breakCommand(engine *evaluator.ExecutionEngine, argument string) {
+	if argument == "" {
+		fmt.Fprintln(debugger.out, "Usage: break <line>|<file:line>|<function>")
+		return
+	}
+
+	var breakpoint Breakpoint
+
+	if line, err := strconv.Atoi(argument); err == nil {
+		breakpoint = debugger.BreakAtLine(engine.File, line)
+	} else if index := strings.LastIndex(argument, ":"); index != -1 {
+		line, err := strconv.Atoi(argument[index+1:])
+		if err != nil {
+			fmt.Fprintf(debugger.out, "Invalid line %s\n", argument[index+1:])
+			return
+		}
+
+		breakpoint = debugger.BreakAtLine(argument[:index], line)
+	} else {
+		breakpoint = debugger.BreakAtFunction(argument)
+	}
+
+	fmt.Fprintln(debugger.out, breakpoint)
+}
+
+func (debugger *Debugger) deleteCommand(argument string) {
+	id, err := strconv.Atoi(argument)
+	if err != nil {
+		fmt.Fprintln(debugger.out, "Usage: delete <id>")
+		return
+	}
+
+	if !debugger.Delete(id) {
+		fmt.Fprintf(debugger.out, "No breakpoint %d\n", id)
+		return
+	}
+
+	fmt.Fprintf(debugger.out, "Deleted breakpoint %d\n", id)
+}
+
+func (debugger *Debugger) listBreakpoints() {
+	if len(debugger.breakpoints) == 0 {
+		fmt.Fprintln(debugger.out, "No breakpoints")
+		return
+	}
+
+	for _, breakpoint := range debugger.breakpoints {
+		fmt.Fprintln(debugger.out, breakpoint)
+	}
+}
+
+func (debugger *Debugger) printLocals(engine *evaluator.ExecutionEngine) {
+	frames := engine.Frames()
+
+	for _, scope := range engine.Scopes() {
+		if scope.Frame == -1 {
+			fmt.Fprintln(debugger.out, "Globals:")
+		} else {
+			fmt.Fprintf(debugger.out, "Scope of %s (frame #%d):\n", frames[scope.Frame].Name(), scope.Frame)
+		}
+
+		if len(scope.Variables) == 0 {
+			fmt.Fprintln(debugger.out, "  no variables")
+		}
+
+		for _, variable := range scope.Variables {
+			fmt.Fprintf(debugger.out, "  %s = %s\n", variable.Name, variable.Value.Inspect())
+		}
+	}
+
+	if len(engine.Functions) > 0 {
+		names := make([]string, 0, len(engine.Functions))
+		for name := range engine.Functions {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+		fmt.Fprintf(debugger.out, "Functions: %s\n", strings.Join(names, ", "))
+	}
+}
+
+// evaluate evaluates an expression with the variables of the current frame, the debugger does not pause in it
+func (debugger *Debugger) evaluate(engine *evaluator.ExecutionEngine, input string) {
+	if input == "" {
+		fmt.Fprintln(debugger.out, "Usage: print <expression>")
+		return
+	}
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		for _, err := range p.Errors() {
+			fmt.Fprintln(debugger.out, "Error: ", err)
+		}
+
+		return
+	}
+
+	hook := engine.Hook
+	engine.Hook = nil
+	result := engine.Eval(program)
+	engine.Hook = hook
+	engine.IsReturnTriggered = false
+
+	if err, ok := result.(*object.Error); ok {
+		fmt.Fprintf(debugger.out, "%s: %s\n", err.Kind, err.Message)
+		return
+	}
+
+	if result == nil {
+		result = evaluator.NULL
+	}
+
+	fmt.Fprintln(debugger.out, result.Inspect())
+}
+
+// printStack shows the active function calls, the innermost one first like a Go stack trace
+func (debugger *Debugger) printStack(engine *evaluator.ExecutionEngine, statement ast.Statement) {
+	span := object.SpanOf(engine.File, statement.Pos())
+
+	for i, frame := range engine.Frames() {
+		fmt.Fprintf(debugger.out, "#%d %s at %s\n", i, frame.Name(), span)
+		span = frame.Call
+	}
+
+	fmt.Fprintf(debugger.out, "#%d <main> at %s\n", len(engine.Frames()), span)
+}
+
+// sourceLine returns the line of the source file, or the statement if the file can not be read
+func (debugger *Debugger) sourceLine(file string, line int, statement ast.Statement) string {
+	lines, ok := debugger.sources[file]
+	if !ok && file != "" {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+
+		debugger.sources[file] = lines
+	}
+
+	if line < 1 || line > len(lines) {
+		return statement.String()
+	}
+
+	return strings.TrimRight(lines[line-1], "\r")
+}
+
+func displayFile(file string) string {
+	if file == "" {
+		return "<input>"
+	}
+
+	return file
+}
@@ -0,0 +1,129 @@
+package debugger
+
+import (
+	"bytes"
+	"curryLang/evaluator"
+	"curryLang/lexer"
+	"curryLang/object"
+	"curryLang/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const program = `fn add(a, b) {
+    let sum = a + b;
+    return sum;
+}
+
+let x = 1;
+let y = add(x, 2);
+let i = 0;
+while (i < 3) {
+    i = i + 1;
+}
+y`
+
+func TestDebugger(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands string
+		expected []string
+	}{
+		{
+			"step into and out of a function",
+			"n\nn\ns\nlocals\nbt\no\nc\n",
+			[]string{
+				"> main.curry:1\n   1  fn add(a, b) {",
+				"> main.curry:6\n   6  let x = 1;",
+				"> main.curry:7\n   7  let y = add(x, 2);",
+				"> main.curry:2\n   2      let sum = a + b;",
+				"Scope of add (frame #0):\n  a = 1\n  b = 2\nGlobals:\n  x = 1\nFunctions: add",
+				"#0 add at main.curry:2:5\n#1 <main> at main.curry:7:9",
+				"add returned 3\n> main.curry:8",
+			},
+		},
+		{
+			"step over a function",
+			"b 7\nc\nn\np y*10\n\nq\n",
+			[]string{
+				"Breakpoint 1 at main.curry:7",
+				"Breakpoint 1 at main.curry:7\n> main.curry:7",
+				"> main.curry:8\n   8  let i = 0;",
+				"(curry) 30\n(curry) 30",
+			},
+		},
+		{
+			"function breakpoint",
+			"b add\nc\np a+b\np missing\nc\n",
+			[]string{
+				"Breakpoint 1 at function add\n> main.curry:2",
+				"(curry) 3",
+				"NameError: Undeclared variable missing used",
+			},
+		},
+		{
+			"breakpoints in a loop",
+			"b 10\nbl\nc\np i\nc\np i\nd 1\nbl\nc\n",
+			[]string{
+				"(curry) Breakpoint 1 at main.curry:10\n(curry) Breakpoint 1 at main.curry:10",
+				"(curry) 0\n(curry) Breakpoint 1 at main.curry:10",
+				"(curry) 1\n(curry) Deleted breakpoint 1\n(curry) No breakpoints",
+			},
+		},
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.curry")
+
+	err := os.WriteFile(file, []byte(program), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		result := runDebugger(file, tt.commands, &out)
+
+		// the temporary directory is not part of the expected output
+		output := strings.ReplaceAll(out.String(), dir+string(filepath.Separator), "")
+
+		for _, expected := range tt.expected {
+			if !strings.Contains(output, expected) {
+				t.Errorf("%s: output does not contain %q. got=\n%s", tt.name, expected, output)
+			}
+		}
+
+		if strings.Contains(tt.commands, "q\n") {
+			err, ok := result.(*object.Error)
+			if !ok || err.Kind != object.ABORT_ERROR {
+				t.Errorf("%s: program was not stopped. got=%T (%+v)", tt.name, result, result)
+			}
+		} else if result == nil || result.Inspect() != "3" {
+			t.Errorf("%s: wrong result. got=%v", tt.name, result)
+		}
+	}
+}
+
+func TestDebuggerDetachesOnEOF(t *testing.T) {
+	var out bytes.Buffer
+	result := runDebugger("", "", &out)
+
+	if result == nil || result.Inspect() != "3" {
+		t.Errorf("wrong result. got=%v", result)
+	}
+
+	// without a source file the statement is shown
+	if !strings.Contains(out.String(), "> <input>:1\n   1  fn") {
+		t.Errorf("wrong output. got=\n%s", out.String())
+	}
+}
+
+func runDebugger(file string, commands string, out *bytes.Buffer) object.Object {
+	engine := evaluator.NewEngine()
+	engine.File = file
+	engine.Hook = New(strings.NewReader(commands), out)
+
+	return engine.Eval(parser.New(lexer.New(program)).ParseProgram())
+}
@@ -0,0 +1,248 @@
+package debugger
+
+import (
+	"curryLang/object"
+	"fmt"
+	"strings"
+)
+
+// Breakpoint pauses the program at a line of a file or when a function is called
+type Breakpoint struct {
+	ID       int
+	File     string // empty if the breakpoint is set on a function
+	Line     int
+	Function string
+}
+
+func (breakpoint Breakpoint) String() string {
+	if breakpoint.Function != "" {
+		return fmt.Sprintf("Breakpoint %d at function %s", breakpoint.ID, breakpoint.Function)
+	}
+
+	return fmt.Sprintf("Breakpoint %d at %s:%d", breakpoint.ID, breakpoint.File, breakpoint.Line)
+}
+
+// Mode is how the program runs after a pause
+type Mode int
+
+const (
+	Continue Mode = iota // run until a breakpoint is hit
+	StepIn               // pause at the next statement
+	StepOver             // pause at the next statement of the current or a calling function
+	StepOut              // pause at the next statement of a calling function
+)
+
+// Location is the position of a statement and the call depth it is executed at
+type Location struct {
+	File   string
+	Line   int
+	Column int
+	Depth  int
+}
+
+// reaches reports if the program reached a new line since the statement at the previous location,
+// the line is reached again if a loop jumps back to one of its earlier statements
+func (current Location) reaches(previous Location) bool {
+	if current.File != previous.File || current.Line != previous.Line || current.Depth != previous.Depth {
+		return true
+	}
+
+	return current.Column <= previous.Column
+}
+
+// StopReason tells why the program paused
+type StopReason string
+
+const (
+	StopEntry              StopReason = "entry"
+	StopStep               StopReason = "step"
+	StopBreakpoint         StopReason = "breakpoint"
+	StopFunctionBreakpoint StopReason = "function breakpoint"
+	StopPause              StopReason = "pause"
+)
+
+// Stop is a pause of the program
+type Stop struct {
+	Reason     StopReason
+	Breakpoint Breakpoint // the breakpoint which was hit, its id is 0 for other reasons
+}
+
+// Abort returns the error which stops the debugged program, programs can not catch it
+func Abort() *object.Error {
+	return &object.Error{Kind: object.ABORT_ERROR, Message: "Program stopped by the debugger"}
+}
+
+// Stepper decides at which statements the program pauses, it is shared by the debugger frontends.
+// A new stepper pauses before the first statement.
+type Stepper struct {
+	breakpoints []Breakpoint
+	nextID      int
+
+	mode Mode
+	// the next pause is not caused by a step, like the first one or a pause requested by the user
+	reason StopReason
+	// location of the statement at which the current step started
+	from Location
+	// location of the previously executed statement, breakpoints only trigger when a line is reached
+	previous Location
+	// a function breakpoint was hit, the program pauses at the first statement of the function
+	entered *Breakpoint
+}
+
+func NewStepper() *Stepper {
+	return &Stepper{nextID: 1, mode: StepIn, reason: StopEntry}
+}
+
+// Breakpoints returns the breakpoints ordered by their ids
+func (stepper *Stepper) Breakpoints() []Breakpoint {
+	return append([]Breakpoint(nil), stepper.breakpoints...)
+}
+
+// BreakAtLine adds a breakpoint at a line of a file
+func (stepper *Stepper) BreakAtLine(file string, line int) Breakpoint {
+	return stepper.addBreakpoint(Breakpoint{File: file, Line: line})
+}
+
+// BreakAtFunction adds a breakpoint which pauses when a function with the name is called
+func (stepper *Stepper) BreakAtFunction(name string) Breakpoint {
+	return stepper.addBreakpoint(Breakpoint{Function: name})
+}
+
+// Delete removes the breakpoint with the id and reports if it existed
+func (stepper *Stepper) Delete(id int) bool {
+	for i, breakpoint := range stepper.breakpoints {
+		if breakpoint.ID == id {
+			stepper.breakpoints = append(stepper.breakpoints[:i], stepper.breakpoints[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClearLines removes the breakpoints on the lines of a file
+func (stepper *Stepper) ClearLines(file string) {
+	stepper.clear(func(breakpoint Breakpoint) bool { return breakpoint.Function == "" && breakpoint.File == file })
+}
+
+// ClearFunctions removes the breakpoints on functions
+func (stepper *Stepper) ClearFunctions() {
+	stepper.clear(func(breakpoint Breakpoint) bool { return breakpoint.Function != "" })
+}
+
+// Resume continues the program from the statement it is paused at
+func (stepper *Stepper) Resume(mode Mode) {
+	stepper.mode = mode
+	stepper.reason = ""
+	stepper.from = stepper.previous
+}
+
+// Pause stops the running program at the next statement
+func (stepper *Stepper) Pause() {
+	stepper.mode = StepIn
+	stepper.reason = StopPause
+	stepper.from = Location{}
+}
+
+// Mode returns how the program runs since it was resumed
+func (stepper *Stepper) Mode() Mode {
+	return stepper.mode
+}
+
+// From returns the location at which the program was resumed
+func (stepper *Stepper) From() Location {
+	return stepper.from
+}
+
+// Reach is called before a statement is executed and reports if the program has to pause at it
+func (stepper *Stepper) Reach(current Location) (Stop, bool) {
+	previous := stepper.previous
+	stepper.previous = current
+
+	if stepper.entered != nil {
+		stop := Stop{Reason: StopFunctionBreakpoint, Breakpoint: *stepper.entered}
+		stepper.entered = nil
+		return stop, true
+	}
+
+	if breakpoint, ok := stepper.lineBreakpoint(current); ok && current.reaches(previous) {
+		return Stop{Reason: StopBreakpoint, Breakpoint: breakpoint}, true
+	}
+
+	if !stepper.stepDone(current) {
+		return Stop{}, false
+	}
+
+	stop := Stop{Reason: StopStep}
+	if stepper.reason != "" {
+		stop.Reason = stepper.reason
+	}
+
+	return stop, true
+}
+
+// Enter is called when a function is called, function breakpoints pause at its first statement
+func (stepper *Stepper) Enter(function string) {
+	for _, breakpoint := range stepper.breakpoints {
+		if breakpoint.Function != "" && breakpoint.Function == function {
+			stepper.entered = &breakpoint
+			return
+		}
+	}
+}
+
+func (stepper *Stepper) addBreakpoint(breakpoint Breakpoint) Breakpoint {
+	breakpoint.ID = stepper.nextID
+	stepper.nextID++
+	stepper.breakpoints = append(stepper.breakpoints, breakpoint)
+
+	return breakpoint
+}
+
+func (stepper *Stepper) clear(remove func(Breakpoint) bool) {
+	breakpoints := stepper.breakpoints[:0]
+	for _, breakpoint := range stepper.breakpoints {
+		if !remove(breakpoint) {
+			breakpoints = append(breakpoints, breakpoint)
+		}
+	}
+
+	stepper.breakpoints = breakpoints
+}
+
+func (stepper *Stepper) lineBreakpoint(current Location) (Breakpoint, bool) {
+	for _, breakpoint := range stepper.breakpoints {
+		if breakpoint.Function == "" && breakpoint.Line == current.Line && sameFile(breakpoint.File, current.File) {
+			return breakpoint, true
+		}
+	}
+
+	return Breakpoint{}, false
+}
+
+// stepDone reports if the current step ends at the statement, steps end when a line is reached
+func (stepper *Stepper) stepDone(current Location) bool {
+	if !current.reaches(stepper.from) {
+		return false
+	}
+
+	switch stepper.mode {
+	case StepIn:
+		return true
+	case StepOver:
+		return current.Depth <= stepper.from.Depth
+	case StepOut:
+		return current.Depth < stepper.from.Depth
+	}
+
+	return false
+}
+
+// sameFile compares the file of a breakpoint with a source file, breakpoints can use a path relative to it
+func sameFile(breakpoint string, file string) bool {
+	if breakpoint == file || breakpoint == "" {
+		return true
+	}
+
+	return strings.HasSuffix(file, "/"+breakpoint)
+}
@@ -0,0 +1,37 @@
+package evaluator
+
+import (
+	"curryLang/object"
+	"curryLang/token"
+	"fmt"
+	"os"
+)
+
+// StandardBuiltins returns the functions implemented in Go which are added to the packages of the standard library,
+// by the path of the package inside of the standard library module
+func StandardBuiltins() map[string]map[string]*object.Builtin {
+	return map[string]map[string]*object.Builtin{
+		"os": {
+			"ReadFile": {Name: "ReadFile", Function: readFile},
+		},
+	}
+}
+
+// readFile(path) returns the content of the file as string, an IOError is raised if it can not be read
+func readFile(position token.Token, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return &object.Error{Kind: object.ARGUMENT_ERROR, Message: fmt.Sprintf("ReadFile expects 1 argument but got %d", len(args))}
+	}
+
+	path, ok := args[0].(*object.String)
+	if !ok {
+		return &object.Error{Kind: object.TYPE_ERROR, Message: fmt.Sprintf("ReadFile expects a string but got %s", args[0].Type())}
+	}
+
+	data, err := os.ReadFile(path.Value)
+	if err != nil {
+		return &object.Error{Kind: object.IO_ERROR, Message: err.Error()}
+	}
+
+	return &object.String{Value: string(data)}
+}
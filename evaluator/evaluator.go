@@ -2,10 +2,12 @@ package evaluator
 
 import (
 	"curryLang/ast"
+	"curryLang/checker"
+	"curryLang/modfile"
 	"curryLang/object"
 	"curryLang/token"
 	"fmt"
-	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -13,6 +15,10 @@ var (
 	NULL = &object.Null{}
 )
 
+// DefaultMaxCallDepth limits the recursion of new engines, deeper calls would exhaust the Go stack.
+// Engines without a call depth limit raise a stack overflow at this depth.
+const DefaultMaxCallDepth = 10000
+
 type Variable struct {
 	Name  string
 	Value object.Object
@@ -20,27 +26,73 @@ type Variable struct {
 
 type Package struct {
 	Name      string
+	Path      string // file or directory containing the source, empty for prebuilt packages
 	Globals   map[string]Variable
 	Functions map[string]*object.Function
+	Structs   map[string]*object.Struct
+	Engine    *ExecutionEngine // engine which evaluated the package source
 }
 
 type Module struct {
 	Name     string
+	Path     string // root directory of the module, empty for prebuilt modules
 	Packages map[string]*Package
 }
 
 type ExecutionEngine struct {
 	StandardLibraryPath   string
 	StandardLibraryModule string
-	Variables             []Variable
+	Variables             []*Variable
 	CurrentStackPos       []uint32
 
 	Functions map[string]*object.Function
+	Structs   map[string]*object.Struct
+	Traits    map[string]*object.Trait
 	Modules   map[string]*Module
 
+	// module described by the curry.mod file of the executed program
+	ModuleFile *modfile.File
+	ModuleRoot string
+
+	// directory of versioned dependencies, modfile.DefaultCacheDir is used if empty
+	ModuleCacheDir string
+
+	// Go functions added to the packages of the standard library, see StandardBuiltins
+	Builtins map[string]map[string]*object.Builtin
+
+	// resources the program can use, package engines share the usage of the engine importing them
+	Limits object.Limits
+	usage  *object.Usage
+
+	// DisableOS denies imports of the os package of the standard library
+	DisableOS bool
+
+	// Tasks runs the tasks spawned by the program, the engines of its packages and tasks share it
+	Tasks *object.Scheduler
+
+	// import paths of the packages which are currently loaded, used to detect cycles
+	importStack []string
+
+	// source file which is currently evaluated, used for error positions
+	File string
+
+	// node which is currently evaluated, errors are raised at its position
+	node ast.Node
+
+	// Hook is notified about evaluated statements and function calls, it is used by debuggers
+	Hook Hook
+
+	// active function calls, the innermost one last
+	frames []Frame
+
+	// generator the engine runs the function of, it is nil for engines running programs
+	generator *generatorRun
+
+	// generators of the program which did not return yet, they are ended by Finish
+	generators *generatorRuns
+
 	// engine state flags
 	IsReturnTriggered bool
-	HasError          bool
 }
 
 func NewPackage(name string) *Package {
@@ -48,6 +100,7 @@ func NewPackage(name string) *Package {
 		Name:      name,
 		Globals:   map[string]Variable{},
 		Functions: map[string]*object.Function{},
+		Structs:   map[string]*object.Struct{},
 	}
 }
 
@@ -60,26 +113,36 @@ func NewModule(name string) *Module {
 
 func NewEngine() *ExecutionEngine {
 	engine := ExecutionEngine{IsReturnTriggered: false}
-	engine.Variables = make([]Variable, 0)
+	engine.Variables = make([]*Variable, 0)
 	engine.CurrentStackPos = make([]uint32, 0)
 	engine.Functions = make(map[string]*object.Function)
+	engine.Structs = map[string]*object.Struct{object.ErrorStruct.Name: object.ErrorStruct}
+	engine.Traits = object.BuiltinTraits()
 	engine.Modules = make(map[string]*Module)
+	engine.Builtins = StandardBuiltins()
+	engine.Limits = object.Limits{MaxCallDepth: DefaultMaxCallDepth}
+	engine.usage = &object.Usage{}
+	engine.Tasks = object.NewScheduler()
+	engine.generators = newGeneratorRuns()
 	return &engine
 }
 
-func (engine *ExecutionEngine) PushStack() {
-	variablesSize := uint32(len(engine.Variables))
-	stackPosLength := len(engine.CurrentStackPos)
+// ResetUsage starts counting the resources used by the next program against the limits
+func (engine *ExecutionEngine) ResetUsage() {
+	*engine.usage = object.Usage{}
+}
 
-	if stackPosLength == 0 {
-		engine.CurrentStackPos = append(engine.CurrentStackPos, variablesSize)
-	} else {
-		currentPos := engine.CurrentStackPos[stackPosLength-1]
+// Finish ends the tasks spawned by the program and the generators which were not iterated until their end once its
+// main task ended, they can not continue afterwards. Hosts call it after evaluating a program, the engine can then
+// evaluate the next one.
+func (engine *ExecutionEngine) Finish() {
+	engine.Tasks.Cancel()
+	engine.generators.stop()
+}
 
-		if currentPos != variablesSize {
-			engine.CurrentStackPos = append(engine.CurrentStackPos, variablesSize)
-		}
-	}
+func (engine *ExecutionEngine) PushStack() {
+	variablesSize := uint32(len(engine.Variables))
+	engine.CurrentStackPos = append(engine.CurrentStackPos, variablesSize)
 }
 
 func (engine *ExecutionEngine) PopStack() {
@@ -95,6 +158,24 @@ func (engine *ExecutionEngine) PopStack() {
 }
 
 func (engine *ExecutionEngine) Eval(node ast.Node) object.Object {
+	parent := engine.node
+	engine.node = node
+
+	var result object.Object
+	if err := engine.Limits.Step(engine.usage); err != nil {
+		result = engine.objectError(err)
+	} else if err := engine.beforeStatement(node); err != nil {
+		result = err
+	} else {
+		result = engine.eval(node)
+	}
+
+	engine.node = parent
+
+	return result
+}
+
+func (engine *ExecutionEngine) eval(node ast.Node) object.Object {
 	switch node := node.(type) {
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
@@ -104,11 +185,15 @@ func (engine *ExecutionEngine) Eval(node ast.Node) object.Object {
 		return &object.String{Value: node.Value}
 	case *ast.ListExpression:
 		return engine.EvalListExpression(node)
+	case *ast.StructExpression:
+		return engine.EvalStructExpression(node)
 	case *ast.Identifier:
 		return engine.EvalIdentifier(node)
 
 	case *ast.IfElseExpression:
 		return engine.EvalIfElseExpression(node)
+	case *ast.MatchExpression:
+		return engine.EvalMatchExpression(node)
 	case *ast.PrefixExpression:
 		return engine.EvalPrefixExpression(node)
 	case *ast.InfixExpression:
@@ -122,12 +207,39 @@ func (engine *ExecutionEngine) Eval(node ast.Node) object.Object {
 	case *ast.AssignmentStatement:
 		return engine.EvalAssignmentStatement(node)
 
+	case *ast.FieldAssignmentStatement:
+		return engine.EvalFieldAssignmentStatement(node)
+
+	case *ast.StructStatement:
+		return engine.EvalStructStatement(node)
+
+	case *ast.TraitStatement:
+		return engine.EvalTraitStatement(node)
+
+	case *ast.ImplStatement:
+		return engine.EvalImplStatement(node)
+
 	case *ast.WhileStatement:
 		return engine.EvalWhileStatement(node)
 
+	case *ast.ForInStatement:
+		return engine.EvalForInStatement(node)
+
 	case *ast.ReturnStatement:
 		return engine.EvalReturnStatement(node)
 
+	case *ast.TryStatement:
+		return engine.EvalTryStatement(node)
+
+	case *ast.ThrowStatement:
+		return engine.EvalThrowStatement(node)
+
+	case *ast.YieldStatement:
+		return engine.EvalYieldStatement(node)
+
+	case *ast.SelectStatement:
+		return engine.EvalSelectStatement(node)
+
 	case *ast.ImportStatement:
 		return engine.EvalImportStatement(node)
 
@@ -137,195 +249,989 @@ func (engine *ExecutionEngine) Eval(node ast.Node) object.Object {
 	case *ast.FunctionCallExpression:
 		return engine.EvalFunctionCallExpression(node)
 
-	case *ast.DotAccessExpression:
-		return engine.EvalDotAccessExpression(node)
+	case *ast.SpawnExpression:
+		return engine.EvalSpawnExpression(node)
+
+	case *ast.DotAccessExpression:
+		return engine.EvalDotAccessExpression(node)
+
+	case *ast.ExpressionStatement:
+		return engine.Eval(node.Expression)
+
+	case *ast.Program:
+		return engine.EvalStatements(node.Statements)
+	}
+
+	return NULL
+}
+
+func (engine *ExecutionEngine) EvalLetStatement(statement *ast.LetStatement) object.Object {
+	val := engine.Eval(statement.Value)
+	if val.Type() == object.ERROR_OBJ {
+		return val
+	}
+
+	if statement.Pattern != nil {
+		variables, err := engine.destructure(statement.Pattern, val)
+		if err != nil {
+			return err
+		}
+
+		engine.Variables = append(engine.Variables, variables...)
+		return NULL
+	}
+
+	variable := &Variable{
+		Name:  statement.Name.Value,
+		Value: val,
+	}
+
+	engine.Variables = append(engine.Variables, variable)
+
+	return NULL
+}
+
+// destructure returns the variables bound by the pattern of a destructuring let or parameter
+func (engine *ExecutionEngine) destructure(pattern ast.Pattern, value object.Object) ([]*Variable, *object.Error) {
+	matcher, err := object.NewPattern(pattern, engine.lookupStruct)
+	if err != nil {
+		return nil, engine.objectError(err)
+	}
+
+	bound, err := object.Destructure(matcher, value)
+	if err != nil {
+		return nil, engine.objectError(err)
+	}
+
+	names := ast.PatternBindings(pattern)
+	variables := make([]*Variable, len(names))
+	for i, name := range names {
+		variables[i] = &Variable{Name: name, Value: bound[i]}
+	}
+
+	return variables, nil
+}
+
+func (engine *ExecutionEngine) lookupStruct(name string) (*object.Struct, bool) {
+	structObj, ok := engine.Structs[name]
+	return structObj, ok
+}
+
+func (engine *ExecutionEngine) EvalAssignmentStatement(statement *ast.AssignmentStatement) object.Object {
+
+	identifierName := statement.Name.Value
+
+	// the innermost variable shadows the outer ones
+	for i := len(engine.Variables) - 1; i >= 0; i-- {
+		if engine.Variables[i].Name == identifierName {
+			value := engine.Eval(statement.Value)
+			if value.Type() == object.ERROR_OBJ {
+				return value
+			}
+
+			engine.Variables[i].Value = value
+			return NULL
+		}
+	}
+
+	return engine.createError(object.NAME_ERROR, fmt.Sprintf("Tried to assign value to not existing variable %s", identifierName))
+}
+
+func (engine *ExecutionEngine) EvalFieldAssignmentStatement(statement *ast.FieldAssignmentStatement) object.Object {
+	sourceExpr := engine.Eval(statement.Target.Source)
+	if sourceExpr.Type() == object.ERROR_OBJ {
+		return sourceExpr
+	}
+
+	fieldName := statement.Target.Value.(*ast.Identifier).Value
+
+	if host, ok := sourceExpr.(object.HostObject); ok {
+		value := engine.Eval(statement.Value)
+		if isError(value) {
+			return value
+		}
+
+		if err := host.SetField(fieldName, value); err != nil {
+			return engine.createError(err.Kind, err.Message)
+		}
+
+		return NULL
+	}
+
+	instance, ok := sourceExpr.(*object.Instance)
+	if !ok {
+		return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Fields can only be assigned on struct instances but got %s", sourceExpr.Type()))
+	}
+
+	if !instance.Struct.HasField(fieldName) {
+		return engine.createError(object.FIELD_ERROR, fmt.Sprintf("Struct %s has no field %s", instance.Struct.Name, fieldName))
+	}
+
+	value := engine.Eval(statement.Value)
+	if value.Type() == object.ERROR_OBJ {
+		return value
+	}
+
+	instance.Fields[fieldName] = value
+
+	return NULL
+}
+
+func (engine *ExecutionEngine) EvalStructStatement(statement *ast.StructStatement) object.Object {
+	structObj := &object.Struct{
+		Name:    statement.Name.Value,
+		Fields:  make([]string, 0, len(statement.Fields)),
+		Methods: map[string]*object.Function{},
+		Traits:  map[string]*object.Trait{},
+		Invoke:  engine.invokeMethod,
+	}
+
+	for _, field := range statement.Fields {
+		if structObj.HasField(field.Value) {
+			return engine.createError(object.DECLARATION_ERROR, fmt.Sprintf("Field %s is declared twice in struct %s", field.Value, structObj.Name))
+		}
+
+		structObj.Fields = append(structObj.Fields, field.Value)
+	}
+
+	engine.Structs[structObj.Name] = structObj
+
+	return NULL
+}
+
+func (engine *ExecutionEngine) EvalTraitStatement(statement *ast.TraitStatement) object.Object {
+	trait := &object.Trait{
+		Name:            statement.Name.Value,
+		RequiredMethods: make([]*object.Function, 0, len(statement.RequiredMethods)),
+		DefaultMethods:  map[string]*object.Function{},
+	}
+
+	declared := map[string]bool{}
+
+	for _, method := range append(statement.RequiredMethods, statement.DefaultMethods...) {
+		if declared[method.Name] {
+			return engine.createError(object.DECLARATION_ERROR, fmt.Sprintf("Method %s is declared twice in trait %s", method.Name, trait.Name))
+		}
+
+		if !hasSelfParameter(method) {
+			return engine.createError(object.DECLARATION_ERROR, fmt.Sprintf("Method %s of trait %s has to take self as first parameter", method.Name, trait.Name))
+		}
+
+		declared[method.Name] = true
+	}
+
+	for _, method := range statement.RequiredMethods {
+		trait.RequiredMethods = append(trait.RequiredMethods, &object.Function{
+			Name:       method.Name,
+			Parameters: method.Parameters,
+		})
+	}
+
+	for _, method := range statement.DefaultMethods {
+		trait.DefaultMethods[method.Name] = &object.Function{
+			Name:       method.Name,
+			Parameters: method.Parameters,
+			Code:       method.Body,
+			Generator:  ast.ContainsYield(method.Body),
+		}
+	}
+
+	engine.Traits[trait.Name] = trait
+
+	return NULL
+}
+
+func (engine *ExecutionEngine) EvalImplStatement(statement *ast.ImplStatement) object.Object {
+	structObj, ok := engine.Structs[statement.Struct.Value]
+	if !ok {
+		return engine.createError(object.NAME_ERROR, fmt.Sprintf("Undeclared struct %s used", statement.Struct.Value))
+	}
+
+	methods := map[string]*object.Function{}
+
+	for _, method := range statement.Methods {
+		if _, ok := structObj.Methods[method.Name]; ok || methods[method.Name] != nil {
+			return engine.createError(object.DECLARATION_ERROR, fmt.Sprintf("Method %s is already implemented for struct %s", method.Name, structObj.Name))
+		}
+
+		if !hasSelfParameter(method) {
+			return engine.createError(object.DECLARATION_ERROR, fmt.Sprintf("Method %s of struct %s has to take self as first parameter", method.Name, structObj.Name))
+		}
+
+		methods[method.Name] = &object.Function{
+			Name:       method.Name,
+			Parameters: method.Parameters,
+			Code:       method.Body,
+			Generator:  ast.ContainsYield(method.Body),
+		}
+	}
+
+	if statement.Trait != nil {
+		trait, ok := engine.Traits[statement.Trait.Value]
+		if !ok {
+			return engine.createError(object.NAME_ERROR, fmt.Sprintf("Undeclared trait %s used", statement.Trait.Value))
+		}
+
+		if structObj.Implements(trait.Name) {
+			return engine.createError(object.DECLARATION_ERROR, fmt.Sprintf("Struct %s already implements trait %s", structObj.Name, trait.Name))
+		}
+
+		for _, required := range trait.RequiredMethods {
+			method, ok := methods[required.Name]
+			if !ok {
+				return engine.createError(object.DECLARATION_ERROR,
+					fmt.Sprintf("Struct %s does not implement method %s of trait %s", structObj.Name, required.Name, trait.Name),
+				)
+			}
+
+			if len(method.Parameters) != len(required.Parameters) {
+				return engine.createError(object.DECLARATION_ERROR,
+					fmt.Sprintf(
+						"Method %s of trait %s expects %d parameters but got %d",
+						required.Name,
+						trait.Name,
+						len(required.Parameters),
+						len(method.Parameters),
+					),
+				)
+			}
+		}
+
+		for name, method := range trait.DefaultMethods {
+			if _, ok := structObj.Methods[name]; !ok && methods[name] == nil {
+				methods[name] = method
+			}
+		}
+
+		structObj.Traits[trait.Name] = trait
+	}
+
+	for name, method := range methods {
+		structObj.Methods[name] = method
+	}
+
+	return NULL
+}
+
+func hasSelfParameter(method *ast.FunctionExpression) bool {
+	return len(method.Parameters) > 0 && method.Parameters[0].Name == "self"
+}
+
+func (engine *ExecutionEngine) EvalWhileStatement(statement *ast.WhileStatement) object.Object {
+	conditionResult := engine.Eval(statement.Condition)
+	if isError(conditionResult) {
+		return conditionResult
+	}
+
+	condition, ok := conditionResult.(*object.Boolean)
+	if !ok {
+		return engine.createError(object.TYPE_ERROR, "Condition resulted with no boolean result")
+	}
+
+	for condition.Value {
+		engine.branch(statement, true)
+
+		engine.PushStack()
+		result := engine.EvalStatements(statement.Body)
+		engine.PopStack()
+
+		if isError(result) || engine.IsReturnTriggered {
+			return result
+		}
+
+		conditionResult = engine.Eval(statement.Condition)
+		if isError(conditionResult) {
+			return conditionResult
+		}
+
+		condition, ok = conditionResult.(*object.Boolean)
+		if !ok {
+			return engine.createError(object.TYPE_ERROR, "Condition resulted with no boolean result")
+		}
+	}
+
+	engine.branch(statement, false)
+
+	return NULL
+}
+
+// EvalForInStatement runs the body for every element of the iterable, the variables are declared in the scope of the body
+func (engine *ExecutionEngine) EvalForInStatement(statement *ast.ForInStatement) object.Object {
+	iterable := engine.Eval(statement.Iterable)
+	if isError(iterable) {
+		return iterable
+	}
+
+	iterator, ok := object.Iterate(iterable, statement.Key != nil)
+	if !ok {
+		return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Values of type %s can not be iterated", iterable.Type()))
+	}
+
+	for {
+		key, value, done, err := iterator.Next()
+		if err != nil {
+			return err
+		}
+
+		if done {
+			break
+		}
+
+		engine.branch(statement, true)
+
+		engine.PushStack()
+		if statement.Key != nil {
+			engine.Variables = append(engine.Variables, &Variable{Name: statement.Key.Value, Value: key})
+		}
+		engine.Variables = append(engine.Variables, &Variable{Name: statement.Variable.Value, Value: value})
+		result := engine.EvalStatements(statement.Body)
+		engine.PopStack()
+
+		if isError(result) || engine.IsReturnTriggered {
+			return result
+		}
+	}
+
+	engine.branch(statement, false)
+
+	return NULL
+}
+
+func (engine *ExecutionEngine) EvalReturnStatement(statement *ast.ReturnStatement) object.Object {
+	result := engine.Eval(statement.ReturnValue)
+	engine.IsReturnTriggered = true
+	return result
+}
+
+// EvalTryStatement evaluates the handler if the body results in an error, errors are values which are
+// returned up to the next try statement
+func (engine *ExecutionEngine) EvalTryStatement(statement *ast.TryStatement) object.Object {
+	engine.PushStack()
+	result := engine.EvalStatements(statement.Body)
+	engine.PopStack()
+
+	err, ok := result.(*object.Error)
+	if !ok || !err.Catchable() {
+		return result
+	}
+
+	engine.PushStack()
+
+	if statement.Parameter != nil {
+		engine.Variables = append(engine.Variables, &Variable{
+			Name:  statement.Parameter.Value,
+			Value: err.Instance(),
+		})
+	}
+
+	result = engine.EvalStatements(statement.Handler)
+	engine.PopStack()
+
+	return result
+}
+
+func (engine *ExecutionEngine) EvalThrowStatement(statement *ast.ThrowStatement) object.Object {
+	value := engine.Eval(statement.Value)
+	if isError(value) {
+		return value
+	}
+
+	err := object.Throw(value)
+	err.Span = object.SpanOf(engine.File, statement.Token)
+
+	return err
+}
+
+// EvalSelectStatement evaluates the channels and sent values of all cases before it waits for one of them
+func (engine *ExecutionEngine) EvalSelectStatement(statement *ast.SelectStatement) object.Object {
+	cases := make([]object.SelectCase, 0, len(statement.Cases))
+
+	for _, selectCase := range statement.Cases {
+		source := engine.Eval(selectCase.Channel)
+		if isError(source) {
+			return source
+		}
+
+		channel, ok := source.(*object.Channel)
+		if !ok {
+			return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Select cases have to use channels but got %s", source.Type()))
+		}
+
+		var value object.Object
+		if selectCase.Send {
+			value = engine.Eval(selectCase.Value)
+			if isError(value) {
+				return value
+			}
+		}
+
+		cases = append(cases, object.SelectCase{Channel: channel, Send: selectCase.Send, Value: value})
+	}
+
+	index, value, err := engine.Tasks.Select(cases, !statement.HasDefault)
+	if err != nil {
+		// errors of spawned tasks keep the position they were raised at
+		if err.Span.Line == 0 {
+			err.Span = object.SpanOf(engine.File, statement.Token)
+		}
+
+		return err
+	}
+
+	statements := statement.Default
+
+	engine.PushStack()
+
+	if index >= 0 {
+		selected := statement.Cases[index]
+		statements = selected.Body
+
+		if selected.Name != nil {
+			if value == nil {
+				value = NULL
+			}
+
+			engine.Variables = append(engine.Variables, &Variable{Name: selected.Name.Value, Value: value})
+		}
+	}
+
+	result := engine.EvalStatements(statements)
+	engine.PopStack()
+
+	return result
+}
+
+func (engine *ExecutionEngine) EvalImportStatement(statement *ast.ImportStatement) object.Object {
+
+	for _, importPath := range statement.Packages {
+		pkg, err := engine.resolvePackage(importPath)
+		if err != nil {
+			return err
+		}
+
+		value := pkg.Object()
+		value.Path = importPath
+
+		engine.Variables = append(engine.Variables, &Variable{
+			Name:  pkg.Name,
+			Value: value,
+		})
+	}
+
+	return NULL
+}
+
+func (engine *ExecutionEngine) EvalFunctionExpression(statement *ast.FunctionExpression) object.Object {
+	function := &object.Function{
+		Name:       statement.Name,
+		Parameters: statement.Parameters,
+		Code:       statement.Body,
+		File:       engine.File,
+		Generator:  ast.ContainsYield(statement.Body),
+	}
+
+	if statement.Name != "" {
+		engine.Functions[statement.Name] = function
+	}
+
+	return function
+}
+
+func (engine *ExecutionEngine) EvalFunctionCallExpression(statement *ast.FunctionCallExpression) object.Object {
+	functionExpr := engine.Eval(statement.FunctionExpr)
+	if isError(functionExpr) {
+		return functionExpr
+	}
+
+	if builtin, ok := functionExpr.(*object.Builtin); ok {
+		return engine.evalBuiltin(builtin, statement)
+	}
+
+	function, ok := functionExpr.(*object.Function)
+	if !ok {
+		return NULL
+	}
+
+	return engine.evalFunction(function, statement)
+}
+
+// EvalSpawnExpression calls the function in a new task, which has its own engine with a copy of the variables
+func (engine *ExecutionEngine) EvalSpawnExpression(expr *ast.SpawnExpression) object.Object {
+	callee := expr.Function
+	var arguments []ast.Expression
+	var namedArguments []*ast.NamedArgument
+
+	if call, ok := expr.Function.(*ast.FunctionCallExpression); ok {
+		callee = call.FunctionExpr
+		arguments = call.Parameters
+		namedArguments = call.NamedParameters
+	}
+
+	function := engine.Eval(callee)
+	if isError(function) {
+		return function
+	}
+
+	args, err := engine.evalExpressions(arguments)
+	if err != nil {
+		return err
+	}
+
+	named, err := engine.evalNamedArguments(namedArguments)
+	if err != nil {
+		return err
+	}
+
+	var run func(task *ExecutionEngine) object.Object
+
+	switch function := function.(type) {
+	case *object.Function:
+		bound, err := object.NewSignature(function.Parameters).Bind("spawned "+function.Name, args, named)
+		if err != nil {
+			return engine.objectError(err)
+		}
+
+		run = func(task *ExecutionEngine) object.Object {
+			return task.callFunction(function, bound)
+		}
+
+	case *object.Builtin:
+		if len(named) > 0 {
+			return engine.createError(object.ARGUMENT_ERROR, "Builtins can not be called with named arguments")
+		}
+
+		run = func(task *ExecutionEngine) object.Object {
+			result := function.Function(expr.Token, args...)
+			if err, ok := result.(*object.Error); ok && err.Span.Line == 0 {
+				err.Span = object.SpanOf(task.File, expr.Token)
+			}
+
+			return result
+		}
+
+	default:
+		return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Only functions can be spawned but got %s", function.Type()))
+	}
+
+	task := engine.newTaskEngine()
+
+	engine.Tasks.Spawn(func() *object.Error {
+		if err, ok := run(task).(*object.Error); ok {
+			return err
+		}
+
+		return nil
+	})
+
+	return NULL
+}
+
+// newTaskEngine returns the engine of a spawned task. It shares the variables visible at the spawn with this engine,
+// so assignments to them are seen by both, and it shares the declarations, limits and hook.
+// The function called by the task is called at the position of the spawn expression.
+func (engine *ExecutionEngine) newTaskEngine() *ExecutionEngine {
+	task := *engine
+	task.Variables = append([]*Variable(nil), engine.Variables...)
+	task.CurrentStackPos = make([]uint32, 0)
+	task.importStack = append([]string(nil), engine.importStack...)
+	task.frames = nil
+	task.generator = nil
+	task.IsReturnTriggered = false
+
+	return &task
+}
+
+func (engine *ExecutionEngine) evalBuiltin(builtin *object.Builtin, call *ast.FunctionCallExpression) object.Object {
+	if len(call.NamedParameters) > 0 {
+		return engine.createError(object.ARGUMENT_ERROR, "Builtins can not be called with named arguments")
+	}
+
+	args, err := engine.evalExpressions(call.Parameters)
+	if err != nil {
+		return err
+	}
+
+	result := builtin.Function(call.Token, args...)
+	if result == nil {
+		return NULL
+	}
+
+	// builtins only know the message, the engine adds where the error happened
+	if err, ok := result.(*object.Error); ok && err.Span.Line == 0 {
+		if err.Kind == "" {
+			err.Kind = object.RUNTIME_ERROR
+		}
+
+		err.Span = object.SpanOf(engine.File, call.Token)
+	}
+
+	return result
+}
+
+func (engine *ExecutionEngine) evalFunction(function *object.Function, call *ast.FunctionCallExpression) object.Object {
+	args, err := engine.evalExpressions(call.Parameters)
+	if err != nil {
+		return err
+	}
+
+	named, err := engine.evalNamedArguments(call.NamedParameters)
+	if err != nil {
+		return err
+	}
+
+	return engine.invokeFunction(function, args, named)
+}
+
+func (engine *ExecutionEngine) evalNamedArguments(arguments []*ast.NamedArgument) ([]object.NamedArgument, *object.Error) {
+	var named []object.NamedArgument
+
+	for _, argument := range arguments {
+		value := engine.Eval(argument.Value)
+		if err, ok := value.(*object.Error); ok {
+			return nil, err
+		}
+
+		named = append(named, object.NamedArgument{Name: argument.Name.Value, Value: value})
+	}
+
+	return named, nil
+}
+
+// invokeFunction binds the arguments to the parameters of the function and calls it
+func (engine *ExecutionEngine) invokeFunction(function *object.Function, args []object.Object, named []object.NamedArgument) object.Object {
+	bound, err := object.NewSignature(function.Parameters).Bind(function.Name, args, named)
+	if err != nil {
+		return engine.objectError(err)
+	}
+
+	return engine.callFunction(function, bound)
+}
+
+// callFunction calls the function with the arguments bound to its parameters
+func (engine *ExecutionEngine) callFunction(function *object.Function, args []object.Object) object.Object {
+	if function.Generator {
+		return engine.newGenerator(function, args)
+	}
+
+	return engine.runFunction(function, args)
+}
+
+// runFunction evaluates the body of the function with its parameters bound to the arguments
+func (engine *ExecutionEngine) runFunction(function *object.Function, args []object.Object) object.Object {
+	var call object.Span
+	if engine.node != nil {
+		call = object.SpanOf(engine.File, engine.node.Pos())
+	}
+
+	if engine.Limits.MaxCallDepth == 0 && engine.usage.CallDepth >= DefaultMaxCallDepth {
+		// the message is not capitalised, so both engines raise the same stack overflow
+		overflow := object.StackOverflow()
+		return engine.createError(overflow.Kind, overflow.Message)
+	}
+
+	if err := engine.Limits.Enter(engine.usage); err != nil {
+		return engine.objectError(err)
+	}
+
+	file := engine.File
+	if function.File != "" {
+		engine.File = function.File
+	}
+
+	engine.PushStack()
+	// add parameters as variables to current stack
+	if err := engine.bindParameters(function, args); err != nil {
+		engine.PopStack()
+		engine.File = file
+		engine.usage.CallDepth--
+
+		return err
+	}
+
+	frame := Frame{Function: function, Call: call, scope: len(engine.CurrentStackPos) - 1}
+	engine.frames = append(engine.frames, frame)
+	if engine.Hook != nil {
+		engine.Hook.EnterFunction(engine, frame)
+	}
+
+	result := engine.EvalStatements(function.Code)
+	engine.IsReturnTriggered = false
+
+	if engine.Hook != nil {
+		engine.Hook.LeaveFunction(engine, frame, result)
+	}
+	engine.frames = engine.frames[:len(engine.frames)-1]
+
+	engine.PopStack()
+	engine.File = file
+	engine.usage.CallDepth--
+
+	if result == nil {
+		return NULL
+	}
+
+	// the stack of an error is built while it leaves the called functions
+	if err, ok := result.(*object.Error); ok {
+		err.Stack = append(err.Stack, object.StackFrame{Function: frame.Name(), Call: call})
+	}
+
+	return result
+}
+
+// bindParameters declares the variables of the parameters, default values are evaluated in the scope of the function
+// after the parameters before them are declared
+func (engine *ExecutionEngine) bindParameters(function *object.Function, args []object.Object) *object.Error {
+	for i, parameter := range function.Parameters {
+		value := args[i]
+		if value == nil {
+			value = engine.Eval(parameter.Default)
+			if err, ok := value.(*object.Error); ok {
+				return err
+			}
+		}
+
+		if parameter.Pattern == nil {
+			engine.Variables = append(engine.Variables, &Variable{Name: parameter.Name, Value: value})
+			continue
+		}
 
-	case *ast.ExpressionStatement:
-		return engine.Eval(node.Expression)
+		variables, err := engine.destructure(parameter.Pattern, value)
+		if err != nil {
+			return err
+		}
 
-	case *ast.Program:
-		return engine.EvalStatements(node.Statements)
+		engine.Variables = append(engine.Variables, variables...)
 	}
 
-	return NULL
+	return nil
 }
 
-func (engine *ExecutionEngine) EvalLetStatement(statement *ast.LetStatement) object.Object {
-	val := engine.Eval(statement.Value)
-	variable := Variable{
-		Name:  statement.Name.Value,
-		Value: val,
-	}
+// CallFunction calls a function declared in this engine with already evaluated arguments
+func (engine *ExecutionEngine) CallFunction(function *object.Function, args ...object.Object) object.Object {
+	return engine.callPackageFunction(function, args, nil)
+}
 
-	engine.Variables = append(engine.Variables, variable)
+// callPackageFunction calls a function of this engine for the engine of another package
+func (engine *ExecutionEngine) callPackageFunction(function *object.Function, args []object.Object, named []object.NamedArgument) object.Object {
+	// the function is not called from Curry code of this engine, so there is no call position
+	parent := engine.node
+	engine.node = nil
 
-	return NULL
+	result := engine.invokeFunction(function, args, named)
+
+	engine.node = parent
+
+	return result
 }
 
-func (engine *ExecutionEngine) EvalAssignmentStatement(statement *ast.AssignmentStatement) object.Object {
+func (engine *ExecutionEngine) invokeMethod(name string, self object.Object, args ...object.Object) object.Object {
+	method := self.(*object.Instance).Struct.Methods[name]
+	return engine.invokeFunction(method, append([]object.Object{self}, args...), nil)
+}
 
-	identifierName := statement.Name.Value
+func (engine *ExecutionEngine) evalExpressions(expressions []ast.Expression) ([]object.Object, *object.Error) {
+	values := make([]object.Object, 0, len(expressions))
 
-	for i, variable := range engine.Variables {
-		if variable.Name == identifierName {
-			engine.Variables[i].Value = engine.Eval(statement.Value)
-			return NULL
+	for _, expr := range expressions {
+		value := engine.Eval(expr)
+		if err, ok := value.(*object.Error); ok {
+			return nil, err
 		}
+
+		values = append(values, value)
 	}
 
-	return engine.createError(fmt.Sprintf("Tried to assign value to not existing variable %s", identifierName))
+	return values, nil
 }
 
-func (engine *ExecutionEngine) EvalWhileStatement(statement *ast.WhileStatement) object.Object {
-	conditionResult := engine.Eval(statement.Condition)
-	condition, ok := conditionResult.(*object.Boolean)
+func (engine *ExecutionEngine) EvalDotAccessExpression(expr *ast.DotAccessExpression) object.Object {
+	objExpr := engine.Eval(expr.Source)
+	if isError(objExpr) {
+		return objExpr
+	}
 
-	if !ok {
-		return engine.createError("Condition resulted with no boolean result")
+	if objExpr == NULL {
+		return engine.createError(object.TYPE_ERROR, "Source object evaluated to null")
 	}
 
-	for condition.Value {
-		engine.PushStack()
-		result := engine.EvalStatements(statement.Body)
-		engine.PopStack()
+	if instance, ok := objExpr.(*object.Instance); ok {
+		if field, ok := expr.Value.(*ast.Identifier); ok {
+			if !instance.Struct.HasField(field.Value) {
+				return engine.createError(object.FIELD_ERROR, fmt.Sprintf("Struct %s has no field %s", instance.Struct.Name, field.Value))
+			}
 
-		if result.Type() == object.ERROR_OBJ {
-			return result
+			return instance.Fields[field.Value]
 		}
 
-		conditionResult = engine.Eval(statement.Condition)
-		condition, ok = conditionResult.(*object.Boolean)
-
-		if !ok {
-			return engine.createError("Condition resulted with no boolean result")
+		if funcCall, ok := expr.Value.(*ast.FunctionCallExpression); ok {
+			return engine.evalMethodCall(instance, funcCall)
 		}
+
+		return engine.createError(object.TYPE_ERROR, "Only fields and methods are allowed to be accessed from a struct instance")
 	}
 
-	return NULL
-}
+	if host, ok := objExpr.(object.HostObject); ok {
+		return engine.evalHostAccess(host, expr.Value)
+	}
 
-func (engine *ExecutionEngine) EvalReturnStatement(statement *ast.ReturnStatement) object.Object {
-	result := engine.Eval(statement.ReturnValue)
-	engine.IsReturnTriggered = true
-	return result
-}
+	if pkg, ok := objExpr.(*object.Package); ok {
+		kind, name := checker.MemberKind(expr.Value)
+		if name != "" && !ast.IsExported(name) {
+			return engine.createError(object.IMPORT_ERROR, checker.UnexportedError(kind, name, pkg.Path))
+		}
 
-func (engine *ExecutionEngine) EvalImportStatement(statement *ast.ImportStatement) object.Object {
+		if variable, ok := expr.Value.(*ast.Identifier); ok {
+			global, ok := pkg.Globals[variable.Value]
+			if !ok {
+				return engine.createError(object.NAME_ERROR, fmt.Sprintf("Package %s has no global %s", pkg.Name, variable.Value))
+			}
+
+			return global
+		}
+
+		if funcCall, ok := expr.Value.(*ast.FunctionCallExpression); ok {
+			if pkgFuncIdentifier, ok := funcCall.FunctionExpr.(*ast.Identifier); ok {
+				return engine.evalPackageFunction(pkg, pkgFuncIdentifier.Value, funcCall)
+			} else {
+				return engine.createError(object.TYPE_ERROR, "You can only use identifiers for package functions")
+			}
+		}
 
-	// TODO: implement loading of file
-	// TODO: implement multi packages path
-	for _, pkg := range statement.Packages {
-		packagePath := strings.SplitN(pkg, "/", 2)
-		moduleName := packagePath[0]
-
-		if module, ok := engine.Modules[moduleName]; ok {
-			if len(packagePath) == 1 {
-				packageName := packagePath[0]
-				pkg := module.Packages[packageName]
-
-				globals := map[string]object.Object{}
-				for varName, variable := range pkg.Globals {
-					globals[varName] = variable.Value
-				}
-
-				engine.Variables = append(engine.Variables, Variable{
-					Name: packageName,
-					Value: &object.Package{
-						Name:      packageName,
-						Functions: pkg.Functions,
-						Globals:   globals,
-					},
-				})
+		if structExpr, ok := expr.Value.(*ast.StructExpression); ok {
+			structObj, ok := pkg.Structs[structExpr.Name.Value]
+			if !ok {
+				return engine.createError(object.NAME_ERROR, fmt.Sprintf("Package %s has no struct %s", pkg.Name, structExpr.Name.Value))
 			}
-		} else {
-			return engine.createError(fmt.Sprintf("Module %s does not exist", moduleName))
+
+			return engine.instantiate(structObj, structExpr)
 		}
 
+		return engine.createError(object.TYPE_ERROR, "Only globals, functions and structs are allowed to be accessed from a package")
+	} else {
+		return engine.createError(object.TYPE_ERROR, "Currently only packages and struct instances are allowed as dot source")
 	}
-
-	return NULL
 }
 
-func (engine *ExecutionEngine) EvalFunctionExpression(statement *ast.FunctionExpression) object.Object {
-	function := &object.Function{
-		Name:       statement.Name,
-		Parameters: statement.Parameters,
-		Code:       statement.Body,
+func (engine *ExecutionEngine) evalPackageFunction(pkg *object.Package, name string, call *ast.FunctionCallExpression) object.Object {
+	function, ok := pkg.Functions[name]
+	if !ok {
+		if builtin, ok := pkg.Globals[name].(*object.Builtin); ok {
+			return engine.evalBuiltin(builtin, call)
+		}
+
+		return engine.createError(object.NAME_ERROR, fmt.Sprintf("Package %s has no function %s", pkg.Name, name))
 	}
 
-	if statement.Name != "" {
-		engine.Functions[statement.Name] = function
+	if pkg.Invoke == nil {
+		return engine.evalFunction(function, call)
 	}
 
-	return function
-}
+	args, err := engine.evalExpressions(call.Parameters)
+	if err != nil {
+		return err
+	}
 
-func (engine *ExecutionEngine) EvalFunctionCallExpression(statement *ast.FunctionCallExpression) object.Object {
-	functionExpr := engine.Eval(statement.FunctionExpr)
-	function, ok := functionExpr.(*object.Function)
-	if !ok {
-		return NULL
+	named, err := engine.evalNamedArguments(call.NamedParameters)
+	if err != nil {
+		return err
+	}
+
+	// the function is executed by the engine of the package, so it sees the package globals,
+	// the position of the call is missing in the stack of its errors
+	result := pkg.Invoke(function, args, named)
+	if err, ok := result.(*object.Error); ok && len(err.Stack) > 0 {
+		err.Stack[len(err.Stack)-1].Call = object.SpanOf(engine.File, engine.node.Pos())
+	} else if ok && err.Span.Line == 0 {
+		// the arguments did not fit the parameters, so the function was not called
+		err.Span = object.SpanOf(engine.File, engine.node.Pos())
 	}
 
-	return engine.evalFunction(function, statement.Parameters)
+	return result
 }
 
-func (engine *ExecutionEngine) evalFunction(function *object.Function, params []ast.Expression) object.Object {
-	engine.PushStack()
-	// add parameters as variables to current stack
-	for i, parameter := range function.Parameters {
-		paramValue := engine.Eval(params[i])
-		engine.Variables = append(engine.Variables, Variable{
-			Name:  parameter.Name,
-			Value: paramValue,
-		})
+// evalHostAccess reads a field of a host object or calls one of its methods
+func (engine *ExecutionEngine) evalHostAccess(host object.HostObject, member ast.Expression) object.Object {
+	name := member
+	funcCall, isCall := member.(*ast.FunctionCallExpression)
+	if isCall {
+		name = funcCall.FunctionExpr
 	}
 
-	result := engine.EvalStatements(function.Code)
-	engine.IsReturnTriggered = false
+	identifier, ok := name.(*ast.Identifier)
+	if !ok {
+		return engine.createError(object.TYPE_ERROR, "Only fields and methods are allowed to be accessed from a host object")
+	}
 
-	engine.PopStack()
+	value, err := host.GetField(identifier.Value)
+	if err != nil {
+		return engine.createError(err.Kind, err.Message)
+	}
 
-	return result
+	if !isCall {
+		return value
+	}
+
+	builtin, ok := value.(*object.Builtin)
+	if !ok {
+		return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Field %s of %s is not a method", identifier.Value, host.Inspect()))
+	}
+
+	return engine.evalBuiltin(builtin, funcCall)
 }
 
-func (engine *ExecutionEngine) EvalDotAccessExpression(expr *ast.DotAccessExpression) object.Object {
-	objExpr := engine.Eval(expr.Source)
-	if objExpr == NULL {
-		return engine.createError("Source object evaluated to null")
+func (engine *ExecutionEngine) evalMethodCall(instance *object.Instance, funcCall *ast.FunctionCallExpression) object.Object {
+	methodIdentifier, ok := funcCall.FunctionExpr.(*ast.Identifier)
+	if !ok {
+		return engine.createError(object.TYPE_ERROR, "You can only use identifiers for methods")
 	}
 
-	if pkg, ok := objExpr.(*object.Package); ok {
-		if variable, ok := expr.Value.(*ast.Identifier); ok {
-			return pkg.Globals[variable.Value]
+	name := methodIdentifier.Value
+
+	if method, ok := instance.Struct.Methods[name]; ok {
+		args, err := engine.evalExpressions(funcCall.Parameters)
+		if err != nil {
+			return err
 		}
 
-		if funcCall, ok := expr.Value.(*ast.FunctionCallExpression); ok {
-			if pkgFuncIdentifier, ok := funcCall.FunctionExpr.(*ast.Identifier); ok {
-				function := pkg.Functions[pkgFuncIdentifier.Value]
-				return engine.evalFunction(function, funcCall.Parameters)
-			} else {
-				return engine.createError("You can only use identifiers for package functions")
-			}
+		named, err := engine.evalNamedArguments(funcCall.NamedParameters)
+		if err != nil {
+			return err
 		}
 
-		return engine.createError("Only globals and functions are allowed to be accessed from a package")
-	} else {
-		return engine.createError("Currently only packages are allowed as dot source")
+		return engine.invokeFunction(method, append([]object.Object{instance}, args...), named)
+	}
+
+	// fields holding a function are called without binding self
+	if function, ok := instance.Fields[name].(*object.Function); ok {
+		return engine.evalFunction(function, funcCall)
+	}
+
+	if builtin, ok := instance.Fields[name].(*object.Builtin); ok {
+		return engine.evalBuiltin(builtin, funcCall)
 	}
+
+	return engine.createError(object.FIELD_ERROR, fmt.Sprintf("Struct %s has no method %s", instance.Struct.Name, name))
 }
 
 func (engine *ExecutionEngine) EvalListExpression(identifier *ast.ListExpression) object.Object {
+	if err := engine.Limits.Allocate(engine.usage, len(identifier.Value)); err != nil {
+		return engine.objectError(err)
+	}
+
 	obj := &object.List{}
 	obj.Value = make([]object.Object, 0)
 
 	for i, valExpr := range identifier.Value {
 		val := engine.Eval(valExpr)
+		if isError(val) {
+			return val
+		}
+
 		if i == 0 {
 			obj.ValueType = val.Type()
 		} else {
 			if val.Type() != obj.ValueType {
-				return engine.createError(
+				return engine.createError(object.TYPE_ERROR,
 					fmt.Sprintf(
 						"List members have to be all of the same type, value #%v has type %s instead of %s",
 						i,
@@ -342,21 +1248,113 @@ func (engine *ExecutionEngine) EvalListExpression(identifier *ast.ListExpression
 	return obj
 }
 
-func (engine *ExecutionEngine) EvalIdentifier(identifier *ast.Identifier) object.Object {
+func (engine *ExecutionEngine) EvalStructExpression(expr *ast.StructExpression) object.Object {
+	structObj, ok := engine.Structs[expr.Name.Value]
+	if !ok {
+		return engine.createError(object.NAME_ERROR, fmt.Sprintf("Undeclared struct %s used", expr.Name.Value))
+	}
+
+	return engine.instantiate(structObj, expr)
+}
+
+func (engine *ExecutionEngine) instantiate(structObj *object.Struct, expr *ast.StructExpression) object.Object {
+	if err := engine.Limits.Allocate(engine.usage, 0); err != nil {
+		return engine.objectError(err)
+	}
+
+	instance := &object.Instance{
+		Struct: structObj,
+		Fields: make(map[string]object.Object, len(structObj.Fields)),
+	}
+
+	for _, field := range structObj.Fields {
+		instance.Fields[field] = NULL
+	}
 
-	identifierName := identifier.Value
+	for _, field := range expr.Fields {
+		if !structObj.HasField(field.Name.Value) {
+			return engine.createError(object.FIELD_ERROR, fmt.Sprintf("Struct %s has no field %s", structObj.Name, field.Name.Value))
+		}
 
-	for _, variable := range engine.Variables {
-		if variable.Name == identifierName {
-			return variable.Value
+		value := engine.Eval(field.Value)
+		if value.Type() == object.ERROR_OBJ {
+			return value
 		}
+
+		instance.Fields[field.Name.Value] = value
 	}
 
-	if val, ok := engine.Functions[identifierName]; ok {
+	return instance
+}
+
+func (engine *ExecutionEngine) EvalIdentifier(identifier *ast.Identifier) object.Object {
+	if val, ok := engine.Lookup(identifier.Value); ok {
 		return val
 	}
 
-	return engine.createError(fmt.Sprintf("Undeclared variable %s used", identifier.Value))
+	return engine.createError(object.NAME_ERROR, fmt.Sprintf("Undeclared variable %s used", identifier.Value))
+}
+
+// Lookup returns the value of the innermost variable, the function or the builtin with the name
+func (engine *ExecutionEngine) Lookup(name string) (object.Object, bool) {
+	for i := len(engine.Variables) - 1; i >= 0; i-- {
+		if engine.Variables[i].Name == name {
+			return engine.Variables[i].Value, true
+		}
+	}
+
+	if val, ok := engine.Functions[name]; ok {
+		return val, true
+	}
+
+	for _, builtin := range engine.Tasks.Builtins() {
+		if builtin.Name == name {
+			return builtin, true
+		}
+	}
+
+	// the builtins calling functions are bound to the engine looking them up, tasks have their own engines
+	for _, builtin := range object.ListBuiltins(engine.callValue) {
+		if builtin.Name == name {
+			return builtin, true
+		}
+	}
+
+	return nil, false
+}
+
+// callValue calls a function passed to a builtin, like the function of map
+func (engine *ExecutionEngine) callValue(function object.Object, args ...object.Object) object.Object {
+	switch function := function.(type) {
+	case *object.Function:
+		return engine.invokeFunction(function, args, nil)
+	case *object.Builtin:
+		var position token.Token
+		if engine.node != nil {
+			position = engine.node.Pos()
+		}
+
+		result := function.Function(position, args...)
+		if result == nil {
+			return NULL
+		}
+
+		return result
+	}
+
+	return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Expected a function but got %s", function.Type()))
+}
+
+// SetVariable assigns the innermost variable with the name or declares it in the current stack
+func (engine *ExecutionEngine) SetVariable(name string, value object.Object) {
+	for i := len(engine.Variables) - 1; i >= 0; i-- {
+		if engine.Variables[i].Name == name {
+			engine.Variables[i].Value = value
+			return
+		}
+	}
+
+	engine.Variables = append(engine.Variables, &Variable{Name: name, Value: value})
 }
 
 func (engine *ExecutionEngine) EvalStatements(statements []ast.Statement) object.Object {
@@ -365,7 +1363,7 @@ func (engine *ExecutionEngine) EvalStatements(statements []ast.Statement) object
 	for _, stmt := range statements {
 		result = engine.Eval(stmt)
 
-		if engine.IsReturnTriggered || engine.HasError {
+		if engine.IsReturnTriggered || isError(result) {
 			break
 		}
 	}
@@ -381,11 +1379,12 @@ func (engine *ExecutionEngine) EvalIfElseExpression(ifElse *ast.IfElseExpression
 		if conditionResType == object.ERROR_OBJ {
 			return conditionResult
 		} else {
-			return engine.createError(fmt.Sprintf("Non boolean type (%s) was returned for condition", conditionResType))
+			return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Non boolean type (%s) was returned for condition", conditionResType))
 		}
 	}
 
 	condition := conditionResult.(*object.Boolean)
+	engine.branch(ifElse, condition.Value)
 
 	var statements []ast.Statement
 
@@ -402,8 +1401,64 @@ func (engine *ExecutionEngine) EvalIfElseExpression(ifElse *ast.IfElseExpression
 	return result
 }
 
+// EvalMatchExpression evaluates the body of the first arm matching the value, the variables bound by the pattern
+// are declared in the scope of the guard and the body
+func (engine *ExecutionEngine) EvalMatchExpression(match *ast.MatchExpression) object.Object {
+	value := engine.Eval(match.Value)
+	if isError(value) {
+		return value
+	}
+
+	for _, arm := range match.Arms {
+		pattern, err := object.NewPattern(arm.Pattern, engine.lookupStruct)
+		if err != nil {
+			return engine.objectError(err)
+		}
+
+		bound, ok := pattern.Match(value, nil)
+		if !ok {
+			continue
+		}
+
+		engine.PushStack()
+		for i, name := range ast.PatternBindings(arm.Pattern) {
+			engine.Variables = append(engine.Variables, &Variable{Name: name, Value: bound[i]})
+		}
+
+		if arm.Guard != nil {
+			guard := engine.Eval(arm.Guard)
+			if isError(guard) {
+				engine.PopStack()
+				return guard
+			}
+
+			condition, ok := guard.(*object.Boolean)
+			if !ok {
+				engine.PopStack()
+				return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Non boolean type (%s) was returned for match guard", guard.Type()))
+			}
+
+			if !condition.Value {
+				engine.PopStack()
+				continue
+			}
+		}
+
+		result := engine.EvalStatements(arm.Body)
+		engine.PopStack()
+
+		return result
+	}
+
+	return engine.createError(object.MATCH_ERROR, fmt.Sprintf("No arm of match matches value %s", value.Inspect()))
+}
+
 func (engine *ExecutionEngine) EvalPrefixExpression(prefix *ast.PrefixExpression) object.Object {
 	value := engine.Eval(prefix.Right)
+	if isError(value) {
+		return value
+	}
+
 	valueType := value.Type()
 
 	if valueType == object.BOOLEAN_OBJ {
@@ -413,7 +1468,7 @@ func (engine *ExecutionEngine) EvalPrefixExpression(prefix *ast.PrefixExpression
 		return engine.EvalIntegerPrefixOperations(value.(*object.Integer), prefix.Operator)
 	}
 
-	return engine.createError(
+	return engine.createError(object.TYPE_ERROR,
 		fmt.Sprintf("Not supported prefix operator (%s) was used for type %s", prefix.Operator, valueType),
 	)
 }
@@ -424,7 +1479,7 @@ func (engine *ExecutionEngine) EvalBooleanPrefixOperations(val *object.Boolean,
 		return &object.Boolean{Value: !val.Value}
 	}
 
-	return engine.createError(fmt.Sprintf("Not supported prefix operator (%s) was used for boolean", operator))
+	return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Not supported prefix operator (%s) was used for boolean", operator))
 }
 
 func (engine *ExecutionEngine) EvalIntegerPrefixOperations(val *object.Integer, operator string) object.Object {
@@ -433,17 +1488,17 @@ func (engine *ExecutionEngine) EvalIntegerPrefixOperations(val *object.Integer,
 		return &object.Integer{Value: -1 * val.Value}
 	}
 
-	return engine.createError(fmt.Sprintf("Not supported prefix operator (%s) was used for integer", operator))
+	return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Not supported prefix operator (%s) was used for integer", operator))
 }
 
 func (engine *ExecutionEngine) EvalInfixExpression(infix *ast.InfixExpression) object.Object {
 	left := engine.Eval(infix.Left)
-	if engine.HasError {
+	if isError(left) {
 		return left
 	}
 
 	right := engine.Eval(infix.Right)
-	if engine.HasError {
+	if isError(right) {
 		return right
 	}
 
@@ -466,7 +1521,7 @@ func (engine *ExecutionEngine) EvalInfixExpression(infix *ast.InfixExpression) o
 			return &object.String{Value: fmt.Sprintf("%v%s", intVal.Value, strVal.Value)}
 		}
 
-		return engine.createError(
+		return engine.createError(object.TYPE_ERROR,
 			fmt.Sprintf("Left and right variable share not the same type(%s and %s)", leftType, rightType),
 		)
 	}
@@ -479,8 +1534,11 @@ func (engine *ExecutionEngine) EvalInfixExpression(infix *ast.InfixExpression) o
 	if leftType == object.STRING_OBJ {
 		return engine.EvalStringInfixOperations(left.(*object.String), right.(*object.String), operator)
 	}
+	if leftType == object.INSTANCE_OBJ {
+		return engine.EvalInstanceInfixOperations(left.(*object.Instance), right.(*object.Instance), operator)
+	}
 
-	return engine.createError(fmt.Sprintf("Not supported infix operator (%s) was used for type %s", operator, leftType))
+	return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Not supported infix operator (%s) was used for type %s", operator, leftType))
 }
 
 func (engine *ExecutionEngine) EvalIntegerInfixOperations(left *object.Integer, right *object.Integer, operator string) object.Object {
@@ -505,9 +1563,15 @@ func (engine *ExecutionEngine) EvalIntegerInfixOperations(left *object.Integer,
 		return &object.Integer{Value: left.Value * right.Value}
 	case token.SLASH:
 		return &object.Integer{Value: left.Value / right.Value}
+
+	// ranges are evaluated lazily by the code iterating them
+	case token.RANGE:
+		return &object.Range{Start: left.Value, End: right.Value}
+	case token.RANGE_INCLUSIVE:
+		return &object.Range{Start: left.Value, End: right.Value, Inclusive: true}
 	}
 
-	return engine.createError(fmt.Sprintf("Not supported infix operator (%s) was used for integers", operator))
+	return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Not supported infix operator (%s) was used for integers", operator))
 }
 
 func (engine *ExecutionEngine) EvalStringInfixOperations(left *object.String, right *object.String, operator string) object.Object {
@@ -518,80 +1582,143 @@ func (engine *ExecutionEngine) EvalStringInfixOperations(left *object.String, ri
 	//	return &object.Boolean{Value: left.Value < right.Value}
 	//case token.GT:
 	//	return &object.Boolean{Value: left.Value > right.Value}
-	//case token.EQ:
-	//	return &object.Boolean{Value: left.Value == right.Value}
-	//case token.NOT_EQ:
-	//	return &object.Boolean{Value: left.Value != right.Value}
+	case token.EQ:
+		return &object.Boolean{Value: left.Value == right.Value}
+	case token.NOT_EQ:
+		return &object.Boolean{Value: left.Value != right.Value}
 
 	case token.PLUS:
+		if err := engine.Limits.Allocate(engine.usage, 0); err != nil {
+			return engine.objectError(err)
+		}
+
 		return &object.String{Value: left.Value + right.Value}
 	}
 
-	return engine.createError(fmt.Sprintf("Not supported infix operator (%s) was used for integers", operator))
+	return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Not supported infix operator (%s) was used for strings", operator))
 }
 
-func (engine *ExecutionEngine) EvalIndexAccessExpression(indexAccess *ast.IndexAccessExpression) object.Object {
+func (engine *ExecutionEngine) EvalInstanceInfixOperations(left *object.Instance, right *object.Instance, operator string) object.Object {
+
+	switch operator {
+	case token.EQ:
+		return &object.Boolean{Value: object.Equal(left, right)}
+	case token.NOT_EQ:
+		return &object.Boolean{Value: !object.Equal(left, right)}
+	case token.LT, token.GT:
+		if !left.Struct.Implements(object.COMPARABLE_TRAIT) {
+			return engine.createError(object.TYPE_ERROR,
+				fmt.Sprintf("Struct %s does not implement trait %s", left.Struct.Name, object.COMPARABLE_TRAIT),
+			)
+		}
+
+		result := engine.invokeMethod("compare", left, right)
+		if result.Type() == object.ERROR_OBJ {
+			return result
+		}
+
+		comparison, ok := result.(*object.Integer)
+		if !ok {
+			return engine.createError(object.TYPE_ERROR,
+				fmt.Sprintf("Method compare of struct %s has to return an integer but returned %s", left.Struct.Name, result.Type()),
+			)
+		}
 
-	indexExpr := engine.Eval(indexAccess.Value)
+		if operator == token.LT {
+			return &object.Boolean{Value: comparison.Value < 0}
+		}
 
-	if indexExpr.Type() == object.ERROR_OBJ {
-		return indexExpr
+		return &object.Boolean{Value: comparison.Value > 0}
 	}
 
-	if indexExpr.Type() != object.INTEGER_OBJ {
-		return engine.createError(fmt.Sprintf("Index type has to be integer but is %s", indexExpr.Type()))
+	return engine.createError(object.TYPE_ERROR, fmt.Sprintf("Not supported infix operator (%s) was used for struct instances", operator))
+}
+
+func (engine *ExecutionEngine) EvalIndexAccessExpression(indexAccess *ast.IndexAccessExpression) object.Object {
+	source := engine.Eval(indexAccess.Source)
+	if isError(source) {
+		return source
 	}
 
-	sourceExpr := engine.Eval(indexAccess.Source)
+	if !indexAccess.Slice {
+		index := engine.Eval(indexAccess.Value)
+		if isError(index) {
+			return index
+		}
 
-	if sourceExpr.Type() == object.ERROR_OBJ {
-		return sourceExpr
+		value, err := object.Index(source, index)
+		if err != nil {
+			return engine.objectError(err)
+		}
+
+		return value
 	}
 
-	if sourceExpr.Type() != object.LIST_OBJ {
-		return engine.createError(fmt.Sprintf("Source type has to be list but is %s", sourceExpr.Type()))
+	// omitted bounds stay nil
+	bounds := make([]object.Object, 2)
+	for i, bound := range []ast.Expression{indexAccess.Value, indexAccess.End} {
+		if bound == nil {
+			continue
+		}
+
+		bounds[i] = engine.Eval(bound)
+		if isError(bounds[i]) {
+			return bounds[i]
+		}
 	}
 
-	indexObj := indexExpr.(*object.Integer)
-	sourceList := sourceExpr.(*object.List)
+	value, err := object.Slice(source, bounds[0], bounds[1])
+	if err != nil {
+		return engine.objectError(err)
+	}
 
-	if int(indexObj.Value) >= len(sourceList.Value) {
-		return engine.createError(
-			fmt.Sprintf("List is too small (%v) for index %v", len(sourceList.Value), indexObj.Value),
-		)
+	if list, ok := value.(*object.List); ok {
+		if err := engine.Limits.Allocate(engine.usage, len(list.Value)); err != nil {
+			return engine.objectError(err)
+		}
 	}
 
-	return sourceList.Value[indexObj.Value]
+	return value
 }
 
-func (engine *ExecutionEngine) IndexStandardLibrary(path string, modulePrefix string) error {
-	entries, err := os.ReadDir(path)
+// IndexStandardLibrary registers the packages of the standard library module in the directory, they are loaded by
+// their first import
+func (engine *ExecutionEngine) IndexStandardLibrary(path string) error {
+	module := NewModule(engine.StandardLibraryModule)
+
+	err := modfile.WalkSources(path, func(file string, rel string) error {
+		pkg := NewPackage(filepath.Base(file))
+		pkg.Path = file
+		module.Packages[strings.TrimSuffix(rel, ".curry")] = pkg
+		return nil
+	})
 
 	if err != nil {
 		return err
 	}
 
-	module := NewModule(engine.StandardLibraryModule)
+	engine.Modules[engine.StandardLibraryModule] = module
 
-	for _, entry := range entries {
-		pkgName := strings.Replace(entry.Name(), ".curry", "", 1)
-		p := path + "/" + pkgName
-		if entry.IsDir() {
-			err := engine.IndexStandardLibrary(p, modulePrefix)
-			if err != nil {
-				return err
-			}
-		} else {
-			module.Packages[pkgName] = NewPackage(entry.Name())
-		}
+	return nil
+}
+
+// createError raises an error at the position of the currently evaluated node
+func (engine *ExecutionEngine) createError(kind object.ErrorKind, message string) *object.Error {
+	err := &object.Error{Kind: kind, Message: message}
+
+	if engine.node != nil {
+		err.Span = object.SpanOf(engine.File, engine.node.Pos())
 	}
 
-	engine.Modules[engine.StandardLibraryModule] = module
+	return err
+}
 
-	return nil
+// objectError raises an error of the object package, like an exceeded limit,
+// at the position of the currently evaluated node
+func (engine *ExecutionEngine) objectError(err *object.Error) *object.Error {
+	return engine.createError(err.Kind, strings.ToUpper(err.Message[:1])+err.Message[1:])
 }
 
-func (engine *ExecutionEngine) createError(message string) *object.Error {
-	engine.HasError = true
-	return &object.Error{Message: message}
+func isError(value object.Object) bool {
+	return value != nil && value.Type() == object.ERROR_OBJ
 }
@@ -1,11 +1,19 @@
 package evaluator
 
 import (
+	"context"
 	"curryLang/ast"
 	"curryLang/lexer"
 	"curryLang/object"
 	"curryLang/parser"
+	"curryLang/token"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestEvalIntegerExpression(t *testing.T) {
@@ -158,6 +166,13 @@ func TestEvalListIndexExpression(t *testing.T) {
 		expected int64
 	}{
 		{"[10, 20][1]", 20},
+		{"[10, 20][-1]", 20},
+		{"[10, 20][-2]", 10},
+		{"let l = [1, 2, 3]; l[l[0] + 1]", 3},
+		{"[1, 2, 3, 4][1:3][1]", 3},
+		{"[1, 2, 3, 4][:2][-1]", 2},
+		{"[1, 2, 3, 4][-2:][0]", 3},
+		{"[1, 2, 3, 4][:][3]", 4},
 	}
 	for _, tt := range tests {
 		result := testEval(tt.input)
@@ -173,6 +188,64 @@ func TestEvalListIndexExpression(t *testing.T) {
 	}
 }
 
+func TestEvalStringIndexExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"hello"[1]`, "e"},
+		{`"hello"[-1]`, "o"},
+		{`"hello"[:2]`, "he"},
+		{`"hello"[3:]`, "lo"},
+		{`"hello"[1:1]`, ""},
+		{`"häll😀"[1]`, "ä"},
+		{`"häll😀"[-1]`, "😀"},
+		{`"häll😀"[1:4]`, "äll"},
+	}
+	for _, tt := range tests {
+		result := testEval(tt.input)
+
+		str, ok := result.(*object.String)
+		if !ok || str.Value != tt.expected {
+			t.Errorf("wrong result for %s. want=%q, got=%s", tt.input, tt.expected, result.Inspect())
+		}
+	}
+}
+
+func TestEvalIndexErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+	}{
+		{"[1, 2][2];", object.INDEX_ERROR, "Index 2 out of range for length 2"},
+		{"[1, 2][-3];", object.INDEX_ERROR, "Index -3 out of range for length 2"},
+		{"[][0];", object.INDEX_ERROR, "Index 0 out of range for length 0"},
+		{`"äb"[2];`, object.INDEX_ERROR, "Index 2 out of range for length 2"},
+		{"[1, 2][1:3];", object.INDEX_ERROR, "Slice bounds [1:3] out of range for length 2"},
+		{"[1, 2][2:1];", object.INDEX_ERROR, "Slice bounds [2:1] out of range for length 2"},
+		{"[1, 2][-3:];", object.INDEX_ERROR, "Slice bounds [-3:2] out of range for length 2"},
+		{"[1, 2][true];", object.TYPE_ERROR, "Index has to be an integer but is BOOLEAN"},
+		{`[1, 2]["a":];`, object.TYPE_ERROR, "Slice bounds have to be integers but got STRING"},
+		{"5[0];", object.TYPE_ERROR, "Values of type INTEGER can not be indexed"},
+		{"(0..3)[0:1];", object.TYPE_ERROR, "Values of type RANGE can not be sliced"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if err.Kind != tt.kind || err.Message != tt.message {
+			t.Errorf("wrong error for %q. want=%s: %s, got=%s: %s", tt.input, tt.kind, tt.message, err.Kind, err.Message)
+		}
+	}
+}
+
 func TestEvalIfExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -199,6 +272,65 @@ func TestEvalLetStatement(t *testing.T) {
 	}
 }
 
+func TestEvalDestructuring(t *testing.T) {
+	structs := "struct Point { x, y } struct Person { name, age } "
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let [a, b] = [1, 2]; a * 10 + b;", 12},
+		{"let [first, ...rest] = [1, 2, 3]; first + rest[0] + rest[1];", 6},
+		{"let [_, second, ...] = [1, 2, 3]; second;", 2},
+		{"let [[a, b], [c]] = [[1, 2], [3]]; a + b + c;", 6},
+		{structs + "let {age} = Person{name: \"a\", age: 7}; age;", 7},
+		{structs + "let {x} = Point{x: 3, y: 4}; x;", 3},
+		{structs + "let Point{x, y: [y]} = Point{x: 3, y: [4]}; x + y;", 7},
+		{"fn add([a, b]) { a + b } add([3, 4]);", 7},
+		{structs + "fn f(Point{x, y}, k) { x * k + y } f(Point{x: 2, y: 1}, 10);", 21},
+		{"let a = 1; fn f([a]) { a } f([5]) + a;", 6},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestEvalDestructuringErrors(t *testing.T) {
+	structs := "struct Point { x, y } struct Person { name } "
+
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+	}{
+		{"let [a, b] = [1];", object.MATCH_ERROR, "List of length 1 can not be destructured into [a, b]"},
+		{"let [a, b, ...rest] = [1];", object.MATCH_ERROR, "List of length 1 can not be destructured into [a, b, ...]"},
+		{"let [a] = 5;", object.TYPE_ERROR, "Values of type INTEGER can not be destructured into [a]"},
+		{"let [1, a] = [2, 3];", object.MATCH_ERROR, "Value 2 does not match 1"},
+		{"let [[a]] = [[1, 2]];", object.MATCH_ERROR, "List of length 2 can not be destructured into [a]"},
+		{structs + "let {age} = Person{name: \"a\"};", object.FIELD_ERROR, "Struct Person has no field age"},
+		{structs + "let Point{x} = Person{name: \"a\"};", object.TYPE_ERROR, "Instance of Person can not be destructured into Point{x}"},
+		{structs + "let Point{z} = Point{x: 1, y: 2};", object.FIELD_ERROR, "Struct Point has no field z"},
+		{"let Line{a} = 1;", object.NAME_ERROR, "Undeclared struct Line used"},
+		{structs + "fn f(Point{x}) { x } f(5);", object.TYPE_ERROR, "Values of type INTEGER can not be destructured into Point{x}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if err.Kind != tt.kind || err.Message != tt.message {
+			t.Errorf("wrong error for %q. want=%s: %s, got=%s: %s", tt.input, tt.kind, tt.message, err.Kind, err.Message)
+		}
+	}
+}
+
 func TestEvalFunctionCall(t *testing.T) {
 	result := testEval("fn foo() { 3; }; foo();")
 
@@ -292,6 +424,104 @@ func TestEvalFunctionCallWithParameters(t *testing.T) {
 	}
 }
 
+func TestEvalFunctionArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"fn f(a, b = 2) { a * 10 + b } f(1);", 12},
+		{"fn f(a, b = 2) { a * 10 + b } f(1, 3);", 13},
+		{"fn f(a, b = a * 2) { a * 10 + b } f(3);", 36},
+		{"fn f(a, b) { a * 10 + b } f(b: 1, a: 2);", 21},
+		{"fn f(a, b = 2, c = 3) { a * 100 + b * 10 + c } f(1, c: 5);", 125},
+		{"fn sum(...values) { let total = 0; for v in values { total = total + v; } total } sum(1, 2, 3);", 6},
+		{"fn sum(...values) { let total = 0; for v in values { total = total + v; } total } sum();", 0},
+		{"fn f(a, ...rest) { a + rest[-1] } f(1, 2, 3);", 4},
+		{"fn f([a, b] = [1, 2]) { a + b } f() + f([3, 4]);", 10},
+		{"struct Counter { n } impl Counter { fn add(self, by = 1) { self.n + by } } let c = Counter{n: 5}; c.add() + c.add(by: 10);", 21},
+		{"fn f(a, b = 2) { a + b } let x = 0; spawn f(1); x;", 0},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestEvalFunctionArgumentErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+	}{
+		{"fn f(a, b) { a } f(1);", object.ARGUMENT_ERROR, "Wrong number of arguments for f: want=2, got=1"},
+		{"fn f(a) { a } f(1, 2);", object.ARGUMENT_ERROR, "Wrong number of arguments for f: want=1, got=2"},
+		{"fn f(a, b = 1) { a } f(1, 2, 3);", object.ARGUMENT_ERROR, "Wrong number of arguments for f: want=1..2, got=3"},
+		{"fn f(a, ...rest) { a } f();", object.ARGUMENT_ERROR, "Wrong number of arguments for f: want=at least 1, got=0"},
+		{"fn f(a, b) { a } f(b: 1);", object.ARGUMENT_ERROR, "Missing argument a for f"},
+		{"fn f(a) { a } f(c: 1);", object.ARGUMENT_ERROR, "Function f has no parameter c"},
+		{"fn f(a) { a } f(1, a: 1);", object.ARGUMENT_ERROR, "Argument a of f is passed twice"},
+		{"fn f(...rest) { 1 } f(rest: 1);", object.ARGUMENT_ERROR, "Variadic parameter rest of f can not be passed by name"},
+		{"fn f(...rest) { 1 } f(1, true);", object.TYPE_ERROR, "Variadic arguments of f have to be all of the same type, argument #1 has type BOOLEAN instead of INTEGER"},
+		{"fn f(a = missing) { a } f();", object.NAME_ERROR, "Undeclared variable missing used"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if err.Kind != tt.kind || err.Message != tt.message {
+			t.Errorf("wrong error for %q. want=%s: %s, got=%s: %s", tt.input, tt.kind, tt.message, err.Kind, err.Message)
+		}
+	}
+}
+
+func TestEvalPipelines(t *testing.T) {
+	helpers := "fn map(list, f) { for x in list { yield f(x); } } fn sum(values) { let total = 0; for v in values { total = total + v; } total } fn add(a, b) { a + b } "
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{helpers + "let xs = [1, 2, 3]; xs |> sum;", 6},
+		{helpers + "let xs = [1, 2, 3]; xs |> map(|x| x * 2) |> sum() |> add(1);", 13},
+		{helpers + "let double = x => x * 2; [1, 2] |> map(double) |> sum;", 6},
+		{helpers + "let pairs = [[1, 2], [3, 4]]; pairs |> map(|[a, b]| a * b) |> sum;", 14},
+		{helpers + "5 |> |x| { return x + 1; };", 6},
+		{helpers + "let offset = 10; [1, 2] |> map(x => x + offset) |> sum;", 23},
+		{"let f = || 7; f();", 7},
+		// the builtins split, map, filter, sum and toInt
+		{`let data = "1\n2\n3"; data |> split("\n") |> map(toInt) |> sum();`, 6},
+		{`"4,5,6" |> split(",") |> map(toInt) |> filter(|x| x > 4) |> map(x => x * 2) |> sum;`, 22},
+		{"[] |> sum();", 0},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestEvalRecursiveFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"fn fib(n) { if (n < 2) { return n; } return fib(n - 1) + fib(n - 2); } fib(10)", 55},
+		{"let n = 100; fn double(n) { n + n } double(2) + n", 104},
+		{"fn f() { let x = 1; x } let a = 2; fn g() { f(); a } g()", 2},
+		{"fn sum(n) { let i = 0; let total = 0; while (i < n) { i = i + 1; total = total + i; } total } sum(4)", 10},
+		{"fn first() { let i = 0; while (true) { i = i + 1; if (i > 2) { return i; } } } first()", 3},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestEvalIdentifierExpression(t *testing.T) {
 	l := lexer.New("let foo = 3;foo;")
 	p := parser.New(l)
@@ -345,8 +575,8 @@ func TestEvalModulePackageFunction(t *testing.T) {
 	module := NewModule("foo")
 
 	pkg := NewPackage("foo")
-	pkg.Functions["bar"] = &object.Function{
-		Name:       "bar",
+	pkg.Functions["Bar"] = &object.Function{
+		Name:       "Bar",
 		Parameters: []ast.Parameter{},
 		Code:       functionCode,
 	}
@@ -356,7 +586,7 @@ func TestEvalModulePackageFunction(t *testing.T) {
 	l := lexer.New(`
 	import "foo";
 
-	foo.bar();
+	foo.Bar();
 `)
 	p := parser.New(l)
 	program := p.ParseProgram()
@@ -385,6 +615,234 @@ func TestEvalModulePackageFunction(t *testing.T) {
 	}
 }
 
+func TestEvalStructs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"struct Point { x, y }; let p = Point{x: 1, y: 2}; p.x;", 1},
+		{"struct Point { x, y }; let p = Point{y: 2, x: 1}; p.y;", 2},
+		{"struct Point { x, y }; let p = Point{x: 1, y: 2}; p.x = 5; p.x + p.y;", 7},
+		{"struct Point { x, y }; struct Line { from, to }; let l = Line{from: Point{x: 1, y: 2}, to: Point{x: 3, y: 4}}; l.to.y;", 4},
+		{"struct Point { x, y }; Point{x: 1, y: 2} == Point{x: 1, y: 2};", true},
+		{"struct Point { x, y }; Point{x: 1, y: 2} == Point{x: 1, y: 3};", false},
+		{"struct Point { x, y }; Point{x: 1, y: 2} != Point{x: 1, y: 3};", true},
+		{"struct Point { x, y }; struct Vector { x, y }; Point{x: 1, y: 2} == Vector{x: 1, y: 2};", false},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalStructInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"struct Point { x, y }; Point{x: 1, y: 2};", "Point{x: 1, y: 2}"},
+		{"struct Point { x, y }; Point{y: \"foo\"};", "Point{x: null, y: foo}"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("evaluated.Inspect() wrong. want=%q, got=%q", tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestEvalTraits(t *testing.T) {
+	shapes := `
+	struct Circle { r }
+	struct Square { side }
+
+	trait Shape {
+		fn area(self)
+		fn describe(self) {
+			return "area" + self.area();
+		}
+	}
+
+	impl Shape for Circle {
+		fn area(self) { self.r * self.r * 3 }
+	}
+
+	impl Shape for Square {
+		fn area(self) { self.side * self.side }
+		fn describe(self) { "square" }
+	}
+
+	impl Circle {
+		fn grow(self, by) {
+			self.r = self.r + by;
+			return self;
+		}
+	}
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"Circle{r: 2}.area();", 12},
+		{"Square{side: 3}.area();", 9},
+		{"Circle{r: 2}.describe();", "area12"},
+		{"Square{side: 3}.describe();", "square"},
+		{"let c = Circle{r: 1}; c.grow(1); c.r;", 2},
+		{"Circle{r: 1}.grow(2).area();", 27},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(shapes + tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalBuiltinTraits(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`
+		struct Point { x, y }
+		impl Stringer for Point {
+			fn string(self) { "P(" + self.x + "," + self.y + ")" }
+		}
+		Point{x: 1, y: 2};
+		`, "P(1,2)"},
+		{`
+		struct Version { major }
+		impl Comparable for Version {
+			fn compare(self, other) { self.major - other.major }
+		}
+		Version{major: 1} < Version{major: 2};
+		`, true},
+		{`
+		struct Version { major }
+		impl Comparable for Version {
+			fn compare(self, other) { self.major - other.major }
+		}
+		Version{major: 1} > Version{major: 2};
+		`, false},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("evaluated.Inspect() wrong. want=%q, got=%q", expected, evaluated.Inspect())
+			}
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalTraitErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"struct Circle { r }; trait Shape { fn area(self) }; impl Shape for Circle { fn perimeter(self) { 1 } }",
+			"Struct Circle does not implement method area of trait Shape",
+		},
+		{
+			"struct Circle { r }; trait Shape { fn area(self) }; impl Shape for Circle { fn area(self, x) { 1 } }",
+			"Method area of trait Shape expects 1 parameters but got 2",
+		},
+		{
+			"struct Circle { r }; impl Shape for Circle { fn area(self) { 1 } }",
+			"Undeclared trait Shape used",
+		},
+		{
+			"impl Circle { fn area(self) { 1 } }",
+			"Undeclared struct Circle used",
+		},
+		{
+			"struct Circle { r }; impl Circle { fn area() { 1 } }",
+			"Method area of struct Circle has to take self as first parameter",
+		},
+		{
+			"struct Circle { r }; Circle{r: 1}.area();",
+			"Struct Circle has no method area",
+		},
+		{
+			"struct Circle { r }; Circle{r: 1} < Circle{r: 2};",
+			"Struct Circle does not implement trait Comparable",
+		},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if err.Message != tt.expected {
+			t.Errorf("wrong error message. want=%q, got=%q", tt.expected, err.Message)
+		}
+	}
+}
+
+func TestEvalBuiltins(t *testing.T) {
+	var positions []token.Token
+	double := &object.Builtin{
+		Name: "double",
+		Function: func(position token.Token, args ...object.Object) object.Object {
+			positions = append(positions, position)
+			if len(args) != 1 {
+				return &object.Error{Message: "double expects 1 argument"}
+			}
+
+			return &object.Integer{Value: args[0].(*object.Integer).Value * 2}
+		},
+	}
+
+	helper := &object.Struct{Name: "Helper", Fields: []string{"double"}}
+
+	tests := []struct {
+		input    string
+		expected object.Object
+	}{
+		{"double(21)", &object.Integer{Value: 42}},
+		{"\n  double(double(1))", &object.Integer{Value: 4}},
+		{"helper.double(5)", &object.Integer{Value: 10}},
+		{"double(); 1", &object.Error{Message: "double expects 1 argument"}},
+	}
+
+	for _, tt := range tests {
+		engine := NewEngine()
+		engine.Variables = append(
+			engine.Variables,
+			&Variable{Name: "double", Value: double},
+			&Variable{Name: "helper", Value: &object.Instance{Struct: helper, Fields: map[string]object.Object{"double": double}}},
+		)
+
+		evaluated := engine.Eval(parser.New(lexer.New(tt.input)).ParseProgram())
+		testObject(t, evaluated, tt.expected)
+	}
+
+	// the inner call is evaluated first and the token of a call is its opening parenthesis
+	if positions[1].Line != 2 || positions[1].Column != 16 || positions[2].Column != 9 {
+		t.Errorf("builtins got wrong positions. got=%+v", positions[1:3])
+	}
+}
+
 func TestEvalErrors(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -395,6 +853,11 @@ func TestEvalErrors(t *testing.T) {
 		{"if(true) {foo};", &object.Error{Message: "Undeclared variable foo used"}},
 		{"fn() { foo; } ()", &object.Error{Message: "Undeclared variable foo used"}},
 		{"fn() { foo; return 1; } ()", &object.Error{Message: "Undeclared variable foo used"}},
+		{"Point{x: 1};", &object.Error{Message: "Undeclared struct Point used"}},
+		{"struct Point { x, y }; Point{z: 1};", &object.Error{Message: "Struct Point has no field z"}},
+		{"struct Point { x, y }; let p = Point{}; p.z;", &object.Error{Message: "Struct Point has no field z"}},
+		{"struct Point { x, y }; let p = Point{}; p.z = 1;", &object.Error{Message: "Struct Point has no field z"}},
+		{"let x = foo; 1;", &object.Error{Message: "Undeclared variable foo used"}},
 		{`
 			fn test() {
 				let x = fn() {
@@ -417,6 +880,782 @@ func TestEvalErrors(t *testing.T) {
 	}
 }
 
+func TestEvalErrorTraceback(t *testing.T) {
+	input := `fn inner(x) {
+    return x + missing;
+}
+
+fn outer() {
+    return inner(1);
+}
+
+outer();`
+
+	engine := NewEngine()
+	engine.File = "main.curry"
+
+	evaluated := engine.Eval(parser.New(lexer.New(input)).ParseProgram())
+
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if err.Kind != object.NAME_ERROR {
+		t.Errorf("wrong error kind. want=%s, got=%s", object.NAME_ERROR, err.Kind)
+	}
+
+	expectedSpan := object.Span{File: "main.curry", Line: 2, Column: 16, Length: 7}
+	if err.Span != expectedSpan {
+		t.Errorf("wrong span. want=%+v, got=%+v", expectedSpan, err.Span)
+	}
+
+	expected := `Traceback (most recent call last):
+  main.curry:9:1 in <main>
+  main.curry:6:12 in outer
+  main.curry:2:16 in inner
+NameError: Undeclared variable missing used`
+
+	if err.Traceback() != expected {
+		t.Errorf("wrong traceback.\nwant=%s\ngot=%s", expected, err.Traceback())
+	}
+
+	// an error must not affect the following programs
+	evaluated = engine.Eval(parser.New(lexer.New("1 + 2;")).ParseProgram())
+	testIntegerObject(t, evaluated, 3)
+}
+
+func TestEvalErrorKinds(t *testing.T) {
+	tests := []struct {
+		input string
+		kind  object.ErrorKind
+	}{
+		{"foo;", object.NAME_ERROR},
+		{"1 + true;", object.TYPE_ERROR},
+		{"[1, 2][5];", object.INDEX_ERROR},
+		{"struct Point { x }; Point{}.y;", object.FIELD_ERROR},
+		{"struct Point { x, x };", object.DECLARATION_ERROR},
+		{`import "unknown/pkg";`, object.IMPORT_ERROR},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if err.Kind != tt.kind {
+			t.Errorf("wrong error kind for %q. want=%s, got=%s", tt.input, tt.kind, err.Kind)
+		}
+	}
+}
+
+func TestEvalTryCatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let x = 1; try { x = 2; } catch { x = 3; }; x;", 2},
+		{"let x = 1; try { missing; x = 2; } catch { x = 3; }; x;", 3},
+		{"let x = 0; try { throw 5; } catch (e) { x = e.value; }; x;", 5},
+		{`let x = ""; try { throw "boom"; } catch (e) { x = e.kind + e.message; }; x;`, "Errorboom"},
+		{`let x = ""; try { missing; } catch (e) { x = e.kind; }; x;`, "NameError"},
+		{`let x = ""; try { throw Error{kind: "Custom", message: "m"}; } catch (e) { x = e.kind; }; x;`, "Custom"},
+		{"fn f() { throw 1; } let x = 0; try { f(); x = 1; } catch { x = 2; }; x;", 2},
+		{"fn f() { try { return 1; } catch { return 2; } } f();", 1},
+		{"fn f() { try { throw 1; } catch { return 2; } } f();", 2},
+		{`
+			let x = 0;
+			try {
+				try {
+					throw 1;
+				} catch (e) {
+					x = x + 1;
+					throw e;
+				}
+			} catch (e) {
+				x = x + e.value;
+			}
+			x;
+		`, 2},
+		{"try { throw 1; } catch { }; 3;", 3},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+
+			if str.Value != expected {
+				t.Errorf("wrong value for %q. want=%q, got=%q", tt.input, expected, str.Value)
+			}
+		}
+	}
+}
+
+func TestEvalUncaughtThrow(t *testing.T) {
+	evaluated := testEval("fn f() {\n  throw 42;\n}\nf();")
+
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if err.Kind != object.THROWN_ERROR || err.Message != "42" {
+		t.Errorf("wrong error. got=%s: %s", err.Kind, err.Message)
+	}
+
+	if err.Span.Line != 2 || len(err.Stack) != 1 || err.Stack[0].Function != "f" {
+		t.Errorf("wrong position of thrown error. got=%+v, stack=%+v", err.Span, err.Stack)
+	}
+}
+
+func TestEvalReadFile(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "os.curry"), []byte("package os"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine()
+	engine.StandardLibraryModule = "internal"
+	err = engine.IndexStandardLibrary(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := `
+	import "internal/os";
+
+	let content = "";
+	try {
+		content = os.ReadFile("` + filepath.Join(dir, "missing.txt") + `");
+	} catch (e) {
+		content = e.kind;
+	}
+	content;
+`
+
+	evaluated := engine.Eval(parser.New(lexer.New(input)).ParseProgram())
+
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != string(object.IO_ERROR) {
+		t.Errorf("wrong error kind. want=%s, got=%s", object.IO_ERROR, str.Value)
+	}
+}
+
+func TestEvalLimits(t *testing.T) {
+	tests := []struct {
+		limits object.Limits
+		input  string
+		kind   object.ErrorKind
+	}{
+		{object.Limits{MaxSteps: 1000}, "while (true) { }", object.STEP_LIMIT_ERROR},
+		{object.Limits{MaxSteps: 1000}, "let x = 0; while (true) { try { x = x + 1; } catch { } }", object.STEP_LIMIT_ERROR},
+		{object.Limits{MaxCallDepth: 10}, "fn f(n) { return f(n + 1); } f(0);", object.CALL_DEPTH_ERROR},
+		{object.Limits{MaxCallDepth: DefaultMaxCallDepth}, "fn f(n) { return f(n + 1); } f(0);", object.CALL_DEPTH_ERROR},
+		{object.Limits{MaxAllocations: 10}, "struct P { x }; while (true) { P{x: 1}; }", object.ALLOCATION_LIMIT_ERROR},
+		{object.Limits{MaxAllocations: 10}, `let s = ""; while (true) { s = s + "a"; }`, object.ALLOCATION_LIMIT_ERROR},
+		{object.Limits{MaxListSize: 2}, "[1, 2, 3];", object.ALLOCATION_LIMIT_ERROR},
+	}
+
+	for _, tt := range tests {
+		engine := NewEngine()
+		engine.Limits = tt.limits
+
+		evaluated := engine.Eval(parser.New(lexer.New(tt.input)).ParseProgram())
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if err.Kind != tt.kind {
+			t.Errorf("wrong error kind for %q. want=%s, got=%s (%s)", tt.input, tt.kind, err.Kind, err.Message)
+		}
+	}
+
+	// programs within the limits are not affected
+	engine := NewEngine()
+	engine.Limits = object.Limits{MaxSteps: 1000, MaxCallDepth: 10, MaxAllocations: 10, MaxListSize: 3}
+	testIntegerObject(t, engine.Eval(parser.New(lexer.New("fn f(n) { return n * 2; }; let l = [1, 2, 3]; f(21);")).ParseProgram()), 42)
+}
+
+func TestEvalStackOverflow(t *testing.T) {
+	tests := []string{
+		"fn f(n) { return f(n + 1); } f(0);",
+		"fn f(n) { try { return f(n + 1); } catch { return 1; } } f(0);",
+	}
+
+	for _, input := range tests {
+		// the stack overflows without a call depth limit as well
+		engine := NewEngine()
+		engine.Limits = object.Limits{}
+
+		evaluated := engine.Eval(parser.New(lexer.New(input)).ParseProgram())
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+		}
+
+		if err.Kind != object.STACK_OVERFLOW_ERROR || err.Message != "stack overflow" {
+			t.Errorf("wrong error for %q. got=%s: %s", input, err.Kind, err.Message)
+		}
+	}
+}
+
+func TestEvalDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	engine := NewEngine()
+	engine.Limits.Context = ctx
+
+	evaluated := engine.Eval(parser.New(lexer.New("let x = 0; while (true) { x = x + 1; }")).ParseProgram())
+
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if err.Kind != object.DEADLINE_ERROR || err.Message != "Execution stopped: context deadline exceeded" {
+		t.Errorf("wrong error. got=%s: %s", err.Kind, err.Message)
+	}
+}
+
+func TestEvalChannels(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let ch = channel(); spawn fn() { ch.send(5); }; ch.receive();", 5},
+		{"let ch = channel(2); ch.send(1); ch.send(2); ch.receive() + ch.receive() * 10;", 21},
+		{"let ch = channel(1); ch.send(3); ch.close(); ch.receive();", 3},
+		{"channel(3).capacity;", 3},
+		{"let ch = channel(2); ch.send(1); ch.length;", 1},
+		// the arguments of a spawned call are evaluated when the task is spawned
+		{"let ch = channel(); fn double(n) { ch.send(n * 2); }; let x = 4; spawn double(x); x = 10; ch.receive();", 8},
+		// tasks share the variables visible where they are spawned
+		{"let x = 1; let ch = channel(); spawn fn() { x = 2; ch.send(x); }; let y = ch.receive(); x + y * 10;", 22},
+		{"let counter = 0; let done = channel(); spawn fn() { counter = counter + 1; done.send(1); }; done.receive(); counter", 1},
+		{`
+			let jobs = channel();
+			let results = channel();
+			spawn fn() {
+				let i = 1;
+				while (i < 4) {
+					jobs.send(i);
+					i = i + 1;
+				}
+				jobs.send(0);
+			};
+			spawn fn() {
+				let sum = 0;
+				let job = jobs.receive();
+				while (job > 0) {
+					sum = sum + job;
+					job = jobs.receive();
+				}
+				results.send(sum);
+			};
+			results.receive();
+		`, 6},
+		{"let ch = channel(); let x = 0; select { case v = ch.receive() { x = v; } default { x = 7; } } x;", 7},
+		{"let ch = channel(1); select { case ch.send(4) { } } ch.receive();", 4},
+		{`
+			let a = channel();
+			let b = channel();
+			spawn fn() { b.send(2); };
+			let x = 0;
+			select {
+				case v = a.receive() { x = v; }
+				case v = b.receive() { x = v * 10; }
+			}
+			x;
+		`, 20},
+		// the first case which can proceed is chosen
+		{`
+			let a = channel(1);
+			let b = channel(1);
+			a.send(1);
+			b.send(2);
+			let x = 0;
+			select {
+				case v = b.receive() { x = v; }
+				case v = a.receive() { x = v; }
+			}
+			x;
+		`, 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+
+	evaluated := testEval("let ch = channel(); ch.close(); ch.receive();")
+	if evaluated != NULL {
+		t.Errorf("receive from closed channel is not null. got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func TestEvalChannelErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+	}{
+		{"let ch = channel(); ch.receive();", object.DEADLOCK_ERROR, "all tasks are blocked on channels, deadlock"},
+		{"let ch = channel(); spawn fn() { ch.send(1); }; spawn fn() { ch.send(2); }; ch.receive(); channel().receive();", object.DEADLOCK_ERROR, "all tasks are blocked on channels, deadlock"},
+		{"let ch = channel(); try { ch.receive(); } catch { 1; };", object.DEADLOCK_ERROR, "all tasks are blocked on channels, deadlock"},
+		{"let ch = channel(1); ch.close(); ch.send(1);", object.CHANNEL_ERROR, "send on closed channel"},
+		{"let ch = channel(); ch.close(); ch.close();", object.CHANNEL_ERROR, "close of closed channel"},
+		{"let ch = channel(); spawn fn() { missing; }; ch.receive();", object.NAME_ERROR, "Undeclared variable missing used"},
+		{"spawn 5;", object.TYPE_ERROR, "Only functions can be spawned but got INTEGER"},
+		{"fn f(a) { } spawn f();", object.ARGUMENT_ERROR, "Wrong number of arguments for spawned f: want=1, got=0"},
+		{"select { case x = 5.receive() { } }", object.TYPE_ERROR, "Select cases have to use channels but got INTEGER"},
+		{"channel(true);", object.TYPE_ERROR, "channel expects an integer capacity but got BOOLEAN"},
+		{"channel(-1);", object.ARGUMENT_ERROR, "channel capacity can not be negative, got -1"},
+		{"channel().missing;", object.FIELD_ERROR, "channel has no field or method missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if err.Kind != tt.kind || err.Message != tt.message {
+			t.Errorf("wrong error for %q. want=%s: %s, got=%s: %s", tt.input, tt.kind, tt.message, err.Kind, err.Message)
+		}
+	}
+}
+
+func TestEvalSpawnedTaskTraceback(t *testing.T) {
+	input := `let ch = channel();
+spawn fn() {
+    missing;
+};
+ch.receive();`
+
+	engine := NewEngine()
+	engine.File = "main.curry"
+
+	evaluated := engine.Eval(parser.New(lexer.New(input)).ParseProgram())
+
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := `Traceback (most recent call last):
+  main.curry:2:1 in <main>
+  main.curry:3:5 in <anonymous>
+NameError: Undeclared variable missing used`
+
+	if err.Traceback() != expected {
+		t.Errorf("wrong traceback.\nwant=%s\ngot=%s", expected, err.Traceback())
+	}
+}
+
+func TestEvalGenerators(t *testing.T) {
+	count := "fn count(n) { let i = 0; while (i < n) { yield i; i = i + 1; } } "
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{count + "let sum = 0; for x in count(5) { sum = sum + x; }; sum;", 10},
+		{count + "let gen = count(2); let a = gen.next(); let b = gen.next(); gen.next(); a * 10 + b;", 1},
+		{count + `
+			let gen = count(1);
+			gen.next();
+			let before = 0;
+			if (gen.done) { before = 1; }
+			gen.next();
+			let after = 0;
+			if (gen.done) { after = 1; }
+			before * 10 + after;
+		`, 1},
+		// the function only runs while values are requested
+		{"let ch = channel(1); fn g() { ch.send(1); yield 2; } let gen = g(); let before = ch.length; gen.next(); before * 10 + ch.length;", 1},
+		{"fn from(n) { while (true) { yield n; n = n + 1; } } fn first(gen) { for x in gen { if (x > 2) { return x; } } } first(from(0));", 3},
+		{count + "fn double(gen) { for x in gen { yield x * 2; } } let sum = 0; for x in double(count(3)) { sum = sum + x; }; sum;", 6},
+		{`
+			fn bad() {
+				yield 1;
+				throw 5;
+			}
+			let sum = 0;
+			try {
+				for x in bad() {
+					sum = sum + x;
+				}
+			} catch (e) {
+				sum = sum + e.value * 10;
+			}
+			sum;
+		`, 51},
+		{"struct Range { to } impl Range { fn values(self) { let i = 0; while (i < self.to) { yield i; i = i + 1; } } } let r = Range{to: 4}; let sum = 0; for x in r.values() { sum = sum + x; }; sum;", 6},
+		// generators share the variables of their caller
+		{"let total = 0; fn g() { total = total + 1; yield total; total = total + 10; } for x in g() { }; total;", 11},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestEvalGeneratorsEnd(t *testing.T) {
+	engine := NewEngine()
+	before := runtime.NumGoroutine()
+
+	program := parser.New(lexer.New("fn from(n) { while (true) { yield n; n = n + 1; } } let gen = from(0); gen.next();")).ParseProgram()
+	for i := 0; i < 50; i++ {
+		testIntegerObject(t, engine.Eval(program), 0)
+		engine.Finish()
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutines of generators were not ended. before=%d, after=%d", before, after)
+	}
+
+	evaluated := engine.Eval(parser.New(lexer.New("gen.next();")).ParseProgram())
+
+	err, ok := evaluated.(*object.Error)
+	if !ok || err.Kind != object.GENERATOR_ERROR || err.Message != "Generator from was ended with the program it was created by" {
+		t.Errorf("wrong error for a generator of a finished program. got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func TestEvalGeneratorErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+	}{
+		{"yield 1;", object.GENERATOR_ERROR, "Yield can only be used inside of functions"},
+		{"for x in 5 { }", object.TYPE_ERROR, "Values of type INTEGER can not be iterated"},
+		{"fn g() { yield 1; missing; } for x in g() { }", object.NAME_ERROR, "Undeclared variable missing used"},
+		{"fn g() { yield 1; } g().missing;", object.FIELD_ERROR, "generator has no field or method missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if err.Kind != tt.kind || err.Message != tt.message {
+			t.Errorf("wrong error for %q. want=%s: %s, got=%s: %s", tt.input, tt.kind, tt.message, err.Kind, err.Message)
+		}
+	}
+}
+
+func TestEvalForInLoops(t *testing.T) {
+	countdown := "struct Countdown { n } impl Iterator for Countdown { fn next(self) { if (self.n > 0) { self.n = self.n - 1; return self.n + 1; } } } "
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let sum = 0; for x in [1, 2, 3] { sum = sum + x; }; sum;", 6},
+		{"let sum = 0; for i, x in [10, 20, 30] { sum = sum + i * x; }; sum;", 80},
+		{"let sum = 0; for x in [] { sum = sum + 1; }; sum;", 0},
+		{"let sum = 0; for i in 0..5 { sum = sum + i; }; sum;", 10},
+		{"let sum = 0; for i, x in 3..5 { sum = sum + i * x; }; sum;", 4},
+		{"let sum = 0; for i in 5..0 { sum = sum + 1; }; sum;", 0},
+		{"let sum = 0; for i in 1..=4 { sum = sum + i; }; sum;", 10},
+		{"let sum = 0; for i in 3..=3 { sum = sum + i; }; sum;", 3},
+		{"let r = 1..3; let sum = 0; for i in r { sum = sum + i; }; for i in r { sum = sum + i; }; sum;", 6},
+		{"struct Point { x, y } let p = Point{x: 1, y: 2}; let names = \"\"; for name in p { names = names + name; }; names;", "xy"},
+		{"struct Point { x, y } let p = Point{x: 1, y: 2}; let sum = 0; for name, value in p { sum = sum + value; }; sum;", 3},
+		{countdown + "let c = Countdown{n: 3}; let sum = 0; for x in c { sum = sum * 10 + x; }; sum;", 321},
+		{countdown + "let c = Countdown{n: 2}; let sum = 0; for i, x in c { sum = sum + i * x; }; sum;", 1},
+		// the loop variables are only declared in the body
+		{"let i = 7; for i in 0..2 { }; i;", 7},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("wrong result for %q. want=%q, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		}
+	}
+}
+
+func TestEvalForInLoopErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+	}{
+		{"for x in \"abc\" { }", object.TYPE_ERROR, "Values of type STRING can not be iterated"},
+		{"struct Bad { } impl Iterator for Bad { fn next(self) { throw 5; } } let b = Bad{}; for x in b { }", object.THROWN_ERROR, "5"},
+		{"struct Bad { } impl Iterator for Bad { }", object.DECLARATION_ERROR, "Struct Bad does not implement method next of trait Iterator"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if err.Kind != tt.kind || err.Message != tt.message {
+			t.Errorf("wrong error for %q. want=%s: %s, got=%s: %s", tt.input, tt.kind, tt.message, err.Kind, err.Message)
+		}
+	}
+}
+
+func TestEvalMatchExpressions(t *testing.T) {
+	describe := `
+		struct Point { x, y }
+		fn describe(v) {
+			match v {
+				0 => "zero",
+				1 | 2 => "small",
+				-1 => "negative",
+				"a" | "b" => "letter",
+				true => "yes",
+				[] => "empty",
+				[first] => first,
+				[first, ...rest] => {
+					let count = 0;
+					for x in rest { count = count + 1; }
+					first + count
+				}
+				Point{x: 0, y} => "axis" + y,
+				Point{x, y} if x == y => "diagonal",
+				Point{x, y} => "point",
+				n if n > 100 => "big",
+				_ => "other"
+			}
+		}
+	`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{describe + "describe(0);", "zero"},
+		{describe + "describe(2);", "small"},
+		{describe + "describe(-1);", "negative"},
+		{describe + `describe("b");`, "letter"},
+		{describe + "describe(true);", "yes"},
+		{describe + "describe([]);", "empty"},
+		{describe + `describe(["x"]);`, "x"},
+		{describe + `describe(["x", "y", "z"]);`, "x2"},
+		{describe + "describe(Point{x: 0, y: 5});", "axis5"},
+		{describe + "describe(Point{x: 3, y: 3});", "diagonal"},
+		{describe + "describe(Point{x: 3, y: 4});", "point"},
+		{describe + "describe(500);", "big"},
+		{describe + "describe(50);", "other"},
+		{"let x = match 5 { n if n > 3 => n * 2, _ => 0 }; x;", 10},
+		{"fn f(n) { match n { 1 => { return 10; } _ => 20 }; 30 } f(1) + f(2);", 40},
+		{"let y = 1; match 5 { y => y }; y;", 1},
+		{"match 1 { 1 => { let a = 1; } _ => 2 };", nil},
+		{"let e = 0; try { throw 7; } catch (err) { e = match err { Error{value} => value, _ => 0 }; } e;", 7},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("wrong result for %q. want=%q, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case nil:
+			if evaluated != NULL {
+				t.Errorf("object is not NULL for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestEvalMatchErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+	}{
+		{"match 3 { 1 => 1, 2 => 2 };", object.MATCH_ERROR, "No arm of match matches value 3"},
+		{"match 3 { n if n => 1 };", object.TYPE_ERROR, "Non boolean type (INTEGER) was returned for match guard"},
+		{"match 3 { Point{x} => 1 };", object.NAME_ERROR, "Undeclared struct Point used"},
+		{"struct Point { x } match 3 { Point{z} => 1 };", object.FIELD_ERROR, "Struct Point has no field z"},
+		{"match missing { _ => 1 };", object.NAME_ERROR, "Undeclared variable missing used"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if err.Kind != tt.kind || err.Message != tt.message {
+			t.Errorf("wrong error for %q. want=%s: %s, got=%s: %s", tt.input, tt.kind, tt.message, err.Kind, err.Message)
+		}
+	}
+}
+
+func TestEvalDisableOS(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "os.curry"), []byte("package os"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine()
+	engine.StandardLibraryModule = "internal"
+	engine.DisableOS = true
+	err = engine.IndexStandardLibrary(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evaluated := engine.Eval(parser.New(lexer.New(`import "internal/os";`)).ParseProgram())
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if errObj.Kind != object.PERMISSION_ERROR || errObj.Message != "Access to package internal/os is disabled" {
+		t.Errorf("wrong error. got=%s: %s", errObj.Kind, errObj.Message)
+	}
+}
+
+type recordingHook struct {
+	lines  []int
+	scopes [][]Scope
+	calls  []string
+	stopAt int
+}
+
+func (hook *recordingHook) BeforeStatement(engine *ExecutionEngine, statement ast.Statement) *object.Error {
+	line := statement.Pos().Line
+	hook.lines = append(hook.lines, line)
+
+	if line == hook.stopAt {
+		hook.scopes = append(hook.scopes, engine.Scopes())
+		return &object.Error{Kind: object.ABORT_ERROR, Message: "Stopped"}
+	}
+
+	return nil
+}
+
+func (hook *recordingHook) EnterFunction(engine *ExecutionEngine, frame Frame) {
+	hook.calls = append(hook.calls, "enter "+frame.Name())
+}
+
+func (hook *recordingHook) LeaveFunction(engine *ExecutionEngine, frame Frame, result object.Object) {
+	hook.calls = append(hook.calls, "leave "+frame.Name()+" "+result.Inspect())
+}
+
+func TestEvalHook(t *testing.T) {
+	input := `let x = 1;
+fn f(a) {
+    try {
+        let b = a + x;
+        return b;
+    } catch {
+        return 0;
+    }
+}
+f(2);
+f(3);`
+
+	hook := &recordingHook{stopAt: 5}
+	engine := NewEngine()
+	engine.Hook = hook
+
+	evaluated := engine.Eval(parser.New(lexer.New(input)).ParseProgram())
+
+	// the abort error is not caught by the try statement
+	errObj, ok := evaluated.(*object.Error)
+	if !ok || errObj.Kind != object.ABORT_ERROR || errObj.Span.Line != 5 {
+		t.Fatalf("program was not stopped at line 5. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expectedLines := []int{1, 2, 10, 3, 4, 5}
+	if fmt.Sprint(hook.lines) != fmt.Sprint(expectedLines) {
+		t.Errorf("wrong statements. want=%v, got=%v", expectedLines, hook.lines)
+	}
+
+	if fmt.Sprint(hook.calls) != "[enter f leave f error#Stopped]" {
+		t.Errorf("wrong calls. got=%v", hook.calls)
+	}
+
+	scopes := hook.scopes[0]
+	if len(scopes) != 3 {
+		t.Fatalf("wrong number of scopes. got=%d", len(scopes))
+	}
+
+	expectedScopes := []struct {
+		variables string
+		frame     int
+	}{
+		{"b", 0},
+		{"a", 0},
+		{"x", -1},
+	}
+
+	for i, expected := range expectedScopes {
+		names := make([]string, 0, len(scopes[i].Variables))
+		for _, variable := range scopes[i].Variables {
+			names = append(names, variable.Name)
+		}
+
+		if strings.Join(names, ",") != expected.variables || scopes[i].Frame != expected.frame {
+			t.Errorf("wrong scope %d. want=%s in frame %d, got=%v in frame %d", i, expected.variables, expected.frame, names, scopes[i].Frame)
+		}
+	}
+
+	if len(engine.Frames()) != 0 {
+		t.Errorf("frames were not removed. got=%d", len(engine.Frames()))
+	}
+}
+
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -0,0 +1,139 @@
+package evaluator
+
+import (
+	"curryLang/ast"
+	"curryLang/object"
+	"fmt"
+	"sync"
+)
+
+// generatorRun hands control back and forth between the engine running a generator function in its own goroutine
+// and the engine resuming it, only one of them runs at a time
+type generatorRun struct {
+	resume  chan struct{}
+	results chan generatorResult
+	stop    chan struct{} // closed once the program finished, the function is ended at its next yield
+	ended   chan struct{} // closed once the goroutine of the function ended
+	started bool
+	stopped bool
+}
+
+// generatorRuns are the generator functions of a program which did not return yet,
+// the engines of the program and its packages, tasks and generators share them
+type generatorRuns struct {
+	mutex sync.Mutex
+	runs  map[*generatorRun]bool
+}
+
+func newGeneratorRuns() *generatorRuns {
+	return &generatorRuns{runs: map[*generatorRun]bool{}}
+}
+
+func (generators *generatorRuns) add(run *generatorRun) {
+	generators.mutex.Lock()
+	defer generators.mutex.Unlock()
+
+	generators.runs[run] = true
+}
+
+func (generators *generatorRuns) remove(run *generatorRun) {
+	generators.mutex.Lock()
+	defer generators.mutex.Unlock()
+
+	delete(generators.runs, run)
+}
+
+// stop ends the functions of all generators which were not iterated until their end
+// and waits until their goroutines ended
+func (generators *generatorRuns) stop() {
+	generators.mutex.Lock()
+	runs := generators.runs
+	generators.runs = map[*generatorRun]bool{}
+	generators.mutex.Unlock()
+
+	for run := range runs {
+		run.stopped = true
+		close(run.stop)
+		<-run.ended
+	}
+}
+
+type generatorResult struct {
+	value object.Object
+	done  bool
+	err   *object.Error
+}
+
+// newGenerator returns the generator of a call of a generator function. The function runs on its own engine,
+// which shares the variables of the calling engine like the engine of a task, until it yields the next value.
+func (engine *ExecutionEngine) newGenerator(function *object.Function, args []object.Object) object.Object {
+	run := &generatorRun{
+		resume:  make(chan struct{}),
+		results: make(chan generatorResult),
+		stop:    make(chan struct{}),
+		ended:   make(chan struct{}),
+	}
+
+	generatorEngine := engine.newTaskEngine()
+	generatorEngine.generator = run
+
+	return object.NewGenerator(function.Name, func() (object.Object, bool, *object.Error) {
+		if run.stopped {
+			return nil, false, &object.Error{Kind: object.GENERATOR_ERROR, Message: fmt.Sprintf("Generator %s was ended with the program it was created by", function.Name)}
+		}
+
+		if run.started {
+			run.resume <- struct{}{}
+		} else {
+			run.started = true
+			engine.generators.add(run)
+			go generatorEngine.runGenerator(function, args)
+		}
+
+		result := <-run.results
+		return result.value, result.done, result.err
+	})
+}
+
+func (engine *ExecutionEngine) runGenerator(function *object.Function, args []object.Object) {
+	defer close(engine.generator.ended)
+
+	result := engine.runFunction(function, args)
+	engine.generators.remove(engine.generator)
+
+	err, _ := result.(*object.Error)
+
+	select {
+	case engine.generator.results <- generatorResult{done: true, err: err}:
+	case <-engine.generator.stop:
+	}
+}
+
+// EvalYieldStatement passes the value to the engine resuming the generator and waits until it is resumed again
+func (engine *ExecutionEngine) EvalYieldStatement(statement *ast.YieldStatement) object.Object {
+	if engine.generator == nil {
+		return engine.createError(object.GENERATOR_ERROR, "Yield can only be used inside of functions")
+	}
+
+	value := engine.Eval(statement.Value)
+	if isError(value) {
+		return value
+	}
+
+	// the calls of a suspended generator do not count towards the call depth of the engines resuming it
+	depth := len(engine.frames)
+	engine.usage.CallDepth -= depth
+
+	engine.generator.results <- generatorResult{value: value}
+
+	select {
+	case <-engine.generator.resume:
+	case <-engine.generator.stop:
+		engine.usage.CallDepth += depth
+		return engine.createError(object.ABORT_ERROR, "Generator was ended because its program finished")
+	}
+
+	engine.usage.CallDepth += depth
+
+	return NULL
+}
@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"curryLang/ast"
+	"curryLang/object"
+)
+
+// Hook is called by the engine while it evaluates a program, debuggers use it to pause the program.
+// The engine can be inspected and used to evaluate expressions while a hook is called.
+type Hook interface {
+	// BeforeStatement is called before a statement is evaluated, a returned error stops the program
+	BeforeStatement(engine *ExecutionEngine, statement ast.Statement) *object.Error
+
+	// EnterFunction is called after the parameters of a called function are declared
+	EnterFunction(engine *ExecutionEngine, frame Frame)
+
+	// LeaveFunction is called after a function returned, the result is nil if it did not return a value
+	LeaveFunction(engine *ExecutionEngine, frame Frame, result object.Object)
+}
+
+// BranchHook is a Hook which is also notified about the branches taken by the program, coverage tools use it
+type BranchHook interface {
+	Hook
+
+	// Branch is called after the condition of an if expression or a while loop was evaluated,
+	// taken reports if the condition was true and the consequence or loop body is evaluated
+	Branch(engine *ExecutionEngine, node ast.Node, taken bool)
+}
+
+// Frame is a function call which is currently evaluated
+type Frame struct {
+	Function *object.Function
+	Call     object.Span // position of the call, the line is 0 if the function was called from Go
+
+	// index of the scope of the parameters in CurrentStackPos
+	scope int
+}
+
+// Name returns the name of the called function
+func (frame Frame) Name() string {
+	if frame.Function.Name == "" {
+		return "<anonymous>"
+	}
+
+	return frame.Function.Name
+}
+
+// Scope is a block of variables, like the parameters of a function or the variables of a loop body
+type Scope struct {
+	Variables []*Variable
+	Frame     int // index of the declaring frame in Frames, -1 for the globals
+}
+
+// Frames returns the active function calls, the innermost one first
+func (engine *ExecutionEngine) Frames() []Frame {
+	frames := make([]Frame, 0, len(engine.frames))
+	for i := len(engine.frames) - 1; i >= 0; i-- {
+		frames = append(frames, engine.frames[i])
+	}
+
+	return frames
+}
+
+// Scopes returns the scopes of the variables, the innermost one first.
+// Variables are resolved dynamically, so all of them are visible to the innermost frame.
+func (engine *ExecutionEngine) Scopes() []Scope {
+	scopes := make([]Scope, 0, len(engine.CurrentStackPos)+1)
+	end := len(engine.Variables)
+	frame := len(engine.frames) - 1
+
+	for i := len(engine.CurrentStackPos) - 1; i >= 0; i-- {
+		// blocks belong to the innermost function called before they were entered
+		for frame >= 0 && engine.frames[frame].scope > i {
+			frame--
+		}
+
+		scope := Scope{Frame: -1}
+		if frame >= 0 {
+			scope.Frame = len(engine.frames) - 1 - frame
+		}
+
+		start := int(engine.CurrentStackPos[i])
+		scope.Variables = engine.Variables[start:end]
+		scopes = append(scopes, scope)
+		end = start
+	}
+
+	return append(scopes, Scope{Variables: engine.Variables[:end], Frame: -1})
+}
+
+// beforeStatement notifies the hook about a statement, the returned error stops the program
+func (engine *ExecutionEngine) beforeStatement(node ast.Node) *object.Error {
+	statement, ok := node.(ast.Statement)
+	if !ok || engine.Hook == nil {
+		return nil
+	}
+
+	err := engine.Hook.BeforeStatement(engine, statement)
+	if err != nil && err.Span.Line == 0 {
+		err.Span = object.SpanOf(engine.File, statement.Pos())
+	}
+
+	return err
+}
+
+func (engine *ExecutionEngine) branch(node ast.Node, taken bool) {
+	if hook, ok := engine.Hook.(BranchHook); ok {
+		hook.Branch(engine, node, taken)
+	}
+}
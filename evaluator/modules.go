@@ -0,0 +1,306 @@
+package evaluator
+
+import (
+	"curryLang/ast"
+	"curryLang/checker"
+	"curryLang/lexer"
+	"curryLang/modfile"
+	"curryLang/object"
+	"curryLang/parser"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// TestFileSuffix marks files which contain tests for the package of their directory
+const TestFileSuffix = "_test.curry"
+
+// LoadModule searches the curry.mod file for the program inside of dir and registers its module and dependencies,
+// programs without a curry.mod file can only import prebuilt modules.
+// If the module has a curry.sum file, the sources of the dependencies have to match it.
+func (engine *ExecutionEngine) LoadModule(dir string) error {
+	root, ok := modfile.Find(dir)
+	if !ok {
+		return nil
+	}
+
+	file, err := modfile.Load(root)
+	if err != nil {
+		return err
+	}
+
+	engine.ModuleFile = file
+	engine.ModuleRoot = root
+
+	module := NewModule(file.Module)
+	module.Path = root
+	engine.Modules[file.Module] = module
+
+	dependencies, err := engine.ResolveDependencies()
+	if err != nil {
+		return err
+	}
+
+	sum, ok, err := modfile.LoadSum(root)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		err = modfile.Verify(dependencies, sum)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, dependency := range dependencies {
+		module := NewModule(dependency.Path)
+		module.Path = dependency.Dir
+		engine.Modules[dependency.Path] = module
+	}
+
+	return nil
+}
+
+// ResolveDependencies returns the dependencies of the loaded module from its vendor directory,
+// local directories or the module cache
+func (engine *ExecutionEngine) ResolveDependencies() ([]modfile.Dependency, error) {
+	if engine.ModuleFile == nil {
+		return nil, nil
+	}
+
+	cacheDir := engine.ModuleCacheDir
+	if cacheDir == "" {
+		cacheDir = modfile.DefaultCacheDir()
+	}
+
+	return modfile.Resolve(engine.ModuleRoot, engine.ModuleFile, cacheDir)
+}
+
+// Object creates the value which is bound to the package name by an import
+func (pkg *Package) Object() *object.Package {
+	globals := map[string]object.Object{}
+	for varName, variable := range pkg.Globals {
+		globals[varName] = variable.Value
+	}
+
+	obj := &object.Package{
+		Name:      pkg.Name,
+		Functions: pkg.Functions,
+		Globals:   globals,
+		Structs:   pkg.Structs,
+	}
+
+	if pkg.Engine != nil {
+		obj.Invoke = pkg.Engine.callPackageFunction
+	}
+
+	return obj
+}
+
+func (engine *ExecutionEngine) resolvePackage(importPath string) (*Package, *object.Error) {
+	module, rel := engine.findModule(importPath)
+	if module == nil {
+		moduleName := strings.SplitN(importPath, "/", 2)[0]
+		return nil, engine.createError(object.IMPORT_ERROR, fmt.Sprintf("Module %s does not exist", moduleName))
+	}
+
+	key := rel
+	if key == "" {
+		key = path.Base(module.Name)
+	}
+
+	if engine.DisableOS && module.Name == engine.StandardLibraryModule && key == "os" {
+		return nil, engine.createError(object.PERMISSION_ERROR, fmt.Sprintf("Access to package %s is disabled", importPath))
+	}
+
+	pkg, ok := module.Packages[key]
+	if ok && (pkg.Path == "" || pkg.Engine != nil) {
+		return pkg, nil
+	}
+
+	var sourcePath string
+	if ok {
+		sourcePath = pkg.Path
+	} else if module.Path != "" {
+		sourcePath = filepath.Join(module.Path, filepath.FromSlash(rel))
+	} else {
+		return nil, engine.createError(object.IMPORT_ERROR, fmt.Sprintf("Package %s does not exist in module %s", key, module.Name))
+	}
+
+	pkg, err := engine.loadPackage(importPath, sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if module.Name == engine.StandardLibraryModule {
+		for name, builtin := range engine.Builtins[key] {
+			pkg.Globals[name] = Variable{Name: name, Value: builtin}
+		}
+	}
+
+	module.Packages[key] = pkg
+
+	return pkg, nil
+}
+
+// findModule returns the module with the longest name which is a prefix of the import path
+// and the remaining path of the package inside of this module
+func (engine *ExecutionEngine) findModule(importPath string) (*Module, string) {
+	var found *Module
+
+	for name, module := range engine.Modules {
+		if importPath != name && !strings.HasPrefix(importPath, name+"/") {
+			continue
+		}
+
+		if found == nil || len(name) > len(found.Name) {
+			found = module
+		}
+	}
+
+	if found == nil {
+		return nil, ""
+	}
+
+	return found, strings.TrimPrefix(strings.TrimPrefix(importPath, found.Name), "/")
+}
+
+func (engine *ExecutionEngine) loadPackage(importPath string, sourcePath string) (*Package, *object.Error) {
+	for i, loading := range engine.importStack {
+		if loading == importPath {
+			cycle := append(append([]string{}, engine.importStack[i:]...), importPath)
+			return nil, engine.createError(object.IMPORT_ERROR, fmt.Sprintf("Import cycle detected: %s", strings.Join(cycle, " -> ")))
+		}
+	}
+
+	files, err := packageFiles(sourcePath)
+	if err != nil {
+		return nil, engine.createError(object.IMPORT_ERROR, fmt.Sprintf("Package %s could not be loaded: %s", importPath, err))
+	}
+
+	if len(files) == 0 {
+		return nil, engine.createError(object.IMPORT_ERROR, fmt.Sprintf("Package %s contains no .curry files", importPath))
+	}
+
+	pkgEngine := engine.newPackageEngine(importPath)
+	packageName := ""
+
+	for _, file := range files {
+		program, errObj := engine.parseFile(file)
+		if errObj != nil {
+			return nil, errObj
+		}
+
+		var packageStatement *ast.PackageStatement
+		if len(program.Statements) > 0 {
+			packageStatement, _ = program.Statements[0].(*ast.PackageStatement)
+		}
+
+		if packageStatement == nil {
+			return nil, engine.createError(object.IMPORT_ERROR, fmt.Sprintf("File %s has to start with a package statement", file))
+		}
+
+		name := packageStatement.Identifier.Value
+		if packageName == "" {
+			packageName = name
+		} else if packageName != name {
+			return nil, engine.createError(object.IMPORT_ERROR,
+				fmt.Sprintf("Found packages %s and %s in %s, only one package per directory is allowed", packageName, name, sourcePath),
+			)
+		}
+
+		pkgEngine.File = file
+		result := pkgEngine.Eval(program)
+		if errObj, ok := result.(*object.Error); ok {
+			return nil, errObj
+		}
+	}
+
+	pkg := NewPackage(packageName)
+	pkg.Path = sourcePath
+	pkg.Engine = pkgEngine
+	pkg.Functions = pkgEngine.Functions
+	pkg.Structs = pkgEngine.Structs
+
+	for _, variable := range pkgEngine.Variables {
+		if _, isPackage := variable.Value.(*object.Package); isPackage {
+			continue
+		}
+
+		pkg.Globals[variable.Name] = *variable
+	}
+
+	return pkg, nil
+}
+
+func (engine *ExecutionEngine) newPackageEngine(importPath string) *ExecutionEngine {
+	pkgEngine := NewEngine()
+	pkgEngine.StandardLibraryPath = engine.StandardLibraryPath
+	pkgEngine.StandardLibraryModule = engine.StandardLibraryModule
+	pkgEngine.Modules = engine.Modules
+	pkgEngine.ModuleFile = engine.ModuleFile
+	pkgEngine.ModuleRoot = engine.ModuleRoot
+	pkgEngine.Limits = engine.Limits
+	pkgEngine.usage = engine.usage
+	pkgEngine.Tasks = engine.Tasks
+	pkgEngine.generators = engine.generators
+	pkgEngine.DisableOS = engine.DisableOS
+
+	pkgEngine.importStack = make([]string, 0, len(engine.importStack)+1)
+	pkgEngine.importStack = append(pkgEngine.importStack, engine.importStack...)
+	pkgEngine.importStack = append(pkgEngine.importStack, importPath)
+
+	return pkgEngine
+}
+
+func (engine *ExecutionEngine) parseFile(file string) (*ast.Program, *object.Error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, engine.createError(object.IMPORT_ERROR, fmt.Sprintf("Failed to read %s: %s", file, err))
+	}
+
+	p := parser.New(lexer.New(string(data)))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		return nil, engine.createError(object.IMPORT_ERROR, fmt.Sprintf("Failed to parse %s: %s", file, strings.Join(p.Errors(), ", ")))
+	}
+
+	if errors := checker.Check(program); len(errors) > 0 {
+		return nil, engine.createError(object.IMPORT_ERROR, fmt.Sprintf("Failed to check %s: %s", file, strings.Join(errors, ", ")))
+	}
+
+	return program, nil
+}
+
+// packageFiles returns the source files of a package, which is either a single file or a directory.
+// Test files are only loaded by the test runner.
+func packageFiles(sourcePath string) ([]string, error) {
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{sourcePath}, nil
+	}
+
+	entries, err := os.ReadDir(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".curry") || strings.HasSuffix(entry.Name(), TestFileSuffix) {
+			continue
+		}
+
+		files = append(files, filepath.Join(sourcePath, entry.Name()))
+	}
+
+	return files, nil
+}
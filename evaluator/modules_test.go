@@ -0,0 +1,198 @@
+package evaluator
+
+import (
+	"curryLang/internal/testfiles"
+	"curryLang/lexer"
+	"curryLang/modfile"
+	"curryLang/object"
+	"curryLang/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEvalModuleImports(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"curry.mod": "module mymodule",
+		"pkg/geo/geo.curry": `
+			package geo
+
+			let Origin = 0;
+
+			fn Distance(a, b) {
+				return abs(a - b);
+			}
+		`,
+		"pkg/geo/abs.curry": `
+			package geo
+
+			struct Point { x, y }
+
+			fn Scale(p, factor) {
+				return Point{x: p.x * factor, y: p.y * factor};
+			}
+
+			fn abs(x) {
+				if (x < 0) {
+					return -x;
+				}
+				return x;
+			}
+		`,
+		"pkg/geo/sub/sub.curry": `
+			package sub
+
+			import "mymodule/pkg/geo";
+
+			fn Twice(a, b) {
+				return geo.Distance(a, b) * 2;
+			}
+		`,
+	})
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "mymodule/pkg/geo"; geo.Distance(3, 10);`, 7},
+		{`import "mymodule/pkg/geo"; geo.Origin;`, 0},
+		{`import "mymodule/pkg/geo/sub"; sub.Twice(10, 3);`, 14},
+		{`import "mymodule/pkg/geo"; let p = geo.Point{x: 1, y: 2}; p.y;`, 2},
+		{`import "mymodule/pkg/geo"; fn abs(x) { 99 }; geo.Distance(1, 2);`, 1},
+		{`import "mymodule/pkg/geo"; geo.Scale(geo.Point{x: 3, y: 4}, 2).y;`, 8},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalInModule(t, root, tt.input)
+		testIntegerObject(t, evaluated, int64(tt.expected.(int)))
+	}
+}
+
+func TestEvalModuleImportErrors(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"curry.mod":       "module mymodule",
+		"cycle/a/a.curry": `package a; import "mymodule/cycle/b";`,
+		"cycle/b/b.curry": `package b; import "mymodule/cycle/a";`,
+		"mixed/one.curry": `package one`,
+		"mixed/two.curry": `package two`,
+		"nopkg/x.curry":   `let x = 1;`,
+		"empty/README":    `nothing here`,
+		"lib/lib.curry":   `package lib; let secret = 1; fn helper() { 2 }; struct point { x }`,
+		"user/user.curry": `package user; import "mymodule/lib"; fn Get() { lib.helper() }`,
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "mymodule/cycle/a";`, "Import cycle detected: mymodule/cycle/a -> mymodule/cycle/b -> mymodule/cycle/a"},
+		{`import "mymodule/mixed";`, "Found packages one and two in " + filepath.Join(root, "mixed") + ", only one package per directory is allowed"},
+		{`import "mymodule/nopkg";`, "File " + filepath.Join(root, "nopkg", "x.curry") + " has to start with a package statement"},
+		{`import "mymodule/empty";`, "Package mymodule/empty contains no .curry files"},
+		{`import "othermodule/pkg";`, "Module othermodule does not exist"},
+		{`import "mymodule/lib"; lib.secret;`, "Cannot access unexported global secret of package mymodule/lib"},
+		{`import "mymodule/lib"; lib.helper();`, "Cannot access unexported function helper of package mymodule/lib"},
+		{`import "mymodule/lib"; lib.point{x: 1};`, "Cannot access unexported struct point of package mymodule/lib"},
+		{
+			`import "mymodule/user";`,
+			"Failed to check " + filepath.Join(root, "user", "user.curry") + ": Cannot access unexported function helper of package mymodule/lib",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalInModule(t, root, tt.input)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if err.Message != tt.expected {
+			t.Errorf("wrong error message. want=%q, got=%q", tt.expected, err.Message)
+		}
+	}
+}
+
+func TestLoadModuleWithoutManifest(t *testing.T) {
+	engine := NewEngine()
+
+	err := engine.LoadModule(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if engine.ModuleFile != nil {
+		t.Errorf("engine.ModuleFile should be nil without curry.mod")
+	}
+}
+
+func TestEvalModuleDependencies(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"app/curry.mod":       "module app\nrequire example.com/lib => ../lib\nrequire example.com/text v1.0.0",
+		"lib/curry.mod":       "module example.com/lib",
+		"lib/util/util.curry": "package util\n\nfn Double(x) { return x + x; }",
+		"cache/example.com/text@v1.0.0/text.curry": "package text\n\nfn Hello() { return \"hi\"; }",
+	})
+
+	app := filepath.Join(root, "app")
+	input := `import "example.com/lib/util";
+import "example.com/text";
+util.Double(21);`
+
+	engine := NewEngine()
+	engine.ModuleCacheDir = filepath.Join(root, "cache")
+
+	err := engine.LoadModule(app)
+	if err != nil {
+		t.Fatalf("failed to load module: %s", err)
+	}
+
+	evaluated := engine.Eval(parser.New(lexer.New(input)).ParseProgram())
+	testIntegerObject(t, evaluated, 42)
+
+	dependencies, err := engine.ResolveDependencies()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := modfile.Sums(dependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = modfile.WriteSum(app, sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(root, "lib", "util", "util.curry"), []byte("package util\n\nfn Double(x) { return x; }"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine = NewEngine()
+	engine.ModuleCacheDir = filepath.Join(root, "cache")
+
+	err = engine.LoadModule(app)
+	if err == nil || !strings.HasPrefix(err.Error(), "checksum mismatch for example.com/lib local") {
+		t.Errorf("expected checksum mismatch. got=%v", err)
+	}
+}
+
+func testEvalInModule(t *testing.T, root string, input string) object.Object {
+	t.Helper()
+	engine := NewEngine()
+
+	err := engine.LoadModule(root)
+	if err != nil {
+		t.Fatalf("failed to load module: %s", err)
+	}
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	return engine.Eval(program)
+}
@@ -10,28 +10,34 @@ type Lexer struct {
 	position     int  // current position in input (points to current char)
 	readPosition int  // current reading position in input (after current char)
 	ch           rune // current char under examination
+	line         int  // line of the current char
+	column       int  // column of the current char
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: []rune(input)}
+	l := &Lexer{input: []rune(input), line: 1}
 	l.readChar()
 
 	return l
 }
 
-func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
+func (l *Lexer) NextToken() (tok token.Token) {
 	l.skipWhitespace()
 
-	if l.ch == '/' && l.peekChar() == '/' {
-		for l.ch != '\n' && l.ch != '\r' {
+	for l.ch == '/' && l.peekChar() == '/' {
+		for l.ch != '\n' && l.ch != '\r' && l.ch != 0 {
 			l.readChar()
 		}
 
 		l.skipWhitespace()
 	}
 
+	line, column := l.line, l.column
+	defer func() {
+		tok.Line = line
+		tok.Column = column
+	}()
+
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
@@ -39,6 +45,9 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			literal := string(ch) + string(l.ch)
 			tok = token.Token{Type: token.EQ, Literal: literal}
+		} else if l.peekChar() == '>' {
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: "=>"}
 		} else {
 			tok = l.newToken(token.ASSIGN, l.ch)
 		}
@@ -67,7 +76,29 @@ func (l *Lexer) NextToken() token.Token {
 	case ']':
 		tok = l.newToken(token.RBRACKET, l.ch)
 	case '.':
-		tok = l.newToken(token.DOT, l.ch)
+		if l.peekChar() == '.' {
+			l.readChar()
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.Token{Type: token.RANGE_INCLUSIVE, Literal: "..="}
+			} else if l.peekChar() == '.' {
+				l.readChar()
+				tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+			} else {
+				tok = token.Token{Type: token.RANGE, Literal: ".."}
+			}
+		} else {
+			tok = l.newToken(token.DOT, l.ch)
+		}
+	case ':':
+		tok = l.newToken(token.COLON, l.ch)
+	case '|':
+		if l.peekChar() == '>' {
+			l.readChar()
+			tok = token.Token{Type: token.PIPELINE, Literal: "|>"}
+		} else {
+			tok = l.newToken(token.PIPE, l.ch)
+		}
 	case '<':
 		tok = l.newToken(token.LT, l.ch)
 	case '>':
@@ -88,7 +119,7 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Type = token.EOF
 
 	default:
-		if unicode.IsLetter(l.ch) {
+		if isIdentifierStart(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
 			return tok
@@ -114,13 +145,22 @@ func (l *Lexer) skipWhitespace() {
 
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for unicode.IsLetter(l.ch) {
+	for isIdentifierStart(l.ch) || unicode.IsDigit(l.ch) {
 		l.readChar()
 	}
 	return string(l.input[position:l.position])
 }
 
+// isIdentifierStart reports if the character can start an identifier, the underscore alone is the wildcard pattern
+func isIdentifierStart(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
+}
+
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+	}
 
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
@@ -130,6 +170,7 @@ func (l *Lexer) readChar() {
 
 	l.position = l.readPosition
 	l.readPosition += 1
+	l.column += 1
 }
 
 func (l *Lexer) newToken(tokenType token.TokenType, ch rune) token.Token {
@@ -2,11 +2,8 @@ package main
 
 import (
 	"curryLang/evaluator"
-	"curryLang/lexer"
-	"curryLang/parser"
 	"curryLang/repl"
 	"fmt"
-	"io"
 	"os"
 	"os/user"
 )
@@ -14,44 +11,56 @@ import (
 func main() {
 
 	args := os.Args[1:]
-	if len(args) > 0 {
-		fileToExecute, err := os.Open(args[0])
+	if len(args) > 0 && args[0] == "mod" {
+		err := runModCommand(args[1:])
 		if err != nil {
-			panic("Failed to open file")
+			exitWithError(err)
 		}
-
-		data, err := io.ReadAll(fileToExecute)
+	} else if len(args) > 0 && args[0] == "test" {
+		ok, err := runTestCommand(args[1:])
 		if err != nil {
-			panic("Failed to read file")
+			exitWithError(err)
 		}
 
-		l := lexer.New(string(data))
-		p := parser.New(l)
-		program := p.ParseProgram()
-
-		if len(p.Errors()) > 0 {
-			for _, err := range p.Errors() {
-				fmt.Println("Error: ", err)
-			}
-
+		if !ok {
 			os.Exit(1)
 		}
+	} else if len(args) > 0 && args[0] == "bench" {
+		ok, err := runBenchCommand(args[1:])
+		if err != nil {
+			exitWithError(err)
+		}
 
-		engine := evaluator.NewEngine()
-
-		// setup standard library
-		engine.StandardLibraryPath = "standard-library"
-		engine.StandardLibraryModule = "internal"
-		engine.IndexStandardLibrary(engine.StandardLibraryPath, engine.StandardLibraryModule)
+		if !ok {
+			os.Exit(1)
+		}
+	} else if len(args) > 0 && args[0] == "dap" {
+		err := runDapCommand(args[1:])
+		if err != nil {
+			exitWithError(err)
+		}
+	} else if len(args) > 0 && args[0] == "debug" {
+		ok, err := runDebugCommand(args[1:])
+		if err != nil {
+			exitWithError(err)
+		}
 
-		evalResult := engine.Eval(program)
+		if !ok {
+			os.Exit(1)
+		}
+	} else if len(args) > 0 {
+		// "curry <file>" is a shortcut for "curry run <file>"
+		if args[0] == "run" {
+			args = args[1:]
+		}
 
-		if engine.HasError {
-			fmt.Println("Errors during execution ", evalResult)
+		ok, err := runRunCommand(args)
+		if err != nil {
+			exitWithError(err)
 		}
 
-		if evalResult != nil {
-			fmt.Println(evalResult.Inspect())
+		if !ok {
+			os.Exit(1)
 		}
 	} else {
 		user, err := user.Current()
@@ -63,3 +72,14 @@ func main() {
 		repl.Start(os.Stdin, os.Stdout)
 	}
 }
+
+func newEngine() *evaluator.ExecutionEngine {
+	engine := evaluator.NewEngine()
+
+	// setup standard library
+	engine.StandardLibraryPath = "standard-library"
+	engine.StandardLibraryModule = "internal"
+	engine.IndexStandardLibrary(engine.StandardLibraryPath)
+
+	return engine
+}
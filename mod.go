@@ -0,0 +1,105 @@
+package main
+
+import (
+	"curryLang/modfile"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const modUsage = `usage: curry mod <command>
+
+commands:
+  tidy    write the content hashes of all dependencies to curry.sum
+  vendor  copy all dependencies into the vendor directory
+  verify  check that the dependencies match curry.sum`
+
+// runModCommand executes "curry mod <command>" for the module containing the current directory
+func runModCommand(args []string) error {
+	if len(args) != 1 {
+		return errors.New(modUsage)
+	}
+
+	root, ok := modfile.Find(".")
+	if !ok {
+		return fmt.Errorf("no %s file found in current directory or any parent directory", modfile.FileName)
+	}
+
+	file, err := modfile.Load(root)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "tidy":
+		dependencies, err := resolveDependencies(root, file, false)
+		if err != nil {
+			return err
+		}
+
+		sum, err := modfile.Sums(dependencies)
+		if err != nil {
+			return err
+		}
+
+		return modfile.WriteSum(root, sum)
+	case "vendor":
+		dependencies, err := resolveDependencies(root, file, true)
+		if err != nil {
+			return err
+		}
+
+		sum, ok, err := modfile.LoadSum(root)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			err = modfile.Verify(dependencies, sum)
+			if err != nil {
+				return err
+			}
+		}
+
+		return modfile.Vendor(root, dependencies)
+	case "verify":
+		dependencies, err := resolveDependencies(root, file, false)
+		if err != nil {
+			return err
+		}
+
+		sum, ok, err := modfile.LoadSum(root)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return fmt.Errorf("no %s file found in %s", modfile.SumFileName, root)
+		}
+
+		err = modfile.Verify(dependencies, sum)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("all modules verified")
+		return nil
+	default:
+		return fmt.Errorf("unknown command %s\n%s", args[0], modUsage)
+	}
+}
+
+// resolveDependencies resolves from the vendor directory unless ignoreVendor is set,
+// which is needed to vendor the original sources again
+func resolveDependencies(root string, file *modfile.File, ignoreVendor bool) ([]modfile.Dependency, error) {
+	if ignoreVendor {
+		return modfile.ResolveWithoutVendor(root, file, modfile.DefaultCacheDir())
+	}
+
+	return modfile.Resolve(root, file, modfile.DefaultCacheDir())
+}
+
+func exitWithError(err error) {
+	fmt.Fprintln(os.Stderr, "Error: ", err)
+	os.Exit(1)
+}
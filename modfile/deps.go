@@ -0,0 +1,221 @@
+package modfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const VendorDir = "vendor"
+
+// VendorManifest lists the vendored dependencies, resolution only uses the vendor directory if it exists
+const VendorManifest = "modules.txt"
+
+// CacheEnv is the environment variable which overrides the module cache directory
+const CacheEnv = "CURRY_MODCACHE"
+
+// Dependency is a resolved requirement of the main module or of one of its dependencies
+type Dependency struct {
+	Require
+	Dir string // absolute directory containing the sources of the dependency
+}
+
+// DefaultCacheDir returns the directory which contains the versioned dependencies as <path>@<version>
+func DefaultCacheDir() string {
+	if dir := os.Getenv(CacheEnv); dir != "" {
+		return dir
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "curry", "mod")
+	}
+
+	return filepath.Join(home, ".curry", "mod")
+}
+
+// CacheDir returns the directory of a versioned dependency inside of the module cache
+func CacheDir(cacheDir string, require Require) string {
+	return filepath.Join(cacheDir, filepath.FromSlash(require.Path)+"@"+require.Version)
+}
+
+// Resolve returns the dependencies of the module at root in breadth-first order. The main module decides
+// which requirement of a path wins, later requirements of the same path are ignored.
+// If root contains a vendor directory, all dependencies are resolved from it.
+func Resolve(root string, file *File, cacheDir string) ([]Dependency, error) {
+	_, err := os.Stat(filepath.Join(root, VendorDir, VendorManifest))
+	return resolve(root, file, cacheDir, err == nil)
+}
+
+// ResolveWithoutVendor resolves the dependencies from local directories and the module cache only
+func ResolveWithoutVendor(root string, file *File, cacheDir string) ([]Dependency, error) {
+	return resolve(root, file, cacheDir, false)
+}
+
+func resolve(root string, file *File, cacheDir string, vendored bool) ([]Dependency, error) {
+	type pending struct {
+		require Require
+		from    string
+	}
+
+	var queue []pending
+	for _, require := range file.Requires {
+		queue = append(queue, pending{require, root})
+	}
+
+	var dependencies []Dependency
+	seen := map[string]bool{file.Module: true}
+
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		if seen[next.require.Path] {
+			continue
+		}
+
+		seen[next.require.Path] = true
+
+		var dir string
+		switch {
+		case vendored:
+			dir = filepath.Join(root, VendorDir, filepath.FromSlash(next.require.Path))
+		case next.require.IsLocal():
+			dir = next.require.Dir
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(next.from, dir)
+			}
+		default:
+			dir = CacheDir(cacheDir, next.require)
+		}
+
+		if !isDir(dir) {
+			return nil, fmt.Errorf("module %s: directory %s does not exist", next.require.Path, dir)
+		}
+
+		dependencies = append(dependencies, Dependency{Require: next.require, Dir: dir})
+
+		if _, err := os.Stat(filepath.Join(dir, FileName)); err != nil {
+			continue
+		}
+
+		depFile, err := Load(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if depFile.Module != next.require.Path {
+			return nil, fmt.Errorf("module %s: %s declares module %s", next.require.Path, dir, depFile.Module)
+		}
+
+		for _, require := range depFile.Requires {
+			queue = append(queue, pending{require, dir})
+		}
+	}
+
+	return dependencies, nil
+}
+
+// Sums calculates the curry.sum entries of all dependencies
+func Sums(dependencies []Dependency) (Sum, error) {
+	sum := Sum{}
+
+	for _, dependency := range dependencies {
+		hash, err := HashDir(dependency.Dir)
+		if err != nil {
+			return nil, err
+		}
+
+		sum.Add(dependency.Require, hash)
+	}
+
+	return sum, nil
+}
+
+// Verify compares the sources of every dependency with its curry.sum entry
+func Verify(dependencies []Dependency, sum Sum) error {
+	var problems []string
+
+	for _, dependency := range dependencies {
+		expected, ok := sum.Lookup(dependency.Require)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("missing %s entry for %s %s", SumFileName, dependency.Path, dependency.SumVersion()))
+			continue
+		}
+
+		hash, err := HashDir(dependency.Dir)
+		if err != nil {
+			return err
+		}
+
+		if hash != expected {
+			problems = append(problems, fmt.Sprintf("checksum mismatch for %s %s: %s has %s", dependency.Path, dependency.SumVersion(), SumFileName, expected))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+// Vendor copies the sources of all dependencies into the vendor directory of root and writes its manifest
+func Vendor(root string, dependencies []Dependency) error {
+	vendorDir := filepath.Join(root, VendorDir)
+
+	err := os.RemoveAll(vendorDir)
+	if err != nil {
+		return err
+	}
+
+	var manifest []string
+
+	for _, dependency := range dependencies {
+		target := filepath.Join(vendorDir, filepath.FromSlash(dependency.Path))
+
+		err := copyFile(filepath.Join(dependency.Dir, FileName), filepath.Join(target, FileName))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		err = WalkSources(dependency.Dir, func(path string, rel string) error {
+			return copyFile(path, filepath.Join(target, filepath.FromSlash(rel)))
+		})
+		if err != nil {
+			return err
+		}
+
+		manifest = append(manifest, dependency.Path+" "+dependency.SumVersion())
+	}
+
+	sort.Strings(manifest)
+
+	err = os.MkdirAll(vendorDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(vendorDir, VendorManifest), []byte(strings.Join(manifest, "\n")+"\n"), 0644)
+}
+
+func copyFile(source string, target string) error {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(target, data, 0644)
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
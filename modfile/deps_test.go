@@ -0,0 +1,230 @@
+package modfile
+
+import (
+	"curryLang/internal/testfiles"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWalkSources(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"b.curry":              "",
+		"a/z.curry":            "",
+		"a/notes.txt":          "",
+		"vendor/x/skip.curry":  "",
+		"a/nested/inner.curry": "",
+	})
+
+	var found []string
+	err := WalkSources(root, func(path string, rel string) error {
+		if path != filepath.Join(root, filepath.FromSlash(rel)) {
+			t.Errorf("path %s does not belong to %s", path, rel)
+		}
+
+		found = append(found, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "a/nested/inner.curry a/z.curry b.curry"
+	if strings.Join(found, " ") != expected {
+		t.Errorf("wrong files. want=%q, got=%q", expected, strings.Join(found, " "))
+	}
+}
+
+func TestResolve(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"app/curry.mod":                            "module app\nrequire example.com/lib => ../lib\nrequire example.com/text v1.0.0",
+		"lib/curry.mod":                            "module example.com/lib\nrequire example.com/text v2.0.0\nrequire example.com/deep => ./deep",
+		"lib/util/util.curry":                      "package util",
+		"lib/deep/deep.curry":                      "package deep",
+		"cache/example.com/text@v1.0.0/text.curry": "package text",
+	})
+
+	app := filepath.Join(root, "app")
+	file, err := Load(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dependencies, err := Resolve(app, file, filepath.Join(root, "cache"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []struct {
+		path string
+		dir  string
+	}{
+		{"example.com/lib", filepath.Join(root, "lib")},
+		{"example.com/text", filepath.Join(root, "cache", "example.com", "text@v1.0.0")},
+		{"example.com/deep", filepath.Join(root, "lib", "deep")},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Fatalf("wrong number of dependencies. want=%d, got=%d", len(expected), len(dependencies))
+	}
+
+	for i, tt := range expected {
+		if dependencies[i].Path != tt.path || dependencies[i].Dir != tt.dir {
+			t.Errorf("dependencies[%d] is not %s in %s. got=%s in %s", i, tt.path, tt.dir, dependencies[i].Path, dependencies[i].Dir)
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		files    map[string]string
+		expected string
+	}{
+		{
+			map[string]string{"app/curry.mod": "module app\nrequire example.com/text v1.0.0"},
+			"module example.com/text: directory",
+		},
+		{
+			map[string]string{
+				"app/curry.mod": "module app\nrequire example.com/lib => ../lib",
+				"lib/curry.mod": "module other",
+			},
+			"declares module other",
+		},
+	}
+
+	for _, tt := range tests {
+		root := testfiles.Write(t, tt.files)
+		app := filepath.Join(root, "app")
+
+		file, err := Load(app)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Resolve(app, file, filepath.Join(root, "cache"))
+		if err == nil {
+			t.Fatalf("expected error containing %q", tt.expected)
+		}
+
+		if !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("wrong error. want=%q, got=%q", tt.expected, err.Error())
+		}
+	}
+}
+
+func TestSumsAndVerify(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"app/curry.mod":       "module app\nrequire example.com/lib => ../lib",
+		"lib/util/util.curry": "package util",
+	})
+
+	app := filepath.Join(root, "app")
+	file, err := Load(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dependencies, err := Resolve(app, file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := Sums(dependencies)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = WriteSum(app, sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, ok, err := LoadSum(app)
+	if err != nil || !ok {
+		t.Fatalf("curry.sum could not be loaded: %v", err)
+	}
+
+	err = Verify(dependencies, loaded)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	err = os.WriteFile(filepath.Join(root, "lib", "util", "util.curry"), []byte("package util\nfn Changed() {}"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Verify(dependencies, loaded)
+	if err == nil || !strings.HasPrefix(err.Error(), "checksum mismatch for example.com/lib local") {
+		t.Errorf("expected checksum mismatch. got=%v", err)
+	}
+
+	err = Verify(dependencies, Sum{})
+	if err == nil || err.Error() != "missing curry.sum entry for example.com/lib local" {
+		t.Errorf("expected missing entry. got=%v", err)
+	}
+}
+
+func TestParseSumErrors(t *testing.T) {
+	_, err := ParseSum("curry.sum", []byte("example.com/lib v1.0.0\n"))
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+
+	expected := "curry.sum:1: malformed line, expected <path> <version> h1:<hash>"
+	if err.Error() != expected {
+		t.Errorf("wrong error. want=%q, got=%q", expected, err.Error())
+	}
+}
+
+func TestVendor(t *testing.T) {
+	root := testfiles.Write(t, map[string]string{
+		"app/curry.mod":       "module app\nrequire example.com/lib => ../lib",
+		"lib/curry.mod":       "module example.com/lib",
+		"lib/util/util.curry": "package util",
+		"lib/README.md":       "not vendored",
+	})
+
+	app := filepath.Join(root, "app")
+	file, err := Load(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dependencies, err := Resolve(app, file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Vendor(app, dependencies)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, name := range []string{"example.com/lib/curry.mod", "example.com/lib/util/util.curry", VendorManifest} {
+		if _, err := os.Stat(filepath.Join(app, VendorDir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s was not vendored: %s", name, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(app, VendorDir, "example.com", "lib", "README.md")); err == nil {
+		t.Errorf("README.md should not be vendored")
+	}
+
+	vendored, err := Resolve(app, file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vendored[0].Dir != filepath.Join(app, VendorDir, "example.com", "lib") {
+		t.Errorf("dependency is not resolved from vendor. got=%s", vendored[0].Dir)
+	}
+
+	original, _ := HashDir(dependencies[0].Dir)
+	copied, _ := HashDir(vendored[0].Dir)
+	if original != copied {
+		t.Errorf("vendored hash differs. want=%s, got=%s", original, copied)
+	}
+}
@@ -0,0 +1,190 @@
+package modfile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const FileName = "curry.mod"
+
+type File struct {
+	Module   string
+	Requires []Require
+}
+
+// Require is a dependency which is either resolved from the module cache by its version or from a local directory
+type Require struct {
+	Path    string
+	Version string
+	Dir     string // local directory relative to the requiring module, set for "require path => dir"
+}
+
+func (r Require) IsLocal() bool {
+	return r.Dir != ""
+}
+
+// SumVersion is the version used for the curry.sum entry of the requirement
+func (r Require) SumVersion() string {
+	if r.IsLocal() {
+		return "local"
+	}
+
+	return r.Version
+}
+
+// Parse reads the content of a curry.mod file, the name is only used for error messages
+func Parse(name string, data []byte) (*File, error) {
+	file := &File{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	inRequireBlock := false
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		if inRequireBlock {
+			if line == ")" {
+				inRequireBlock = false
+				continue
+			}
+
+			err := file.addRequire(fields)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %s", name, lineNumber, err)
+			}
+
+			continue
+		}
+
+		switch fields[0] {
+		case "module":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("%s:%d: usage: module <path>", name, lineNumber)
+			}
+
+			if file.Module != "" {
+				return nil, fmt.Errorf("%s:%d: module is declared twice", name, lineNumber)
+			}
+
+			file.Module = strings.Trim(fields[1], "\"")
+
+			if err := checkModulePath(file.Module); err != nil {
+				return nil, fmt.Errorf("%s:%d: %s", name, lineNumber, err)
+			}
+		case "require":
+			if len(fields) == 2 && fields[1] == "(" {
+				inRequireBlock = true
+				continue
+			}
+
+			err := file.addRequire(fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %s", name, lineNumber, err)
+			}
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown directive %s", name, lineNumber, fields[0])
+		}
+	}
+
+	if inRequireBlock {
+		return nil, fmt.Errorf("%s: require block is not closed", name)
+	}
+
+	if file.Module == "" {
+		return nil, fmt.Errorf("%s: missing module declaration", name)
+	}
+
+	return file, nil
+}
+
+// Find looks for a curry.mod file in dir and all its parents and returns the directory containing it
+func Find(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		info, err := os.Stat(filepath.Join(dir, FileName))
+		if err == nil && !info.IsDir() {
+			return dir, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+
+		dir = parent
+	}
+}
+
+// Load parses the curry.mod file inside of dir
+func Load(dir string) (*File, error) {
+	path := filepath.Join(dir, FileName)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(path, data)
+}
+
+func (file *File) addRequire(fields []string) error {
+	var require Require
+
+	switch {
+	case len(fields) == 2:
+		require = Require{Path: fields[0], Version: fields[1]}
+	case len(fields) == 3 && fields[1] == "=>":
+		require = Require{Path: fields[0], Dir: fields[2]}
+	default:
+		return fmt.Errorf("usage: require <path> <version> or require <path> => <dir>")
+	}
+
+	if err := checkModulePath(require.Path); err != nil {
+		return err
+	}
+
+	for _, existing := range file.Requires {
+		if existing.Path == require.Path {
+			return fmt.Errorf("%s is required twice", require.Path)
+		}
+	}
+
+	file.Requires = append(file.Requires, require)
+
+	return nil
+}
+
+// checkModulePath rejects module paths which could leave the vendor directory or the module cache,
+// their elements are used as directory names
+func checkModulePath(modulePath string) error {
+	for _, element := range strings.Split(modulePath, "/") {
+		if element == "" || element == "." || element == ".." || strings.Contains(element, "\\") {
+			return fmt.Errorf("invalid module path %s", modulePath)
+		}
+	}
+
+	return nil
+}
+
+func stripComment(line string) string {
+	if i := strings.Index(line, "//"); i >= 0 {
+		return line[:i]
+	}
+
+	return line
+}
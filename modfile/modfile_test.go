@@ -0,0 +1,129 @@
+package modfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input  string
+		module string
+	}{
+		{"module mymodule", "mymodule"},
+		{"// my module\nmodule example.com/app // trailing\n", "example.com/app"},
+		{"\n\nmodule \"quoted\"\n", "quoted"},
+	}
+
+	for _, tt := range tests {
+		file, err := Parse("curry.mod", []byte(tt.input))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if file.Module != tt.module {
+			t.Errorf("file.Module is not %s. got=%s", tt.module, file.Module)
+		}
+	}
+}
+
+func TestParseRequires(t *testing.T) {
+	input := `module app
+
+require example.com/text v1.2.0
+require (
+	example.com/lib => ../lib // local checkout
+	example.com/other v0.1.0
+)
+`
+
+	file, err := Parse("curry.mod", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []Require{
+		{Path: "example.com/text", Version: "v1.2.0"},
+		{Path: "example.com/lib", Dir: "../lib"},
+		{Path: "example.com/other", Version: "v0.1.0"},
+	}
+
+	if len(file.Requires) != len(expected) {
+		t.Fatalf("wrong number of requires. want=%d, got=%d", len(expected), len(file.Requires))
+	}
+
+	for i, require := range expected {
+		if file.Requires[i] != require {
+			t.Errorf("requires[%d] is not %+v. got=%+v", i, require, file.Requires[i])
+		}
+	}
+
+	if file.Requires[1].SumVersion() != "local" {
+		t.Errorf("SumVersion of local require is not local. got=%s", file.Requires[1].SumVersion())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", "curry.mod: missing module declaration"},
+		{"module", "curry.mod:1: usage: module <path>"},
+		{"module a\nmodule b", "curry.mod:2: module is declared twice"},
+		{"module a\nreplace b", "curry.mod:2: unknown directive replace"},
+		{"module a\nrequire b", "curry.mod:2: usage: require <path> <version> or require <path> => <dir>"},
+		{"module a\nrequire b v1\nrequire b v2", "curry.mod:3: b is required twice"},
+		{"module a\nrequire (\nb v1\n", "curry.mod: require block is not closed"},
+		{"module a/../b", "curry.mod:1: invalid module path a/../b"},
+		{"module a\nrequire ../b v1", "curry.mod:2: invalid module path ../b"},
+		{"module a\nrequire b/../../etc => ./etc", "curry.mod:2: invalid module path b/../../etc"},
+		{"module a\nrequire /b v1", "curry.mod:2: invalid module path /b"},
+		{"module a\nrequire b/./c v1", "curry.mod:2: invalid module path b/./c"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse("curry.mod", []byte(tt.input))
+		if err == nil {
+			t.Fatalf("expected error %q", tt.expected)
+		}
+
+		if err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%q", tt.expected, err.Error())
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "pkg", "sub")
+
+	err := os.MkdirAll(nested, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(root, FileName), []byte("module mymodule"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, ok := Find(nested)
+	if !ok {
+		t.Fatalf("curry.mod was not found from %s", nested)
+	}
+
+	if dir != root {
+		t.Errorf("wrong directory. want=%s, got=%s", root, dir)
+	}
+
+	file, err := Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if file.Module != "mymodule" {
+		t.Errorf("file.Module is not mymodule. got=%s", file.Module)
+	}
+}
@@ -0,0 +1,126 @@
+package modfile
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const SumFileName = "curry.sum"
+
+// Sum maps "path version" of a dependency to the hash of its sources
+type Sum map[string]string
+
+func sumKey(path string, version string) string {
+	return path + " " + version
+}
+
+func (sum Sum) Lookup(require Require) (string, bool) {
+	hash, ok := sum[sumKey(require.Path, require.SumVersion())]
+	return hash, ok
+}
+
+func (sum Sum) Add(require Require, hash string) {
+	sum[sumKey(require.Path, require.SumVersion())] = hash
+}
+
+// Format returns the content of the curry.sum file with one sorted line per dependency
+func (sum Sum) Format() []byte {
+	keys := make([]string, 0, len(sum))
+	for key := range sum {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var out bytes.Buffer
+	for _, key := range keys {
+		fmt.Fprintf(&out, "%s %s\n", key, sum[key])
+	}
+
+	return out.Bytes()
+}
+
+func ParseSum(name string, data []byte) (Sum, error) {
+	sum := Sum{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) != 3 || !strings.HasPrefix(fields[2], "h1:") {
+			return nil, fmt.Errorf("%s:%d: malformed line, expected <path> <version> h1:<hash>", name, lineNumber)
+		}
+
+		sum[sumKey(fields[0], fields[1])] = fields[2]
+	}
+
+	return sum, nil
+}
+
+// LoadSum reads the curry.sum file of the module root, the second return value is false if there is none
+func LoadSum(root string) (Sum, bool, error) {
+	path := filepath.Join(root, SumFileName)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Sum{}, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	sum, err := ParseSum(path, data)
+	return sum, err == nil, err
+}
+
+func WriteSum(root string, sum Sum) error {
+	return os.WriteFile(filepath.Join(root, SumFileName), sum.Format(), 0644)
+}
+
+// HashDir hashes the curry.mod file and all sources of a module, the result does not depend on file modes or times
+func HashDir(dir string) (string, error) {
+	var files []string
+	paths := map[string]string{}
+
+	if _, err := os.Stat(filepath.Join(dir, FileName)); err == nil {
+		files = append(files, FileName)
+		paths[FileName] = filepath.Join(dir, FileName)
+	}
+
+	err := WalkSources(dir, func(path string, rel string) error {
+		files = append(files, rel)
+		paths[rel] = path
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(files)
+
+	summary := sha256.New()
+	for _, file := range files {
+		data, err := os.ReadFile(paths[file])
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(summary, "%x  %s\n", sha256.Sum256(data), file)
+	}
+
+	return "h1:" + base64.StdEncoding.EncodeToString(summary.Sum(nil)), nil
+}
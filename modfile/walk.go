@@ -0,0 +1,52 @@
+package modfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WalkSources calls fn for every .curry file inside of dir and its sub directories in lexical order,
+// rel is the slash separated path of the file relative to dir. Vendored dependencies are skipped.
+func WalkSources(dir string, fn func(path string, rel string) error) error {
+	return walkSources(dir, "", fn)
+}
+
+func walkSources(dir string, rel string, fn func(path string, rel string) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		entryRel := entry.Name()
+		if rel != "" {
+			entryRel = rel + "/" + entry.Name()
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == VendorDir {
+				continue
+			}
+
+			err := walkSources(path, entryRel, fn)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !strings.HasSuffix(entry.Name(), ".curry") {
+			continue
+		}
+
+		err := fn(path, entryRel)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
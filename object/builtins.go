@@ -0,0 +1,166 @@
+package object
+
+import (
+	"curryLang/token"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BuiltinNames are the builtins every program can use, the compiler refers to them by their index.
+// The builtins of Scheduler.Builtins are followed by the ones of ListBuiltins.
+var BuiltinNames = []string{"channel", "split", "map", "filter", "sum", "toInt"}
+
+// FunctionCaller calls a function value inside of the executing engine, errors are returned as *Error
+type FunctionCaller func(function Object, args ...Object) Object
+
+// ListBuiltins returns the builtins of BuiltinNames which work on lists, the functions passed to map and filter
+// are called by call
+func ListBuiltins(call FunctionCaller) []*Builtin {
+	return []*Builtin{
+		{Name: "split", Function: split},
+		{Name: "map", Function: func(position token.Token, args ...Object) Object {
+			return mapList(call, args)
+		}},
+		{Name: "filter", Function: func(position token.Token, args ...Object) Object {
+			return filterList(call, args)
+		}},
+		{Name: "sum", Function: sum},
+		{Name: "toInt", Function: toInt},
+	}
+}
+
+// split(string, separator) returns the parts of the string between the separators
+func split(position token.Token, args ...Object) Object {
+	if len(args) != 2 {
+		return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("split expects 2 arguments but got %d", len(args))}
+	}
+
+	str, ok := args[0].(*String)
+	if !ok {
+		return &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("split expects a string but got %s", args[0].Type())}
+	}
+
+	separator, ok := args[1].(*String)
+	if !ok {
+		return &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("split expects a string separator but got %s", args[1].Type())}
+	}
+
+	list := &List{ValueType: STRING_OBJ}
+	for _, part := range strings.Split(str.Value, separator.Value) {
+		list.Value = append(list.Value, &String{Value: part})
+	}
+
+	return list
+}
+
+// mapList implements map(list, function), it returns the results of calling the function with every element
+func mapList(call FunctionCaller, args []Object) Object {
+	list, err := listAndFunction("map", args)
+	if err != nil {
+		return err
+	}
+
+	mapped := &List{Value: make([]Object, 0, len(list.Value))}
+	for i, element := range list.Value {
+		result := call(args[1], element)
+		if err, ok := result.(*Error); ok {
+			return err
+		}
+
+		if i == 0 {
+			mapped.ValueType = result.Type()
+		} else if result.Type() != mapped.ValueType {
+			return &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf(
+				"map has to return values of the same type, value #%d has type %s instead of %s", i, result.Type(), mapped.ValueType,
+			)}
+		}
+
+		mapped.Value = append(mapped.Value, result)
+	}
+
+	return mapped
+}
+
+// filterList implements filter(list, function), it returns the elements for which the function returns true
+func filterList(call FunctionCaller, args []Object) Object {
+	list, err := listAndFunction("filter", args)
+	if err != nil {
+		return err
+	}
+
+	filtered := &List{ValueType: list.ValueType, Value: []Object{}}
+	for _, element := range list.Value {
+		result := call(args[1], element)
+		if err, ok := result.(*Error); ok {
+			return err
+		}
+
+		keep, ok := result.(*Boolean)
+		if !ok {
+			return &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("filter expects the function to return a boolean but got %s", result.Type())}
+		}
+
+		if keep.Value {
+			filtered.Value = append(filtered.Value, element)
+		}
+	}
+
+	return filtered
+}
+
+func listAndFunction(name string, args []Object) (*List, *Error) {
+	if len(args) != 2 {
+		return nil, &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("%s expects 2 arguments but got %d", name, len(args))}
+	}
+
+	list, ok := args[0].(*List)
+	if !ok {
+		return nil, &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("%s expects a list but got %s", name, args[0].Type())}
+	}
+
+	return list, nil
+}
+
+// sum(list) returns the sum of a list of integers, it is 0 for empty lists
+func sum(position token.Token, args ...Object) Object {
+	if len(args) != 1 {
+		return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("sum expects 1 argument but got %d", len(args))}
+	}
+
+	list, ok := args[0].(*List)
+	if !ok {
+		return &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("sum expects a list but got %s", args[0].Type())}
+	}
+
+	var total int64
+	for _, element := range list.Value {
+		integer, ok := element.(*Integer)
+		if !ok {
+			return &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("sum expects a list of integers but got %s", element.Type())}
+		}
+
+		total += integer.Value
+	}
+
+	return &Integer{Value: total}
+}
+
+// toInt(string) parses a decimal integer, surrounding whitespace is ignored
+func toInt(position token.Token, args ...Object) Object {
+	if len(args) != 1 {
+		return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("toInt expects 1 argument but got %d", len(args))}
+	}
+
+	str, ok := args[0].(*String)
+	if !ok {
+		return &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("toInt expects a string but got %s", args[0].Type())}
+	}
+
+	value, err := strconv.ParseInt(strings.TrimSpace(str.Value), 10, 64)
+	if err != nil {
+		return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("toInt can not parse %q as integer", str.Value)}
+	}
+
+	return &Integer{Value: value}
+}
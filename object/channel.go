@@ -0,0 +1,466 @@
+package object
+
+import (
+	"curryLang/token"
+	"fmt"
+	"sync"
+)
+
+const (
+	// CHANNEL_ERROR is raised by sends on closed channels and by closing a channel twice
+	CHANNEL_ERROR ErrorKind = "ChannelError"
+	// DEADLOCK_ERROR is raised in every blocked task once no task can run anymore, it can not be caught
+	DEADLOCK_ERROR ErrorKind = "DeadlockError"
+)
+
+// Scheduler runs the tasks of a program. Only one task runs at a time, the others wait until it blocks on a
+// channel or ends, so the values shared by tasks are never accessed concurrently. The task creating the
+// scheduler is the main task and is running right away.
+type Scheduler struct {
+	// held by the running task
+	running sync.Mutex
+	current *task
+	main    *task
+
+	spawned sync.WaitGroup // goroutines of the spawned tasks which did not end yet
+
+	// lock guards the state below and the channels created by the scheduler, a single lock per scheduler lets
+	// a select wait on several channels at once while independent programs never block each other
+	lock     sync.Mutex
+	tasks    int // number of started tasks, used as id of the next one
+	runnable int // tasks which are not blocked on a channel, including the ones waiting to run
+	blocked  map[*waiter]bool
+	failure  *Error // error of a spawned task which has not been raised in the main task yet
+	cancel   bool   // set while Cancel ends the spawned tasks
+
+	builtins []*Builtin
+}
+
+type task struct {
+	id int
+}
+
+func NewScheduler() *Scheduler {
+	main := &task{id: 0}
+	scheduler := &Scheduler{current: main, main: main, tasks: 1, runnable: 1, blocked: map[*waiter]bool{}}
+	scheduler.builtins = []*Builtin{
+		{Name: "channel", Function: scheduler.newChannel},
+	}
+
+	scheduler.running.Lock()
+
+	return scheduler
+}
+
+// Builtins returns the builtins of BuiltinNames which are bound to this scheduler
+func (scheduler *Scheduler) Builtins() []*Builtin {
+	return scheduler.builtins
+}
+
+// Spawn runs the function in a new task once the running tasks blocks or ends. An error of the task is raised
+// in the main task when it waits on a channel, spawned tasks which did not start when the main task ends never run.
+func (scheduler *Scheduler) Spawn(run func() *Error) {
+	scheduler.lock.Lock()
+	spawned := &task{id: scheduler.tasks}
+	scheduler.tasks++
+	scheduler.runnable++
+	scheduler.lock.Unlock()
+
+	scheduler.spawned.Add(1)
+	go func() {
+		defer scheduler.spawned.Done()
+
+		scheduler.running.Lock()
+		scheduler.current = spawned
+
+		var err *Error
+		if !scheduler.cancelled() {
+			err = run()
+		}
+
+		scheduler.lock.Lock()
+		scheduler.runnable--
+		if err != nil && err.Kind != DEADLOCK_ERROR && !scheduler.cancel {
+			scheduler.fail(err)
+		}
+		scheduler.detectDeadlock()
+		scheduler.lock.Unlock()
+
+		scheduler.running.Unlock()
+	}()
+}
+
+// SelectCase is a channel operation of a select, Value is sent if Send is set
+type SelectCase struct {
+	Channel *Channel
+	Send    bool
+	Value   Object
+}
+
+// Select performs the first case which can proceed and returns its index and the received value, which is nil for
+// sends and receives from closed channels. If no case can proceed, the running task waits for the first one
+// which can, unless block is false, then -1 is returned. The channels have to be created by this scheduler.
+func (scheduler *Scheduler) Select(cases []SelectCase, block bool) (int, Object, *Error) {
+	for _, selectCase := range cases {
+		if selectCase.Channel.scheduler != scheduler {
+			return -1, nil, &Error{Kind: CHANNEL_ERROR, Message: "channel was created by another program"}
+		}
+	}
+
+	scheduler.lock.Lock()
+
+	if scheduler.cancel {
+		scheduler.lock.Unlock()
+		return -1, nil, taskCancelled()
+	}
+
+	for i, selectCase := range cases {
+		ready, value, err := selectCase.try()
+		if ready || err != nil {
+			scheduler.lock.Unlock()
+			return i, value, err
+		}
+	}
+
+	if !block {
+		scheduler.lock.Unlock()
+		return -1, nil, nil
+	}
+
+	blocked := &waiter{scheduler: scheduler, task: scheduler.current, cases: cases, wake: make(chan struct{}, 1)}
+	for i, selectCase := range cases {
+		selectCase.Channel.enqueue(blocked, i, selectCase.Send)
+	}
+
+	scheduler.blocked[blocked] = true
+	scheduler.runnable--
+	scheduler.detectDeadlock()
+	scheduler.lock.Unlock()
+
+	scheduler.running.Unlock()
+	<-blocked.wake
+	scheduler.running.Lock()
+	scheduler.current = blocked.task
+
+	err := blocked.err
+	if err == nil && blocked.task == scheduler.main {
+		err = scheduler.takeFailure()
+	}
+
+	return blocked.index, blocked.value, err
+}
+
+// Cancel ends the spawned tasks once the main task finished, it has to be called by the main task. Tasks which did
+// not start never run and blocked tasks are woken with an error which can not be caught. Cancel returns once all
+// goroutines of the tasks ended, afterwards the scheduler can run the next program.
+func (scheduler *Scheduler) Cancel() {
+	scheduler.lock.Lock()
+	scheduler.cancel = true
+	for blocked := range scheduler.blocked {
+		blocked.complete(-1, nil, taskCancelled())
+	}
+	scheduler.lock.Unlock()
+
+	scheduler.running.Unlock()
+	scheduler.spawned.Wait()
+	scheduler.running.Lock()
+
+	scheduler.lock.Lock()
+	scheduler.current = scheduler.main
+	scheduler.cancel = false
+	scheduler.failure = nil
+	scheduler.lock.Unlock()
+}
+
+func (scheduler *Scheduler) cancelled() bool {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	return scheduler.cancel
+}
+
+func taskCancelled() *Error {
+	return &Error{Kind: ABORT_ERROR, Message: "task was cancelled because the main task ended"}
+}
+
+// detectDeadlock wakes all blocked tasks with an error once none of them can be woken by a running task anymore
+func (scheduler *Scheduler) detectDeadlock() {
+	if scheduler.runnable > 0 {
+		return
+	}
+
+	for blocked := range scheduler.blocked {
+		blocked.complete(-1, nil, &Error{Kind: DEADLOCK_ERROR, Message: "all tasks are blocked on channels, deadlock"})
+	}
+}
+
+// fail keeps the error of a spawned task until the main task wakes up, it is woken if it is blocked
+func (scheduler *Scheduler) fail(err *Error) {
+	if scheduler.failure != nil {
+		return
+	}
+
+	scheduler.failure = err
+
+	for blocked := range scheduler.blocked {
+		if blocked.task == scheduler.main {
+			blocked.complete(-1, nil, nil)
+		}
+	}
+}
+
+func (scheduler *Scheduler) takeFailure() *Error {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	err := scheduler.failure
+	scheduler.failure = nil
+
+	return err
+}
+
+// waiter is a task blocked on the channels of its cases, the first case which can proceed completes it
+type waiter struct {
+	scheduler *Scheduler
+	task      *task
+	cases     []SelectCase
+	wake      chan struct{}
+
+	// result of the completed case
+	index int
+	value Object
+	err   *Error
+}
+
+// complete removes the waiter from all channels and lets its task continue with the result
+func (waiter *waiter) complete(index int, value Object, err *Error) {
+	for _, selectCase := range waiter.cases {
+		selectCase.Channel.remove(waiter)
+	}
+
+	waiter.index = index
+	waiter.value = value
+	waiter.err = err
+
+	delete(waiter.scheduler.blocked, waiter)
+	waiter.scheduler.runnable++
+	waiter.wake <- struct{}{}
+}
+
+// waiting is the case of a waiter which sends to or receives from a channel
+type waiting struct {
+	waiter *waiter
+	index  int
+}
+
+func (waiting waiting) value() Object {
+	return waiting.waiter.cases[waiting.index].Value
+}
+
+// Channel passes values between tasks, sends wait until the value is received or fits into the buffer
+type Channel struct {
+	capacity int
+	buffer   []Object
+	closed   bool
+
+	senders   []waiting
+	receivers []waiting
+
+	scheduler *Scheduler // scheduler of the program which created the channel
+}
+
+func NewChannel(scheduler *Scheduler, capacity int) *Channel {
+	return &Channel{scheduler: scheduler, capacity: capacity}
+}
+
+func (channel *Channel) Type() ObjectType { return CHANNEL_OBJ }
+func (channel *Channel) Inspect() string  { return fmt.Sprintf("channel(%d)", channel.capacity) }
+
+// GetField returns the methods send, receive and close or the number of buffered values as length and the capacity
+func (channel *Channel) GetField(name string) (Object, *Error) {
+	switch name {
+	case "send":
+		return &Builtin{Name: "send", Function: channel.send}, nil
+	case "receive":
+		return &Builtin{Name: "receive", Function: channel.receive}, nil
+	case "close":
+		return &Builtin{Name: "close", Function: channel.close}, nil
+	case "length":
+		channel.scheduler.lock.Lock()
+		defer channel.scheduler.lock.Unlock()
+
+		return &Integer{Value: int64(len(channel.buffer))}, nil
+	case "capacity":
+		return &Integer{Value: int64(channel.capacity)}, nil
+	}
+
+	return nil, &Error{Kind: FIELD_ERROR, Message: fmt.Sprintf("channel has no field or method %s", name)}
+}
+
+func (channel *Channel) SetField(name string, value Object) *Error {
+	return &Error{Kind: PERMISSION_ERROR, Message: fmt.Sprintf("field %s of channel is read-only", name)}
+}
+
+// Close wakes all waiting receivers with null and fails all waiting senders
+func (channel *Channel) Close() *Error {
+	channel.scheduler.lock.Lock()
+	defer channel.scheduler.lock.Unlock()
+
+	if channel.closed {
+		return &Error{Kind: CHANNEL_ERROR, Message: "close of closed channel"}
+	}
+
+	channel.closed = true
+
+	for len(channel.receivers) > 0 {
+		receiver := channel.receivers[0]
+		receiver.waiter.complete(receiver.index, nil, nil)
+	}
+
+	for len(channel.senders) > 0 {
+		sender := channel.senders[0]
+		sender.waiter.complete(sender.index, nil, sendOnClosedChannel())
+	}
+
+	return nil
+}
+
+func (channel *Channel) send(position token.Token, args ...Object) Object {
+	if len(args) != 1 {
+		return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("send expects 1 argument but got %d", len(args))}
+	}
+
+	_, _, err := channel.scheduler.Select([]SelectCase{{Channel: channel, Send: true, Value: args[0]}}, true)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (channel *Channel) receive(position token.Token, args ...Object) Object {
+	if len(args) != 0 {
+		return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("receive expects no arguments but got %d", len(args))}
+	}
+
+	_, value, err := channel.scheduler.Select([]SelectCase{{Channel: channel}}, true)
+	if err != nil {
+		return err
+	}
+
+	return value
+}
+
+func (channel *Channel) close(position token.Token, args ...Object) Object {
+	if len(args) != 0 {
+		return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("close expects no arguments but got %d", len(args))}
+	}
+
+	if err := channel.Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// try performs the case if it can proceed without waiting
+func (selectCase SelectCase) try() (bool, Object, *Error) {
+	channel := selectCase.Channel
+
+	if selectCase.Send {
+		if channel.closed {
+			return false, nil, sendOnClosedChannel()
+		}
+
+		if len(channel.receivers) > 0 {
+			receiver := channel.receivers[0]
+			receiver.waiter.complete(receiver.index, selectCase.Value, nil)
+			return true, nil, nil
+		}
+
+		if len(channel.buffer) < channel.capacity {
+			channel.buffer = append(channel.buffer, selectCase.Value)
+			return true, nil, nil
+		}
+
+		return false, nil, nil
+	}
+
+	if len(channel.buffer) > 0 {
+		value := channel.buffer[0]
+		channel.buffer = channel.buffer[1:]
+
+		// the first waiting sender moves its value into the freed slot
+		if len(channel.senders) > 0 {
+			sender := channel.senders[0]
+			channel.buffer = append(channel.buffer, sender.value())
+			sender.waiter.complete(sender.index, nil, nil)
+		}
+
+		return true, value, nil
+	}
+
+	if len(channel.senders) > 0 {
+		sender := channel.senders[0]
+		value := sender.value()
+		sender.waiter.complete(sender.index, nil, nil)
+		return true, value, nil
+	}
+
+	if channel.closed {
+		return true, nil, nil
+	}
+
+	return false, nil, nil
+}
+
+func (channel *Channel) enqueue(waiter *waiter, index int, send bool) {
+	if send {
+		channel.senders = append(channel.senders, waiting{waiter: waiter, index: index})
+	} else {
+		channel.receivers = append(channel.receivers, waiting{waiter: waiter, index: index})
+	}
+}
+
+func (channel *Channel) remove(waiter *waiter) {
+	channel.senders = removeWaiter(channel.senders, waiter)
+	channel.receivers = removeWaiter(channel.receivers, waiter)
+}
+
+func removeWaiter(queue []waiting, waiter *waiter) []waiting {
+	kept := queue[:0]
+	for _, waiting := range queue {
+		if waiting.waiter != waiter {
+			kept = append(kept, waiting)
+		}
+	}
+
+	return kept
+}
+
+func sendOnClosedChannel() *Error {
+	return &Error{Kind: CHANNEL_ERROR, Message: "send on closed channel"}
+}
+
+// newChannel implements channel(capacity), the capacity defaults to 0, so every send waits for its receiver
+func (scheduler *Scheduler) newChannel(position token.Token, args ...Object) Object {
+	if len(args) > 1 {
+		return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("channel expects at most 1 argument but got %d", len(args))}
+	}
+
+	capacity := 0
+	if len(args) == 1 {
+		integer, ok := args[0].(*Integer)
+		if !ok {
+			return &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("channel expects an integer capacity but got %s", args[0].Type())}
+		}
+
+		if integer.Value < 0 {
+			return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("channel capacity can not be negative, got %d", integer.Value)}
+		}
+
+		capacity = int(integer.Value)
+	}
+
+	return NewChannel(scheduler, capacity)
+}
@@ -0,0 +1,165 @@
+package object
+
+import (
+	"bytes"
+	"curryLang/token"
+	"fmt"
+)
+
+// ErrorKind classifies runtime errors, so callers can react to them without parsing the message
+type ErrorKind string
+
+const (
+	RUNTIME_ERROR     ErrorKind = "RuntimeError"
+	NAME_ERROR        ErrorKind = "NameError"
+	TYPE_ERROR        ErrorKind = "TypeError"
+	FIELD_ERROR       ErrorKind = "FieldError"
+	INDEX_ERROR       ErrorKind = "IndexError"
+	ARGUMENT_ERROR    ErrorKind = "ArgumentError"
+	DECLARATION_ERROR ErrorKind = "DeclarationError"
+	IMPORT_ERROR      ErrorKind = "ImportError"
+	IO_ERROR          ErrorKind = "IOError"
+	PERMISSION_ERROR  ErrorKind = "PermissionError"
+
+	// THROWN_ERROR is the kind of values thrown by the program which are not errors themselves
+	THROWN_ERROR ErrorKind = "Error"
+
+	// ABORT_ERROR stops a program on request of its host, like a debugger, it can not be caught
+	ABORT_ERROR ErrorKind = "AbortError"
+)
+
+// ErrorStruct is the struct of the errors bound by catch blocks
+var ErrorStruct = &Struct{
+	Name:    "Error",
+	Fields:  []string{"kind", "message", "value"},
+	Methods: map[string]*Function{},
+	Traits:  map[string]*Trait{},
+}
+
+// Span is the source code position an error was raised at
+type Span struct {
+	File   string
+	Line   int
+	Column int
+	Length int // number of characters, a span never crosses a line
+}
+
+func SpanOf(file string, position token.Token) Span {
+	return Span{File: file, Line: position.Line, Column: position.Column, Length: len(position.Literal)}
+}
+
+func (span Span) String() string {
+	file := span.File
+	if file == "" {
+		file = "<input>"
+	}
+
+	if span.Line == 0 {
+		return file
+	}
+
+	return fmt.Sprintf("%s:%d:%d", file, span.Line, span.Column)
+}
+
+// StackFrame is a function call which was active when the error was raised
+type StackFrame struct {
+	Function string
+	Call     Span // where the function was called, the line is 0 if it was called from Go
+}
+
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Span    Span
+	Value   Object // the thrown value, nil for errors raised by the runtime
+
+	// the called functions, the innermost one first
+	Stack []StackFrame
+}
+
+// Throw turns a thrown value into an error, instances of ErrorStruct are thrown again with their kind
+func Throw(value Object) *Error {
+	if instance, ok := value.(*Instance); ok && instance.Struct == ErrorStruct {
+		err := &Error{Kind: THROWN_ERROR}
+
+		if kind, ok := instance.Fields["kind"].(*String); ok {
+			err.Kind = ErrorKind(kind.Value)
+		}
+
+		if message, ok := instance.Fields["message"].(*String); ok {
+			err.Message = message.Value
+		}
+
+		if thrown := instance.Fields["value"]; thrown != nil && thrown.Type() != NULL_OBJ {
+			err.Value = thrown
+		}
+
+		return err
+	}
+
+	return &Error{Kind: THROWN_ERROR, Message: value.Inspect(), Value: value}
+}
+
+// Instance returns the value catch blocks bind for the error
+func (err *Error) Instance() *Instance {
+	var value Object = &Null{}
+	if err.Value != nil {
+		value = err.Value
+	}
+
+	kind := err.Kind
+	if kind == "" {
+		kind = RUNTIME_ERROR
+	}
+
+	return &Instance{
+		Struct: ErrorStruct,
+		Fields: map[string]Object{
+			"kind":    &String{Value: string(kind)},
+			"message": &String{Value: err.Message},
+			"value":   value,
+		},
+	}
+}
+
+func (err *Error) Type() ObjectType { return ERROR_OBJ }
+func (err *Error) Inspect() string  { return fmt.Sprintf("error#%s", err.Message) }
+
+// Error makes runtime errors usable as Go errors
+func (err *Error) Error() string { return err.Message }
+
+// Traceback prints the call stack like Python, the most recent call last
+func (err *Error) Traceback() string {
+	var out bytes.Buffer
+
+	out.WriteString("Traceback (most recent call last):\n")
+
+	for i := len(err.Stack) - 1; i >= 0; i-- {
+		caller := "<main>"
+		if i+1 < len(err.Stack) {
+			caller = err.Stack[i+1].Function
+		}
+
+		if err.Stack[i].Call.Line != 0 {
+			out.WriteString(fmt.Sprintf("  %s in %s\n", err.Stack[i].Call, caller))
+		}
+	}
+
+	function := "<main>"
+	if len(err.Stack) > 0 {
+		function = err.Stack[0].Function
+	}
+
+	if err.Span.Line != 0 {
+		out.WriteString(fmt.Sprintf("  %s in %s\n", err.Span, function))
+	}
+
+	kind := err.Kind
+	if kind == "" {
+		kind = RUNTIME_ERROR
+	}
+
+	out.WriteString(fmt.Sprintf("%s: %s", kind, err.Message))
+
+	return out.String()
+}
@@ -0,0 +1,86 @@
+package object
+
+import (
+	"curryLang/token"
+	"fmt"
+)
+
+// GENERATOR_ERROR is raised by yields outside of functions and by generators resuming themselves
+const GENERATOR_ERROR ErrorKind = "GeneratorError"
+
+// Resume continues a generator function until its next yield and returns the yielded value,
+// done is set instead once the function returned
+type Resume func() (value Object, done bool, err *Error)
+
+// Generator is returned by calls of functions containing yield. The function only runs while the next value is
+// requested, every call of Next resumes it until it yields again.
+type Generator struct {
+	Name    string
+	resume  Resume
+	running bool
+	done    bool
+}
+
+// NewGenerator returns a generator for the function with the name, the engine calling it provides resume
+func NewGenerator(name string, resume Resume) *Generator {
+	return &Generator{Name: name, resume: resume}
+}
+
+func (generator *Generator) Type() ObjectType { return GENERATOR_OBJ }
+func (generator *Generator) Inspect() string  { return fmt.Sprintf("generator %s", generator.Name) }
+
+// Next returns the next yielded value, done is set once the function returned.
+// A generator is finished once it raised an error.
+func (generator *Generator) Next() (Object, bool, *Error) {
+	if generator.done {
+		return nil, true, nil
+	}
+
+	if generator.running {
+		return nil, false, &Error{Kind: GENERATOR_ERROR, Message: fmt.Sprintf("generator %s is already running", generator.Name)}
+	}
+
+	generator.running = true
+	value, done, err := generator.resume()
+	generator.running = false
+
+	if done || err != nil {
+		generator.done = true
+	}
+
+	return value, done, err
+}
+
+// GetField returns the method next, which returns the next value or null once the generator is done,
+// and the field done
+func (generator *Generator) GetField(name string) (Object, *Error) {
+	switch name {
+	case "next":
+		return &Builtin{Name: "next", Function: generator.next}, nil
+	case "done":
+		return &Boolean{Value: generator.done}, nil
+	}
+
+	return nil, &Error{Kind: FIELD_ERROR, Message: fmt.Sprintf("generator has no field or method %s", name)}
+}
+
+func (generator *Generator) SetField(name string, value Object) *Error {
+	return &Error{Kind: PERMISSION_ERROR, Message: fmt.Sprintf("field %s of generator is read-only", name)}
+}
+
+func (generator *Generator) next(position token.Token, args ...Object) Object {
+	if len(args) != 0 {
+		return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("next expects no arguments but got %d", len(args))}
+	}
+
+	value, done, err := generator.Next()
+	if err != nil {
+		return err
+	}
+
+	if done {
+		return nil
+	}
+
+	return value
+}
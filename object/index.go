@@ -0,0 +1,116 @@
+package object
+
+import "fmt"
+
+// Index returns the element of a list or the character of a string at the index,
+// negative indices count from the end. Strings are indexed by their characters, not by their bytes.
+func Index(source Object, index Object) (Object, *Error) {
+	position, ok := index.(*Integer)
+	if !ok {
+		return nil, &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("index has to be an integer but is %s", index.Type())}
+	}
+
+	switch source := source.(type) {
+	case *List:
+		i, err := resolveIndex(position.Value, len(source.Value))
+		if err != nil {
+			return nil, err
+		}
+
+		return source.Value[i], nil
+
+	case *String:
+		characters := []rune(source.Value)
+
+		i, err := resolveIndex(position.Value, len(characters))
+		if err != nil {
+			return nil, err
+		}
+
+		return &String{Value: string(characters[i])}, nil
+	}
+
+	return nil, &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("values of type %s can not be indexed", source.Type())}
+}
+
+// Slice returns the elements of a list or the characters of a string from start up to end, which is not included.
+// Omitted bounds are nil or null, they default to the start and the end of the source.
+// Negative bounds count from the end like indices.
+func Slice(source Object, start Object, end Object) (Object, *Error) {
+	switch source := source.(type) {
+	case *List:
+		from, to, err := resolveSlice(start, end, len(source.Value))
+		if err != nil {
+			return nil, err
+		}
+
+		elements := make([]Object, to-from)
+		copy(elements, source.Value[from:to])
+
+		return &List{Value: elements, ValueType: source.ValueType}, nil
+
+	case *String:
+		characters := []rune(source.Value)
+
+		from, to, err := resolveSlice(start, end, len(characters))
+		if err != nil {
+			return nil, err
+		}
+
+		return &String{Value: string(characters[from:to])}, nil
+	}
+
+	return nil, &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("values of type %s can not be sliced", source.Type())}
+}
+
+func resolveIndex(index int64, length int) (int, *Error) {
+	resolved := index
+	if resolved < 0 {
+		resolved += int64(length)
+	}
+
+	if resolved < 0 || resolved >= int64(length) {
+		return 0, &Error{Kind: INDEX_ERROR, Message: fmt.Sprintf("index %d out of range for length %d", index, length)}
+	}
+
+	return int(resolved), nil
+}
+
+func resolveSlice(start Object, end Object, length int) (int, int, *Error) {
+	from, err := sliceBound(start, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	to, err := sliceBound(end, int64(length))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	resolvedFrom, resolvedTo := from, to
+	if resolvedFrom < 0 {
+		resolvedFrom += int64(length)
+	}
+	if resolvedTo < 0 {
+		resolvedTo += int64(length)
+	}
+
+	if resolvedFrom < 0 || resolvedTo > int64(length) || resolvedFrom > resolvedTo {
+		return 0, 0, &Error{Kind: INDEX_ERROR, Message: fmt.Sprintf("slice bounds [%d:%d] out of range for length %d", from, to, length)}
+	}
+
+	return int(resolvedFrom), int(resolvedTo), nil
+}
+
+func sliceBound(bound Object, omitted int64) (int64, *Error) {
+	if bound == nil || bound.Type() == NULL_OBJ {
+		return omitted, nil
+	}
+
+	integer, ok := bound.(*Integer)
+	if !ok {
+		return 0, &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("slice bounds have to be integers but got %s", bound.Type())}
+	}
+
+	return integer.Value, nil
+}
@@ -0,0 +1,137 @@
+package object
+
+import "fmt"
+
+// Iterator produces the elements iterated by a for loop
+type Iterator interface {
+	Object
+	// Next returns the key and the value of the next element, done is set instead once there are no more elements
+	Next() (key Object, value Object, done bool, err *Error)
+}
+
+// Range is the lazy sequence of the integers from Start up to End, which is only included if Inclusive is set
+type Range struct {
+	Start     int64
+	End       int64
+	Inclusive bool
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	if r.Inclusive {
+		return fmt.Sprintf("%d..=%d", r.Start, r.End)
+	}
+
+	return fmt.Sprintf("%d..%d", r.Start, r.End)
+}
+
+// Contains reports if the integer is part of the range
+func (r *Range) Contains(value int64) bool {
+	if r.Inclusive {
+		return value >= r.Start && value <= r.End
+	}
+
+	return value >= r.Start && value < r.End
+}
+
+// Iterate returns an iterator over the elements of lists, ranges, generators and instances. The keys of the elements
+// are their indices, instances iterate the names and values of their fields instead, unless their struct implements
+// the Iterator trait. If keys is false, only the field names of instances are iterated as values.
+// ok is false for values which can not be iterated.
+func Iterate(value Object, keys bool) (Iterator, bool) {
+	switch value := value.(type) {
+	case *List:
+		index := 0
+		return &sequence{next: func() (Object, bool, *Error) {
+			if index >= len(value.Value) {
+				return nil, true, nil
+			}
+
+			index++
+			return value.Value[index-1], false, nil
+		}}, true
+
+	case *Range:
+		current := value.Start
+		return &sequence{next: func() (Object, bool, *Error) {
+			if !value.Contains(current) {
+				return nil, true, nil
+			}
+
+			current++
+			return &Integer{Value: current - 1}, false, nil
+		}}, true
+
+	case *Generator:
+		return &sequence{next: value.Next}, true
+
+	case *Instance:
+		if value.Struct.Implements(ITERATOR_TRAIT) && value.Struct.Invoke != nil {
+			return &sequence{next: func() (Object, bool, *Error) {
+				result := value.Struct.Invoke("next", value)
+				if err, ok := result.(*Error); ok {
+					return nil, false, err
+				}
+
+				return result, result == nil || result.Type() == NULL_OBJ, nil
+			}}, true
+		}
+
+		return &fields{instance: value, keys: keys}, true
+
+	case Iterator:
+		return value, true
+	}
+
+	return nil, false
+}
+
+// NewSequence returns an iterator over the values produced by next, the key of every value is its index
+func NewSequence(next func() (Object, bool, *Error)) Iterator {
+	return &sequence{next: next}
+}
+
+// sequence numbers the values produced by a function, the key of every value is its index
+type sequence struct {
+	next  func() (Object, bool, *Error)
+	index int64
+}
+
+func (seq *sequence) Type() ObjectType { return ITERATOR_OBJ }
+func (seq *sequence) Inspect() string  { return "iterator" }
+
+func (seq *sequence) Next() (Object, Object, bool, *Error) {
+	value, done, err := seq.next()
+	if done || err != nil {
+		return nil, nil, done, err
+	}
+
+	seq.index++
+
+	return &Integer{Value: seq.index - 1}, value, false, nil
+}
+
+// fields iterates the fields of an instance in the order of their declaration
+type fields struct {
+	instance *Instance
+	keys     bool
+	index    int
+}
+
+func (fields *fields) Type() ObjectType { return ITERATOR_OBJ }
+func (fields *fields) Inspect() string  { return "iterator" }
+
+func (fields *fields) Next() (Object, Object, bool, *Error) {
+	if fields.index >= len(fields.instance.Struct.Fields) {
+		return nil, nil, true, nil
+	}
+
+	name := fields.instance.Struct.Fields[fields.index]
+	fields.index++
+
+	if !fields.keys {
+		return nil, &String{Value: name}, false, nil
+	}
+
+	return &String{Value: name}, fields.instance.Fields[name], false, nil
+}
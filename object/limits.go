@@ -0,0 +1,105 @@
+package object
+
+import (
+	"context"
+	"fmt"
+)
+
+// errors raised when a program exceeds its Limits, they can not be caught by the program
+const (
+	STEP_LIMIT_ERROR       ErrorKind = "StepLimitError"
+	CALL_DEPTH_ERROR       ErrorKind = "CallDepthError"
+	ALLOCATION_LIMIT_ERROR ErrorKind = "AllocationLimitError"
+	DEADLINE_ERROR         ErrorKind = "DeadlineError"
+)
+
+// STACK_OVERFLOW_ERROR is raised by calls nested deeper than the engine can hold, also when the call depth is not
+// limited. Like the limits it can not be caught.
+const STACK_OVERFLOW_ERROR ErrorKind = "StackOverflowError"
+
+// StackOverflow returns the error of both engines for calls nested too deep
+func StackOverflow() *Error {
+	return &Error{Kind: STACK_OVERFLOW_ERROR, Message: "stack overflow"}
+}
+
+// contextCheckInterval is the number of steps after which the context is checked again
+const contextCheckInterval = 1024
+
+// Limits restrict the resources a program can use, zero values are not limited
+type Limits struct {
+	MaxSteps       int64 // evaluated nodes or executed instructions
+	MaxCallDepth   int
+	MaxAllocations int64 // created lists, instances and concatenated strings
+	MaxListSize    int
+
+	// the program is stopped once the context is done
+	Context context.Context
+}
+
+// Usage counts the resources used by a program, engines running the same program share it
+type Usage struct {
+	Steps       int64
+	Allocations int64
+	CallDepth   int
+}
+
+// Step counts an evaluated node or executed instruction
+func (limits *Limits) Step(usage *Usage) *Error {
+	usage.Steps++
+
+	if limits.MaxSteps > 0 && usage.Steps > limits.MaxSteps {
+		return &Error{Kind: STEP_LIMIT_ERROR, Message: fmt.Sprintf("step limit of %d exceeded", limits.MaxSteps)}
+	}
+
+	if limits.Context != nil && usage.Steps%contextCheckInterval == 0 {
+		return limits.checkContext()
+	}
+
+	return nil
+}
+
+// Enter counts a function call, the caller has to decrease usage.CallDepth once the call returns
+func (limits *Limits) Enter(usage *Usage) *Error {
+	if limits.MaxCallDepth > 0 && usage.CallDepth >= limits.MaxCallDepth {
+		return &Error{Kind: CALL_DEPTH_ERROR, Message: fmt.Sprintf("call depth limit of %d exceeded", limits.MaxCallDepth)}
+	}
+
+	usage.CallDepth++
+
+	return nil
+}
+
+// Allocate counts a created object, size is the number of elements of a list
+func (limits *Limits) Allocate(usage *Usage, size int) *Error {
+	usage.Allocations++
+
+	if limits.MaxAllocations > 0 && usage.Allocations > limits.MaxAllocations {
+		return &Error{Kind: ALLOCATION_LIMIT_ERROR, Message: fmt.Sprintf("allocation limit of %d objects exceeded", limits.MaxAllocations)}
+	}
+
+	if limits.MaxListSize > 0 && size > limits.MaxListSize {
+		return &Error{Kind: ALLOCATION_LIMIT_ERROR, Message: fmt.Sprintf("list size limit of %d exceeded with %d elements", limits.MaxListSize, size)}
+	}
+
+	return nil
+}
+
+func (limits *Limits) checkContext() *Error {
+	err := limits.Context.Err()
+	if err == nil {
+		return nil
+	}
+
+	return &Error{Kind: DEADLINE_ERROR, Message: fmt.Sprintf("execution stopped: %s", err)}
+}
+
+// Catchable reports if the error can be handled by a try statement,
+// exceeded limits, stack overflows, deadlocks and aborted programs always stop the program
+func (err *Error) Catchable() bool {
+	switch err.Kind {
+	case STEP_LIMIT_ERROR, CALL_DEPTH_ERROR, STACK_OVERFLOW_ERROR, ALLOCATION_LIMIT_ERROR, DEADLINE_ERROR, ABORT_ERROR, DEADLOCK_ERROR:
+		return false
+	}
+
+	return true
+}
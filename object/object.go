@@ -1,21 +1,37 @@
 package object
 
 import (
+	"bytes"
 	"curryLang/ast"
+	"curryLang/code"
+	"curryLang/token"
 	"fmt"
 )
 
 type ObjectType string
 
 const (
-	INTEGER_OBJ  = "INTEGER"
-	BOOLEAN_OBJ  = "BOOLEAN"
-	STRING_OBJ   = "STRING"
-	FUNCITON_OBJ = "FUNCTION"
-	LIST_OBJ     = "LIST"
-	PACKAGE_OBJ  = "PACKAGE"
-	ERROR_OBJ    = "ERROR"
-	NULL_OBJ     = "NULL"
+	INTEGER_OBJ           = "INTEGER"
+	BOOLEAN_OBJ           = "BOOLEAN"
+	STRING_OBJ            = "STRING"
+	FUNCITON_OBJ          = "FUNCTION"
+	BUILTIN_OBJ           = "BUILTIN"
+	COMPILED_FUNCTION_OBJ = "COMPILED_FUNCTION"
+	LIST_OBJ              = "LIST"
+	PACKAGE_OBJ           = "PACKAGE"
+	STRUCT_OBJ            = "STRUCT"
+	TRAIT_OBJ             = "TRAIT"
+	INSTANCE_OBJ          = "INSTANCE"
+	ERROR_OBJ             = "ERROR"
+	HOST_OBJ              = "HOST"
+	CHANNEL_OBJ           = "CHANNEL"
+	GENERATOR_OBJ         = "GENERATOR"
+	RANGE_OBJ             = "RANGE"
+	ITERATOR_OBJ          = "ITERATOR"
+	PATTERN_OBJ           = "PATTERN"
+	JUMP_TABLE_OBJ        = "JUMP_TABLE"
+	BOUND_METHOD_OBJ      = "BOUND_METHOD"
+	NULL_OBJ              = "NULL"
 )
 
 type Object interface {
@@ -48,11 +64,45 @@ type Function struct {
 	Name       string
 	Parameters []ast.Parameter
 	Code       []ast.Statement
+	File       string // source file declaring the function, empty if unknown
+	Generator  bool   // the function contains yield, calling it returns a generator
 }
 
 func (function *Function) Type() ObjectType { return FUNCITON_OBJ }
 func (function *Function) Inspect() string  { return fmt.Sprintf("fn %s", function.Name) }
 
+// CompiledFunction is a function compiled to bytecode for the virtual machine
+type CompiledFunction struct {
+	Name          string
+	Instructions  code.Instructions
+	NumLocals     int
+	NumParameters int
+	Signature     *Signature     // binds the arguments of calls which do not pass every parameter by position
+	Handlers      []code.Handler // innermost try blocks come first
+	Generator     bool           // the function contains yield, calling it returns a generator
+
+	// debug information, the source positions of the statements and instructions
+	// and the names of the locals by their index
+	Statements *code.Statements
+	Locals     []string
+}
+
+func (function *CompiledFunction) Type() ObjectType { return COMPILED_FUNCTION_OBJ }
+func (function *CompiledFunction) Inspect() string {
+	return fmt.Sprintf("compiled fn %s", function.Name)
+}
+
+// BuiltinFunction is implemented in Go, position is the token of the call expression
+type BuiltinFunction func(position token.Token, args ...Object) Object
+
+type Builtin struct {
+	Name     string
+	Function BuiltinFunction
+}
+
+func (builtin *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (builtin *Builtin) Inspect() string  { return fmt.Sprintf("builtin %s", builtin.Name) }
+
 type List struct {
 	ValueType ObjectType
 	Value     []Object
@@ -61,24 +111,202 @@ type List struct {
 func (list *List) Type() ObjectType { return LIST_OBJ }
 func (list *List) Inspect() string  { return "list<" + string(list.ValueType) + ">" }
 
+// FunctionInvoker calls a function inside of the engine which declared it
+type FunctionInvoker func(function *Function, args []Object, named []NamedArgument) Object
+
 type Package struct {
 	ValueType ObjectType
 	Name      string
+	Path      string // import path
 	Globals   map[string]Object
 	Functions map[string]*Function
+	Structs   map[string]*Struct
+	Invoke    FunctionInvoker
 }
 
 func (pkg *Package) Type() ObjectType { return PACKAGE_OBJ }
 func (pkg *Package) Inspect() string  { return "Package " + pkg.Name }
 
+// MethodInvoker calls the method with the given name with self bound to the given instance, it is provided by the
+// executing engine
+type MethodInvoker func(name string, self Object, args ...Object) Object
+
+type Struct struct {
+	Name    string
+	Fields  []string
+	Methods map[string]*Function
+	Traits  map[string]*Trait
+	Invoke  MethodInvoker
+
+	// CompiledMethods are the methods of the struct compiled for the virtual machine
+	CompiledMethods map[string]*CompiledFunction
+}
+
+func (s *Struct) Type() ObjectType { return STRUCT_OBJ }
+func (s *Struct) Inspect() string  { return "struct " + s.Name }
+
+func (s *Struct) HasField(name string) bool {
+	for _, field := range s.Fields {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Struct) Implements(trait string) bool {
+	_, ok := s.Traits[trait]
+	return ok
+}
+
+const (
+	// STRINGER_TRAIT is used by Inspect for instances
+	STRINGER_TRAIT = "Stringer"
+	// COMPARABLE_TRAIT is used by the < and > operators for instances
+	COMPARABLE_TRAIT = "Comparable"
+	// ITERATOR_TRAIT is used by for loops for instances, they call next until it returns null
+	ITERATOR_TRAIT = "Iterator"
+)
+
+type Trait struct {
+	Name            string
+	RequiredMethods []*Function // only name and parameters are set
+	DefaultMethods  map[string]*Function
+}
+
+func (trait *Trait) Type() ObjectType { return TRAIT_OBJ }
+func (trait *Trait) Inspect() string  { return "trait " + trait.Name }
+
+// BuiltinTraits returns the traits which are known by the language itself
+func BuiltinTraits() map[string]*Trait {
+	return map[string]*Trait{
+		STRINGER_TRAIT: {
+			Name: STRINGER_TRAIT,
+			RequiredMethods: []*Function{
+				{Name: "string", Parameters: []ast.Parameter{{Name: "self"}}},
+			},
+			DefaultMethods: map[string]*Function{},
+		},
+		COMPARABLE_TRAIT: {
+			Name: COMPARABLE_TRAIT,
+			RequiredMethods: []*Function{
+				{Name: "compare", Parameters: []ast.Parameter{{Name: "self"}, {Name: "other"}}},
+			},
+			DefaultMethods: map[string]*Function{},
+		},
+		ITERATOR_TRAIT: {
+			Name: ITERATOR_TRAIT,
+			RequiredMethods: []*Function{
+				{Name: "next", Parameters: []ast.Parameter{{Name: "self"}}},
+			},
+			DefaultMethods: map[string]*Function{},
+		},
+	}
+}
+
+// BoundMethod is a compiled method together with the instance it was accessed on, calling it passes the instance as self
+type BoundMethod struct {
+	Self   *Instance
+	Method *CompiledFunction
+}
+
+func (method *BoundMethod) Type() ObjectType { return BOUND_METHOD_OBJ }
+func (method *BoundMethod) Inspect() string {
+	return fmt.Sprintf("method %s.%s", method.Self.Struct.Name, method.Method.Name)
+}
+
+type Instance struct {
+	Struct *Struct
+	Fields map[string]Object
+}
+
+func (instance *Instance) Type() ObjectType { return INSTANCE_OBJ }
+func (instance *Instance) Inspect() string {
+	if instance.Struct.Implements(STRINGER_TRAIT) && instance.Struct.Invoke != nil {
+		result := instance.Struct.Invoke("string", instance)
+		if str, ok := result.(*String); ok {
+			return str.Value
+		}
+	}
+
+	var out bytes.Buffer
+
+	out.WriteString(instance.Struct.Name)
+	out.WriteString("{")
+
+	for i, field := range instance.Struct.Fields {
+		out.WriteString(field)
+		out.WriteString(": ")
+		out.WriteString(instance.Fields[field].Inspect())
+		if i < len(instance.Struct.Fields)-1 {
+			out.WriteString(", ")
+		}
+	}
+
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// HostObject is a value of the program embedding Curry, like a Go struct, whose fields are accessed by name.
+// Methods are fields holding builtins which are bound to the object.
+type HostObject interface {
+	Object
+	GetField(name string) (Object, *Error)
+	SetField(name string, value Object) *Error
+}
+
 type Null struct{}
 
 func (i *Null) Type() ObjectType { return NULL_OBJ }
 func (i *Null) Inspect() string  { return "null" }
 
-type Error struct {
-	Message string
-}
+// Equal compares two objects by value, instances and lists are compared field by field
+func Equal(left Object, right Object) bool {
+	if left.Type() != right.Type() {
+		return false
+	}
 
-func (err *Error) Type() ObjectType { return ERROR_OBJ }
-func (err *Error) Inspect() string  { return fmt.Sprintf("error#%s", err.Message) }
+	switch left := left.(type) {
+	case *Integer:
+		return left.Value == right.(*Integer).Value
+	case *Boolean:
+		return left.Value == right.(*Boolean).Value
+	case *String:
+		return left.Value == right.(*String).Value
+	case *Null:
+		return true
+	case *Range:
+		rightRange := right.(*Range)
+		return left.Start == rightRange.Start && left.End == rightRange.End && left.Inclusive == rightRange.Inclusive
+	case *List:
+		rightList := right.(*List)
+		if len(left.Value) != len(rightList.Value) {
+			return false
+		}
+
+		for i := range left.Value {
+			if !Equal(left.Value[i], rightList.Value[i]) {
+				return false
+			}
+		}
+
+		return true
+	case *Instance:
+		rightInstance := right.(*Instance)
+		if left.Struct != rightInstance.Struct {
+			return false
+		}
+
+		for _, field := range left.Struct.Fields {
+			if !Equal(left.Fields[field], rightInstance.Fields[field]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return left == right
+}
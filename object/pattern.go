@@ -0,0 +1,366 @@
+package object
+
+import (
+	"curryLang/ast"
+	"fmt"
+	"strings"
+)
+
+// MATCH_ERROR is raised by match expressions if none of their arms matches the value
+const MATCH_ERROR ErrorKind = "MatchError"
+
+// Pattern is matched against values by the arms of match expressions. Match appends the values of the variables
+// bound by the pattern to bound, in the order the variables are declared in the pattern.
+type Pattern interface {
+	Object
+	Match(value Object, bound []Object) ([]Object, bool)
+}
+
+// WildcardPattern matches every value
+type WildcardPattern struct{}
+
+func (pattern *WildcardPattern) Type() ObjectType { return PATTERN_OBJ }
+func (pattern *WildcardPattern) Inspect() string  { return "_" }
+
+func (pattern *WildcardPattern) Match(value Object, bound []Object) ([]Object, bool) {
+	return bound, true
+}
+
+// BindingPattern matches every value and binds it to the variable Name
+type BindingPattern struct {
+	Name string
+}
+
+func (pattern *BindingPattern) Type() ObjectType { return PATTERN_OBJ }
+func (pattern *BindingPattern) Inspect() string  { return pattern.Name }
+
+func (pattern *BindingPattern) Match(value Object, bound []Object) ([]Object, bool) {
+	return append(bound, value), true
+}
+
+// LiteralPattern matches values equal to Value, values of other types do not match
+type LiteralPattern struct {
+	Value Object
+}
+
+func (pattern *LiteralPattern) Type() ObjectType { return PATTERN_OBJ }
+func (pattern *LiteralPattern) Inspect() string {
+	if str, ok := pattern.Value.(*String); ok {
+		return fmt.Sprintf("%q", str.Value)
+	}
+
+	return pattern.Value.Inspect()
+}
+
+func (pattern *LiteralPattern) Match(value Object, bound []Object) ([]Object, bool) {
+	return bound, Equal(pattern.Value, value)
+}
+
+// AlternativePattern matches values matching one of its alternatives, which bind no variables
+type AlternativePattern struct {
+	Alternatives []Pattern
+}
+
+func (pattern *AlternativePattern) Type() ObjectType { return PATTERN_OBJ }
+func (pattern *AlternativePattern) Inspect() string {
+	return strings.Join(inspectPatterns(pattern.Alternatives), " | ")
+}
+
+func (pattern *AlternativePattern) Match(value Object, bound []Object) ([]Object, bool) {
+	for _, alternative := range pattern.Alternatives {
+		if _, ok := alternative.Match(value, nil); ok {
+			return bound, true
+		}
+	}
+
+	return bound, false
+}
+
+// ListPattern matches lists whose elements match Elements. Lists with a rest can have more elements,
+// they are bound as list unless the rest is ignored.
+type ListPattern struct {
+	Elements []Pattern
+	HasRest  bool
+	BindRest bool
+}
+
+func (pattern *ListPattern) Type() ObjectType { return PATTERN_OBJ }
+func (pattern *ListPattern) Inspect() string {
+	elements := inspectPatterns(pattern.Elements)
+	if pattern.HasRest {
+		elements = append(elements, "...")
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+func (pattern *ListPattern) Match(value Object, bound []Object) ([]Object, bool) {
+	list, ok := value.(*List)
+	if !ok || len(list.Value) < len(pattern.Elements) || (!pattern.HasRest && len(list.Value) != len(pattern.Elements)) {
+		return bound, false
+	}
+
+	for i, element := range pattern.Elements {
+		bound, ok = element.Match(list.Value[i], bound)
+		if !ok {
+			return bound, false
+		}
+	}
+
+	if pattern.BindRest {
+		rest := make([]Object, len(list.Value)-len(pattern.Elements))
+		copy(rest, list.Value[len(pattern.Elements):])
+		bound = append(bound, &List{Value: rest, ValueType: list.ValueType})
+	}
+
+	return bound, true
+}
+
+// StructPattern matches instances of the struct with the name whose fields match the patterns of Fields,
+// without a name instances of every struct having the fields match
+type StructPattern struct {
+	Name     string
+	Fields   []string
+	Patterns []Pattern
+}
+
+func (pattern *StructPattern) Type() ObjectType { return PATTERN_OBJ }
+func (pattern *StructPattern) Inspect() string {
+	fields := inspectPatterns(pattern.Patterns)
+	for i, field := range pattern.Fields {
+		if fields[i] != field {
+			fields[i] = field + ": " + fields[i]
+		}
+	}
+
+	return pattern.Name + "{" + strings.Join(fields, ", ") + "}"
+}
+
+func (pattern *StructPattern) Match(value Object, bound []Object) ([]Object, bool) {
+	instance, ok := value.(*Instance)
+	if !ok || (pattern.Name != "" && instance.Struct.Name != pattern.Name) {
+		return bound, false
+	}
+
+	for i, field := range pattern.Fields {
+		fieldValue, ok := instance.Fields[field]
+		if !ok {
+			return bound, false
+		}
+
+		bound, ok = pattern.Patterns[i].Match(fieldValue, bound)
+		if !ok {
+			return bound, false
+		}
+	}
+
+	return bound, true
+}
+
+// Destructure matches the value against the pattern of a destructuring let or parameter and returns the bound
+// values, the error explains why the value does not match
+func Destructure(pattern Pattern, value Object) ([]Object, *Error) {
+	bound, ok := pattern.Match(value, nil)
+	if !ok {
+		return nil, mismatch(pattern, value)
+	}
+
+	return bound, nil
+}
+
+// mismatch returns the error for a value which does not match the pattern
+func mismatch(pattern Pattern, value Object) *Error {
+	switch pattern := pattern.(type) {
+	case *ListPattern:
+		list, ok := value.(*List)
+		if !ok {
+			return &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("values of type %s can not be destructured into %s", value.Type(), pattern.Inspect())}
+		}
+
+		if len(list.Value) < len(pattern.Elements) || (!pattern.HasRest && len(list.Value) != len(pattern.Elements)) {
+			return &Error{Kind: MATCH_ERROR, Message: fmt.Sprintf("list of length %d can not be destructured into %s", len(list.Value), pattern.Inspect())}
+		}
+
+		for i, element := range pattern.Elements {
+			if _, ok := element.Match(list.Value[i], nil); !ok {
+				return mismatch(element, list.Value[i])
+			}
+		}
+
+	case *StructPattern:
+		instance, ok := value.(*Instance)
+		if !ok {
+			return &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("values of type %s can not be destructured into %s", value.Type(), pattern.Inspect())}
+		}
+
+		if pattern.Name != "" && instance.Struct.Name != pattern.Name {
+			return &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("instance of %s can not be destructured into %s", instance.Struct.Name, pattern.Inspect())}
+		}
+
+		for i, field := range pattern.Fields {
+			fieldValue, ok := instance.Fields[field]
+			if !ok {
+				return &Error{Kind: FIELD_ERROR, Message: fmt.Sprintf("struct %s has no field %s", instance.Struct.Name, field)}
+			}
+
+			if _, ok := pattern.Patterns[i].Match(fieldValue, nil); !ok {
+				return mismatch(pattern.Patterns[i], fieldValue)
+			}
+		}
+	}
+
+	return &Error{Kind: MATCH_ERROR, Message: fmt.Sprintf("value %s does not match %s", value.Inspect(), pattern.Inspect())}
+}
+
+func inspectPatterns(patterns []Pattern) []string {
+	inspected := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		inspected[i] = pattern.Inspect()
+	}
+
+	return inspected
+}
+
+// JumpKey identifies the integer, string or boolean a jump table jumps for
+type JumpKey struct {
+	Type  ObjectType
+	Value string
+}
+
+// JumpKeyOf returns the key of integers, strings and booleans, ok is false for values of other types
+func JumpKeyOf(value Object) (JumpKey, bool) {
+	switch value.(type) {
+	case *Integer, *String, *Boolean:
+		return JumpKey{Type: value.Type(), Value: value.Inspect()}, true
+	}
+
+	return JumpKey{}, false
+}
+
+// JumpTable maps the literals of the arms of a match expression to the offsets of their bodies,
+// values without a target continue at Default
+type JumpTable struct {
+	Targets map[JumpKey]int
+	Default int
+}
+
+func (table *JumpTable) Type() ObjectType { return JUMP_TABLE_OBJ }
+func (table *JumpTable) Inspect() string {
+	return fmt.Sprintf("jump table (%d targets)", len(table.Targets))
+}
+
+// Target returns the offset the value jumps to
+func (table *JumpTable) Target(value Object) int {
+	key, ok := JumpKeyOf(value)
+	if !ok {
+		return table.Default
+	}
+
+	target, ok := table.Targets[key]
+	if !ok {
+		return table.Default
+	}
+
+	return target
+}
+
+// NewPattern returns the matcher of a parsed pattern, structs looks up the declared structs by their name
+func NewPattern(pattern ast.Pattern, structs func(name string) (*Struct, bool)) (Pattern, *Error) {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		return &WildcardPattern{}, nil
+
+	case *ast.BindingPattern:
+		return &BindingPattern{Name: pattern.Name.Value}, nil
+
+	case *ast.LiteralPattern:
+		return newLiteralPattern(pattern.Value)
+
+	case *ast.AlternativePattern:
+		alternatives, err := newPatterns(pattern.Alternatives, structs)
+		if err != nil {
+			return nil, err
+		}
+
+		return &AlternativePattern{Alternatives: alternatives}, nil
+
+	case *ast.ListPattern:
+		elements, err := newPatterns(pattern.Elements, structs)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ListPattern{Elements: elements, HasRest: pattern.HasRest, BindRest: pattern.Rest != nil}, nil
+
+	case *ast.StructPattern:
+		if pattern.Name == nil {
+			structPattern := &StructPattern{}
+			for _, field := range pattern.Fields {
+				fieldPattern, err := NewPattern(field.Pattern, structs)
+				if err != nil {
+					return nil, err
+				}
+
+				structPattern.Fields = append(structPattern.Fields, field.Name.Value)
+				structPattern.Patterns = append(structPattern.Patterns, fieldPattern)
+			}
+
+			return structPattern, nil
+		}
+
+		structObj, ok := structs(pattern.Name.Value)
+		if !ok {
+			return nil, &Error{Kind: NAME_ERROR, Message: fmt.Sprintf("undeclared struct %s used", pattern.Name.Value)}
+		}
+
+		structPattern := &StructPattern{Name: pattern.Name.Value}
+		for _, field := range pattern.Fields {
+			if !structObj.HasField(field.Name.Value) {
+				return nil, &Error{Kind: FIELD_ERROR, Message: fmt.Sprintf("struct %s has no field %s", pattern.Name.Value, field.Name.Value)}
+			}
+
+			fieldPattern, err := NewPattern(field.Pattern, structs)
+			if err != nil {
+				return nil, err
+			}
+
+			structPattern.Fields = append(structPattern.Fields, field.Name.Value)
+			structPattern.Patterns = append(structPattern.Patterns, fieldPattern)
+		}
+
+		return structPattern, nil
+	}
+
+	return nil, &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("unsupported pattern %s", pattern.String())}
+}
+
+func newPatterns(patterns []ast.Pattern, structs func(name string) (*Struct, bool)) ([]Pattern, *Error) {
+	matchers := make([]Pattern, len(patterns))
+	for i, pattern := range patterns {
+		matcher, err := NewPattern(pattern, structs)
+		if err != nil {
+			return nil, err
+		}
+
+		matchers[i] = matcher
+	}
+
+	return matchers, nil
+}
+
+func newLiteralPattern(literal ast.Expression) (Pattern, *Error) {
+	switch literal := literal.(type) {
+	case *ast.IntegerLiteral:
+		return &LiteralPattern{Value: &Integer{Value: literal.Value}}, nil
+	case *ast.StringLiteral:
+		return &LiteralPattern{Value: &String{Value: literal.Value}}, nil
+	case *ast.Boolean:
+		return &LiteralPattern{Value: &Boolean{Value: literal.Value}}, nil
+	case *ast.PrefixExpression:
+		if integer, ok := literal.Right.(*ast.IntegerLiteral); ok && literal.Operator == "-" {
+			return &LiteralPattern{Value: &Integer{Value: -integer.Value}}, nil
+		}
+	}
+
+	return nil, &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("unsupported literal pattern %s", literal.String())}
+}
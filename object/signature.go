@@ -0,0 +1,141 @@
+package object
+
+import (
+	"curryLang/ast"
+	"fmt"
+)
+
+// Signature describes how the arguments of a call are bound to the parameters of a function
+type Signature struct {
+	Names    []string // the names of the parameters, destructuring parameters have no name
+	Required int      // the number of parameters without default value, they come before the others
+	Variadic bool     // the last parameter collects the remaining positional arguments in a list
+}
+
+// NamedArgument is an argument passed by the name of its parameter
+type NamedArgument struct {
+	Name  string
+	Value Object
+}
+
+// NewSignature returns the signature of the parameters of a function
+func NewSignature(parameters []ast.Parameter) *Signature {
+	signature := &Signature{Names: make([]string, len(parameters))}
+
+	for i, parameter := range parameters {
+		signature.Names[i] = parameter.Name
+
+		if parameter.Variadic {
+			signature.Variadic = true
+		} else if parameter.Default == nil {
+			signature.Required = i + 1
+		}
+	}
+
+	return signature
+}
+
+// Bind returns the arguments of a call in the order of the parameters. Parameters with a default value which got no
+// argument are nil, the variadic parameter gets a list of the remaining positional arguments.
+// The function name is only used in the messages of the errors.
+func (signature *Signature) Bind(function string, args []Object, named []NamedArgument) ([]Object, *Error) {
+	fixed := len(signature.Names)
+	if signature.Variadic {
+		fixed--
+	}
+
+	if len(args) > fixed && !signature.Variadic {
+		return nil, signature.arityError(function, len(args)+len(named))
+	}
+
+	bound := make([]Object, len(signature.Names))
+	copy(bound[:fixed], args)
+
+	if signature.Variadic {
+		var rest []Object
+		if len(args) > fixed {
+			rest = args[fixed:]
+		}
+
+		list, err := variadicList(function, rest)
+		if err != nil {
+			return nil, err
+		}
+
+		bound[fixed] = list
+	}
+
+	for _, argument := range named {
+		index := signature.index(argument.Name)
+		switch {
+		case index < 0:
+			return nil, &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("function %s has no parameter %s", function, argument.Name)}
+		case index == fixed:
+			return nil, &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("variadic parameter %s of %s can not be passed by name", argument.Name, function)}
+		case bound[index] != nil:
+			return nil, &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("argument %s of %s is passed twice", argument.Name, function)}
+		}
+
+		bound[index] = argument.Value
+	}
+
+	for i := 0; i < signature.Required; i++ {
+		if bound[i] != nil {
+			continue
+		}
+
+		if len(named) == 0 || signature.Names[i] == "" {
+			return nil, signature.arityError(function, len(args)+len(named))
+		}
+
+		return nil, &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("missing argument %s for %s", signature.Names[i], function)}
+	}
+
+	return bound, nil
+}
+
+// index returns the position of the parameter with the name, -1 if there is none
+func (signature *Signature) index(name string) int {
+	for i, parameter := range signature.Names {
+		if parameter == name && name != "" {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (signature *Signature) arityError(function string, got int) *Error {
+	fixed := len(signature.Names)
+	if signature.Variadic {
+		fixed--
+	}
+
+	want := fmt.Sprint(signature.Required)
+	if signature.Variadic {
+		want = fmt.Sprintf("at least %d", signature.Required)
+	} else if signature.Required < fixed {
+		want = fmt.Sprintf("%d..%d", signature.Required, fixed)
+	}
+
+	return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf("wrong number of arguments for %s: want=%s, got=%d", function, want, got)}
+}
+
+// variadicList returns the list of the arguments passed to a variadic parameter, they have to be of the same type
+func variadicList(function string, args []Object) (*List, *Error) {
+	list := &List{Value: make([]Object, len(args))}
+	copy(list.Value, args)
+
+	for i, arg := range list.Value {
+		if i == 0 {
+			list.ValueType = arg.Type()
+		} else if arg.Type() != list.ValueType {
+			return nil, &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf(
+				"variadic arguments of %s have to be all of the same type, argument #%d has type %s instead of %s",
+				function, i, arg.Type(), list.ValueType,
+			)}
+		}
+	}
+
+	return list, nil
+}
@@ -16,8 +16,10 @@ type (
 const (
 	_ int = iota
 	LOWEST
+	PIPELINE    // x |> f()
 	EQUALS      // ==
 	LessGreater // > or <
+	RANGE       // a..b or a..=b
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
@@ -27,17 +29,21 @@ const (
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LessGreater,
-	token.GT:       LessGreater,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.DOT:      DotAccess,
-	token.LPAREN:   CALL,
-	token.LBRACKET: ListIndex,
+	token.PIPELINE:        PIPELINE,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LessGreater,
+	token.GT:              LessGreater,
+	token.RANGE:           RANGE,
+	token.RANGE_INCLUSIVE: RANGE,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.DOT:             DotAccess,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        ListIndex,
+	token.LBRACE:          CALL,
 }
 
 type Parser struct {
@@ -48,6 +54,11 @@ type Parser struct {
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 
+	// struct literals are not allowed in conditions, as their { would be ambiguous with the body
+	noStructLiterals bool
+	// short functions "x => x" are not allowed in match guards, as their => would be ambiguous with the arm
+	noArrowFunctions bool
+
 	// error handling
 	errors []string
 }
@@ -66,8 +77,11 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfElseExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionExpression)
+	p.registerPrefix(token.PIPE, p.parseShortFunction)
 	p.registerPrefix(token.QUOTE, p.parseStringExpression)
 	p.registerPrefix(token.LBRACKET, p.parseListExpression)
+	p.registerPrefix(token.SPAWN, p.parseSpawnExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
@@ -81,9 +95,13 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.RANGE, p.parseInfixExpression)
+	p.registerInfix(token.RANGE_INCLUSIVE, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseFunctionCall)
+	p.registerInfix(token.PIPELINE, p.parsePipeline)
 	p.registerInfix(token.LBRACKET, p.parseIndexAccess)
 	p.registerInfix(token.DOT, p.parseDotAccess)
+	p.registerInfix(token.LBRACE, p.parseStructExpression)
 
 	// Read two tokens, so curToken and peekToken are both set
 	p.nextToken()
@@ -129,6 +147,22 @@ func (p *Parser) parseStatement() ast.Statement {
 		statement = p.parseReturnStatement()
 	case token.WHILE:
 		statement = p.parseWhileStatement()
+	case token.FOR:
+		statement = p.parseForInStatement()
+	case token.TRY:
+		statement = p.parseTryStatement()
+	case token.THROW:
+		statement = p.parseThrowStatement()
+	case token.YIELD:
+		statement = p.parseYieldStatement()
+	case token.SELECT:
+		statement = p.parseSelectStatement()
+	case token.STRUCT:
+		statement = p.parseStructStatement()
+	case token.TRAIT:
+		statement = p.parseTraitStatement()
+	case token.IMPL:
+		statement = p.parseImplStatement()
 	case token.IDENT:
 		if p.peekTokenIs(token.ASSIGN) {
 			statement = p.parseAssignmentStatement()
@@ -147,6 +181,11 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 		Token: p.curToken,
 	}
 
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		return p.parseDestructuringLet(statement)
+	}
+
 	if !p.peekTokenIs(token.IDENT) {
 		p.peekError(token.IDENT)
 		return nil
@@ -154,6 +193,10 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	p.nextToken()
 
+	if p.peekTokenIs(token.LBRACE) {
+		return p.parseDestructuringLet(statement)
+	}
+
 	name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	statement.Name = name
 
@@ -162,10 +205,7 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 		p.nextToken()
 		statement.Value = p.parseExpression(LOWEST)
 
-		_, isIfElse := statement.Value.(*ast.IfElseExpression)
-		_, isFunction := statement.Value.(*ast.FunctionExpression)
-
-		if !isIfElse && !isFunction && !p.expectPeek(token.SEMICOLON) {
+		if !p.expectStatementEnd(statement.Value) {
 			return nil
 		}
 	} else {
@@ -177,14 +217,39 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	return statement
 }
 
+// parseDestructuringLet parses "let [a, b] = value;", "let {a, b} = value;" or "let Point{x, y} = value;",
+// the current token is the first token of the pattern
+func (p *Parser) parseDestructuringLet(statement *ast.LetStatement) *ast.LetStatement {
+	statement.Pattern = p.parseSinglePattern()
+	if statement.Pattern == nil || !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+	statement.Value = p.parseExpression(LOWEST)
+
+	if !p.expectStatementEnd(statement.Value) {
+		return nil
+	}
+
+	return statement
+}
+
 func (p *Parser) parsePackageStatement() *ast.PackageStatement {
 	statement := &ast.PackageStatement{
 		Token: p.curToken,
 	}
 
-	p.nextToken()
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
 	statement.Identifier = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
 	return statement
 }
 
@@ -208,8 +273,6 @@ func (p *Parser) parseImportStatement() *ast.ImportStatement {
 			statement.Packages = append(statement.Packages, p.parseQuotedText())
 			p.nextToken()
 		}
-
-		p.nextToken()
 	} else {
 
 		if p.curToken.Type != token.QUOTE {
@@ -219,6 +282,9 @@ func (p *Parser) parseImportStatement() *ast.ImportStatement {
 
 		p.nextToken()
 		statement.Packages = append(statement.Packages, p.parseQuotedText())
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -236,10 +302,7 @@ func (p *Parser) parseAssignmentStatement() *ast.AssignmentStatement {
 		p.nextToken()
 		statement.Value = p.parseExpression(LOWEST)
 
-		_, isIfElse := statement.Value.(*ast.IfElseExpression)
-		_, isFunction := statement.Value.(*ast.FunctionExpression)
-
-		if !isIfElse && !isFunction && !p.expectPeek(token.SEMICOLON) {
+		if !p.expectStatementEnd(statement.Value) {
 			return nil
 		}
 	} else {
@@ -271,7 +334,7 @@ func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 	}
 
 	p.nextToken()
-	statement.Condition = p.parseExpression(LOWEST)
+	statement.Condition = p.parseConditionExpression()
 
 	if !p.expectPeek(token.LBRACE) {
 		p.errors = append(p.errors, "Missing { after while condition")
@@ -280,293 +343,1187 @@ func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 
 	p.nextToken()
 
-	for !p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.EOF) {
+	for p.curToken.Type != token.RBRACE && p.curToken.Type != token.EOF {
 		stmt := p.parseStatement()
 		statement.Body = append(statement.Body, stmt)
-	}
-
-	p.nextToken()
 
-	return statement
-}
+		p.nextToken()
+	}
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
-	stmt := &ast.ExpressionStatement{Token: p.curToken}
-	stmt.Expression = p.parseExpression(LOWEST)
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
-	return stmt
-}
 
-func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	return statement
 }
 
-func (p *Parser) parseExpression(precedence int) ast.Expression {
-	prefix := p.prefixParseFns[p.curToken.Type]
+func (p *Parser) parseForInStatement() *ast.ForInStatement {
+	statement := &ast.ForInStatement{
+		Token: p.curToken,
+	}
 
-	if prefix == nil {
-		p.noPrefixParseFnError(p.curToken.Type)
+	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
 
-	leftExp := prefix()
+	statement.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
-	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
-		infix := p.infixParseFns[p.peekToken.Type]
-		if infix == nil {
-			return leftExp
-		}
+	// the first of two variables is bound to the key of the element
+	if p.peekTokenIs(token.COMMA) {
 		p.nextToken()
-		leftExp = infix(leftExp)
-	}
 
-	return leftExp
-}
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
 
-func (p *Parser) parsePrefixExpression() ast.Expression {
-	expression := &ast.PrefixExpression{
-		Token:    p.curToken,
-		Operator: p.curToken.Literal,
+		statement.Key = statement.Variable
+		statement.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	}
-	p.nextToken()
-	expression.Right = p.parseExpression(PREFIX)
-	return expression
-}
 
-func (p *Parser) parseIndexAccess(left ast.Expression) ast.Expression {
-	expression := &ast.IndexAccessExpression{
-		Token:  p.curToken,
-		Source: left,
+	if !p.expectPeek(token.IN) {
+		return nil
 	}
 
 	p.nextToken()
+	statement.Iterable = p.parseConditionExpression()
 
-	expression.Value = p.parseExpression(PREFIX)
-
-	if !p.expectPeek(token.RBRACKET) {
+	if !p.expectPeek(token.LBRACE) {
+		p.errors = append(p.errors, "Missing { after for loop iterable")
 		return nil
 	}
 
-	return expression
-}
+	statement.Body = p.parseBlockStatements()
 
-func (p *Parser) parseDotAccess(left ast.Expression) ast.Expression {
-	expression := &ast.DotAccessExpression{
-		Token:  p.curToken,
-		Source: left,
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
 	}
 
-	p.nextToken()
-
-	expression.Value = p.parseExpression(DotAccess)
-	return expression
+	return statement
 }
 
-func (p *Parser) parseFunctionCall(left ast.Expression) ast.Expression {
-	expression := &ast.FunctionCallExpression{
-		Token:        p.curToken,
-		FunctionExpr: left,
+func (p *Parser) parseTryStatement() *ast.TryStatement {
+	statement := &ast.TryStatement{
+		Token: p.curToken,
 	}
 
-	expression.Parameters = p.parseCallArguments()
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
 
-	return expression
-}
+	statement.Body = p.parseBlockStatements()
 
-func (p *Parser) parseCallArguments() []ast.Expression {
-	var args []ast.Expression
-	if p.peekTokenIs(token.RPAREN) {
-		p.nextToken()
-		return args
-	}
-	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
-		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
-	}
-	if !p.expectPeek(token.RPAREN) {
+	if !p.expectPeek(token.CATCH) {
+		p.errors = append(p.errors, "Missing catch after try block")
 		return nil
 	}
-	return args
-}
 
-func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
-	expression := &ast.InfixExpression{
-		Token:    p.curToken,
-		Operator: p.curToken.Literal,
-		Left:     left,
-	}
-	precedence := p.curPrecedence()
-	p.nextToken()
-	expression.Right = p.parseExpression(precedence)
-	return expression
-}
+	if p.peekTokenIs(token.LPAREN) {
+		p.nextToken()
 
-func (p *Parser) parseIdentifier() ast.Expression {
-	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-}
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
 
-func (p *Parser) parseBoolean() ast.Expression {
-	booleanValue := p.curToken.Literal == "true"
-	return &ast.Boolean{Token: p.curToken, Value: booleanValue}
-}
+		statement.Parameter = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
-func (p *Parser) parseIntegerLiteral() ast.Expression {
-	lit := &ast.IntegerLiteral{Token: p.curToken}
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
-	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
-	lit.Value = value
-	return lit
-}
 
-func (p *Parser) parseStringExpression() ast.Expression {
-	lit := &ast.StringLiteral{Token: p.curToken}
-	p.nextToken()
+	statement.Handler = p.parseBlockStatements()
 
-	lit.Value = p.parseQuotedText()
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
 
-	return lit
+	return statement
 }
 
-func (p *Parser) parseListExpression() ast.Expression {
-	lit := &ast.ListExpression{Token: p.curToken}
-	p.nextToken()
-
-	lit.Value = make([]ast.Expression, 0)
-
-	for p.curToken.Type != token.RBRACKET {
-		expr := p.parseExpression(LOWEST)
-
-		if expr != nil {
-			lit.Value = append(lit.Value, expr)
-		}
+// parseBlockStatements parses the statements up to the closing brace, the current token has to be the opening one
+func (p *Parser) parseBlockStatements() []ast.Statement {
+	var statements []ast.Statement
 
-		if !p.peekTokenIs(token.COMMA) {
-			break
-		}
+	p.nextToken()
 
-		p.nextToken()
+	for p.curToken.Type != token.RBRACE && p.curToken.Type != token.EOF {
+		statements = append(statements, p.parseStatement())
 		p.nextToken()
 	}
 
-	p.nextToken()
-
-	return lit
+	return statements
 }
 
-func (p *Parser) parseGroupedExpression() ast.Expression {
-	p.nextToken()
-	exp := p.parseExpression(LOWEST)
-	if !p.expectPeek(token.RPAREN) {
-		return nil
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	statement := &ast.ThrowStatement{
+		Token: p.curToken,
 	}
-	return exp
-}
 
-func (p *Parser) parseIfElseExpression() ast.Expression {
-	lit := &ast.IfElseExpression{Token: p.curToken}
 	p.nextToken()
+	statement.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
 
-	lit.Condition = p.parseExpression(LOWEST)
+	return statement
+}
 
-	if !p.expectPeek(token.LBRACE) {
-		return nil
+func (p *Parser) parseYieldStatement() *ast.YieldStatement {
+	statement := &ast.YieldStatement{
+		Token: p.curToken,
 	}
 
 	p.nextToken()
+	statement.Value = p.parseExpression(LOWEST)
 
-	lit.Consequence = []ast.Statement{}
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
 
-	for p.curToken.Type != token.EOF && p.curToken.Type != token.RBRACE {
-		statement := p.parseStatement()
-		lit.Consequence = append(lit.Consequence, statement)
+	return statement
+}
 
-		p.nextToken()
+func (p *Parser) parseSelectStatement() *ast.SelectStatement {
+	statement := &ast.SelectStatement{
+		Token: p.curToken,
 	}
 
-	if p.curToken.Type == token.EOF {
+	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
 
-	p.nextToken()
-
-	if p.curToken.Type == token.ELSE {
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
 
-		if !p.expectPeek(token.LBRACE) {
-			return nil
-		}
+		switch p.curToken.Type {
+		case token.CASE:
+			selectCase := p.parseSelectCase()
+			if selectCase == nil {
+				return nil
+			}
 
-		p.nextToken()
+			statement.Cases = append(statement.Cases, selectCase)
 
-		lit.Alternative = []ast.Statement{}
+		case token.DEFAULT:
+			if statement.HasDefault {
+				p.errors = append(p.errors, "Select statement has more than one default case")
+				return nil
+			}
 
-		for p.curToken.Type != token.EOF && p.curToken.Type != token.RBRACE {
-			statement := p.parseStatement()
-			lit.Alternative = append(lit.Alternative, statement)
+			if !p.expectPeek(token.LBRACE) {
+				return nil
+			}
 
-			p.nextToken()
-		}
+			statement.HasDefault = true
+			statement.Default = p.parseBlockStatements()
 
-		if p.curToken.Type == token.EOF {
+		default:
+			p.errors = append(p.errors, fmt.Sprintf("expected case or default in select statement, got %s instead", p.curToken.Type))
 			return nil
 		}
+	}
 
+	p.nextToken()
+
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
-	return lit
+	return statement
 }
 
-func (p *Parser) parseFunctionExpression() ast.Expression {
-	lit := &ast.FunctionExpression{Token: p.curToken}
+// parseSelectCase parses "case [name =] channel.receive() { ... }" or "case channel.send(value) { ... }"
+func (p *Parser) parseSelectCase() *ast.SelectCase {
+	selectCase := &ast.SelectCase{
+		Token: p.curToken,
+	}
 
-	if p.peekTokenIs(token.IDENT) {
+	p.nextToken()
+
+	if p.curToken.Type == token.IDENT && p.peekTokenIs(token.ASSIGN) {
+		selectCase.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		p.nextToken()
 		p.nextToken()
-		funcNameExpr := p.parseIdentifier()
-		lit.Name = funcNameExpr.String()
 	}
 
-	if !p.expectPeek(token.LPAREN) {
+	operation := p.parseConditionExpression()
+
+	access, ok := operation.(*ast.DotAccessExpression)
+	if !ok {
+		p.selectCaseError(operation)
 		return nil
 	}
 
-	p.nextToken()
-
-	lit.Parameters = []ast.Parameter{}
+	call, ok := access.Value.(*ast.FunctionCallExpression)
+	if !ok {
+		p.selectCaseError(operation)
+		return nil
+	}
 
-	for p.curToken.Type != token.RPAREN {
-		identifier := p.parseIdentifier()
+	method, _ := call.FunctionExpr.(*ast.Identifier)
 
-		if identifier == nil {
-			p.errors = append(p.errors, "Parameter could not be parsed")
-			return nil
+	switch {
+	case method != nil && method.Value == "receive" && len(call.Parameters) == 0:
+		selectCase.Channel = access.Source
+	case method != nil && method.Value == "send" && len(call.Parameters) == 1 && selectCase.Name == nil:
+		selectCase.Channel = access.Source
+		selectCase.Send = true
+		selectCase.Value = call.Parameters[0]
+	default:
+		p.selectCaseError(operation)
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	selectCase.Body = p.parseBlockStatements()
+
+	return selectCase
+}
+
+func (p *Parser) selectCaseError(operation ast.Expression) {
+	description := "nothing"
+	if operation != nil {
+		description = operation.String()
+	}
+
+	p.errors = append(p.errors, fmt.Sprintf("Select cases have to be channel.receive() or channel.send(value), got %s", description))
+}
+
+func (p *Parser) parseStructStatement() *ast.StructStatement {
+	statement := &ast.StructStatement{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	statement.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		statement.Fields = append(statement.Fields, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+		}
+	}
+
+	p.nextToken()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return statement
+}
+
+func (p *Parser) parseTraitStatement() *ast.TraitStatement {
+	statement := &ast.TraitStatement{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	statement.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.FUNCTION) {
+			return nil
+		}
+
+		method := p.parseMethod(false)
+		if method == nil {
+			return nil
+		}
+
+		if method.Body == nil {
+			statement.RequiredMethods = append(statement.RequiredMethods, method)
+		} else {
+			statement.DefaultMethods = append(statement.DefaultMethods, method)
+		}
+	}
+
+	p.nextToken()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return statement
+}
+
+func (p *Parser) parseImplStatement() *ast.ImplStatement {
+	statement := &ast.ImplStatement{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.FOR) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		statement.Trait = name
+		name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	statement.Struct = name
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.FUNCTION) {
+			return nil
+		}
+
+		method := p.parseMethod(true)
+		if method == nil {
+			return nil
+		}
+
+		statement.Methods = append(statement.Methods, method)
+	}
+
+	p.nextToken()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return statement
+}
+
+func (p *Parser) parseExpressionStatement() ast.Statement {
+	stmt := &ast.ExpressionStatement{Token: p.curToken}
+	stmt.Expression = p.parseExpression(LOWEST)
+
+	if target, ok := stmt.Expression.(*ast.DotAccessExpression); ok && p.peekTokenIs(token.ASSIGN) {
+		return p.parseFieldAssignmentStatement(target)
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseFieldAssignmentStatement(target *ast.DotAccessExpression) ast.Statement {
+	statement := &ast.FieldAssignmentStatement{Target: target}
+
+	if _, ok := target.Value.(*ast.Identifier); !ok {
+		p.errors = append(p.errors, fmt.Sprintf("Only fields can be assigned, got %s", target.Value.String()))
+		return nil
+	}
+
+	p.nextToken()
+	p.nextToken()
+	statement.Value = p.parseExpression(LOWEST)
+
+	if !p.expectStatementEnd(statement.Value) {
+		return nil
+	}
+
+	return statement
+}
+
+func (p *Parser) noPrefixParseFnError(t token.TokenType) {
+	msg := fmt.Sprintf("no prefix parse function for %s found", t)
+	p.errors = append(p.errors, msg)
+}
+
+func (p *Parser) parseExpression(precedence int) ast.Expression {
+	prefix := p.prefixParseFns[p.curToken.Type]
+
+	if prefix == nil {
+		p.noPrefixParseFnError(p.curToken.Type)
+		return nil
+	}
+
+	leftExp := prefix()
+
+	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+		infix := p.infixParseFns[p.peekToken.Type]
+		if infix == nil {
+			return leftExp
+		}
+		p.nextToken()
+		leftExp = infix(leftExp)
+	}
+
+	return leftExp
+}
+
+func (p *Parser) parsePrefixExpression() ast.Expression {
+	expression := &ast.PrefixExpression{
+		Token:    p.curToken,
+		Operator: p.curToken.Literal,
+	}
+	p.nextToken()
+	expression.Right = p.parseExpression(PREFIX)
+	return expression
+}
+
+func (p *Parser) parseIndexAccess(left ast.Expression) ast.Expression {
+	expression := &ast.IndexAccessExpression{
+		Token:  p.curToken,
+		Source: left,
+	}
+
+	// the start of a slice can be omitted, list[:3]
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		expression.Value = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		expression.Slice = true
+
+		// the end as well, list[1:]
+		if !p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			expression.End = p.parseExpression(LOWEST)
+		}
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return expression
+}
+
+func (p *Parser) parseDotAccess(left ast.Expression) ast.Expression {
+	expression := &ast.DotAccessExpression{
+		Token:  p.curToken,
+		Source: left,
+	}
+
+	p.nextToken()
+
+	expression.Value = p.parseExpression(DotAccess)
+	return expression
+}
+
+func (p *Parser) parseStructExpression(left ast.Expression) ast.Expression {
+	name, ok := left.(*ast.Identifier)
+	if !ok {
+		p.errors = append(p.errors, fmt.Sprintf("Struct name has to be an identifier, got %s", left.String()))
+		return nil
+	}
+
+	expression := &ast.StructExpression{
+		Token: p.curToken,
+		Name:  name,
+	}
+
+	previous := p.noStructLiterals
+	p.noStructLiterals = false
+	defer func() { p.noStructLiterals = previous }()
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		field := ast.StructField{
+			Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+		}
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		field.Value = p.parseExpression(LOWEST)
+		expression.Fields = append(expression.Fields, field)
+
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+		} else if !p.peekTokenIs(token.RBRACE) {
+			p.peekError(token.RBRACE)
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	return expression
+}
+
+func (p *Parser) parseSpawnExpression() ast.Expression {
+	expression := &ast.SpawnExpression{
+		Token: p.curToken,
+	}
+
+	p.nextToken()
+	expression.Function = p.parseExpression(LOWEST)
+
+	return expression
+}
+
+// parseMatchExpression parses "match value { pattern [if guard] => body, ... }",
+// the body of an arm is an expression or a block
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{
+		Token: p.curToken,
+	}
+
+	p.nextToken()
+	expression.Value = p.parseConditionExpression()
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	previous := p.noStructLiterals
+	p.noStructLiterals = false
+	defer func() { p.noStructLiterals = previous }()
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+
+		expression.Arms = append(expression.Arms, arm)
+
+		// the comma after a block is optional
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+		} else if !p.curTokenIs(token.RBRACE) && !p.peekTokenIs(token.RBRACE) {
+			p.peekError(token.COMMA)
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	return expression
+}
+
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{
+		Token: p.curToken,
+	}
+
+	arm.Pattern = p.parsePattern()
+	if arm.Pattern == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+		p.noArrowFunctions = true
+		arm.Guard = p.parseExpression(LOWEST)
+		p.noArrowFunctions = false
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+
+	p.nextToken()
+
+	if p.curTokenIs(token.LBRACE) {
+		arm.Body = p.parseBlockStatements()
+	} else {
+		body := &ast.ExpressionStatement{Token: p.curToken}
+		body.Expression = p.parseExpression(LOWEST)
+		arm.Body = []ast.Statement{body}
+	}
+
+	return arm
+}
+
+// parsePattern parses a pattern with its alternatives, alternatives can not bind variables
+func (p *Parser) parsePattern() ast.Pattern {
+	pattern := p.parseSinglePattern()
+	if pattern == nil || !p.peekTokenIs(token.PIPE) {
+		return pattern
+	}
+
+	alternatives := &ast.AlternativePattern{Token: p.curToken, Alternatives: []ast.Pattern{pattern}}
+
+	for p.peekTokenIs(token.PIPE) {
+		p.nextToken()
+		p.nextToken()
+
+		pattern = p.parseSinglePattern()
+		if pattern == nil {
+			return nil
+		}
+
+		alternatives.Alternatives = append(alternatives.Alternatives, pattern)
+	}
+
+	if names := ast.PatternBindings(alternatives); len(names) > 0 {
+		p.errors = append(p.errors, fmt.Sprintf("Alternative patterns can not bind variables, got %s", names[0]))
+		return nil
+	}
+
+	return alternatives
+}
+
+func (p *Parser) parseSinglePattern() ast.Pattern {
+	switch p.curToken.Type {
+	case token.IDENT:
+		if p.curToken.Literal == "_" {
+			return &ast.WildcardPattern{Token: p.curToken}
+		}
+
+		name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if p.peekTokenIs(token.LBRACE) {
+			p.nextToken()
+			return p.parseStructPattern(name.Token, name)
+		}
+
+		return &ast.BindingPattern{Name: name}
+
+	case token.LBRACKET:
+		return p.parseListPattern()
+
+	case token.LBRACE:
+		return p.parseStructPattern(p.curToken, nil)
+
+	case token.MINUS:
+		if !p.peekTokenIs(token.INT) {
+			p.peekError(token.INT)
+			return nil
+		}
+
+		pattern := &ast.LiteralPattern{Token: p.curToken}
+		pattern.Value = p.parseExpression(PREFIX)
+		return pattern
+
+	case token.INT, token.QUOTE, token.TRUE, token.FALSE:
+		pattern := &ast.LiteralPattern{Token: p.curToken}
+		pattern.Value = p.parseExpression(PREFIX)
+		return pattern
+	}
+
+	p.errors = append(p.errors, fmt.Sprintf("Expected a pattern, got %s instead", p.curToken.Type))
+	return nil
+}
+
+// parseListPattern parses "[a, b]" or "[first, ...rest]", the rest is the last element
+func (p *Parser) parseListPattern() ast.Pattern {
+	pattern := &ast.ListPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+
+		if p.curTokenIs(token.ELLIPSIS) {
+			pattern.HasRest = true
+
+			if p.peekTokenIs(token.IDENT) {
+				p.nextToken()
+				if p.curToken.Literal != "_" {
+					pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+				}
+			}
+
+			if !p.peekTokenIs(token.RBRACKET) {
+				p.errors = append(p.errors, "The rest has to be the last element of a list pattern")
+				return nil
+			}
+
+			break
+		}
+
+		element := p.parseSinglePattern()
+		if element == nil {
+			return nil
+		}
+
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !p.peekTokenIs(token.RBRACKET) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	return pattern
+}
+
+// parseStructPattern parses "Point{x, y: 0}" or "{x, y: 0}", a field without a pattern is bound to a variable of its name
+func (p *Parser) parseStructPattern(tok token.Token, name *ast.Identifier) ast.Pattern {
+	pattern := &ast.StructPattern{Token: tok, Name: name}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		field := &ast.FieldPattern{Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}}
+
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+
+			field.Pattern = p.parseSinglePattern()
+			if field.Pattern == nil {
+				return nil
+			}
+		} else {
+			field.Pattern = &ast.BindingPattern{Name: field.Name}
+		}
+
+		pattern.Fields = append(pattern.Fields, field)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	return pattern
+}
+
+func (p *Parser) parseFunctionCall(left ast.Expression) ast.Expression {
+	expression := &ast.FunctionCallExpression{
+		Token:        p.curToken,
+		FunctionExpr: left,
+	}
+
+	expression.Parameters, expression.NamedParameters = p.parseCallArguments()
+
+	return expression
+}
+
+// parseCallArguments parses the positional arguments of a call followed by the named ones like "b: 3"
+func (p *Parser) parseCallArguments() ([]ast.Expression, []*ast.NamedArgument) {
+	var args []ast.Expression
+	var named []*ast.NamedArgument
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return args, named
+	}
+
+	for {
+		p.nextToken()
+
+		if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) {
+			argument := &ast.NamedArgument{Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}}
+			p.nextToken()
+			p.nextToken()
+			argument.Value = p.parseExpression(LOWEST)
+			named = append(named, argument)
+		} else if len(named) > 0 {
+			p.errors = append(p.errors, fmt.Sprintf("Positional argument %s can not follow named arguments", p.curToken.Literal))
+			return nil, nil
+		} else {
+			args = append(args, p.parseExpression(LOWEST))
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil, nil
+	}
+	return args, named
+}
+
+func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
+	expression := &ast.InfixExpression{
+		Token:    p.curToken,
+		Operator: p.curToken.Literal,
+		Left:     left,
+	}
+	precedence := p.curPrecedence()
+	p.nextToken()
+	expression.Right = p.parseExpression(precedence)
+	return expression
+}
+
+func (p *Parser) parseIdentifier() ast.Expression {
+	if p.peekTokenIs(token.ARROW) && !p.noArrowFunctions {
+		return p.parseArrowFunction()
+	}
+
+	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+}
+
+func (p *Parser) parseBoolean() ast.Expression {
+	booleanValue := p.curToken.Literal == "true"
+	return &ast.Boolean{Token: p.curToken, Value: booleanValue}
+}
+
+func (p *Parser) parseIntegerLiteral() ast.Expression {
+	lit := &ast.IntegerLiteral{Token: p.curToken}
+	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseStringExpression() ast.Expression {
+	lit := &ast.StringLiteral{Token: p.curToken}
+	p.nextToken()
+
+	lit.Value = p.parseQuotedText()
+
+	return lit
+}
+
+func (p *Parser) parseListExpression() ast.Expression {
+	lit := &ast.ListExpression{Token: p.curToken}
+	lit.Value = make([]ast.Expression, 0)
+
+	// the current token stays on the closing bracket, like for every other expression
+	if p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		return lit
+	}
+
+	p.nextToken()
+
+	for {
+		expr := p.parseExpression(LOWEST)
+
+		if expr != nil {
+			lit.Value = append(lit.Value, expr)
 		}
 
-		lit.Parameters = append(lit.Parameters, ast.Parameter{Name: identifier.String()})
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 
 		p.nextToken()
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return lit
+}
+
+func (p *Parser) parseGroupedExpression() ast.Expression {
+	previous, previousArrow := p.noStructLiterals, p.noArrowFunctions
+	p.noStructLiterals, p.noArrowFunctions = false, false
+	defer func() { p.noStructLiterals, p.noArrowFunctions = previous, previousArrow }()
+
+	p.nextToken()
+	exp := p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	return exp
+}
+
+func (p *Parser) parseIfElseExpression() ast.Expression {
+	lit := &ast.IfElseExpression{Token: p.curToken}
+	p.nextToken()
+
+	lit.Condition = p.parseConditionExpression()
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	p.nextToken()
+
+	lit.Consequence = []ast.Statement{}
+
+	for p.curToken.Type != token.EOF && p.curToken.Type != token.RBRACE {
+		statement := p.parseStatement()
+		lit.Consequence = append(lit.Consequence, statement)
+
+		p.nextToken()
+	}
+
+	if p.curToken.Type == token.EOF {
+		return nil
+	}
+
+	if p.peekTokenIs(token.ELSE) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		p.nextToken()
+
+		lit.Alternative = []ast.Statement{}
+
+		for p.curToken.Type != token.EOF && p.curToken.Type != token.RBRACE {
+			statement := p.parseStatement()
+			lit.Alternative = append(lit.Alternative, statement)
 
-		if p.curToken.Type == token.COMMA {
 			p.nextToken()
 		}
 
+		if p.curToken.Type == token.EOF {
+			return nil
+		}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return lit
+}
+
+// parseConditionExpression parses the condition of if and while, where a following { starts the body
+// expectStatementEnd expects the semicolon after the assigned value, it is optional after values ending with a block
+func (p *Parser) expectStatementEnd(value ast.Expression) bool {
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+		return true
+	}
+
+	switch value.(type) {
+	case *ast.IfElseExpression, *ast.FunctionExpression, *ast.MatchExpression:
+		return true
+	}
+
+	p.peekError(token.SEMICOLON)
+	return false
+}
+
+func (p *Parser) parseConditionExpression() ast.Expression {
+	previous := p.noStructLiterals
+	p.noStructLiterals = true
+	defer func() { p.noStructLiterals = previous }()
+
+	return p.parseExpression(LOWEST)
+}
+
+func (p *Parser) parseFunctionExpression() ast.Expression {
+	lit := &ast.FunctionExpression{Token: p.curToken}
+
+	if p.peekTokenIs(token.IDENT) {
+		p.nextToken()
+		funcNameExpr := p.parseIdentifier()
+		lit.Name = funcNameExpr.String()
+	}
+
+	if !p.parseFunctionParameters(lit) {
+		return nil
 	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
 
+	p.parseFunctionBody(lit)
+
+	return lit
+}
+
+func (p *Parser) parseFunctionParameters(lit *ast.FunctionExpression) bool {
+	if !p.expectPeek(token.LPAREN) {
+		return false
+	}
+
+	p.nextToken()
+
+	lit.Parameters = []ast.Parameter{}
+
+	for p.curToken.Type != token.RPAREN {
+		if p.curToken.Type == token.EOF {
+			p.errors = append(p.errors, "expected next token to be ), got EOF instead")
+			return false
+		}
+
+		parameter := ast.Parameter{}
+
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+
+			parameter.Name = p.curToken.Literal
+			parameter.Variadic = true
+
+			// the parameters and the body are still parsed, so the error is not followed by unrelated ones
+			if !p.peekTokenIs(token.RPAREN) {
+				p.errors = append(p.errors, fmt.Sprintf("The variadic parameter %s has to be the last parameter", parameter.Name))
+			}
+		} else if p.curTokenIs(token.LBRACKET) || p.curTokenIs(token.LBRACE) || (p.curTokenIs(token.IDENT) && p.peekTokenIs(token.LBRACE)) {
+			parameter.Pattern = p.parseSinglePattern()
+			if parameter.Pattern == nil {
+				return false
+			}
+		} else {
+			identifier := p.parseIdentifier()
+
+			if identifier == nil {
+				p.errors = append(p.errors, "Parameter could not be parsed")
+				return false
+			}
+
+			parameter.Name = identifier.String()
+		}
+
+		if p.peekTokenIs(token.ASSIGN) && !parameter.Variadic {
+			p.nextToken()
+			p.nextToken()
+			parameter.Default = p.parseExpression(LOWEST)
+		} else if !parameter.Variadic && len(lit.Parameters) > 0 && lit.Parameters[len(lit.Parameters)-1].Default != nil {
+			p.errors = append(p.errors, fmt.Sprintf("Parameter %s without default value can not follow parameters with default values", parameter.String()))
+		}
+
+		lit.Parameters = append(lit.Parameters, parameter)
+
+		p.nextToken()
+
+		if p.curToken.Type == token.COMMA {
+			p.nextToken()
+		}
+
+	}
+
+	return true
+}
+
+// parseShortFunction parses "|a, b| a + b" or "|[a, b]| { return a + b; }"
+func (p *Parser) parseShortFunction() ast.Expression {
+	lit := &ast.FunctionExpression{Token: p.shortFunctionToken(), Parameters: []ast.Parameter{}}
+
+	for !p.peekTokenIs(token.PIPE) {
+		p.nextToken()
+
+		switch {
+		case p.curTokenIs(token.LBRACKET) || p.curTokenIs(token.LBRACE):
+			pattern := p.parseSinglePattern()
+			if pattern == nil {
+				return nil
+			}
+
+			lit.Parameters = append(lit.Parameters, ast.Parameter{Pattern: pattern})
+		case p.curTokenIs(token.IDENT):
+			lit.Parameters = append(lit.Parameters, ast.Parameter{Name: p.curToken.Literal})
+		default:
+			p.errors = append(p.errors, fmt.Sprintf("Expected a parameter, got %s instead", p.curToken.Type))
+			return nil
+		}
+
+		if !p.peekTokenIs(token.PIPE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+	p.parseShortFunctionBody(lit)
+
+	return lit
+}
+
+// parseArrowFunction parses "x => x + 1", the current token is the parameter
+func (p *Parser) parseArrowFunction() ast.Expression {
+	lit := &ast.FunctionExpression{
+		Token:      p.shortFunctionToken(),
+		Parameters: []ast.Parameter{{Name: p.curToken.Literal}},
+	}
+
+	p.nextToken()
+	p.parseShortFunctionBody(lit)
+
+	return lit
+}
+
+// shortFunctionToken returns a fn token at the position of the current token
+func (p *Parser) shortFunctionToken() token.Token {
+	return token.Token{Type: token.FUNCTION, Literal: "fn", Line: p.curToken.Line, Column: p.curToken.Column}
+}
+
+// parseShortFunctionBody parses a block or a single expression, whose value is returned
+func (p *Parser) parseShortFunctionBody(lit *ast.FunctionExpression) {
+	p.nextToken()
+
+	if p.curTokenIs(token.LBRACE) {
+		lit.Body = p.parseBlockStatements()
+		return
+	}
+
+	body := &ast.ExpressionStatement{Token: p.curToken}
+	body.Expression = p.parseExpression(LOWEST)
+	lit.Body = []ast.Statement{body}
+}
+
+// parsePipeline desugars "x |> f(a)" to "f(x, a)" and "x |> f" to "f(x)"
+func (p *Parser) parsePipeline(left ast.Expression) ast.Expression {
+	tok := p.curToken
+	p.nextToken()
+
+	right := p.parseExpression(PIPELINE)
+
+	call := right
+	if dot, ok := right.(*ast.DotAccessExpression); ok {
+		call = dot.Value
+	}
+
+	if call, ok := call.(*ast.FunctionCallExpression); ok {
+		call.Parameters = append([]ast.Expression{left}, call.Parameters...)
+		return right
+	}
+
+	switch right.(type) {
+	case *ast.Identifier, *ast.FunctionExpression:
+		return &ast.FunctionCallExpression{Token: tok, FunctionExpr: right, Parameters: []ast.Expression{left}}
+	case nil:
+		return nil
+	}
+
+	p.errors = append(p.errors, fmt.Sprintf("The right side of |> has to be a function or a call, got %s", right.String()))
+	return nil
+}
+
+func (p *Parser) parseFunctionBody(lit *ast.FunctionExpression) {
 	p.nextToken()
 
+	lit.Body = []ast.Statement{}
+
 	for p.curToken.Type != token.RBRACE && p.curToken.Type != token.EOF {
 		stmt := p.parseStatement()
 		lit.Body = append(lit.Body, stmt)
@@ -577,6 +1534,31 @@ func (p *Parser) parseFunctionExpression() ast.Expression {
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
+}
+
+// parseMethod parses a named function inside of a trait or impl block, the body is optional for trait methods
+func (p *Parser) parseMethod(bodyRequired bool) *ast.FunctionExpression {
+	lit := &ast.FunctionExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	lit.Name = p.curToken.Literal
+
+	if !p.parseFunctionParameters(lit) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		p.parseFunctionBody(lit)
+	} else if bodyRequired {
+		p.peekError(token.LBRACE)
+		return nil
+	} else if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
 
 	return lit
 }
@@ -603,6 +1585,10 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 }
 
 func (p *Parser) peekPrecedence() int {
+	if p.peekTokenIs(token.LBRACE) && (p.noStructLiterals || p.curToken.Type != token.IDENT) {
+		return LOWEST
+	}
+
 	if p, ok := precedences[p.peekToken.Type]; ok {
 		return p
 	}
@@ -618,6 +1604,10 @@ func (p *Parser) curPrecedence() int {
 	return LOWEST
 }
 
+func (p *Parser) curTokenIs(t token.TokenType) bool {
+	return p.curToken.Type == t
+}
+
 func (p *Parser) peekTokenIs(t token.TokenType) bool {
 	return p.peekToken.Type == t
 }
@@ -44,6 +44,54 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = pair;", "let [a, b] = pair;"},
+		{"let [first, ...rest] = list;", "let [first, ...rest] = list;"},
+		{"let [[a, _], ...] = list;", "let [[a, _], ...] = list;"},
+		{"let {name, age: [a]} = person;", "let {name, age: [a]} = person;"},
+		{"let Point{x, y} = point;", "let Point{x, y} = point;"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("statement is not ast.LetStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.Pattern == nil || stmt.Name != nil {
+			t.Errorf("let statement of %q has no pattern", tt.input)
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("wrong statement. want=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+
+	p := New(lexer.New("fn f([a, b], Point{x}, {y}, c) { }"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	function := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionExpression)
+	if function.ParametersString() != "([a, b], Point{x}, {y}, c)" {
+		t.Errorf("wrong parameters. got=%q", function.ParametersString())
+	}
+
+	p = New(lexer.New("let [a, b];"))
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 || p.Errors()[0] != "expected next token to be =, got ; instead" {
+		t.Errorf("wrong parser errors for a destructuring let without value. got=%v", p.Errors())
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	input := `
    return 5;
@@ -100,6 +148,51 @@ func TestWhileStatements(t *testing.T) {
 	}
 }
 
+func TestBlocksFollowedByStatements(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedStatements []string
+	}{
+		{
+			"while (i < 3) { let x = 10; i = i + 1; } let y = 1;",
+			[]string{"while", "let"},
+		},
+		{
+			"if (a) { 1 } let y = 1; if (a) { 1 } else { 2 } return y",
+			[]string{"if", "let", "if", "return"},
+		},
+		{
+			"let x = if (a) { 1 } else { 2 }; x",
+			[]string{"let", "x"},
+		},
+		{
+			`import "foo" import ("bar" "baz") foo`,
+			[]string{"import", "import", "foo"},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != len(tt.expectedStatements) {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d (%s)", len(tt.expectedStatements), len(program.Statements), program.String())
+		}
+
+		for i, expected := range tt.expectedStatements {
+			if program.Statements[i].TokenLiteral() != expected {
+				t.Errorf("statement %d is not %s. got=%s", i, expected, program.Statements[i].TokenLiteral())
+			}
+		}
+
+		if while, ok := program.Statements[0].(*ast.WhileStatement); ok && len(while.Body) != 2 {
+			t.Errorf("Expected len(while.Body) to be 2 but was %v", len(while.Body))
+		}
+	}
+}
+
 func TestPackageStatements(t *testing.T) {
 	input := "package main"
 	l := lexer.New(input)
@@ -121,6 +214,34 @@ func TestPackageStatements(t *testing.T) {
 	}
 }
 
+func TestPackageStatementFollowedByStatements(t *testing.T) {
+	input := `
+	package geo;
+	let x = 1;
+`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.PackageStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] not *ast.PackageStatement. got=%T", program.Statements[0])
+	}
+
+	if stmt.Identifier.String() != "geo" {
+		t.Fatalf("Expected stmt.Identifier.String() to be geo but was %s", stmt.Identifier.String())
+	}
+
+	if !testLetStatement(t, program.Statements[1], "x", "1") {
+		return
+	}
+}
+
 func TestVariableAssignment(t *testing.T) {
 	input := "x = 10;"
 	l := lexer.New(input)
@@ -575,6 +696,34 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"!(true == true)",
 			"(!(true == true));",
 		},
+		{
+			"0..n + 1",
+			"(0 .. (n + 1));",
+		},
+		{
+			"a..b < c",
+			"((a .. b) < c);",
+		},
+		{
+			"1..=n - 1",
+			"(1 ..= (n - 1));",
+		},
+		{
+			"a[i + 1] * 2",
+			"(a[(i + 1)] * 2);",
+		},
+		{
+			"a + 1 |> f(b) |> g",
+			"g(f((a + 1), b));",
+		},
+		{
+			"a == b |> f()",
+			"f((a == b));",
+		},
+		{
+			"xs |> pkg.split(1)",
+			"pkg.split(xs, 1);",
+		},
 	}
 	for _, tt := range tests {
 		l := lexer.New(tt.input)
@@ -699,6 +848,8 @@ func TestFunctionCallExpressions(t *testing.T) {
 	}{
 		{"add(x, y);", "add", "(x, y)"},
 		{"add(x+1, y*2);", "add", "((x + 1), (y * 2))"},
+		{"add(x, y: 2, z: f(1));", "add", "(x, y: 2, z: f(1))"},
+		{"add(y: Point{x: 1});", "add", "(y: Point{x: 1})"},
 	}
 	for _, tt := range infixTests {
 		l := lexer.New(tt.input)
@@ -734,6 +885,234 @@ func TestFunctionCallExpressions(t *testing.T) {
 	}
 }
 
+func TestFunctionParameters(t *testing.T) {
+	tests := []struct {
+		input      string
+		parameters string
+	}{
+		{"fn f(a, b = 2) { }", "(a, b = 2)"},
+		{"fn f(a, b = a * 2, c = g(a)) { }", "(a, b = (a * 2), c = g(a))"},
+		{"fn f(...rest) { }", "(...rest)"},
+		{"fn f(a, b = 1, ...rest) { }", "(a, b = 1, ...rest)"},
+		{"fn f([a, b] = [1, 2]) { }", "([a, b] = [1, 2])"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		function := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionExpression)
+		if function.ParametersString() != tt.parameters {
+			t.Errorf("wrong parameters of %q. want=%q, got=%q", tt.input, tt.parameters, function.ParametersString())
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"fn f(...rest, a) { }", "The variadic parameter rest has to be the last parameter"},
+		{"fn f(a = 1, b) { }", "Parameter b without default value can not follow parameters with default values"},
+		{"fn f(...) { }", "expected next token to be IDENT, got ) instead"},
+		{"f(a: 1, 2);", "Positional argument 2 can not follow named arguments"},
+	}
+
+	for _, tt := range errors {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. want=%q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+
+	// the parameters and the body of the function are still parsed, so only the error itself is reported
+	single := []struct {
+		input    string
+		expected string
+	}{
+		{"fn f(a = 1, b) { return a; } let x = 1;", "Parameter b without default value can not follow parameters with default values"},
+		{"let g = fn(a = 1, b) { a }; g(1);", "Parameter b without default value can not follow parameters with default values"},
+		{"struct P { } impl P { fn m(self, a = 1, b) { a } }", "Parameter b without default value can not follow parameters with default values"},
+		{"fn f(...rest, a) { } f(1);", "The variadic parameter rest has to be the last parameter"},
+		{"fn f(a", "expected next token to be ), got EOF instead"},
+	}
+
+	for _, tt := range single {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) != 1 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. want=[%s], got=%q", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestShortFunctions(t *testing.T) {
+	tests := []struct {
+		input      string
+		parameters string
+		body       string
+	}{
+		{"|x| x + 1", "(x)", "(x + 1);"},
+		{"|a, b| a * b", "(a, b)", "(a * b);"},
+		{"|| 5", "()", "5;"},
+		{"|[a, b], {c}| a", "([a, b], {c})", "a;"},
+		{"|x| { return x; }", "(x)", "return x;"},
+		{"x => x + 1", "(x)", "(x + 1);"},
+		{"x => y => x + y", "(x)", "fn;"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function, ok := stmt.Expression.(*ast.FunctionExpression)
+		if !ok {
+			t.Fatalf("Expression is not ast.FunctionExpression for %q. got=%T", tt.input, stmt.Expression)
+		}
+
+		if function.ParametersString() != tt.parameters {
+			t.Errorf("wrong parameters of %q. want=%q, got=%q", tt.input, tt.parameters, function.ParametersString())
+		}
+
+		if len(function.Body) != 1 || function.Body[0].String() != tt.body {
+			t.Errorf("wrong body of %q. want=%q, got=%q", tt.input, tt.body, function.BodyString())
+		}
+	}
+
+	p := New(lexer.New("match x { n if ok => n, _ => 0 }"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	match := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MatchExpression)
+	if match.Arms[0].Guard.String() != "ok" {
+		t.Errorf("wrong guard. got=%q", match.Arms[0].Guard.String())
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"x |> 5", "The right side of |> has to be a function or a call, got 5"},
+		{"|x, 1| x", "Expected a parameter, got INT instead"},
+		{"|x y| x", "expected next token to be ,, got IDENT instead"},
+	}
+
+	for _, tt := range errors {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. want=%q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestIndexAccessExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		slice    bool
+	}{
+		{"list[1]", "list[1]", false},
+		{"list[-1]", "list[(-1)]", false},
+		{"list[1:3]", "list[1:3]", true},
+		{"text[:5]", "text[:5]", true},
+		{"text[i + 1:]", "text[(i + 1):]", true},
+		{"list[:]", "list[:]", true},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		expr, ok := stmt.Expression.(*ast.IndexAccessExpression)
+		if !ok {
+			t.Fatalf("Expression is not ast.IndexAccessExpression. got=%T", stmt.Expression)
+		}
+
+		if expr.String() != tt.expected {
+			t.Errorf("wrong expression. want=%q, got=%q", tt.expected, expr.String())
+		}
+
+		if expr.Slice != tt.slice {
+			t.Errorf("wrong slice flag for %q. want=%t, got=%t", tt.input, tt.slice, expr.Slice)
+		}
+	}
+
+	p := New(lexer.New("list[1:2:3]"))
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 || p.Errors()[0] != "expected next token to be ], got : instead" {
+		t.Errorf("expected missing ] error. got=%v", p.Errors())
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match x { 1 => a, _ => b }", "match x { 1 => a;, _ => b; }"},
+		{`match x { 1 | -2 => a, "a" | "b" => b, true => c }`, `match x { 1 | (-2) => a;, a | b => b;, true => c; }`},
+		{"match x { [] => a, [first, ...rest] => b, [_, ...] => c }", "match x { [] => a;, [first, ...rest] => b;, [_, ...] => c; }"},
+		{"match p { Point{x: 0, y} => y, Point{x, y: [a]} => x }", "match p { Point{x: 0, y} => y;, Point{x, y: [a]} => x; }"},
+		{"match x { n if n > 1 => n, _ => 0, }", "match x { n if (n > 1) => n;, _ => 0; }"},
+		{"match x { 1 => { let y = 2; y } _ => { 3 } }", "match x { 1 => let y = 2;y;, _ => 3; }"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		match, ok := stmt.Expression.(*ast.MatchExpression)
+		if !ok {
+			t.Fatalf("Expression is not ast.MatchExpression. got=%T", stmt.Expression)
+		}
+
+		if match.String() != tt.expected {
+			t.Errorf("wrong expression. want=%q, got=%q", tt.expected, match.String())
+		}
+	}
+
+	p := New(lexer.New("let y = match x { _ => 1 }; let z = match x { _ => 2 }"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Errorf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match x { 1 a }", "expected next token to be =>, got IDENT instead"},
+		{"match x { 1 => a 2 => b }", "expected next token to be ,, got INT instead"},
+		{"match x { a | 1 => a }", "Alternative patterns can not bind variables, got a"},
+		{"match x { [...rest, last] => a }", "The rest has to be the last element of a list pattern"},
+		{"match x { (1) => a }", "Expected a pattern, got ( instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. want=%q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
 func TestDotAccessExpressions(t *testing.T) {
 	infixTests := []struct {
 		input   string
@@ -964,6 +1343,441 @@ func TestParsingComplexFunctionStatements(t *testing.T) {
 	}
 }
 
+func TestStructStatements(t *testing.T) {
+	input := `
+	struct Point {
+		x,
+		y,
+	}
+	struct Empty {};
+`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	tests := []struct {
+		name   string
+		fields []string
+	}{
+		{"Point", []string{"x", "y"}},
+		{"Empty", []string{}},
+	}
+
+	for i, tt := range tests {
+		stmt, ok := program.Statements[i].(*ast.StructStatement)
+		if !ok {
+			t.Fatalf("program.Statements[%d] not *ast.StructStatement. got=%T", i, program.Statements[i])
+		}
+
+		if stmt.Name.String() != tt.name {
+			t.Fatalf("Expected stmt.Name.String() to be %s but was %s", tt.name, stmt.Name.String())
+		}
+
+		if len(stmt.Fields) != len(tt.fields) {
+			t.Fatalf("len(stmt.Fields) is not %d. got=%d", len(tt.fields), len(stmt.Fields))
+		}
+
+		for j, field := range tt.fields {
+			if stmt.Fields[j].String() != field {
+				t.Fatalf("stmt.Fields[%d] is not %s. got=%s", j, field, stmt.Fields[j].String())
+			}
+		}
+	}
+}
+
+func TestStructExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Point{x: 1, y: 2}", "Point{x: 1, y: 2};"},
+		{"Point{}", "Point{};"},
+		{"let p = Point{x: 1 + 2, y: Point{x: 3}};", "let p = Point{x: (1 + 2), y: Point{x: 3}};"},
+		{"if (p == Point{x: 1}) { 1 }", "if;"},
+		{"p.x = 5;", "p.x = 5;"},
+		{"p.x.y = 5;", "p.x.y = 5;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestStructLiteralsNotAllowedInConditions(t *testing.T) {
+	tests := []struct {
+		input     string
+		condition string
+	}{
+		{"if x { 1 }", "x"},
+		{"while foo { foo = false; }", "foo"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		var condition ast.Expression
+
+		switch stmt := program.Statements[0].(type) {
+		case *ast.ExpressionStatement:
+			ifExpr, ok := stmt.Expression.(*ast.IfElseExpression)
+			if !ok {
+				t.Fatalf("Expression is not ast.IfElseExpression. got=%T", stmt.Expression)
+			}
+			condition = ifExpr.Condition
+		case *ast.WhileStatement:
+			condition = stmt.Condition
+		default:
+			t.Fatalf("program.Statements[0] is neither if nor while. got=%T", stmt)
+		}
+
+		if _, ok := condition.(*ast.Identifier); !ok {
+			t.Fatalf("condition is not ast.Identifier. got=%T", condition)
+		}
+
+		if condition.String() != tt.condition {
+			t.Errorf("condition.String() not '%s'. got=%s", tt.condition, condition.String())
+		}
+	}
+}
+
+func TestTraitStatements(t *testing.T) {
+	input := `
+	trait Shape {
+		fn area(self)
+		fn scale(self, factor);
+		fn describe(self) { "shape" }
+	}
+`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.TraitStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] not *ast.TraitStatement. got=%T", program.Statements[0])
+	}
+
+	if stmt.Name.String() != "Shape" {
+		t.Fatalf("Expected stmt.Name.String() to be Shape but was %s", stmt.Name.String())
+	}
+
+	if len(stmt.RequiredMethods) != 2 {
+		t.Fatalf("len(stmt.RequiredMethods) is not 2. got=%d", len(stmt.RequiredMethods))
+	}
+
+	if stmt.RequiredMethods[1].Name != "scale" || stmt.RequiredMethods[1].ParametersString() != "(self, factor)" {
+		t.Fatalf("stmt.RequiredMethods[1] is not scale(self, factor). got=%s%s", stmt.RequiredMethods[1].Name, stmt.RequiredMethods[1].ParametersString())
+	}
+
+	if len(stmt.DefaultMethods) != 1 {
+		t.Fatalf("len(stmt.DefaultMethods) is not 1. got=%d", len(stmt.DefaultMethods))
+	}
+
+	if stmt.DefaultMethods[0].BodyString() != "shape;" {
+		t.Fatalf("stmt.DefaultMethods[0].BodyString() is not shape;. got=%s", stmt.DefaultMethods[0].BodyString())
+	}
+}
+
+func TestImplStatements(t *testing.T) {
+	tests := []struct {
+		input   string
+		trait   string
+		name    string
+		methods []string
+	}{
+		{"impl Shape for Circle { fn area(self) { 3 } fn describe(self) { \"circle\" } }", "Shape", "Circle", []string{"area", "describe"}},
+		{"impl Circle { fn grow(self, by) { self.r = self.r + by; } }", "", "Circle", []string{"grow"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ImplStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] not *ast.ImplStatement. got=%T", program.Statements[0])
+		}
+
+		if tt.trait == "" && stmt.Trait != nil {
+			t.Fatalf("Expected stmt.Trait to be nil but was %s", stmt.Trait.String())
+		}
+
+		if tt.trait != "" && (stmt.Trait == nil || stmt.Trait.String() != tt.trait) {
+			t.Fatalf("Expected stmt.Trait to be %s but was %v", tt.trait, stmt.Trait)
+		}
+
+		if stmt.Struct.String() != tt.name {
+			t.Fatalf("Expected stmt.Struct.String() to be %s but was %s", tt.name, stmt.Struct.String())
+		}
+
+		if len(stmt.Methods) != len(tt.methods) {
+			t.Fatalf("len(stmt.Methods) is not %d. got=%d", len(tt.methods), len(stmt.Methods))
+		}
+
+		for i, method := range tt.methods {
+			if stmt.Methods[i].Name != method {
+				t.Errorf("stmt.Methods[%d].Name is not %s. got=%s", i, method, stmt.Methods[i].Name)
+			}
+		}
+	}
+}
+
+func TestTryStatements(t *testing.T) {
+	tests := []struct {
+		input     string
+		body      int
+		parameter string
+		handler   int
+	}{
+		{"try { foo(); let x = 1; } catch (e) { bar(e); }", 2, "e", 1},
+		{"try { foo(); } catch { }; 1;", 1, "", 0},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.TryStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] not *ast.TryStatement. got=%T", program.Statements[0])
+		}
+
+		if len(stmt.Body) != tt.body {
+			t.Errorf("len(stmt.Body) is not %d. got=%d", tt.body, len(stmt.Body))
+		}
+
+		if len(stmt.Handler) != tt.handler {
+			t.Errorf("len(stmt.Handler) is not %d. got=%d", tt.handler, len(stmt.Handler))
+		}
+
+		if tt.parameter == "" && stmt.Parameter != nil {
+			t.Errorf("Expected stmt.Parameter to be nil but was %s", stmt.Parameter.String())
+		}
+
+		if tt.parameter != "" && (stmt.Parameter == nil || stmt.Parameter.Value != tt.parameter) {
+			t.Errorf("Expected stmt.Parameter to be %s but was %v", tt.parameter, stmt.Parameter)
+		}
+	}
+
+	p := New(lexer.New("try { foo(); } bar();"))
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 || p.Errors()[len(p.Errors())-1] != "Missing catch after try block" {
+		t.Errorf("expected missing catch error. got=%v", p.Errors())
+	}
+}
+
+func TestThrowStatements(t *testing.T) {
+	l := lexer.New(`throw "not found"; throw Error{message: 1};`)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	for _, statement := range program.Statements {
+		if _, ok := statement.(*ast.ThrowStatement); !ok {
+			t.Errorf("statement not *ast.ThrowStatement. got=%T", statement)
+		}
+	}
+}
+
+func TestSpawnExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"spawn worker(jobs, 1);", "spawn worker(jobs, 1)"},
+		{"spawn fn() { run(); };", "spawn fn"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] not *ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		spawn, ok := stmt.Expression.(*ast.SpawnExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression not *ast.SpawnExpression. got=%T", stmt.Expression)
+		}
+
+		if spawn.String() != tt.expected {
+			t.Errorf("wrong spawn expression. want=%q, got=%q", tt.expected, spawn.String())
+		}
+	}
+}
+
+func TestSelectStatements(t *testing.T) {
+	input := `
+	select {
+		case job = jobs.receive() { run(job); }
+		case results.send(1) { }
+		default { wait(); }
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.SelectStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] not *ast.SelectStatement. got=%T", program.Statements[0])
+	}
+
+	if len(stmt.Cases) != 2 {
+		t.Fatalf("select does not have 2 cases. got=%d", len(stmt.Cases))
+	}
+
+	receive := stmt.Cases[0]
+	if receive.Send || receive.Name == nil || receive.Name.Value != "job" || receive.Channel.String() != "jobs" || len(receive.Body) != 1 {
+		t.Errorf("wrong receive case. got=%s", receive.String())
+	}
+
+	send := stmt.Cases[1]
+	if !send.Send || send.Name != nil || send.Channel.String() != "results" || send.Value.String() != "1" {
+		t.Errorf("wrong send case. got=%s", send.String())
+	}
+
+	if !stmt.HasDefault || len(stmt.Default) != 1 {
+		t.Errorf("wrong default case. got=%s", stmt.String())
+	}
+}
+
+func TestSelectStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"select { case jobs.peek() { } }", "Select cases have to be channel.receive() or channel.send(value), got jobs.peek()"},
+		{"select { case x = jobs.send(1) { } }", "Select cases have to be channel.receive() or channel.send(value), got jobs.send(1)"},
+		{"select { case jobs { } }", "Select cases have to be channel.receive() or channel.send(value), got jobs"},
+		{"select { default { } default { } }", "Select statement has more than one default case"},
+		{"select { let x = 1; }", "expected case or default in select statement, got LET instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser error for %q. want=%q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestForInStatements(t *testing.T) {
+	l := lexer.New("for line in lines(file) { count(line); }")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ForInStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] not *ast.ForInStatement. got=%T", program.Statements[0])
+	}
+
+	if !testIdentifier(t, stmt.Variable, "line") {
+		return
+	}
+
+	if stmt.Iterable.String() != "lines(file)" {
+		t.Errorf("wrong iterable. want=%q, got=%q", "lines(file)", stmt.Iterable.String())
+	}
+
+	if len(stmt.Body) != 1 {
+		t.Errorf("len(stmt.Body) is not 1. got=%d", len(stmt.Body))
+	}
+
+	p = New(lexer.New("for x lines { }"))
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 || p.Errors()[0] != "expected next token to be IN, got IDENT instead" {
+		t.Errorf("expected missing in error. got=%v", p.Errors())
+	}
+
+	p = New(lexer.New("for i, x in [] { }"))
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt = program.Statements[0].(*ast.ForInStatement)
+	if !testIdentifier(t, stmt.Key, "i") || !testIdentifier(t, stmt.Variable, "x") {
+		return
+	}
+
+	if stmt.String() != "for i, x in []" {
+		t.Errorf("wrong string. got=%q", stmt.String())
+	}
+}
+
+func TestYieldStatements(t *testing.T) {
+	l := lexer.New("fn numbers() { yield 1; yield a + 2; }")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	function := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionExpression)
+	if len(function.Body) != 2 {
+		t.Fatalf("function body does not contain 2 statements. got=%d", len(function.Body))
+	}
+
+	expected := []string{"yield 1;", "yield (a + 2);"}
+	for i, statement := range function.Body {
+		yield, ok := statement.(*ast.YieldStatement)
+		if !ok {
+			t.Fatalf("statement not *ast.YieldStatement. got=%T", statement)
+		}
+
+		if yield.String() != expected[i] {
+			t.Errorf("wrong yield statement. want=%q, got=%q", expected[i], yield.String())
+		}
+	}
+}
+
 func testLetStatement(t *testing.T, s ast.Statement, name string, value string) bool {
 	if s.TokenLiteral() != "let" {
 		t.Errorf("s.TokenLiteral not 'let'. got=%q", s.TokenLiteral())
@@ -0,0 +1,199 @@
+package profiler
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// field numbers of the messages of the pprof format, see profile.proto of github.com/google/pprof
+const (
+	profileSampleType    = 1
+	profileSample        = 2
+	profileLocation      = 4
+	profileFunction      = 5
+	profileStringTable   = 6
+	profileTimeNanos     = 9
+	profileDurationNanos = 10
+	profilePeriodType    = 11
+	profilePeriod        = 12
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+)
+
+// WriteProfile writes the samples as gzip compressed pprof profile.
+// Every sample has two values, the number of samples and the sampled time in nanoseconds.
+func (profiler *Profiler) WriteProfile(w io.Writer) error {
+	writer := &profileWriter{strings: map[string]int{"": 0}, stringTable: []string{""}, functions: map[Frame]uint64{}, locations: map[Frame]uint64{}}
+	out := &writer.out
+
+	out.message(profileSampleType, writer.valueType("samples", "count"))
+	out.message(profileSampleType, writer.valueType("cpu", "nanoseconds"))
+
+	for _, sample := range profiler.samples {
+		var ids []uint64
+		for _, frame := range sample.Stack {
+			ids = append(ids, writer.location(frame))
+		}
+
+		var message buffer
+		message.packed(sampleLocationID, ids)
+		message.packed(sampleValue, []uint64{uint64(sample.Count), uint64(sample.Count * profiler.Period.Nanoseconds())})
+		out.message(profileSample, message)
+	}
+
+	out.data = append(out.data, writer.definitions.data...)
+
+	if !profiler.start.IsZero() {
+		out.integer(profileTimeNanos, uint64(profiler.start.UnixNano()))
+	}
+
+	out.integer(profileDurationNanos, uint64(profiler.duration.Nanoseconds()))
+	out.message(profilePeriodType, writer.valueType("cpu", "nanoseconds"))
+	out.integer(profilePeriod, uint64(profiler.Period.Nanoseconds()))
+
+	// the string table is written last, all strings have been added by then
+	for _, s := range writer.stringTable {
+		out.bytes(profileStringTable, []byte(s))
+	}
+
+	compressed := gzip.NewWriter(w)
+	if _, err := compressed.Write(out.data); err != nil {
+		return err
+	}
+
+	return compressed.Close()
+}
+
+// profileWriter numbers the strings, functions and locations of a profile
+type profileWriter struct {
+	out buffer
+	// the locations and functions in the order they were numbered
+	definitions buffer
+
+	strings     map[string]int
+	stringTable []string
+
+	functions map[Frame]uint64 // keyed by the function and file of a frame
+	locations map[Frame]uint64
+}
+
+func (writer *profileWriter) valueType(typ string, unit string) buffer {
+	var message buffer
+	message.integer(valueTypeType, writer.str(typ))
+	message.integer(valueTypeUnit, writer.str(unit))
+
+	return message
+}
+
+// location returns the id of the line of the frame, a location has one line
+func (writer *profileWriter) location(frame Frame) uint64 {
+	if id, ok := writer.locations[frame]; ok {
+		return id
+	}
+
+	id := uint64(len(writer.locations) + 1)
+	writer.locations[frame] = id
+
+	var line buffer
+	line.integer(lineFunctionID, writer.function(frame))
+	line.integer(lineLine, uint64(frame.Line))
+
+	var message buffer
+	message.integer(locationID, id)
+	message.message(locationLine, line)
+	writer.definitions.message(profileLocation, message)
+
+	return id
+}
+
+func (writer *profileWriter) function(frame Frame) uint64 {
+	key := Frame{Function: frame.Function, File: frame.File}
+	if id, ok := writer.functions[key]; ok {
+		return id
+	}
+
+	id := uint64(len(writer.functions) + 1)
+	writer.functions[key] = id
+
+	var message buffer
+	message.integer(functionID, id)
+	message.integer(functionName, writer.str(frame.Function))
+	message.integer(functionSystemName, writer.str(frame.Function))
+	message.integer(functionFilename, writer.str(frame.File))
+	writer.definitions.message(profileFunction, message)
+
+	return id
+}
+
+func (writer *profileWriter) str(s string) uint64 {
+	index, ok := writer.strings[s]
+	if !ok {
+		index = len(writer.stringTable)
+		writer.strings[s] = index
+		writer.stringTable = append(writer.stringTable, s)
+	}
+
+	return uint64(index)
+}
+
+// buffer encodes protocol buffer messages, fields with default values are not omitted
+type buffer struct {
+	data []byte
+}
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (b *buffer) varint(value uint64) {
+	for value >= 0x80 {
+		b.data = append(b.data, byte(value)|0x80)
+		value >>= 7
+	}
+
+	b.data = append(b.data, byte(value))
+}
+
+func (b *buffer) key(field int, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (b *buffer) integer(field int, value uint64) {
+	b.key(field, wireVarint)
+	b.varint(value)
+}
+
+func (b *buffer) bytes(field int, data []byte) {
+	b.key(field, wireBytes)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *buffer) message(field int, message buffer) {
+	b.bytes(field, message.data)
+}
+
+func (b *buffer) packed(field int, values []uint64) {
+	var content buffer
+	for _, value := range values {
+		content.varint(value)
+	}
+
+	b.bytes(field, content.data)
+}
@@ -0,0 +1,216 @@
+// Package profiler implements a sampling CPU profiler for Curry programs.
+// A timer requests a sample every period, the engine records its call stack before the next statement:
+//
+//	prof := profiler.New()
+//	engine.Hook = prof.EvaluatorHook()
+//	prof.Start()
+//	engine.Eval(program)
+//	prof.Stop()
+//	prof.WriteProfile(file)
+//
+// The profile is written in the pprof format and can be opened with go tool pprof.
+package profiler
+
+import (
+	"curryLang/ast"
+	"curryLang/code"
+	"curryLang/evaluator"
+	"curryLang/object"
+	"curryLang/vm"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPeriod is the sampling period of new profilers, the same as the one of the Go profiler
+const DefaultPeriod = 10 * time.Millisecond
+
+// MainFunction is the name of the frame of the main program, pprof does not show names in angle brackets
+const MainFunction = "main"
+
+// Frame is a function call of a sampled stack
+type Frame struct {
+	Function string
+	File     string
+	Line     int // line which was executed by the function when the sample was taken
+}
+
+// Sample is a call stack and how often it was sampled
+type Sample struct {
+	Stack []Frame // the innermost call first, the main program last
+	Count int64
+}
+
+type Profiler struct {
+	Period time.Duration
+
+	// set by the timer when a sample is requested, it is the only field written by the timer
+	requested int32
+	// time up to which the samples were recorded
+	sampled time.Time
+
+	samples []*Sample
+	indexes map[string]int // index of the samples by their stack
+
+	start    time.Time
+	duration time.Duration
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+func New() *Profiler {
+	return &Profiler{Period: DefaultPeriod, indexes: map[string]int{}}
+}
+
+// Start starts the timer which requests samples
+func (profiler *Profiler) Start() {
+	profiler.start = time.Now()
+	profiler.sampled = profiler.start
+	profiler.stop = make(chan struct{})
+	profiler.stopped = make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(profiler.Period)
+		defer ticker.Stop()
+		defer close(profiler.stopped)
+
+		for {
+			select {
+			case <-ticker.C:
+				atomic.StoreInt32(&profiler.requested, 1)
+			case <-profiler.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the timer, requested samples which were not recorded anymore are dropped
+func (profiler *Profiler) Stop() {
+	if profiler.stop == nil {
+		return
+	}
+
+	close(profiler.stop)
+	<-profiler.stopped
+
+	profiler.stop = nil
+	profiler.duration = time.Since(profiler.start)
+}
+
+// Record records the stack returned by the callback if a sample was requested since the last one.
+// The stack is only built when it is needed. The timer drops ticks while the program is busy,
+// so a sample counts all periods which passed since the previous one.
+func (profiler *Profiler) Record(stack func() []Frame) {
+	if atomic.LoadInt32(&profiler.requested) == 0 {
+		return
+	}
+
+	atomic.StoreInt32(&profiler.requested, 0)
+
+	count := int64(time.Since(profiler.sampled) / profiler.Period)
+	if count < 1 {
+		count = 1
+	}
+
+	profiler.sampled = profiler.sampled.Add(time.Duration(count) * profiler.Period)
+	profiler.add(stack(), count)
+}
+
+// Samples returns the recorded stacks in the order they were first sampled
+func (profiler *Profiler) Samples() []Sample {
+	samples := make([]Sample, 0, len(profiler.samples))
+	for _, sample := range profiler.samples {
+		samples = append(samples, *sample)
+	}
+
+	return samples
+}
+
+// EvaluatorHook returns the hook which records the samples of the tree-walking evaluator
+func (profiler *Profiler) EvaluatorHook() evaluator.Hook {
+	return evaluatorHook{profiler: profiler}
+}
+
+// VMHook returns the hook which records the samples of the virtual machine
+func (profiler *Profiler) VMHook() vm.Hook {
+	return vmHook{profiler: profiler}
+}
+
+func (profiler *Profiler) add(stack []Frame, count int64) {
+	var key strings.Builder
+	for _, frame := range stack {
+		key.WriteString(frame.Function)
+		key.WriteByte(0)
+		key.WriteString(frame.File)
+		key.WriteByte(0)
+		key.WriteString(strconv.Itoa(frame.Line))
+		key.WriteByte(0)
+	}
+
+	if index, ok := profiler.indexes[key.String()]; ok {
+		profiler.samples[index].Count += count
+		return
+	}
+
+	profiler.indexes[key.String()] = len(profiler.samples)
+	profiler.samples = append(profiler.samples, &Sample{Stack: stack, Count: count})
+}
+
+type evaluatorHook struct {
+	profiler *Profiler
+}
+
+func (hook evaluatorHook) BeforeStatement(engine *evaluator.ExecutionEngine, statement ast.Statement) *object.Error {
+	hook.profiler.Record(func() []Frame {
+		file := engine.File
+		line := statement.Pos().Line
+
+		// every function was called at the line shown for the frame of its caller
+		var stack []Frame
+		for _, frame := range engine.Frames() {
+			stack = append(stack, Frame{Function: frame.Name(), File: file, Line: line})
+			file = frame.Call.File
+			line = frame.Call.Line
+		}
+
+		return append(stack, Frame{Function: MainFunction, File: file, Line: line})
+	})
+
+	return nil
+}
+
+func (hook evaluatorHook) EnterFunction(engine *evaluator.ExecutionEngine, frame evaluator.Frame) {
+}
+
+func (hook evaluatorHook) LeaveFunction(engine *evaluator.ExecutionEngine, frame evaluator.Frame, result object.Object) {
+}
+
+type vmHook struct {
+	profiler *Profiler
+}
+
+func (hook vmHook) BeforeStatement(machine *vm.VM, statement code.Statement) *object.Error {
+	hook.profiler.Record(func() []Frame {
+		frames := machine.Frames()
+
+		stack := make([]Frame, 0, len(frames))
+		for _, frame := range frames {
+			stack = append(stack, Frame{Function: frame.Function, File: frame.Statement.File, Line: frame.Statement.Line})
+		}
+
+		// the vm names its main frame like a function
+		stack[len(stack)-1].Function = MainFunction
+
+		return stack
+	})
+
+	return nil
+}
+
+func (hook vmHook) EnterFunction(machine *vm.VM, function *object.CompiledFunction) {
+}
+
+func (hook vmHook) LeaveFunction(machine *vm.VM, function *object.CompiledFunction, result object.Object) {
+}
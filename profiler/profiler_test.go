@@ -0,0 +1,166 @@
+package profiler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"curryLang/ast"
+	"curryLang/code"
+	"curryLang/compiler"
+	"curryLang/evaluator"
+	"curryLang/lexer"
+	"curryLang/object"
+	"curryLang/parser"
+	"curryLang/vm"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+const program = `fn add(a, b) {
+    let sum = a + b;
+    return sum;
+}
+
+fn twice(a) {
+    return add(a, a);
+}
+
+let x = twice(1);
+x`
+
+// the sample is requested at the statement of add, so it is recorded deterministically
+const sampledLine = 2
+
+const expectedStack = "[{add main.curry 2} {twice main.curry 7} {main main.curry 10}]"
+
+type requestingEvaluatorHook struct {
+	evaluator.Hook
+	profiler *Profiler
+}
+
+func (hook requestingEvaluatorHook) BeforeStatement(engine *evaluator.ExecutionEngine, statement ast.Statement) *object.Error {
+	if statement.Pos().Line == sampledLine {
+		atomic.StoreInt32(&hook.profiler.requested, 1)
+	}
+
+	return hook.Hook.BeforeStatement(engine, statement)
+}
+
+type requestingVMHook struct {
+	vm.Hook
+	profiler *Profiler
+}
+
+func (hook requestingVMHook) BeforeStatement(machine *vm.VM, statement code.Statement) *object.Error {
+	if statement.Line == sampledLine {
+		atomic.StoreInt32(&hook.profiler.requested, 1)
+	}
+
+	return hook.Hook.BeforeStatement(machine, statement)
+}
+
+func TestEvaluatorSamples(t *testing.T) {
+	profiler := New()
+
+	engine := evaluator.NewEngine()
+	engine.File = "main.curry"
+	engine.Hook = requestingEvaluatorHook{Hook: profiler.EvaluatorHook(), profiler: profiler}
+
+	profiler.Start()
+	result := engine.Eval(parse(program))
+	profiler.Stop()
+
+	if result == nil || result.Inspect() != "2" {
+		t.Fatalf("wrong result. got=%v", result)
+	}
+
+	testSamples(t, profiler)
+}
+
+func TestVMSamples(t *testing.T) {
+	profiler := New()
+
+	c := compiler.New()
+	c.File = "main.curry"
+
+	err := c.Compile(parse(program))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	machine := vm.New(c.Bytecode())
+	machine.Hook = requestingVMHook{Hook: profiler.VMHook(), profiler: profiler}
+
+	profiler.Start()
+	err = machine.Run()
+	profiler.Stop()
+
+	if err != nil {
+		t.Fatalf("vm error: %s", err)
+	}
+
+	testSamples(t, profiler)
+}
+
+func TestWriteProfile(t *testing.T) {
+	profiler := New()
+	profiler.add([]Frame{{"add", "main.curry", 2}, {MainFunction, "main.curry", 10}}, 3)
+	profiler.add([]Frame{{MainFunction, "main.curry", 10}}, 1)
+	profiler.add([]Frame{{"add", "main.curry", 2}, {MainFunction, "main.curry", 10}}, 2)
+
+	if samples := profiler.Samples(); len(samples) != 2 || samples[0].Count != 5 || samples[1].Count != 1 {
+		t.Fatalf("wrong samples. got=%+v", samples)
+	}
+
+	var out bytes.Buffer
+	err := profiler.WriteProfile(&out)
+	if err != nil {
+		t.Fatalf("failed to write profile: %s", err)
+	}
+
+	reader, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatalf("profile is not compressed: %s", err)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to decompress profile: %s", err)
+	}
+
+	// the string table is the last field
+	stringTable := "\x32\x00\x32\x07samples\x32\x05count\x32\x03cpu\x32\x0bnanoseconds\x32\x03add\x32\x0amain.curry\x32\x04main"
+	if !bytes.HasSuffix(data, []byte(stringTable)) {
+		t.Errorf("wrong string table. got=%q", data)
+	}
+
+	// the first sample references the locations 1 and 2 and has the values 5 and 50ms
+	sample := "\x12\x0b\x0a\x02\x01\x02\x12\x05\x05\x80\xe1\xeb\x17"
+	if !bytes.Contains(data, []byte(sample)) {
+		t.Errorf("profile does not contain the first sample. got=%q", data)
+	}
+}
+
+func testSamples(t *testing.T, profiler *Profiler) {
+	t.Helper()
+
+	samples := profiler.Samples()
+	if len(samples) != 1 {
+		t.Fatalf("wrong number of samples. want=1, got=%+v", samples)
+	}
+
+	if stack := fmt.Sprint(samples[0].Stack); stack != expectedStack {
+		t.Errorf("wrong stack. want=%s, got=%s", expectedStack, stack)
+	}
+
+	if samples[0].Count < 1 {
+		t.Errorf("wrong count. got=%d", samples[0].Count)
+	}
+}
+
+func parse(input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	return p.ParseProgram()
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"curryLang/ast"
+	"curryLang/checker"
+	"curryLang/compiler"
+	"curryLang/evaluator"
+	"curryLang/lexer"
+	"curryLang/object"
+	"curryLang/parser"
+	"curryLang/profiler"
+	"curryLang/tracer"
+	"curryLang/vm"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// runRunCommand executes "curry run [flags] <file>" and returns false if the program failed
+func runRunCommand(args []string) (bool, error) {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	backend := flags.String("backend", "evaluator", "engine running the program: evaluator or vm")
+	cpuProfile := flags.String("cpuprofile", "", "write a pprof CPU profile of the program to the file")
+	trace := flags.Bool("trace", false, "log every executed statement with its position to stderr")
+
+	err := flags.Parse(args)
+	if err == flag.ErrHelp {
+		// the flag set already printed the usage
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if flags.NArg() != 1 {
+		return false, errors.New("usage: curry run [flags] <file>")
+	}
+
+	if *backend != "evaluator" && *backend != "vm" {
+		return false, fmt.Errorf("unknown backend %s, expected evaluator or vm", *backend)
+	}
+
+	// tracing slows down every statement, so a profile of a traced program is worthless
+	if *trace && *cpuProfile != "" {
+		return false, errors.New("-trace and -cpuprofile can not be combined")
+	}
+
+	file := flags.Arg(0)
+
+	program, ok, err := parseFile(file)
+	if !ok || err != nil {
+		return false, err
+	}
+
+	var instrument instrumentation
+	var prof *profiler.Profiler
+
+	if *cpuProfile != "" {
+		prof = profiler.New()
+		instrument = prof
+		prof.Start()
+	} else if *trace {
+		instrument = tracer.New(os.Stderr)
+	}
+
+	var result object.Object
+	if *backend == "vm" {
+		result, err = runWithVM(file, program, instrument)
+	} else {
+		result, err = runWithEvaluator(file, program, instrument)
+	}
+
+	if prof != nil {
+		prof.Stop()
+
+		if profileErr := writeFile(*cpuProfile, prof.WriteProfile); profileErr != nil {
+			return false, profileErr
+		}
+	}
+
+	if runtimeErr, ok := err.(*object.Error); ok {
+		fmt.Fprintln(os.Stderr, runtimeErr.Traceback())
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if result != nil {
+		fmt.Println(result.Inspect())
+	}
+
+	return true, nil
+}
+
+// parseFile parses and checks a program, the errors in its source are printed and reported as false
+func parseFile(file string) (*ast.Program, bool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false, err
+	}
+
+	p := parser.New(lexer.New(string(data)))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		for _, err := range p.Errors() {
+			fmt.Println("Error: ", err)
+		}
+
+		return nil, false, nil
+	}
+
+	if errors := checker.Check(program); len(errors) > 0 {
+		for _, err := range errors {
+			fmt.Println("Error: ", err)
+		}
+
+		return nil, false, nil
+	}
+
+	for _, warning := range checker.Warnings(program) {
+		fmt.Fprintf(os.Stderr, "Warning: %s:%s\n", file, warning)
+	}
+
+	return program, true, nil
+}
+
+// instrumentation observes the statements of a program with the hook of the engine running it
+type instrumentation interface {
+	EvaluatorHook() evaluator.Hook
+	VMHook() vm.Hook
+}
+
+func runWithEvaluator(file string, program *ast.Program, instrument instrumentation) (object.Object, error) {
+	engine := newEngine()
+
+	// setup module of the program
+	err := engine.LoadModule(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+
+	engine.File = file
+
+	if instrument != nil {
+		engine.Hook = instrument.EvaluatorHook()
+	}
+
+	result := engine.Eval(program)
+	engine.Finish()
+	if err, ok := result.(*object.Error); ok {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func runWithVM(file string, program *ast.Program, instrument instrumentation) (object.Object, error) {
+	c := compiler.New()
+	c.File = file
+
+	err := c.Compile(program)
+	if err != nil {
+		return nil, err
+	}
+
+	machine := vm.New(c.Bytecode())
+
+	if instrument != nil {
+		machine.Hook = instrument.VMHook()
+	}
+
+	err = machine.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(program.Statements) == 0 || !compiler.LeavesValue(program.Statements[len(program.Statements)-1]) {
+		return nil, nil
+	}
+
+	return machine.LastPoppedStackElem(), nil
+}
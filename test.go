@@ -0,0 +1,107 @@
+package main
+
+import (
+	"curryLang/coverage"
+	"curryLang/currytest"
+	"flag"
+	"io"
+	"os"
+	"regexp"
+)
+
+// runTestCommand executes "curry test [flags] [packages]" and returns false if a test failed
+func runTestCommand(args []string) (bool, error) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	verbose := flags.Bool("v", false, "print all tests, not only the failing ones")
+	run := flags.String("run", "", "only run tests matching the regular expression")
+	jsonOutput := flags.Bool("json", false, "print the results in the format of go test -json")
+	junitFile := flags.String("junit", "", "write the results as JUnit XML to the file")
+	cover := flags.Bool("cover", false, "record the statement and branch coverage of the package files")
+	coverProfile := flags.String("coverprofile", "", "write a coverage profile in the format of go test to the file, implies -cover")
+	coverHTML := flags.String("coverhtml", "", "write an HTML coverage report to the file, implies -cover")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return false, err
+	}
+
+	options := currytest.Options{NewEngine: newEngine}
+
+	if *cover || *coverProfile != "" || *coverHTML != "" {
+		options.Coverage = coverage.New()
+	}
+
+	if *run != "" {
+		options.Run, err = regexp.Compile(*run)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	var reporters currytest.MultiReporter
+	if *jsonOutput {
+		reporters = append(reporters, &currytest.JSONReporter{Out: os.Stdout})
+	} else {
+		reporters = append(reporters, &currytest.TextReporter{Out: os.Stdout, Verbose: *verbose})
+	}
+
+	var junit *currytest.JUnitReporter
+	if *junitFile != "" {
+		file, err := os.Create(*junitFile)
+		if err != nil {
+			return false, err
+		}
+		defer file.Close()
+
+		junit = &currytest.JUnitReporter{Out: file}
+		reporters = append(reporters, junit)
+	}
+
+	options.Reporter = reporters
+
+	dirs, err := currytest.Discover(flags.Args())
+	if err != nil {
+		return false, err
+	}
+
+	ok := currytest.Run(dirs, options)
+
+	if junit != nil {
+		err = junit.Flush()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if *coverProfile != "" {
+		err = writeFile(*coverProfile, options.Coverage.WriteProfile)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if *coverHTML != "" {
+		err = writeFile(*coverHTML, options.Coverage.WriteHTML)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return ok, nil
+}
+
+// writeFile creates the file and writes its content with write
+func writeFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -5,6 +5,10 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+
+	// position of the first character in the source, both start at 1
+	Line   int
+	Column int
 }
 
 const (
@@ -16,15 +20,22 @@ const (
 	INT   = "INT"   // 1343456
 
 	// Operators
-	ASSIGN   = "="
-	PLUS     = "+"
-	MINUS    = "-"
-	BANG     = "!"
-	ASTERISK = "*"
-	SLASH    = "/"
-	LT       = "<"
-	GT       = ">"
-	DOT      = "."
+	ASSIGN          = "="
+	PLUS            = "+"
+	MINUS           = "-"
+	BANG            = "!"
+	ASTERISK        = "*"
+	SLASH           = "/"
+	LT              = "<"
+	GT              = ">"
+	DOT             = "."
+	RANGE           = ".."
+	RANGE_INCLUSIVE = "..="
+	ELLIPSIS        = "..."
+	COLON           = ":"
+	ARROW           = "=>"
+	PIPE            = "|"
+	PIPELINE        = "|>"
 
 	EQ     = "=="
 	NOT_EQ = "!="
@@ -54,6 +65,20 @@ const (
 	RETURN   = "RETURN"
 	WHILE    = "WHILE"
 	BREAK    = "BREAK"
+	STRUCT   = "STRUCT"
+	TRAIT    = "TRAIT"
+	IMPL     = "IMPL"
+	FOR      = "FOR"
+	IN       = "IN"
+	YIELD    = "YIELD"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	THROW    = "THROW"
+	SPAWN    = "SPAWN"
+	SELECT   = "SELECT"
+	CASE     = "CASE"
+	DEFAULT  = "DEFAULT"
+	MATCH    = "MATCH"
 )
 
 var keywords = map[string]TokenType{
@@ -68,6 +93,20 @@ var keywords = map[string]TokenType{
 	"return":  RETURN,
 	"while":   WHILE,
 	"break":   BREAK,
+	"struct":  STRUCT,
+	"trait":   TRAIT,
+	"impl":    IMPL,
+	"for":     FOR,
+	"in":      IN,
+	"yield":   YIELD,
+	"try":     TRY,
+	"catch":   CATCH,
+	"throw":   THROW,
+	"spawn":   SPAWN,
+	"select":  SELECT,
+	"case":    CASE,
+	"default": DEFAULT,
+	"match":   MATCH,
 }
 
 func LookupIdent(ident string) TokenType {
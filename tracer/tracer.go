@@ -0,0 +1,101 @@
+// Package tracer logs every statement executed by a Curry program with its position.
+// Statements are indented by the depth of the function calls executing them:
+//
+//	main.curry:7:1    let y = add(x, 2);
+//	main.curry:2:5      let sum = a + b;
+package tracer
+
+import (
+	"curryLang/ast"
+	"curryLang/code"
+	"curryLang/evaluator"
+	"curryLang/object"
+	"curryLang/vm"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type Tracer struct {
+	out io.Writer
+
+	// lines of the source files, they are read when a statement of the file is traced for the first time
+	sources map[string][]string
+}
+
+func New(out io.Writer) *Tracer {
+	return &Tracer{out: out, sources: map[string][]string{}}
+}
+
+// EvaluatorHook returns the hook which traces the statements of the tree-walking evaluator
+func (tracer *Tracer) EvaluatorHook() evaluator.Hook {
+	return evaluatorHook{tracer: tracer}
+}
+
+// VMHook returns the hook which traces the statements of the virtual machine
+func (tracer *Tracer) VMHook() vm.Hook {
+	return vmHook{tracer: tracer}
+}
+
+func (tracer *Tracer) trace(file string, line int, column int, depth int) {
+	position := fmt.Sprintf("%s:%d:%d", object.Span{File: file}, line, column)
+	fmt.Fprintf(tracer.out, "%-20s %s%s\n", position, strings.Repeat("  ", depth), tracer.source(file, line, column))
+}
+
+// source returns the text of the line from the column on, the file is read only once
+func (tracer *Tracer) source(file string, line int, column int) string {
+	lines, ok := tracer.sources[file]
+	if !ok {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+
+		tracer.sources[file] = lines
+	}
+
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+
+	text := []rune(strings.TrimRight(lines[line-1], " \t\r"))
+	if column < 1 || column > len(text) {
+		return ""
+	}
+
+	return string(text[column-1:])
+}
+
+type evaluatorHook struct {
+	tracer *Tracer
+}
+
+func (hook evaluatorHook) BeforeStatement(engine *evaluator.ExecutionEngine, statement ast.Statement) *object.Error {
+	position := statement.Pos()
+	hook.tracer.trace(engine.File, position.Line, position.Column, len(engine.Frames()))
+
+	return nil
+}
+
+func (hook evaluatorHook) EnterFunction(engine *evaluator.ExecutionEngine, frame evaluator.Frame) {
+}
+
+func (hook evaluatorHook) LeaveFunction(engine *evaluator.ExecutionEngine, frame evaluator.Frame, result object.Object) {
+}
+
+type vmHook struct {
+	tracer *Tracer
+}
+
+func (hook vmHook) BeforeStatement(machine *vm.VM, statement code.Statement) *object.Error {
+	hook.tracer.trace(statement.File, statement.Line, statement.Column, machine.Depth())
+
+	return nil
+}
+
+func (hook vmHook) EnterFunction(machine *vm.VM, function *object.CompiledFunction) {
+}
+
+func (hook vmHook) LeaveFunction(machine *vm.VM, function *object.CompiledFunction, result object.Object) {
+}
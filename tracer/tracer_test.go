@@ -0,0 +1,78 @@
+package tracer
+
+import (
+	"bytes"
+	"curryLang/ast"
+	"curryLang/compiler"
+	"curryLang/evaluator"
+	"curryLang/lexer"
+	"curryLang/parser"
+	"curryLang/vm"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const program = `fn add(a, b) {
+    let sum = a + b;
+    return sum;
+}
+
+let x = 1; let y = add(x, 2);
+y`
+
+func TestTracer(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.curry")
+
+	err := os.WriteFile(file, []byte(program), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the path of the temporary file is longer than the padding of the positions
+	expected := `main.curry:1:1 fn add(a, b) {
+main.curry:6:1 let x = 1; let y = add(x, 2);
+main.curry:6:12 let y = add(x, 2);
+main.curry:2:5   let sum = a + b;
+main.curry:3:5   return sum;
+main.curry:7:1 y
+`
+
+	var evaluated bytes.Buffer
+	engine := evaluator.NewEngine()
+	engine.File = file
+	engine.Hook = New(&evaluated).EvaluatorHook()
+	engine.Eval(parse(program))
+
+	var executed bytes.Buffer
+	c := compiler.New()
+	c.File = file
+
+	err = c.Compile(parse(program))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	machine := vm.New(c.Bytecode())
+	machine.Hook = New(&executed).VMHook()
+
+	err = machine.Run()
+	if err != nil {
+		t.Fatalf("vm error: %s", err)
+	}
+
+	for backend, out := range map[string]string{"evaluator": evaluated.String(), "vm": executed.String()} {
+		trace := strings.ReplaceAll(out, filepath.Dir(file)+string(filepath.Separator), "")
+
+		if trace != expected {
+			t.Errorf("%s: wrong trace.\nwant=%q\ngot=%q", backend, expected, trace)
+		}
+	}
+}
+
+func parse(input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	return p.ParseProgram()
+}
@@ -0,0 +1,121 @@
+package vm
+
+import (
+	"curryLang/code"
+	"curryLang/object"
+	"sort"
+)
+
+// Hook is called by the vm while it runs a program, debuggers use it to pause the program.
+// The vm can be inspected while a hook is called.
+type Hook interface {
+	// BeforeStatement is called before the first instruction of a statement, a returned error stops the program
+	BeforeStatement(vm *VM, statement code.Statement) *object.Error
+
+	// EnterFunction is called after the frame of a called function was pushed
+	EnterFunction(vm *VM, function *object.CompiledFunction)
+
+	// LeaveFunction is called after a function returned
+	LeaveFunction(vm *VM, function *object.CompiledFunction, result object.Object)
+}
+
+// Variable is a named local or global of the program
+type Variable struct {
+	Name  string
+	Value object.Object
+}
+
+// DebugFrame is an active function call as it is shown by debuggers
+type DebugFrame struct {
+	Function string
+
+	// statement which is currently executed with its source file, the line is 0 if the function has no debug information
+	Statement code.Statement
+
+	// locals which have already been set, redefined names only show the last definition
+	Locals []Variable
+}
+
+// Frames returns the active function calls, the innermost one first
+func (vm *VM) Frames() []DebugFrame {
+	frames := make([]DebugFrame, 0, vm.framesIndex)
+
+	for i := vm.framesIndex - 1; i >= 0; i-- {
+		frame := vm.frames[i]
+		debugFrame := DebugFrame{Function: frame.name(), Locals: vm.locals(frame)}
+
+		// the ip of a frame points to the current instruction or to the end of the call instruction
+		if statement, ok := frame.fn.Statements.StatementOf(frame.ip); ok {
+			debugFrame.Statement = statement
+		}
+
+		frames = append(frames, debugFrame)
+	}
+
+	return frames
+}
+
+// Position returns the source position of the current instruction of the innermost frame
+func (vm *VM) Position() (code.Position, bool) {
+	frame := vm.currentFrame()
+	return frame.fn.Statements.Lookup(frame.ip)
+}
+
+// Depth returns the number of active function calls, the main program is not a call
+func (vm *VM) Depth() int {
+	return vm.framesIndex - 1
+}
+
+// Globals returns the globals which have already been set, ordered by their name
+func (vm *VM) Globals() []Variable {
+	globals := make([]Variable, 0, len(vm.globalNames))
+	for name, index := range vm.globalNames {
+		if vm.globals[index] != nil {
+			globals = append(globals, Variable{Name: name, Value: vm.globals[index]})
+		}
+	}
+
+	sort.Slice(globals, func(i, j int) bool { return globals[i].Name < globals[j].Name })
+
+	return globals
+}
+
+func (vm *VM) locals(frame *Frame) []Variable {
+	// the main frame stores its variables in the globals
+	if frame == vm.frames[0] {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	locals := make([]Variable, 0, len(frame.fn.Locals))
+
+	for i := len(frame.fn.Locals) - 1; i >= 0; i-- {
+		name := frame.fn.Locals[i]
+		value := vm.stack[frame.basePointer+i]
+		// hidden variables like iterators of for loops have no name
+		if name == "" || seen[name] || value == nil {
+			continue
+		}
+
+		seen[name] = true
+		locals = append(locals, Variable{Name: name, Value: value})
+	}
+
+	// show the locals in the order they were defined
+	for i, j := 0, len(locals)-1; i < j; i, j = i+1, j-1 {
+		locals[i], locals[j] = locals[j], locals[i]
+	}
+
+	return locals
+}
+
+// beforeInstruction notifies the hook about the statements starting at the current instruction of the frame
+func (vm *VM) beforeInstruction(frame *Frame) error {
+	for _, statement := range frame.fn.Statements.StatementsAt(frame.ip) {
+		if err := vm.Hook.BeforeStatement(vm, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package vm
+
+import (
+	"curryLang/code"
+	"curryLang/object"
+)
+
+type Frame struct {
+	fn          *object.CompiledFunction
+	ip          int
+	basePointer int // stack position of the first local, the function itself is directly below
+
+	generator *generator // set if the frame runs a generator function, it is suspended by OpYield
+}
+
+func NewFrame(fn *object.CompiledFunction, basePointer int) *Frame {
+	return &Frame{fn: fn, ip: -1, basePointer: basePointer}
+}
+
+func (f *Frame) Instructions() code.Instructions {
+	return f.fn.Instructions
+}
+
+// span returns the source position of the instruction the frame is executing
+func (f *Frame) span() object.Span {
+	position, ok := f.fn.Statements.Lookup(f.ip)
+	if !ok {
+		return object.Span{}
+	}
+
+	return object.Span{File: position.File, Line: position.Line, Column: position.Column, Length: position.Length}
+}
+
+// name returns the name of the function shown in tracebacks and debuggers
+func (f *Frame) name() string {
+	if f.fn.Name == "" {
+		return "<anonymous>"
+	}
+
+	return f.fn.Name
+}
@@ -0,0 +1,103 @@
+package vm
+
+import (
+	"curryLang/object"
+)
+
+// generator is the suspended frame of a generator function. Its locals and the values above them are moved off the
+// stack while the function is suspended and pushed again once it is resumed.
+type generator struct {
+	fn    *object.CompiledFunction
+	ip    int
+	stack []object.Object
+	done  bool
+}
+
+// newGenerator pops the arguments and the generator function and pushes the generator of the call,
+// the function does not run until the first value is requested
+func (vm *VM) newGenerator(fn *object.CompiledFunction, numArgs int) error {
+	state := &generator{fn: fn, ip: -1, stack: make([]object.Object, fn.NumLocals)}
+	copy(state.stack, vm.stack[vm.sp-numArgs:vm.sp])
+	vm.sp = vm.sp - numArgs - 1
+
+	return vm.push(object.NewGenerator(fn.Name, func() (object.Object, bool, *object.Error) {
+		return vm.resume(state)
+	}))
+}
+
+// resume pushes the frame of the generator and runs it until it yields the next value or returns
+func (vm *VM) resume(state *generator) (object.Object, bool, *object.Error) {
+	depth := vm.framesIndex
+	sp := vm.sp
+
+	frame := &Frame{fn: state.fn, ip: state.ip, basePointer: vm.sp + 1, generator: state}
+	if frame.basePointer+len(state.stack) >= StackSize {
+		return nil, false, vm.runtimeError(object.StackOverflow())
+	}
+
+	if err := vm.pushFrame(frame); err != nil {
+		return nil, false, vm.runtimeError(err)
+	}
+
+	vm.stack[vm.sp] = state.fn
+	copy(vm.stack[frame.basePointer:], state.stack)
+	vm.sp = frame.basePointer + len(state.stack)
+	state.stack = nil
+
+	if vm.Hook != nil {
+		vm.Hook.EnterFunction(vm, state.fn)
+	}
+
+	if err := vm.run(depth); err != nil {
+		// reset the frames, so the code resuming the generator can handle the error
+		vm.framesIndex = depth
+		vm.sp = sp
+		return nil, false, vm.runtimeError(err)
+	}
+
+	return vm.pop(), state.done, nil
+}
+
+// suspend moves the frame of the generator off the stack and returns the yielded value to the resuming code
+func (vm *VM) suspend(value object.Object) error {
+	frame := vm.popFrame()
+
+	frame.generator.ip = frame.ip
+	frame.generator.stack = append([]object.Object(nil), vm.stack[frame.basePointer:vm.sp]...)
+	vm.sp = frame.basePointer - 1
+
+	if vm.Hook != nil {
+		vm.Hook.LeaveFunction(vm, frame.fn, value)
+	}
+
+	return vm.push(value)
+}
+
+// executeIterNext pops an iterator and pushes the key and the value of its next element,
+// it reports if the iterator is done instead
+func (vm *VM) executeIterNext() (bool, error) {
+	iterator := vm.pop().(object.Iterator)
+
+	key, value, done, err := iterator.Next()
+	if err != nil {
+		return false, err
+	}
+
+	if done {
+		return true, nil
+	}
+
+	if key == nil {
+		key = Null
+	}
+
+	if value == nil {
+		value = Null
+	}
+
+	if err := vm.push(key); err != nil {
+		return false, err
+	}
+
+	return false, vm.push(value)
+}
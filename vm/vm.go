@@ -4,33 +4,148 @@ import (
 	"curryLang/code"
 	"curryLang/compiler"
 	"curryLang/object"
+	"curryLang/token"
 	"fmt"
+	"sort"
 )
 
 const StackSize = 2048
 const GlobalsSize = 65536
+const MaxFrames = 1024
 
 type VM struct {
-	constants    []object.Object
-	instructions code.Instructions
-	stack        []object.Object
-	globals      []object.Object
-	sp           int // Always points to the next value. Top of stack is stack[sp-1]
-	DebugMode    bool
+	constants   []object.Object
+	stack       []object.Object
+	globals     []object.Object
+	globalNames map[string]int
+	sp          int // Always points to the next value. Top of stack is stack[sp-1]
+	DebugMode   bool
+
+	frames      []*Frame
+	framesIndex int
+
+	// resources the program can use, the call depth is also limited by MaxFrames.
+	// The usage is shared with the vms of spawned tasks.
+	Limits object.Limits
+	usage  *object.Usage
+
+	// Tasks runs the tasks spawned by the program
+	Tasks *object.Scheduler
+	lists []*object.Builtin // builtins calling functions of this vm, created by their first use
+
+	// Hook is notified about executed statements and function calls, it is used by debuggers
+	Hook Hook
 }
 
 var True = &object.Boolean{Value: true}
 var False = &object.Boolean{Value: false}
+var Null = &object.Null{}
 
 func New(bytecode *compiler.Bytecode) *VM {
-	return &VM{
-		instructions: bytecode.Instructions,
-		constants:    bytecode.Constants,
-		stack:        make([]object.Object, StackSize),
-		globals:      make([]object.Object, GlobalsSize),
-		sp:           0,
-		DebugMode:    false,
+	mainFn := &object.CompiledFunction{
+		Name:         "main",
+		Instructions: bytecode.Instructions,
+		Handlers:     bytecode.Handlers,
+		Statements:   bytecode.Statements,
+	}
+
+	frames := make([]*Frame, MaxFrames)
+	frames[0] = NewFrame(mainFn, 0)
+
+	vm := &VM{
+		constants:   bytecode.Constants,
+		stack:       make([]object.Object, StackSize),
+		globals:     make([]object.Object, GlobalsSize),
+		globalNames: bytecode.Globals,
+		sp:          0,
+		DebugMode:   false,
+		frames:      frames,
+		framesIndex: 1,
+		usage:       &object.Usage{},
+		Tasks:       object.NewScheduler(),
 	}
+
+	// the object package calls methods through the struct, e.g. Inspect calls string of Stringer instances
+	for _, constant := range bytecode.Constants {
+		if structObj, ok := constant.(*object.Struct); ok && len(structObj.CompiledMethods) > 0 {
+			structObj.Invoke = vm.invokeMethod
+		}
+	}
+
+	return vm
+}
+
+// NewWithGlobals creates a vm which uses the globals of previously run programs
+func NewWithGlobals(bytecode *compiler.Bytecode, globals []object.Object) *VM {
+	vm := New(bytecode)
+	vm.globals = globals
+	return vm
+}
+
+func (vm *VM) currentFrame() *Frame {
+	return vm.frames[vm.framesIndex-1]
+}
+
+func (vm *VM) pushFrame(f *Frame) error {
+	if vm.framesIndex >= MaxFrames {
+		return object.StackOverflow()
+	}
+
+	// the main frame is not a call
+	if vm.Limits.MaxCallDepth > 0 && vm.framesIndex > vm.Limits.MaxCallDepth {
+		return newError(object.CALL_DEPTH_ERROR, "call depth limit of %d exceeded", vm.Limits.MaxCallDepth)
+	}
+
+	vm.frames[vm.framesIndex] = f
+	vm.framesIndex++
+
+	return nil
+}
+
+func (vm *VM) popFrame() *Frame {
+	vm.framesIndex--
+	return vm.frames[vm.framesIndex]
+}
+
+// Global returns the value of a global by its name after the program was run
+func (vm *VM) Global(name string) (object.Object, bool) {
+	index, ok := vm.globalNames[name]
+	if !ok || vm.globals[index] == nil {
+		return nil, false
+	}
+
+	return vm.globals[index], true
+}
+
+// Call runs a compiled or builtin function with the arguments and returns its result
+func (vm *VM) Call(function object.Object, args ...object.Object) (object.Object, error) {
+	depth := vm.framesIndex
+
+	err := vm.push(function)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, arg := range args {
+		err = vm.push(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = vm.callFunction(len(args), nil)
+	if err != nil {
+		return nil, vm.runtimeError(err)
+	}
+
+	err = vm.run(depth)
+	if err != nil {
+		// reset the frames, so the vm can still be used for further calls
+		vm.framesIndex = depth
+		return nil, err
+	}
+
+	return vm.pop(), nil
 }
 
 func (vm *VM) StackTop() object.Object {
@@ -50,8 +165,101 @@ func (vm *VM) Run() error {
 		vm.DumpByteCode()
 	}
 
-	for ip := 0; ip < len(vm.instructions); ip++ {
-		op := code.Opcode(vm.instructions[ip])
+	err := vm.run(0)
+
+	// spawned tasks can not continue once the main task ended
+	vm.Tasks.Cancel()
+
+	if vm.DebugMode {
+		fmt.Println()
+		fmt.Println()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// run executes instructions until the main frame is finished or the number of frames drops to depth,
+// errors are passed to the innermost handler of the frames above depth
+func (vm *VM) run(depth int) error {
+	for {
+		err := vm.execute(depth)
+		if err == nil {
+			return nil
+		}
+
+		runtimeErr := vm.runtimeError(err)
+		if !vm.catch(runtimeErr, depth) {
+			return runtimeErr
+		}
+	}
+}
+
+// catch unwinds the frames above depth until one of them has a handler for the current instruction,
+// the stack is reset to the locals of this frame and the error instance is pushed for the handler
+func (vm *VM) catch(err *object.Error, depth int) bool {
+	if !err.Catchable() {
+		return false
+	}
+
+	for vm.framesIndex > depth {
+		frame := vm.currentFrame()
+
+		for _, handler := range frame.fn.Handlers {
+			if frame.ip < handler.Start || frame.ip >= handler.End {
+				continue
+			}
+
+			vm.sp = frame.basePointer + frame.fn.NumLocals
+			frame.ip = handler.Target - 1
+
+			return vm.push(err.Instance()) == nil
+		}
+
+		// the main frame is never popped, it is still needed to report the error
+		if vm.framesIndex == 1 {
+			return false
+		}
+
+		vm.popFrame()
+	}
+
+	return false
+}
+
+// execute runs instructions until the main frame is finished, the number of frames drops to depth or an error is raised
+func (vm *VM) execute(depth int) error {
+	var ip int
+	var ins code.Instructions
+	var op code.Opcode
+
+	for vm.framesIndex > depth {
+		frame := vm.currentFrame()
+		if frame.ip >= len(frame.Instructions())-1 {
+			if vm.framesIndex == 1 {
+				return nil
+			}
+
+			return fmt.Errorf("function %s ended without return", frame.fn.Name)
+		}
+
+		if err := vm.Limits.Step(vm.usage); err != nil {
+			return err
+		}
+
+		frame.ip++
+		ip = frame.ip
+		ins = frame.Instructions()
+		op = code.Opcode(ins[ip])
+
+		if vm.Hook != nil {
+			if err := vm.beforeInstruction(frame); err != nil {
+				return err
+			}
+		}
 
 		if vm.DebugMode {
 			opDef, _ := code.Lookup(byte(op))
@@ -61,8 +269,8 @@ func (vm *VM) Run() error {
 		switch op {
 
 		case code.OpConstant:
-			constIndex := code.ReadUint16(vm.instructions[ip+1:])
-			ip += 2
+			constIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
 			err := vm.push(vm.constants[constIndex])
 			if err != nil {
 				return err
@@ -80,18 +288,59 @@ func (vm *VM) Run() error {
 				return err
 			}
 
+		case code.OpNull:
+			err := vm.push(Null)
+			if err != nil {
+				return err
+			}
+
+		case code.OpInstance:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			err := vm.executeInstance(vm.constants[constIndex])
+			if err != nil {
+				return err
+			}
+
+		case code.OpGetField:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			err := vm.executeGetField(vm.constants[constIndex])
+			if err != nil {
+				return err
+			}
+
+		case code.OpGetMethod:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			err := vm.executeGetMethod(vm.constants[constIndex])
+			if err != nil {
+				return err
+			}
+
+		case code.OpSetField:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			err := vm.executeSetField(vm.constants[constIndex])
+			if err != nil {
+				return err
+			}
+
 		case code.OpMinus:
 			right := vm.pop()
 			rightType := right.Type()
 
 			if rightType != object.INTEGER_OBJ {
-				return fmt.Errorf("%s does not support minus operator", rightType)
+				return newError(object.TYPE_ERROR, "%s does not support minus operator", rightType)
 			}
 
 			intVal := right.(*object.Integer)
-			intVal.Value *= -1
 
-			err := vm.push(intVal)
+			err := vm.push(&object.Integer{Value: -intVal.Value})
 			if err != nil {
 				return err
 			}
@@ -101,7 +350,7 @@ func (vm *VM) Run() error {
 			rightType := right.Type()
 
 			if rightType != object.BOOLEAN_OBJ {
-				return fmt.Errorf("%s does not support bang operator", rightType)
+				return newError(object.TYPE_ERROR, "%s does not support bang operator", rightType)
 			}
 
 			boolValue := right.(*object.Boolean)
@@ -123,43 +372,290 @@ func (vm *VM) Run() error {
 			}
 
 		case code.OpPop:
-			vm.pop()
+			_, err := vm.popOperand()
+			if err != nil {
+				return err
+			}
 
 		case code.OpJumpIfFalse:
-			jumpVal := code.ReadUint16(vm.instructions[ip+1:])
-			conditionVal := vm.pop()
+			jumpVal := code.ReadUint16(ins[ip+1:])
+			conditionVal, err := vm.popOperand()
+			if err != nil {
+				return err
+			}
 
 			if conditionVal.Type() != object.BOOLEAN_OBJ {
-				return fmt.Errorf("unsupported type for boolean jump: %s", conditionVal.Type())
+				return newError(object.TYPE_ERROR, "unsupported type for boolean jump: %s", conditionVal.Type())
 			}
 
 			boolVal, _ := conditionVal.(*object.Boolean)
 
-			if boolVal == False {
-				ip += int(jumpVal) - 1
+			// booleans of host objects are not the shared instances of the vm
+			if !boolVal.Value {
+				vm.currentFrame().ip = int(jumpVal) - 1
 			} else {
-				ip += 2
+				vm.currentFrame().ip += 2
 			}
 
 		case code.OpJump:
-			jumpVal := code.ReadUint16(vm.instructions[ip+1:])
-			ip += int(jumpVal)
+			jumpVal := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip = int(jumpVal) - 1
+
+		case code.OpSetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			vm.stack[vm.currentFrame().basePointer+int(localIndex)] = vm.pop()
+
+		case code.OpGetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			err := vm.push(vm.stack[vm.currentFrame().basePointer+int(localIndex)])
+			if err != nil {
+				return err
+			}
+
+		case code.OpCall:
+			numArgs := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			err := vm.callFunction(int(numArgs), nil)
+			if err != nil {
+				return err
+			}
+
+		case code.OpCallNamed:
+			numArgs := code.ReadUint8(ins[ip+1:])
+			constIndex := code.ReadUint16(ins[ip+2:])
+			vm.currentFrame().ip += 3
+
+			var names []string
+			for _, name := range vm.constants[constIndex].(*object.List).Value {
+				names = append(names, name.(*object.String).Value)
+			}
+
+			err := vm.callFunction(int(numArgs), names)
+			if err != nil {
+				return err
+			}
+
+		case code.OpDefault:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			target := code.ReadUint16(ins[ip+2:])
+			vm.currentFrame().ip += 3
+
+			if vm.stack[vm.currentFrame().basePointer+int(localIndex)] != nil {
+				vm.currentFrame().ip = int(target) - 1
+			}
+
+		case code.OpReturnValue:
+			returnValue := vm.pop()
+
+			frame := vm.popFrame()
+			vm.sp = frame.basePointer - 1
+
+			if frame.generator != nil {
+				frame.generator.done = true
+			}
+
+			if vm.Hook != nil {
+				vm.Hook.LeaveFunction(vm, frame.fn, returnValue)
+			}
+
+			err := vm.push(returnValue)
+			if err != nil {
+				return err
+			}
+
+		case code.OpReturn:
+			frame := vm.popFrame()
+			vm.sp = frame.basePointer - 1
+
+			if frame.generator != nil {
+				frame.generator.done = true
+			}
+
+			if vm.Hook != nil {
+				vm.Hook.LeaveFunction(vm, frame.fn, Null)
+			}
+
+			err := vm.push(Null)
+			if err != nil {
+				return err
+			}
 
 		case code.OpSetGlobal:
-			variableIndex := code.ReadUint16(vm.instructions[ip+1:])
-			ip += 2
+			variableIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
 
 			vm.globals[variableIndex] = vm.pop()
 
 		case code.OpGetGlobal:
-			variableIndex := code.ReadUint16(vm.instructions[ip+1:])
-			ip += 2
+			variableIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
 
 			err := vm.push(vm.globals[variableIndex])
 			if err != nil {
 				return err
 			}
 
+		case code.OpThrow:
+			return object.Throw(vm.pop())
+
+		case code.OpGetBuiltin:
+			builtinIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			err := vm.push(vm.builtin(int(builtinIndex)))
+			if err != nil {
+				return err
+			}
+
+		case code.OpSpawn:
+			numArgs := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			err := vm.spawn(int(numArgs))
+			if err != nil {
+				return err
+			}
+
+		case code.OpSelect:
+			numCases := int(code.ReadUint8(ins[ip+1:]))
+			hasDefault := code.ReadUint8(ins[ip+2:]) == 1
+			vm.currentFrame().ip += 2
+
+			index, err := vm.executeSelect(numCases, !hasDefault)
+			if err != nil {
+				return err
+			}
+
+			// the jumps to the bodies follow, the one of the default case is the last
+			if index < 0 {
+				index = numCases
+			}
+
+			vm.currentFrame().ip += index * jumpWidth
+
+		case code.OpYield:
+			err := vm.suspend(vm.pop())
+			if err != nil {
+				return err
+			}
+
+		case code.OpIter:
+			variables := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			iterator, err := vm.iterate(vm.pop(), variables == 2)
+			if err != nil {
+				return err
+			}
+
+			err = vm.push(iterator)
+			if err != nil {
+				return err
+			}
+
+		case code.OpList:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			err := vm.executeList(numElements)
+			if err != nil {
+				return err
+			}
+
+		case code.OpRange:
+			inclusive := code.ReadUint8(ins[ip+1:]) == 1
+			vm.currentFrame().ip += 1
+
+			end := vm.pop()
+			start := vm.pop()
+
+			startValue, startOk := start.(*object.Integer)
+			endValue, endOk := end.(*object.Integer)
+			if !startOk || !endOk {
+				return newError(object.TYPE_ERROR, "unsupported types for range: %s %s", start.Type(), end.Type())
+			}
+
+			err := vm.push(&object.Range{Start: startValue.Value, End: endValue.Value, Inclusive: inclusive})
+			if err != nil {
+				return err
+			}
+
+		case code.OpIndex:
+			index := vm.pop()
+			source := vm.pop()
+
+			value, err := object.Index(source, index)
+			if err != nil {
+				return err
+			}
+
+			if err := vm.push(value); err != nil {
+				return err
+			}
+
+		case code.OpMatch:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			err := vm.executeMatch(vm.constants[constIndex].(object.Pattern))
+			if err != nil {
+				return err
+			}
+
+		case code.OpDestructure:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			bound, err := object.Destructure(vm.constants[constIndex].(object.Pattern), vm.pop())
+			if err != nil {
+				return err
+			}
+
+			for _, value := range bound {
+				if err := vm.push(value); err != nil {
+					return err
+				}
+			}
+
+		case code.OpNoMatch:
+			return newError(object.MATCH_ERROR, "no arm of match matches value %s", vm.pop().Inspect())
+
+		case code.OpJumpTable:
+			constIndex := code.ReadUint16(ins[ip+1:])
+
+			table := vm.constants[constIndex].(*object.JumpTable)
+			vm.currentFrame().ip = table.Target(vm.pop()) - 1
+
+		case code.OpSlice:
+			end := vm.pop()
+			start := vm.pop()
+			source := vm.pop()
+
+			err := vm.executeSlice(source, start, end)
+			if err != nil {
+				return err
+			}
+
+		case code.OpIterNext:
+			jumpVal := code.ReadUint16(ins[ip+1:])
+
+			// the generator runs its frames on top of this one until it yields
+			done, err := vm.executeIterNext()
+			if err != nil {
+				return err
+			}
+
+			if done {
+				vm.currentFrame().ip = int(jumpVal) - 1
+			} else {
+				vm.currentFrame().ip += 2
+			}
+
 		default:
 			def, err := code.Lookup(byte(op))
 			if err != nil {
@@ -170,9 +666,230 @@ func (vm *VM) Run() error {
 		}
 	}
 
-	if vm.DebugMode {
-		fmt.Println()
-		fmt.Println()
+	return nil
+}
+
+// runtimeError adds the position of the current instruction and the functions of the active frames
+// to an error raised while executing instructions, errors passed on by nested runs already have them
+func (vm *VM) runtimeError(err error) *object.Error {
+	runtimeErr, ok := err.(*object.Error)
+	if !ok {
+		runtimeErr = &object.Error{Kind: object.RUNTIME_ERROR, Message: err.Error()}
+	}
+
+	if runtimeErr.Span.Line == 0 {
+		runtimeErr.Span = vm.frames[vm.framesIndex-1].span()
+	}
+
+	if len(runtimeErr.Stack) > 0 {
+		return runtimeErr
+	}
+
+	// every function was called at the instruction its caller is paused at
+	for i := vm.framesIndex - 1; i > 0; i-- {
+		runtimeErr.Stack = append(runtimeErr.Stack, object.StackFrame{Function: vm.frames[i].name(), Call: vm.frames[i-1].span()})
+	}
+
+	return runtimeErr
+}
+
+// jumpWidth is the size of an OpJump instruction, OpSelect skips the jumps of the cases which were not selected
+const jumpWidth = 1 + code.OpcodeU16
+
+// spawn calls the function below the arguments on the stack in a new task
+func (vm *VM) spawn(numArgs int) error {
+	args := make([]object.Object, numArgs)
+	copy(args, vm.stack[vm.sp-numArgs:vm.sp])
+	callee := vm.stack[vm.sp-1-numArgs]
+	vm.sp = vm.sp - numArgs - 1
+
+	switch callee := callee.(type) {
+	case *object.CompiledFunction:
+		// the arguments are bound by the task, but invalid calls fail when they are spawned
+		if _, err := callee.Signature.Bind("spawned "+callee.Name, args, nil); err != nil {
+			return err
+		}
+	case *object.Builtin:
+	default:
+		return newError(object.TYPE_ERROR, "spawning non-function: %s", callee.Type())
+	}
+
+	task := vm.newTask()
+
+	vm.Tasks.Spawn(func() *object.Error {
+		_, err := task.Call(callee, args...)
+		if runtimeErr, ok := err.(*object.Error); ok {
+			return runtimeErr
+		} else if err != nil {
+			return &object.Error{Kind: object.RUNTIME_ERROR, Message: err.Error()}
+		}
+
+		return nil
+	})
+
+	return vm.push(Null)
+}
+
+// newTask returns the vm of a spawned task, it shares the constants, globals, limits and hook with this vm.
+// Its bottom frame is a copy of the spawning one, so errors of the task show where it was spawned.
+func (vm *VM) newTask() *VM {
+	spawner := vm.currentFrame()
+
+	frames := make([]*Frame, MaxFrames)
+	frames[0] = &Frame{fn: spawner.fn, ip: spawner.ip, basePointer: 0}
+
+	return &VM{
+		constants:   vm.constants,
+		stack:       make([]object.Object, StackSize),
+		globals:     vm.globals,
+		globalNames: vm.globalNames,
+		frames:      frames,
+		framesIndex: 1,
+		Limits:      vm.Limits,
+		usage:       vm.usage,
+		Tasks:       vm.Tasks,
+		Hook:        vm.Hook,
+	}
+}
+
+// executeSelect pops the cases of OpSelect, performs one of them and pushes its received value,
+// it returns the index of the performed case or -1 if none could proceed without blocking
+func (vm *VM) executeSelect(numCases int, block bool) (int, error) {
+	cases := make([]object.SelectCase, numCases)
+
+	for i := numCases - 1; i >= 0; i-- {
+		send := vm.pop() == True
+		value := vm.pop()
+		source := vm.pop()
+
+		channel, ok := source.(*object.Channel)
+		if !ok {
+			return 0, newError(object.TYPE_ERROR, "unsupported type for select case: %s", source.Type())
+		}
+
+		cases[i] = object.SelectCase{Channel: channel, Send: send, Value: value}
+	}
+
+	index, value, err := vm.Tasks.Select(cases, block)
+	if err != nil {
+		return 0, err
+	}
+
+	if value == nil {
+		value = Null
+	}
+
+	return index, vm.push(value)
+}
+
+func newError(kind object.ErrorKind, format string, args ...interface{}) *object.Error {
+	return &object.Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// callFunction calls the function below its arguments on the stack, the last of them are passed by the names
+func (vm *VM) callFunction(numArgs int, names []string) error {
+	callee := vm.stack[vm.sp-1-numArgs]
+
+	switch callee := callee.(type) {
+	case *object.CompiledFunction:
+		// calls passing all parameters by position keep their arguments on the stack
+		if len(names) > 0 || numArgs != callee.NumParameters || callee.Signature.Variadic {
+			err := vm.bindArguments(callee, numArgs, names)
+			if err != nil {
+				return err
+			}
+
+			numArgs = callee.NumParameters
+		}
+
+		if callee.Generator {
+			return vm.newGenerator(callee, numArgs)
+		}
+
+		frame := NewFrame(callee, vm.sp-numArgs)
+		err := vm.pushFrame(frame)
+		if err != nil {
+			return err
+		}
+
+		vm.sp = frame.basePointer + callee.NumLocals
+		if vm.sp >= StackSize {
+			return object.StackOverflow()
+		}
+
+		if vm.Hook != nil {
+			// debuggers only show the locals which were set by the function itself
+			for i := frame.basePointer + numArgs; i < vm.sp; i++ {
+				vm.stack[i] = nil
+			}
+
+			vm.Hook.EnterFunction(vm, callee)
+		}
+
+		return nil
+
+	case *object.BoundMethod:
+		// the instance is passed as first argument, in front of the arguments of the call
+		if vm.sp >= StackSize {
+			return object.StackOverflow()
+		}
+
+		copy(vm.stack[vm.sp-numArgs+1:vm.sp+1], vm.stack[vm.sp-numArgs:vm.sp])
+		vm.stack[vm.sp-numArgs] = callee.Self
+		vm.stack[vm.sp-numArgs-1] = callee.Method
+		vm.sp++
+
+		return vm.callFunction(numArgs+1, names)
+
+	case *object.Builtin:
+		if len(names) > 0 {
+			return newError(object.ARGUMENT_ERROR, "builtins can not be called with named arguments")
+		}
+
+		args := make([]object.Object, numArgs)
+		copy(args, vm.stack[vm.sp-numArgs:vm.sp])
+		vm.sp = vm.sp - numArgs - 1
+
+		result := callee.Function(token.Token{}, args...)
+		if err, ok := result.(*object.Error); ok {
+			if err.Kind == "" {
+				err.Kind = object.RUNTIME_ERROR
+			}
+
+			return err
+		}
+
+		if result == nil {
+			result = Null
+		}
+
+		return vm.push(result)
+	}
+
+	return newError(object.TYPE_ERROR, "calling non-function: %s", callee.Type())
+}
+
+// bindArguments replaces the arguments on the stack by the values of the parameters of the function,
+// parameters with a default value which got no argument are nil
+func (vm *VM) bindArguments(fn *object.CompiledFunction, numArgs int, names []string) error {
+	args := vm.stack[vm.sp-numArgs : vm.sp]
+	positional := args[:numArgs-len(names)]
+
+	named := make([]object.NamedArgument, len(names))
+	for i, name := range names {
+		named[i] = object.NamedArgument{Name: name, Value: args[len(positional)+i]}
+	}
+
+	bound, err := fn.Signature.Bind(fn.Name, positional, named)
+	if err != nil {
+		return err
+	}
+
+	vm.sp -= numArgs
+	for _, value := range bound {
+		if err := vm.push(value); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -186,7 +903,14 @@ func (vm *VM) executeBinaryOperation(op code.Opcode) error {
 	if leftType == object.INTEGER_OBJ && rightType == object.INTEGER_OBJ {
 		return vm.executeBinaryIntegerOperation(op, left, right)
 	}
-	return fmt.Errorf("unsupported types for binary operation: %s %s", leftType, rightType)
+	if leftType == object.STRING_OBJ && rightType == object.STRING_OBJ && op == code.OpAdd {
+		if err := vm.Limits.Allocate(vm.usage, 0); err != nil {
+			return err
+		}
+
+		return vm.push(&object.String{Value: left.(*object.String).Value + right.(*object.String).Value})
+	}
+	return newError(object.TYPE_ERROR, "unsupported types for binary operation: %s %s", leftType, rightType)
 }
 
 func (vm *VM) executeBinaryIntegerOperation(op code.Opcode, left, right object.Object) error {
@@ -203,7 +927,7 @@ func (vm *VM) executeBinaryIntegerOperation(op code.Opcode, left, right object.O
 	case code.OpDiv:
 		result = leftValue / rightValue
 	default:
-		return fmt.Errorf("unknown integer operator: %d", op)
+		return newError(object.TYPE_ERROR, "unknown integer operator: %d", op)
 	}
 	return vm.push(&object.Integer{Value: result})
 }
@@ -222,7 +946,15 @@ func (vm *VM) executeComparison(op code.Opcode) error {
 		return vm.executeComparisonBoolean(op, left, right)
 	}
 
-	return fmt.Errorf("unsupported types for binary operation: %s %s", leftType, rightType)
+	if leftType == object.INSTANCE_OBJ && rightType == object.INSTANCE_OBJ && op == code.OpGreaterThan {
+		return vm.executeComparisonInstance(left.(*object.Instance), right)
+	}
+
+	if op == code.OpEqual || op == code.OpNotEqual {
+		return vm.executeComparisonEquality(op, left, right)
+	}
+
+	return newError(object.TYPE_ERROR, "unsupported types for binary operation: %s %s", leftType, rightType)
 }
 
 func (vm *VM) executeComparisonInteger(op code.Opcode, left, right object.Object) error {
@@ -238,7 +970,7 @@ func (vm *VM) executeComparisonInteger(op code.Opcode, left, right object.Object
 		result = leftValue != rightValue
 	default:
 		def, _ := code.Lookup(byte(op))
-		return fmt.Errorf("unknown integer operator: %s", def.Name)
+		return newError(object.TYPE_ERROR, "unknown integer operator: %s", def.Name)
 	}
 
 	return vm.push(nativeBooleanToVmBoolean(result))
@@ -250,17 +982,263 @@ func (vm *VM) executeComparisonBoolean(op code.Opcode, left, right object.Object
 	var result bool
 	switch op {
 	case code.OpEqual:
-		result = leftValue == rightValue
+		result = leftValue.Value == rightValue.Value
 	case code.OpNotEqual:
-		result = leftValue != rightValue
+		result = leftValue.Value != rightValue.Value
 	default:
 		def, _ := code.Lookup(byte(op))
-		return fmt.Errorf("unknown integer operator: %s", def.Name)
+		return newError(object.TYPE_ERROR, "unknown integer operator: %s", def.Name)
+	}
+
+	return vm.push(nativeBooleanToVmBoolean(result))
+}
+
+// executeComparisonInstance compares instances of structs implementing the Comparable trait by calling their compare method
+func (vm *VM) executeComparisonInstance(left *object.Instance, right object.Object) error {
+	method, ok := left.Struct.CompiledMethods["compare"]
+	if !ok || !left.Struct.Implements(object.COMPARABLE_TRAIT) {
+		return newError(object.TYPE_ERROR, "struct %s does not implement trait %s", left.Struct.Name, object.COMPARABLE_TRAIT)
+	}
+
+	result, err := vm.Call(method, left, right)
+	if err != nil {
+		return err
+	}
+
+	comparison, ok := result.(*object.Integer)
+	if !ok {
+		return newError(object.TYPE_ERROR, "method compare of struct %s has to return an integer but returned %s", left.Struct.Name, result.Type())
+	}
+
+	return vm.push(nativeBooleanToVmBoolean(comparison.Value > 0))
+}
+
+// invokeMethod calls a compiled method of an instance, errors are returned as values like the evaluator does
+func (vm *VM) invokeMethod(name string, self object.Object, args ...object.Object) object.Object {
+	instance := self.(*object.Instance)
+
+	method, ok := instance.Struct.CompiledMethods[name]
+	if !ok {
+		return newError(object.TYPE_ERROR, "method %s of struct %s is not compiled for the vm", name, instance.Struct.Name)
+	}
+
+	return vm.callValue(method, append([]object.Object{self}, args...)...)
+}
+
+// callValue calls a function passed to a builtin, like the function of map, errors are returned as values
+func (vm *VM) callValue(function object.Object, args ...object.Object) object.Object {
+	result, err := vm.Call(function, args...)
+	if runtimeErr, ok := err.(*object.Error); ok {
+		return runtimeErr
+	} else if err != nil {
+		return &object.Error{Kind: object.RUNTIME_ERROR, Message: err.Error()}
+	}
+
+	return result
+}
+
+// builtin returns the builtin of object.BuiltinNames with the index, the ones calling functions are bound to this vm
+func (vm *VM) builtin(index int) *object.Builtin {
+	tasks := vm.Tasks.Builtins()
+	if index < len(tasks) {
+		return tasks[index]
+	}
+
+	if vm.lists == nil {
+		vm.lists = object.ListBuiltins(vm.callValue)
+	}
+
+	return vm.lists[index-len(tasks)]
+}
+
+// iterate returns the iterator of a for loop, instances of structs implementing the Iterator trait are iterated by
+// calling their compiled next method until it returns null
+func (vm *VM) iterate(iterable object.Object, keys bool) (object.Iterator, error) {
+	instance, ok := iterable.(*object.Instance)
+	if ok && instance.Struct.Implements(object.ITERATOR_TRAIT) {
+		method, ok := instance.Struct.CompiledMethods["next"]
+		if !ok {
+			return nil, newError(object.TYPE_ERROR, "method next of struct %s is not compiled for the vm", instance.Struct.Name)
+		}
+
+		return object.NewSequence(func() (object.Object, bool, *object.Error) {
+			result, err := vm.Call(method, instance)
+			if runtimeErr, ok := err.(*object.Error); ok {
+				return nil, false, runtimeErr
+			} else if err != nil {
+				return nil, false, &object.Error{Kind: object.RUNTIME_ERROR, Message: err.Error()}
+			}
+
+			return result, result.Type() == object.NULL_OBJ, nil
+		}), nil
+	}
+
+	iterator, ok := object.Iterate(iterable, keys)
+	if !ok {
+		return nil, newError(object.TYPE_ERROR, "unsupported type for iteration: %s", iterable.Type())
+	}
+
+	return iterator, nil
+}
+
+func (vm *VM) executeComparisonEquality(op code.Opcode, left, right object.Object) error {
+	result := object.Equal(left, right)
+	if op == code.OpNotEqual {
+		result = !result
 	}
 
 	return vm.push(nativeBooleanToVmBoolean(result))
 }
 
+func (vm *VM) executeInstance(definition object.Object) error {
+	structObj, ok := definition.(*object.Struct)
+	if !ok {
+		return newError(object.TYPE_ERROR, "unsupported type for instance creation: %s", definition.Type())
+	}
+
+	if err := vm.Limits.Allocate(vm.usage, 0); err != nil {
+		return err
+	}
+
+	instance := &object.Instance{
+		Struct: structObj,
+		Fields: make(map[string]object.Object, len(structObj.Fields)),
+	}
+
+	for i := len(structObj.Fields) - 1; i >= 0; i-- {
+		instance.Fields[structObj.Fields[i]] = vm.pop()
+	}
+
+	return vm.push(instance)
+}
+
+// executeList pops the elements of a list literal, all of them need to have the type of the first one
+func (vm *VM) executeList(numElements int) error {
+	if err := vm.Limits.Allocate(vm.usage, numElements); err != nil {
+		return err
+	}
+
+	list := &object.List{Value: make([]object.Object, numElements)}
+	copy(list.Value, vm.stack[vm.sp-numElements:vm.sp])
+	vm.sp -= numElements
+
+	for i, element := range list.Value {
+		if i == 0 {
+			list.ValueType = element.Type()
+		} else if element.Type() != list.ValueType {
+			return newError(object.TYPE_ERROR, "list members have to be all of the same type, value #%d has type %s instead of %s",
+				i, element.Type(), list.ValueType)
+		}
+	}
+
+	return vm.push(list)
+}
+
+// executeMatch pops a value and pushes the values bound by the pattern followed by true if it matches,
+// only false otherwise
+func (vm *VM) executeMatch(pattern object.Pattern) error {
+	bound, ok := pattern.Match(vm.pop(), nil)
+	if !ok {
+		return vm.push(False)
+	}
+
+	for _, value := range bound {
+		if err := vm.push(value); err != nil {
+			return err
+		}
+	}
+
+	return vm.push(True)
+}
+
+func (vm *VM) executeSlice(source, start, end object.Object) error {
+	value, err := object.Slice(source, start, end)
+	if err != nil {
+		return err
+	}
+
+	if list, ok := value.(*object.List); ok {
+		if err := vm.Limits.Allocate(vm.usage, len(list.Value)); err != nil {
+			return err
+		}
+	}
+
+	return vm.push(value)
+}
+
+func (vm *VM) executeGetField(fieldName object.Object) error {
+	source := vm.pop()
+
+	if host, ok := source.(object.HostObject); ok {
+		value, err := host.GetField(fieldName.(*object.String).Value)
+		if err != nil {
+			return err
+		}
+
+		return vm.push(value)
+	}
+
+	instance, ok := source.(*object.Instance)
+	if !ok {
+		return newError(object.TYPE_ERROR, "unsupported type for field access: %s", source.Type())
+	}
+
+	name := fieldName.(*object.String).Value
+	if !instance.Struct.HasField(name) {
+		return newError(object.FIELD_ERROR, "struct %s has no field %s", instance.Struct.Name, name)
+	}
+
+	return vm.push(instance.Fields[name])
+}
+
+// executeGetMethod pushes the method of an instance bound to it, other values are handled like field access
+func (vm *VM) executeGetMethod(methodName object.Object) error {
+	instance, ok := vm.StackTop().(*object.Instance)
+	if !ok {
+		return vm.executeGetField(methodName)
+	}
+
+	name := methodName.(*object.String).Value
+
+	method, ok := instance.Struct.CompiledMethods[name]
+	if !ok && !instance.Struct.HasField(name) {
+		return newError(object.FIELD_ERROR, "struct %s has no method %s", instance.Struct.Name, name)
+	} else if !ok {
+		return vm.executeGetField(methodName)
+	}
+
+	vm.pop()
+
+	return vm.push(&object.BoundMethod{Self: instance, Method: method})
+}
+
+func (vm *VM) executeSetField(fieldName object.Object) error {
+	value := vm.pop()
+	source := vm.pop()
+
+	if host, ok := source.(object.HostObject); ok {
+		if err := host.SetField(fieldName.(*object.String).Value, value); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	instance, ok := source.(*object.Instance)
+	if !ok {
+		return newError(object.TYPE_ERROR, "unsupported type for field assignment: %s", source.Type())
+	}
+
+	name := fieldName.(*object.String).Value
+	if !instance.Struct.HasField(name) {
+		return newError(object.FIELD_ERROR, "struct %s has no field %s", instance.Struct.Name, name)
+	}
+
+	instance.Fields[name] = value
+
+	return nil
+}
+
 func nativeBooleanToVmBoolean(val bool) *object.Boolean {
 	if val {
 		return True
@@ -270,8 +1248,9 @@ func nativeBooleanToVmBoolean(val bool) *object.Boolean {
 }
 
 func (vm *VM) push(o object.Object) error {
+	// only deep recursion can fill the stack
 	if vm.sp >= StackSize {
-		return fmt.Errorf("stack overflow")
+		return object.StackOverflow()
 	}
 
 	vm.stack[vm.sp] = o
@@ -286,6 +1265,40 @@ func (vm *VM) pop() object.Object {
 	return o
 }
 
+// popOperand pops a value pushed by the current frame, invalid bytecode must not pop its locals or the frames below
+func (vm *VM) popOperand() (object.Object, error) {
+	frame := vm.currentFrame()
+	if vm.sp <= frame.basePointer+frame.fn.NumLocals {
+		return nil, newError(object.RUNTIME_ERROR, "stack underflow in function %s", frame.name())
+	}
+
+	return vm.pop(), nil
+}
+
+// DumpByteCode prints the instructions of the main program and of the compiled functions with their source lines
 func (vm *VM) DumpByteCode() {
-	fmt.Println(vm.instructions)
+	fmt.Println(vm.frames[0].Instructions().Disassemble(vm.frames[0].fn.Statements, nil))
+
+	for _, constant := range vm.constants {
+		switch constant := constant.(type) {
+		case *object.CompiledFunction:
+			fmt.Printf("fn %s:\n", constant.Name)
+			fmt.Println(constant.Instructions.Disassemble(constant.Statements, nil))
+
+		// methods are not constants, they are stored in their struct
+		case *object.Struct:
+			names := make([]string, 0, len(constant.CompiledMethods))
+			for name := range constant.CompiledMethods {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+
+			for _, name := range names {
+				method := constant.CompiledMethods[name]
+				fmt.Printf("fn %s.%s:\n", constant.Name, name)
+				fmt.Println(method.Instructions.Disassemble(method.Statements, nil))
+			}
+		}
+	}
 }
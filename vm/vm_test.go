@@ -1,13 +1,18 @@
 package vm
 
 import (
+	"context"
 	"curryLang/ast"
+	"curryLang/code"
 	"curryLang/compiler"
 	"curryLang/lexer"
 	"curryLang/object"
 	"curryLang/parser"
+	"curryLang/token"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestIntegerArithmetic(t *testing.T) {
@@ -89,6 +94,916 @@ func TestGlobalLetStatements(t *testing.T) {
 	runVmTests(t, tests, true)
 }
 
+func TestStructs(t *testing.T) {
+	tests := []vmTestCase{
+		{"struct Point { x, y }; let p = Point{x: 1, y: 2}; p.x", 1},
+		{"struct Point { x, y }; let p = Point{y: 2, x: 1}; p.y", 2},
+		{"struct Point { x, y }; let p = Point{x: 1, y: 2}; p.x = 5; p.x + p.y", 7},
+		{"struct Point { x, y }; struct Line { from, to }; let l = Line{from: Point{x: 1}, to: Point{y: 4}}; l.to.y", 4},
+		{"struct Point { x, y }; Point{x: 1, y: 2} == Point{x: 1, y: 2}", true},
+		{"struct Point { x, y }; Point{x: 1, y: 2} == Point{x: 1, y: 3}", false},
+		{"struct Point { x, y }; Point{x: 1} != Point{x: 1}", false},
+		{"struct Point { x, y }; let p = Point{x: 1}; let q = p; q.x = 3; p.x", 3},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestTraits(t *testing.T) {
+	shapes := `
+	struct Circle { r }
+	struct Square { side }
+
+	trait Shape {
+		fn area(self)
+		fn describe(self) {
+			return "area" + self.describeArea();
+		}
+	}
+
+	impl Shape for Circle {
+		fn area(self) { self.r * self.r * 3 }
+	}
+
+	impl Shape for Square {
+		fn area(self) { self.side * self.side }
+		fn describe(self) { "square" }
+	}
+
+	impl Circle {
+		fn grow(self, by = 1) {
+			self.r = self.r + by;
+			return self;
+		}
+
+		fn describeArea(self) {
+			if (self.area() > 10) { return "big"; }
+			return "small";
+		}
+	}
+
+	struct Version { major }
+	impl Comparable for Version {
+		fn compare(self, other) { self.major - other.major }
+	}
+`
+	tests := []vmTestCase{
+		{shapes + "Circle{r: 2}.area()", 12},
+		{shapes + "Square{side: 3}.area()", 9},
+		{shapes + "Circle{r: 2}.describe()", "areabig"},
+		{shapes + "Square{side: 3}.describe()", "square"},
+		{shapes + "let c = Circle{r: 1}; c.grow(); c.r", 2},
+		{shapes + "Circle{r: 1}.grow(by: 2).area()", 27},
+		{shapes + "Version{major: 1} < Version{major: 2}", true},
+		{shapes + "Version{major: 1} > Version{major: 2}", false},
+		{"struct Box { f } fn five() { 5 } Box{f: five}.f()", 5},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestStringerInspect(t *testing.T) {
+	input := `
+	struct Point { x, y }
+	impl Stringer for Point {
+		fn string(self) { "P(" + self.x + "," + self.y + ")" }
+	}
+	struct Plain { x }
+	`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input + `Point{x: "1", y: "2"};`, "P(1,2)"},
+		{input + "Plain{x: 1};", "Plain{x: 1}"},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		if err := comp.Compile(parse(tt.input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := New(comp.Bytecode())
+		if err := vm.Run(); err != nil {
+			t.Fatalf("vm error: %s", err)
+		}
+
+		if inspected := vm.LastPoppedStackElem().Inspect(); inspected != tt.expected {
+			t.Errorf("wrong inspection. want=%q, got=%q", tt.expected, inspected)
+		}
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	tests := []vmTestCase{
+		{"fn five() { 5 }; five()", 5},
+		{"fn add(a, b) { return a + b; }; add(1, 2)", 3},
+		{"fn add(a, b) { a + b }; fn twice(x) { add(x, x) }; twice(4) + 1", 9},
+		{"fn local() { let x = 2; let y = 3; x * y }; local()", 6},
+		{"let x = 10; fn shadow(x) { x + 1 }; shadow(1) + x", 12},
+		{"fn early(x) { if (x > 1) { return 1; } return 2; }; early(5) + early(0)", 3},
+		{"fn fib(n) { if (n < 2) { return n; } return fib(n - 1) + fib(n - 2); }; fib(15)", 610},
+		{"fn nothing() { let x = 1; }; nothing() == nothing()", true},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestFunctionArguments(t *testing.T) {
+	tests := []vmTestCase{
+		{"fn f(a, b = 2) { a * 10 + b }; f(1)", 12},
+		{"fn f(a, b = 2) { a * 10 + b }; f(1, 3)", 13},
+		{"fn f(a, b = a * 2) { let c = 1; a * 10 + b + c }; f(3)", 37},
+		{"fn f(a, b) { a * 10 + b }; f(b: 1, a: 2)", 21},
+		{"fn f(a, b = 2, c = 3) { a * 100 + b * 10 + c }; f(1, c: 5)", 125},
+		{"fn sum(...values) { let total = 0; for v in values { total = total + v; } total }; sum(1, 2, 3)", 6},
+		{"fn sum(...values) { let total = 0; for v in values { total = total + v; } total }; sum()", 0},
+		{"fn f(a, ...rest) { a + rest[-1] }; f(1, 2, 3)", 4},
+		{"fn f([a, b] = [1, 2]) { a + b }; f() + f([3, 4])", 10},
+		{"fn count(...values) { for v in values { yield v; } }; let n = 0; for v in count(4, 5) { n = n + v; }; n", 9},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestPipelines(t *testing.T) {
+	helpers := "fn map(list, f) { for x in list { yield f(x); } }; fn sum(values) { let total = 0; for v in values { total = total + v; } total }; fn add(a, b) { a + b }; "
+
+	tests := []vmTestCase{
+		{helpers + "let xs = [1, 2, 3]; xs |> sum", 6},
+		{helpers + "let xs = [1, 2, 3]; xs |> map(|x| x * 2) |> sum() |> add(1)", 13},
+		{helpers + "let double = x => x * 2; [1, 2] |> map(double) |> sum", 6},
+		{helpers + "let pairs = [[1, 2], [3, 4]]; pairs |> map(|[a, b]| a * b) |> sum", 14},
+		{helpers + "5 |> |x| { return x + 1; }", 6},
+		{"let f = || 7; f()", 7},
+		{"let sign = |x| if (x > 0) { 1 } else { 2 }; sign(3) * 10 + sign(0)", 12},
+		{"fn pick(x) { if (x) { 5 } else { 6 } }; pick(true) * 10 + pick(false)", 56},
+		// the builtins split, map, filter, sum and toInt
+		{`let data = "1\n2\n3"; data |> split("\n") |> map(toInt) |> sum()`, 6},
+		{`"4,5,6" |> split(",") |> map(toInt) |> filter(|x| x > 4) |> map(x => x * 2) |> sum`, 22},
+		{"[] |> sum()", 0},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestWhileStatements(t *testing.T) {
+	tests := []vmTestCase{
+		{"let i = 0; while (i < 10) { i = i + 1; }; i", 10},
+		{"fn sum(n) { let total = 0; let i = 0; while (i < n) { i = i + 1; total = total + i; } return total; }; sum(100)", 5050},
+		{"fn negate() { let i = 0; let x = 0; while (i < 3) { x = -1; i = i + 1; } return x; }; negate()", -1},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestStrings(t *testing.T) {
+	tests := []vmTestCase{
+		{`"curry"`, "curry"},
+		{`"cur" + "ry"`, "curry"},
+		{`fn greet(name) { "hi " + name }; greet("you")`, "hiyou"},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestCall(t *testing.T) {
+	program := parse("fn add(a, b) { a + b }; let ten = 10;")
+	comp := compiler.New()
+	err := comp.Compile(program)
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	machine := New(comp.Bytecode())
+	err = machine.Run()
+	if err != nil {
+		t.Fatalf("vm error: %s", err)
+	}
+
+	ten, ok := machine.Global("ten")
+	if !ok {
+		t.Fatalf("global ten is not defined")
+	}
+
+	add, ok := machine.Global("add")
+	if !ok {
+		t.Fatalf("global add is not defined")
+	}
+
+	result, err := machine.Call(add, ten, &object.Integer{Value: 5})
+	if err != nil {
+		t.Fatalf("vm error: %s", err)
+	}
+	testExpectedObject(t, 15, result)
+
+	_, err = machine.Call(add, ten)
+	if err == nil || err.Error() != "wrong number of arguments for add: want=2, got=1" {
+		t.Errorf("wrong error. got=%v", err)
+	}
+
+	double := &object.Builtin{Name: "double", Function: func(position token.Token, args ...object.Object) object.Object {
+		return &object.Integer{Value: args[0].(*object.Integer).Value * 2}
+	}}
+
+	result, err = machine.Call(double, ten)
+	if err != nil {
+		t.Fatalf("vm error: %s", err)
+	}
+	testExpectedObject(t, 20, result)
+}
+
+func TestListBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+	}{
+		{`toInt("x")`, object.ARGUMENT_ERROR, `toInt can not parse "x" as integer`},
+		{`sum(["a"])`, object.TYPE_ERROR, "sum expects a list of integers but got STRING"},
+		{`map([1, 2], |x| if (x > 1) { "b" } else { 1 })`, object.TYPE_ERROR, "map has to return values of the same type, value #1 has type STRING instead of INTEGER"},
+		{`filter([1], |x| x)`, object.TYPE_ERROR, "filter expects the function to return a boolean but got INTEGER"},
+		{`map([1], |x| x + true)`, object.TYPE_ERROR, "unsupported types for binary operation: INTEGER BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		if err := comp.Compile(parse(tt.input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		err := New(comp.Bytecode()).Run()
+		runtimeErr, ok := err.(*object.Error)
+		if !ok {
+			t.Fatalf("expected a runtime error for %q, got %v", tt.input, err)
+		}
+
+		if runtimeErr.Kind != tt.kind || runtimeErr.Message != tt.message {
+			t.Errorf("wrong error for %q. want=%s: %s, got=%s: %s", tt.input, tt.kind, tt.message, runtimeErr.Kind, runtimeErr.Message)
+		}
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+		stack   []string
+	}{
+		{"1 + true", object.TYPE_ERROR, "unsupported types for binary operation: INTEGER BOOLEAN", nil},
+		{
+			"fn inner() { 1 + true }; fn outer() { inner() }; outer()",
+			object.TYPE_ERROR,
+			"unsupported types for binary operation: INTEGER BOOLEAN",
+			[]string{"inner", "outer"},
+		},
+		{"fn one(a) { a }; fn call() { one() }; call()", object.ARGUMENT_ERROR, "wrong number of arguments for one: want=1, got=0", []string{"call"}},
+		{"let x = 1; x()", object.TYPE_ERROR, "calling non-function: INTEGER", nil},
+		{"fn f(a, b = 1) { a }; f(1, 2, 3)", object.ARGUMENT_ERROR, "wrong number of arguments for f: want=1..2, got=3", nil},
+		{"fn f(a, b) { a }; f(b: 1)", object.ARGUMENT_ERROR, "missing argument a for f", nil},
+		{"fn f(a) { a }; f(c: 1)", object.ARGUMENT_ERROR, "function f has no parameter c", nil},
+		{"fn f(...rest) { 1 }; f(1, true)", object.TYPE_ERROR, "variadic arguments of f have to be all of the same type, argument #1 has type BOOLEAN instead of INTEGER", nil},
+		{"struct Circle { r }; Circle{r: 1}.area()", object.FIELD_ERROR, "struct Circle has no method area", nil},
+		{"struct Circle { r }; Circle{r: 1} > Circle{r: 2}", object.TYPE_ERROR, "struct Circle does not implement trait Comparable", nil},
+		{"struct Circle { r } impl Circle { fn area(self) { self.r + true } } Circle{r: 1}.area()", object.TYPE_ERROR, "unsupported types for binary operation: INTEGER BOOLEAN", []string{"area"}},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		err := comp.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		err = New(comp.Bytecode()).Run()
+
+		runtimeErr, ok := err.(*object.Error)
+		if !ok {
+			t.Errorf("error is not object.Error. got=%T (%+v)", err, err)
+			continue
+		}
+
+		if runtimeErr.Kind != tt.kind || runtimeErr.Message != tt.message {
+			t.Errorf("wrong error. want=%s: %s, got=%s: %s", tt.kind, tt.message, runtimeErr.Kind, runtimeErr.Message)
+		}
+
+		var stack []string
+		for _, frame := range runtimeErr.Stack {
+			stack = append(stack, frame.Function)
+		}
+
+		if fmt.Sprint(stack) != fmt.Sprint(tt.stack) {
+			t.Errorf("wrong stack. want=%v, got=%v", tt.stack, stack)
+		}
+	}
+}
+
+func TestRuntimeErrorPositions(t *testing.T) {
+	comp := compiler.New()
+	comp.File = "main.curry"
+
+	err := comp.Compile(parse("fn inner(a) {\n  a + true\n}\nfn outer() {\n  inner(1)\n}\nouter()"))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	err = New(comp.Bytecode()).Run()
+
+	runtimeErr, ok := err.(*object.Error)
+	if !ok {
+		t.Fatalf("error is not object.Error. got=%T (%+v)", err, err)
+	}
+
+	expected := `Traceback (most recent call last):
+  main.curry:7:1 in <main>
+  main.curry:5:3 in outer
+  main.curry:2:5 in inner
+TypeError: unsupported types for binary operation: INTEGER BOOLEAN`
+
+	if runtimeErr.Traceback() != expected {
+		t.Errorf("wrong traceback.\nwant=%s\ngot=%s", expected, runtimeErr.Traceback())
+	}
+}
+
+func TestSpawnedTaskTraceback(t *testing.T) {
+	comp := compiler.New()
+	comp.File = "main.curry"
+
+	err := comp.Compile(parse("let ch = channel();\nspawn fn() {\n    1 + true;\n};\nch.receive();"))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	err = New(comp.Bytecode()).Run()
+
+	runtimeErr, ok := err.(*object.Error)
+	if !ok {
+		t.Fatalf("error is not object.Error. got=%T (%+v)", err, err)
+	}
+
+	expected := `Traceback (most recent call last):
+  main.curry:2:1 in <main>
+  main.curry:3:7 in <anonymous>
+TypeError: unsupported types for binary operation: INTEGER BOOLEAN`
+
+	if runtimeErr.Traceback() != expected {
+		t.Errorf("wrong traceback.\nwant=%s\ngot=%s", expected, runtimeErr.Traceback())
+	}
+}
+
+func TestTryCatch(t *testing.T) {
+	tests := []vmTestCase{
+		{"let x = 1; try { x = 2; } catch { x = 3; }; x", 2},
+		{"let x = 1; try { 1 + true; x = 2; } catch { x = 3; }; x", 3},
+		{"let x = 0; try { throw 5; } catch (e) { x = e.value; }; x", 5},
+		{`let x = ""; try { 1 + true; } catch (e) { x = e.kind; }; x`, "TypeError"},
+		{`let x = ""; try { throw Error{kind: "Custom"}; } catch (e) { x = e.kind; }; x`, "Custom"},
+		{"fn f() { throw 1; }; let x = 0; try { f(); x = 1; } catch { x = 2; }; x", 2},
+		{"fn g() { throw 1; }; fn f() { g(); 5 }; let x = 0; try { f(); } catch (e) { x = 7; }; x", 7},
+		{"fn f(a) { let b = 2; try { throw a; } catch (e) { return e.value + b; } }; f(3)", 5},
+		{"fn f() { try { throw 1; } catch (e) { throw e; } }; let x = 0; try { f(); } catch (e) { x = e.value; }; x", 1},
+		{"let x = 0; while (x < 3) { try { throw x; } catch { x = x + 1; } }; x", 3},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestUncaughtThrow(t *testing.T) {
+	comp := compiler.New()
+	err := comp.Compile(parse("fn f() { try { 1; } catch { }; throw 42; }; fn g() { f() }; g()"))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	err = New(comp.Bytecode()).Run()
+
+	runtimeErr, ok := err.(*object.Error)
+	if !ok {
+		t.Fatalf("error is not object.Error. got=%T (%+v)", err, err)
+	}
+
+	if runtimeErr.Kind != object.THROWN_ERROR || runtimeErr.Message != "42" {
+		t.Errorf("wrong error. got=%s: %s", runtimeErr.Kind, runtimeErr.Message)
+	}
+
+	if len(runtimeErr.Stack) != 2 || runtimeErr.Stack[0].Function != "f" || runtimeErr.Stack[1].Function != "g" {
+		t.Errorf("wrong stack. got=%+v", runtimeErr.Stack)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		limits object.Limits
+		input  string
+		kind   object.ErrorKind
+	}{
+		{object.Limits{MaxSteps: 1000}, "while (true) { }", object.STEP_LIMIT_ERROR},
+		{object.Limits{MaxSteps: 1000}, "let x = 0; while (true) { try { x = x + 1; } catch { } }", object.STEP_LIMIT_ERROR},
+		{object.Limits{MaxCallDepth: 10}, "fn f(n) { f(n + 1) }; f(0)", object.CALL_DEPTH_ERROR},
+		{object.Limits{MaxCallDepth: 10}, "fn f(n) { try { f(n + 1) } catch { 1 } }; f(0)", object.CALL_DEPTH_ERROR},
+		{object.Limits{MaxAllocations: 10}, "struct P { x }; while (true) { P{x: 1}; }", object.ALLOCATION_LIMIT_ERROR},
+		{object.Limits{MaxAllocations: 10}, `let s = ""; while (true) { s = s + "a"; }`, object.ALLOCATION_LIMIT_ERROR},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		err := comp.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		machine := New(comp.Bytecode())
+		machine.Limits = tt.limits
+
+		err = machine.Run()
+
+		runtimeErr, ok := err.(*object.Error)
+		if !ok {
+			t.Errorf("error is not object.Error. got=%T (%+v)", err, err)
+			continue
+		}
+
+		if runtimeErr.Kind != tt.kind {
+			t.Errorf("wrong error kind for %q. want=%s, got=%s (%s)", tt.input, tt.kind, runtimeErr.Kind, runtimeErr.Message)
+		}
+	}
+}
+
+func TestStackOverflow(t *testing.T) {
+	tests := []string{
+		"fn f(n) { f(n + 1) }; f(0)",
+		"fn f(n) { try { f(n + 1) } catch { 1 } }; f(0)",
+	}
+
+	for _, input := range tests {
+		comp := compiler.New()
+		if err := comp.Compile(parse(input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		// the stack overflows without a call depth limit as well
+		err := New(comp.Bytecode()).Run()
+
+		runtimeErr, ok := err.(*object.Error)
+		if !ok {
+			t.Fatalf("error is not object.Error. got=%T (%+v)", err, err)
+		}
+
+		if runtimeErr.Kind != object.STACK_OVERFLOW_ERROR || runtimeErr.Message != "stack overflow" {
+			t.Errorf("wrong error for %q. got=%s: %s", input, runtimeErr.Kind, runtimeErr.Message)
+		}
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	comp := compiler.New()
+	err := comp.Compile(parse("while (true) { }"))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	machine := New(comp.Bytecode())
+	machine.Limits.Context = ctx
+
+	err = machine.Run()
+
+	runtimeErr, ok := err.(*object.Error)
+	if !ok {
+		t.Fatalf("error is not object.Error. got=%T (%+v)", err, err)
+	}
+
+	if runtimeErr.Kind != object.DEADLINE_ERROR {
+		t.Errorf("wrong error kind. want=%s, got=%s", object.DEADLINE_ERROR, runtimeErr.Kind)
+	}
+}
+
+func TestChannels(t *testing.T) {
+	tests := []vmTestCase{
+		{"let ch = channel(); spawn fn() { ch.send(5); }; ch.receive()", 5},
+		{"let ch = channel(2); ch.send(1); ch.send(2); ch.receive() + ch.receive() * 10", 21},
+		{"let ch = channel(1); ch.send(3); ch.close(); ch.receive()", 3},
+		{"let ch = channel(2); ch.send(1); ch.length + channel(3).capacity", 4},
+		{"let ch = channel(); fn double(n) { ch.send(n * 2); }; let x = 4; spawn double(x); x = 10; ch.receive()", 8},
+		{"let counter = 0; let done = channel(); spawn fn() { counter = counter + 1; done.send(1); }; done.receive(); counter", 1},
+		{`
+			let jobs = channel();
+			let results = channel();
+			spawn fn() {
+				let i = 1;
+				while (i < 4) {
+					jobs.send(i);
+					i = i + 1;
+				}
+				jobs.send(0);
+			};
+			spawn fn() {
+				let sum = 0;
+				let job = jobs.receive();
+				while (job > 0) {
+					sum = sum + job;
+					job = jobs.receive();
+				}
+				results.send(sum);
+			};
+			results.receive()
+		`, 6},
+		{"let ch = channel(); let x = 0; select { case v = ch.receive() { x = v; } default { x = 7; } } x", 7},
+		{"let ch = channel(1); select { case ch.send(4) { } } ch.receive()", 4},
+		{`
+			let a = channel();
+			let b = channel();
+			spawn fn() { b.send(2); };
+			let x = 0;
+			select {
+				case v = a.receive() { x = v; }
+				case v = b.receive() { x = v * 10; }
+			}
+			x
+		`, 20},
+		{`
+			fn pick(a, b) {
+				select {
+					case v = b.receive() { return v; }
+					case v = a.receive() { return v * 10; }
+				}
+			}
+			let a = channel(1);
+			let b = channel(1);
+			a.send(1);
+			b.send(2);
+			pick(a, b)
+		`, 2},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestChannelErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+	}{
+		{"let ch = channel(); ch.receive()", object.DEADLOCK_ERROR, "all tasks are blocked on channels, deadlock"},
+		{"let ch = channel(); try { ch.receive(); } catch { 1; }", object.DEADLOCK_ERROR, "all tasks are blocked on channels, deadlock"},
+		{"let ch = channel(1); ch.close(); ch.send(1)", object.CHANNEL_ERROR, "send on closed channel"},
+		{"let ch = channel(); spawn fn() { 1 + true; }; ch.receive()", object.TYPE_ERROR, "unsupported types for binary operation: INTEGER BOOLEAN"},
+		{"spawn 5", object.TYPE_ERROR, "spawning non-function: INTEGER"},
+		{"fn f(a) { a }; spawn f()", object.ARGUMENT_ERROR, "wrong number of arguments for spawned f: want=1, got=0"},
+		{"fn f(a, b = 1) { a }; spawn f(1, 2, 3)", object.ARGUMENT_ERROR, "wrong number of arguments for spawned f: want=1..2, got=3"},
+		{"select { case x = 5.receive() { } }", object.TYPE_ERROR, "unsupported type for select case: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		err := comp.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		err = New(comp.Bytecode()).Run()
+
+		runtimeErr, ok := err.(*object.Error)
+		if !ok {
+			t.Errorf("error is not object.Error for %q. got=%T (%+v)", tt.input, err, err)
+			continue
+		}
+
+		if runtimeErr.Kind != tt.kind || runtimeErr.Message != tt.message {
+			t.Errorf("wrong error for %q. want=%s: %s, got=%s: %s", tt.input, tt.kind, tt.message, runtimeErr.Kind, runtimeErr.Message)
+		}
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	count := "fn count(n) { let i = 0; while (i < n) { yield i; i = i + 1; } } "
+
+	tests := []vmTestCase{
+		{count + "let sum = 0; for x in count(5) { sum = sum + x; }; sum", 10},
+		{count + "let gen = count(2); let a = gen.next(); let b = gen.next(); gen.next(); a * 10 + b", 1},
+		{count + `
+			let gen = count(1);
+			gen.next();
+			let before = 0;
+			if (gen.done) { before = 1; }
+			gen.next();
+			let after = 0;
+			if (gen.done) { after = 1; }
+			before * 10 + after
+		`, 1},
+		{"let ch = channel(1); fn g() { ch.send(1); yield 2; } let gen = g(); let before = ch.length; gen.next(); before * 10 + ch.length", 1},
+		{"fn from(n) { while (true) { yield n; n = n + 1; } } fn first(gen) { for x in gen { if (x > 2) { return x; } } } first(from(0))", 3},
+		{count + "fn double(gen) { for x in gen { yield x * 2; } } let sum = 0; for x in double(count(3)) { sum = sum + x; }; sum", 6},
+		{`
+			fn bad() {
+				yield 1;
+				throw 5;
+			}
+			fn consume() {
+				let sum = 0;
+				try {
+					for x in bad() {
+						sum = sum + x;
+					}
+				} catch (e) {
+					sum = sum + e.value * 10;
+				}
+				return sum;
+			}
+			consume()
+		`, 51},
+		// the locals of a suspended generator are kept while other functions use the stack
+		{count + "fn add(a, b) { a + b }; let gen = count(3); gen.next(); add(5, 6); gen.next() + gen.next()", 3},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+		stack   []string
+	}{
+		{"for x in 5 { }", object.TYPE_ERROR, "unsupported type for iteration: INTEGER", nil},
+		{"fn g() { yield 1; 1 + true; }; for x in g() { }", object.TYPE_ERROR, "unsupported types for binary operation: INTEGER BOOLEAN", []string{"g"}},
+		{"let gen = 0; fn g() { gen.next(); yield 1; }; gen = g(); gen.next()", object.GENERATOR_ERROR, "generator g is already running", []string{"g"}},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		err := comp.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		err = New(comp.Bytecode()).Run()
+
+		runtimeErr, ok := err.(*object.Error)
+		if !ok {
+			t.Errorf("error is not object.Error for %q. got=%T (%+v)", tt.input, err, err)
+			continue
+		}
+
+		if runtimeErr.Kind != tt.kind || runtimeErr.Message != tt.message {
+			t.Errorf("wrong error for %q. want=%s: %s, got=%s: %s", tt.input, tt.kind, tt.message, runtimeErr.Kind, runtimeErr.Message)
+		}
+
+		var stack []string
+		for _, frame := range runtimeErr.Stack {
+			stack = append(stack, frame.Function)
+		}
+
+		if fmt.Sprint(stack) != fmt.Sprint(tt.stack) {
+			t.Errorf("wrong stack for %q. want=%v, got=%v", tt.input, tt.stack, stack)
+		}
+	}
+}
+
+func TestForInLoops(t *testing.T) {
+	countdown := "struct Countdown { n } impl Iterator for Countdown { fn next(self) { if (self.n > 0) { self.n = self.n - 1; return self.n + 1; } } } "
+
+	tests := []vmTestCase{
+		{"let sum = 0; for x in [1, 2, 3] { sum = sum + x; }; sum", 6},
+		{"let sum = 0; for i, x in [10, 20, 30] { sum = sum + i * x; }; sum", 80},
+		{"let sum = 0; for x in [] { sum = sum + 1; }; sum", 0},
+		{"let sum = 0; for i in 0..5 { sum = sum + i; }; sum", 10},
+		{"let sum = 0; for i, x in 3..5 { sum = sum + i * x; }; sum", 4},
+		{"let sum = 0; for i in 5..0 { sum = sum + 1; }; sum", 0},
+		{"let sum = 0; for i in 1..=4 { sum = sum + i; }; sum", 10},
+		{"struct Point { x, y } let p = Point{x: 1, y: 2}; let names = \"\"; for name in p { names = names + name; }; names", "xy"},
+		{"struct Point { x, y } let p = Point{x: 1, y: 2}; let sum = 0; for name, value in p { sum = sum + value; }; sum", 3},
+		{"fn total(list) { let sum = 0; for i, x in list { sum = sum + i + x; }; sum }; total([1, 2, 3])", 9},
+		{"fn count(n) { for i in 0..n { yield i * i; } } let sum = 0; for i, x in count(4) { sum = sum + i + x; }; sum", 20},
+		// instances of structs implementing Iterator are iterated by calling next until it returns null
+		{countdown + "let c = Countdown{n: 3}; let sum = 0; for x in c { sum = sum * 10 + x; }; sum", 321},
+		{countdown + "let c = Countdown{n: 2}; let sum = 0; for i, x in c { sum = sum + i * x; }; sum", 1},
+		{countdown + "fn first(c) { for x in c { return x; } } first(Countdown{n: 5})", 5},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestIndexExpressions(t *testing.T) {
+	tests := []vmTestCase{
+		{"[10, 20][1]", 20},
+		{"[10, 20][-1]", 20},
+		{"let l = [1, 2, 3]; l[l[0] + 1]", 3},
+		{"fn last(l) { l[-1] }; last([1, 2, 3])", 3},
+		{"[1, 2, 3, 4][1:3][1]", 3},
+		{"[1, 2, 3, 4][:2][-1]", 2},
+		{"[1, 2, 3, 4][-2:][0]", 3},
+		{`"häll😀"[1]`, "ä"},
+		{`"häll😀"[-1]`, "😀"},
+		{`"häll😀"[1:4]`, "äll"},
+		{`"hello"[:2]`, "he"},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestMatchExpressions(t *testing.T) {
+	describe := `
+		struct Point { x, y }
+		fn describe(v) {
+			match v {
+				0 => "zero",
+				1 | 2 => "small",
+				"a" | "b" => "letter",
+				[] => "empty",
+				[first] => first,
+				[first, ...rest] => first + rest[-1],
+				Point{x: 0, y} => "axis",
+				Point{x, y} if x == y => "diagonal",
+				Point{x, y} => "point",
+				n if n > 100 => "big",
+				_ => "other"
+			}
+		}
+	`
+	names := `
+		fn name(n) {
+			match n {
+				1 | 2 => "low",
+				3 => { return "three"; }
+				"x" => "ex",
+				_ => "many"
+			}
+		}
+	`
+
+	tests := []vmTestCase{
+		{describe + "describe(0)", "zero"},
+		{describe + "describe(2)", "small"},
+		{describe + `describe("b")`, "letter"},
+		{describe + "describe([])", "empty"},
+		{describe + `describe(["x"])`, "x"},
+		{describe + `describe(["x", "y", "z"])`, "xz"},
+		{describe + "describe(Point{x: 0, y: 5})", "axis"},
+		{describe + "describe(Point{x: 3, y: 3})", "diagonal"},
+		{describe + "describe(Point{x: 3, y: 4})", "point"},
+		{describe + "describe(500)", "big"},
+		{describe + "describe(50)", "other"},
+		{names + "name(2)", "low"},
+		{names + "name(3)", "three"},
+		{names + `name("x")`, "ex"},
+		{names + "name(true)", "many"},
+		{"let x = match 5 { n if n > 3 => n * 2, _ => 0 }; x", 10},
+		{"let s = 0; match 10 { 10 => { s = 1; }, _ => { s = 2; } }; s", 1},
+		{"fn f(n) { match n { 1 => 10, 2 => 20 } }; f(2)", 20},
+		// if expressions in arm bodies leave the value of their taken branch
+		// variables bound by an arm are only visible in the arm
+		{"let x = 1; let r = match 5 { x => x * 2 }; x", 1},
+		{"let x = 1; let r = match [5] { [x] if x > 3 => x * 2, _ => 0 }; x * 100 + r", 110},
+		{"fn f(x) { let r = match x + 1 { x => x * 2 }; x * 100 + r }; f(2)", 206},
+		{"match 2 { 1 => 1, _ => if (true) { 5 } else { 6 } }", 5},
+		{"match 4 { n if n > 3 => if (n > 5) { 0 } else { n }, _ => 1 }", 4},
+		{"let r = match 1 { 1 => if (false) { 5 }, _ => 0 }; match r { 0 => 1, 5 => 2, _ => 3 }", 3},
+		{"fn f(n) { match n { 1 => { let a = 2; if (a > 1) { a * 10 } }, _ => if (n > 5) { if (n > 8) { 9 } else { 6 } } else { 0 } } }; f(1) + f(3) + f(7) + f(9)", 35},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestStackUnderflow(t *testing.T) {
+	bytecode := &compiler.Bytecode{Instructions: code.Make(code.OpPop)}
+
+	err := New(bytecode).Run()
+
+	runtimeErr, ok := err.(*object.Error)
+	if !ok || runtimeErr.Kind != object.RUNTIME_ERROR || runtimeErr.Message != "stack underflow in function main" {
+		t.Errorf("wrong error for invalid bytecode. got=%T (%+v)", err, err)
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	structs := "struct Point { x, y } struct Person { name, age } "
+
+	tests := []vmTestCase{
+		{"let [a, b] = [1, 2]; a * 10 + b", 12},
+		{"let [first, ...rest] = [1, 2, 3]; first + rest[0] + rest[1]", 6},
+		{"let [_, second, ...] = [1, 2, 3]; second", 2},
+		{"let [[a, b], [c]] = [[1, 2], [3]]; a + b + c", 6},
+		{structs + "let {age} = Person{name: \"a\", age: 7}; age", 7},
+		{structs + "let Point{x, y: [y]} = Point{x: 3, y: [4]}; x + y", 7},
+		{"fn add([a, b]) { a + b } add([3, 4])", 7},
+		{structs + "fn f(Point{x, y}, k) { let z = x * k; z + y } f(Point{x: 2, y: 1}, 10)", 21},
+		{"fn f() { let [a, b] = [1, 2]; a + b } f()", 3},
+	}
+
+	runVmTests(t, tests, false)
+}
+
+func TestForInLoopErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    object.ErrorKind
+		message string
+	}{
+		{"for x in \"abc\" { }", object.TYPE_ERROR, "unsupported type for iteration: STRING"},
+		{"struct Broken { } impl Iterator for Broken { fn next(self) { 1 + true } } let b = Broken{}; for x in b { }", object.TYPE_ERROR, "unsupported types for binary operation: INTEGER BOOLEAN"},
+		{"[1, true]", object.TYPE_ERROR, "list members have to be all of the same type, value #1 has type BOOLEAN instead of INTEGER"},
+		{"1..true", object.TYPE_ERROR, "unsupported types for range: INTEGER BOOLEAN"},
+		{"[1, 2][2]", object.INDEX_ERROR, "index 2 out of range for length 2"},
+		{"[1, 2][-3]", object.INDEX_ERROR, "index -3 out of range for length 2"},
+		{"[1, 2][1:3]", object.INDEX_ERROR, "slice bounds [1:3] out of range for length 2"},
+		{"5[0]", object.TYPE_ERROR, "values of type INTEGER can not be indexed"},
+		{"match 3 { 1 => 1, 2 => 2 }", object.MATCH_ERROR, "no arm of match matches value 3"},
+		{"match [3] { [a] if a > 3 => 1 }", object.MATCH_ERROR, "no arm of match matches value list<INTEGER>"},
+		{"let [a, b] = [1];", object.MATCH_ERROR, "list of length 1 can not be destructured into [a, b]"},
+		{"fn f([a]) { a } f(5)", object.TYPE_ERROR, "values of type INTEGER can not be destructured into [a]"},
+		{"struct Person { name } let {age} = Person{name: 1};", object.FIELD_ERROR, "struct Person has no field age"},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		err := comp.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		err = New(comp.Bytecode()).Run()
+
+		runtimeErr, ok := err.(*object.Error)
+		if !ok {
+			t.Errorf("error is not object.Error for %q. got=%T (%+v)", tt.input, err, err)
+			continue
+		}
+
+		if runtimeErr.Kind != tt.kind || runtimeErr.Message != tt.message {
+			t.Errorf("wrong error for %q. want=%s: %s, got=%s: %s", tt.input, tt.kind, tt.message, runtimeErr.Kind, runtimeErr.Message)
+		}
+	}
+}
+
+type recordingHook struct {
+	events []string
+	locals []Variable
+}
+
+func (hook *recordingHook) BeforeStatement(vm *VM, statement code.Statement) *object.Error {
+	hook.events = append(hook.events, fmt.Sprintf("line %d depth %d", statement.Line, vm.Depth()))
+
+	if statement.Line == 4 {
+		hook.locals = vm.Frames()[0].Locals
+	}
+
+	return nil
+}
+
+func (hook *recordingHook) EnterFunction(vm *VM, function *object.CompiledFunction) {
+	hook.events = append(hook.events, "enter "+function.Name)
+}
+
+func (hook *recordingHook) LeaveFunction(vm *VM, function *object.CompiledFunction, result object.Object) {
+	hook.events = append(hook.events, "leave "+function.Name+" "+result.Inspect())
+}
+
+func TestHook(t *testing.T) {
+	comp := compiler.New()
+	err := comp.Compile(parse("fn f(a) {\n  let b = a * 2;\n  let c = b;\n  c\n}\nlet x = f(1);"))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	hook := &recordingHook{}
+	vm := New(comp.Bytecode())
+	vm.Hook = hook
+
+	err = vm.Run()
+	if err != nil {
+		t.Fatalf("vm error: %s", err)
+	}
+
+	expected := []string{"line 1 depth 0", "line 6 depth 0", "enter f", "line 2 depth 1", "line 3 depth 1", "line 4 depth 1", "leave f 2"}
+	if fmt.Sprint(hook.events) != fmt.Sprint(expected) {
+		t.Errorf("wrong events. want=%v, got=%v", expected, hook.events)
+	}
+
+	if locals := inspectVariables(hook.locals); locals != "a=1 b=2 c=2" {
+		t.Errorf("wrong locals. got=%s", locals)
+	}
+
+	if globals := inspectVariables(vm.Globals()); globals != "f=compiled fn f x=2" {
+		t.Errorf("wrong globals. got=%s", globals)
+	}
+}
+
+func inspectVariables(variables []Variable) string {
+	pairs := make([]string, 0, len(variables))
+	for _, variable := range variables {
+		pairs = append(pairs, variable.Name+"="+variable.Value.Inspect())
+	}
+
+	return strings.Join(pairs, " ")
+}
+
 func parse(input string) *ast.Program {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -136,6 +1051,14 @@ func testExpectedObject(t *testing.T, expected interface{}, actual object.Object
 		if err != nil {
 			t.Errorf("testBooleanObject failed: %s", err)
 		}
+
+	case string:
+		str, ok := actual.(*object.String)
+		if !ok {
+			t.Errorf("object is not String. got=%T (%+v)", actual, actual)
+		} else if str.Value != expected {
+			t.Errorf("object has wrong value. got=%q, want=%q", str.Value, expected)
+		}
 	}
 }
 
@@ -161,3 +1084,44 @@ func testBooleanObject(expected bool, actual object.Object) error {
 
 	return nil
 }
+
+type countingHook struct {
+	statements int
+}
+
+func (hook *countingHook) BeforeStatement(vm *VM, statement code.Statement) *object.Error {
+	hook.statements++
+	return nil
+}
+
+func (hook *countingHook) EnterFunction(vm *VM, function *object.CompiledFunction) {}
+func (hook *countingHook) LeaveFunction(vm *VM, function *object.CompiledFunction, result object.Object) {
+}
+
+// BenchmarkHookedRun runs a program with many statements and a loop while a hook is notified about every statement,
+// like profilers, tracers and debuggers are
+func BenchmarkHookedRun(b *testing.B) {
+	var source strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&source, "let v%d = %d;\n", i, i)
+	}
+	source.WriteString("let i = 0; while (i < 1000) { i = i + 1; }")
+
+	comp := compiler.New()
+	err := comp.Compile(parse(source.String()))
+	if err != nil {
+		b.Fatalf("compiler error: %s", err)
+	}
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		machine := New(comp.Bytecode())
+		machine.Hook = &countingHook{}
+
+		err := machine.Run()
+		if err != nil {
+			b.Fatalf("vm error: %s", err)
+		}
+	}
+}